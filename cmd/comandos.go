@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/migrations"
+	"primerProjecto/internal/services"
+	"syscall"
+	"time"
+)
+
+// ejecutarComando corre un comando de línea en lugar de levantar el servidor. El comando migrate se corre
+// antes, en main, porque el resto de los comandos necesitan el esquema actualizado.
+func ejecutarComando(args []string, serviceBackfill *services.BackfillService) error {
+	switch args[0] {
+	case "backfill":
+		return comandoBackfill(args[1:], serviceBackfill)
+	}
+	return fmt.Errorf("comando %s desconocido, los comandos disponibles son: backfill, migrate", args[0])
+}
+
+// comandoBackfill carga cotizaciones históricas en primer plano. Crea el backfill, o toma el pendiente
+// que ya cubre el rango pedido, y lo corre hasta el final. Con -reanudar sigue uno existente desde
+// su cursor. Ctrl+C deja el backfill pendiente para seguirlo después.
+//
+//	go run ./cmd backfill -moneda Bitcoin -desde 2024-01-01 -hasta 2024-03-31 [-resolucion horaria] [-api coingecko] [-fiat USD]
+//	go run ./cmd backfill -reanudar 7
+func comandoBackfill(args []string, service *services.BackfillService) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	moneda := flags.String("moneda", "", "nombre de la criptomoneda")
+	desde := flags.String("desde", "", "inicio del rango, AAAA-MM-DD o RFC3339")
+	hasta := flags.String("hasta", "", "fin del rango, AAAA-MM-DD o RFC3339, por defecto ahora")
+	resolucion := flags.String("resolucion", "diaria", "diaria u horaria")
+	api := flags.String("api", "", "proveedor con historia, por ejemplo coinpaprika o coingecko")
+	fiat := flags.String("fiat", "", "moneda fiat, por defecto USD")
+	reanudar := flags.Int("reanudar", 0, "id de un backfill existente para seguir desde su cursor")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	id := *reanudar
+	if id == 0 {
+		backfill := criptomonedas.Backfill{Moneda: *moneda, Resolucion: *resolucion, Api: *api, Fiat: *fiat, Hasta: time.Now()}
+		var err error
+		if backfill.Desde, err = parsearFechaComando(*desde); err != nil {
+			return fmt.Errorf("-desde inválido: %w", err)
+		}
+		if *hasta != "" {
+			if backfill.Hasta, err = parsearFechaComando(*hasta); err != nil {
+				return fmt.Errorf("-hasta inválido: %w", err)
+			}
+		}
+		creado, err := service.CrearBackfill(backfill)
+		if err != nil {
+			return err
+		}
+		id = creado.Id
+	} else if _, err := service.Reanudar(id); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("Corriendo el backfill %d\n", id)
+	backfill, err := service.CorrerBackfill(ctx, id)
+	if backfill != nil {
+		fmt.Printf("Backfill %d %s: cursor %s, %d cotizaciones nuevas, %d ya guardadas\n",
+			backfill.Id, backfill.Estado, backfill.Cursor.Format(time.RFC3339), backfill.Guardadas, backfill.Omitidas)
+	}
+	return err
+}
+
+// comandoMigrate aplica las migraciones pendientes, revierte las últimas o lista el estado de todas.
+//
+//	go run ./cmd migrate up
+//	go run ./cmd migrate down [-pasos 1]
+//	go run ./cmd migrate status
+func comandoMigrate(args []string, migrador *migrations.Migrador) error {
+	if len(args) == 0 {
+		return errors.New("falta el subcomando de migrate: up, down o status")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		subidas, err := migrador.Subir(ctx)
+		for _, migracion := range subidas {
+			fmt.Printf("Aplicada %04d_%s\n", migracion.Version, migracion.Nombre)
+		}
+		if err == nil && len(subidas) == 0 {
+			fmt.Println("El esquema ya estaba actualizado")
+		}
+		return err
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		pasos := flags.Int("pasos", 1, "cantidad de migraciones a revertir, de la más nueva a la más vieja")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		bajadas, err := migrador.Bajar(ctx, *pasos)
+		for _, migracion := range bajadas {
+			fmt.Printf("Revertida %04d_%s\n", migracion.Version, migracion.Nombre)
+		}
+		if err == nil && len(bajadas) == 0 {
+			fmt.Println("No hay migraciones aplicadas")
+		}
+		return err
+	case "status":
+		estados, err := migrador.Estado(ctx)
+		if err != nil {
+			return err
+		}
+		for _, estado := range estados {
+			nombre, situacion := estado.Nombre, "pendiente"
+			if nombre == "" {
+				nombre = "(desconocida)"
+			}
+			if estado.Aplicada != nil {
+				situacion = "aplicada " + estado.Aplicada.Format(time.RFC3339)
+			}
+			if estado.Sucia {
+				situacion = "sucia"
+			}
+			fmt.Printf("%04d_%-30s %s\n", estado.Version, nombre, situacion)
+		}
+		return nil
+	}
+	return fmt.Errorf("subcomando migrate %s desconocido, los disponibles son: up, down y status", args[0])
+}
+
+func parsearFechaComando(valor string) (time.Time, error) {
+	if fecha, err := time.Parse("2006-01-02", valor); err == nil {
+		return fecha, nil
+	}
+	return time.Parse(time.RFC3339, valor)
+}
@@ -1,8 +1,16 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "primerProjecto/docs"
 
@@ -10,7 +18,7 @@ import (
 
 	controllers "primerProjecto/internal/adapters/controllers"
 	"primerProjecto/internal/adapters/cotizadores"
-	repositories "primerProjecto/internal/adapters/repositories"
+	"primerProjecto/internal/migrations"
 	"primerProjecto/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -32,108 +40,121 @@ func main() {
 		c.File("primerProjecto/docs/swagger.json")
 	})
 
-	// Configurar la conexión a la base de datos MySQL
-	db, err := sql.Open("mysql", "root:1234@tcp(172.18.224.1:3306)/?parseTime=true")
+	// Configurar la conexión a la base de datos, MySQL, SQLite o PostgreSQL según DB_DRIVER
+	baseDatos := configuracionBaseDatos()
+	db, err := abrirBaseDatos(baseDatos)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
 
-	// Intentar crear la base de datos (opcional si ya existe)
-	_, err = db.Exec("CREATE DATABASE IF NOT EXISTS proyecto_cripto")
+	// El esquema se crea y actualiza con go run ./cmd migrate up, ver internal/migrations
+	migrador, err := migrations.NewMigrador(db, baseDatos.Driver)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	// Seleccionar la base de datos recién creada o existente
-	_, err = db.Exec("USE proyecto_cripto")
-	if err != nil {
-		log.Fatal(err)
+	// una base en memoria empieza vacía en cada arranque, así que se migra siempre
+	if baseDatos.enMemoria() {
+		if _, err := migrador.Subir(context.Background()); err != nil {
+			log.Fatal(err)
+		}
 	}
-
-	// Crear tabla monedas
-	_, err = db.Exec(`
-    CREATE TABLE IF NOT EXISTS monedas (
-        id INT AUTO_INCREMENT PRIMARY KEY,
-        nombre VARCHAR(100) NOT NULL
-    )
-`)
-	if err != nil {
-		log.Fatal(err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := comandoMigrate(os.Args[2:], migrador); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
-
-	// Crear tabla cotizaciones
-	_, err = db.Exec(`
-    CREATE TABLE IF NOT EXISTS cotizaciones (
-    id INT AUTO_INCREMENT PRIMARY KEY,
-    cripto_id INT NOT NULL,
-    cotizacion DECIMAL(10, 2) NOT NULL,
-    fecha DATETIME NOT NULL,
-    manual BOOLEAN NOT NULL DEFAULT FALSE,
-    usuario_id INT DEFAULT NULL,
-    FOREIGN KEY (cripto_id) REFERENCES monedas(id),
-    FOREIGN KEY (usuario_id) REFERENCES usuarios(id)
-)
-`)
-	if err != nil {
-		log.Fatal(err)
+	if err := migrador.Verificar(context.Background()); err != nil {
+		log.Fatalf("%s; correr go run ./cmd migrate up antes de levantar el servidor", err)
 	}
 
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS usuarios (
-    id INT AUTO_INCREMENT PRIMARY KEY,
-    nombre VARCHAR(100) NOT NULL,
-    apellidos VARCHAR(100) NOT NULL,
-    fecha_nacimiento DATE NOT NULL,
-    codigo_usuario VARCHAR(100) NOT NULL UNIQUE,
-    email VARCHAR(255) NOT NULL UNIQUE,
-    tipo_documento ENUM('DNI', 'pasaporte', 'cedula') NOT NULL,
-    fecha_registro DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    esta_activo BOOLEAN NOT NULL DEFAULT TRUE
-    )
-`)
-	if err != nil {
-		log.Fatal(err)
+	// Cotizadores HTTP/JSON definidos en un archivo, ver config/cotizadores.example.json
+	if config := os.Getenv("COTIZADORES_CONFIG"); config != "" {
+		nombres, err := cotizadores.CargarCotizadoresGenericos(config)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Cotizadores cargados desde", config, ":", strings.Join(nombres, ", "))
 	}
 
-	_, err = db.Exec(`
-    CREATE TABLE IF NOT EXISTS usuario_moneda (
-            usuario_id INT NOT NULL,
-            moneda_id INT NOT NULL,
-            PRIMARY KEY (usuario_id, moneda_id),
-            FOREIGN KEY (usuario_id) REFERENCES usuarios(id),
-            FOREIGN KEY (moneda_id) REFERENCES monedas(id)
-    )
-`)
-	if err != nil {
-		log.Fatal(err)
+	// Orden de proveedores del cotizador fallback, por ejemplo COTIZADORES_FALLBACK=criptoya,coinpaprika
+	if orden := os.Getenv("COTIZADORES_FALLBACK"); orden != "" {
+		cotizadores.CotizadoresMap["fallback"] = cotizadores.NewFallbackCotizador(strings.Split(orden, ","))
 	}
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS auditoria_cotizacion (
-		id BIGINT AUTO_INCREMENT PRIMARY KEY,
-		usuario_id INT,
-		cotizacion_id INT,
-		log TEXT NOT NULL,
-		created_at DATETIME DEFAULT NOW(),
-		FOREIGN KEY (usuario_id) REFERENCES usuarios(id) ON DELETE SET NULL,
-		FOREIGN KEY (cotizacion_id) REFERENCES cotizaciones(id) ON DELETE SET NULL
-	)
-`)
-	if err != nil {
-		log.Fatal(err)
+
+	// Snapshot en disco de la lista de monedas de CoinPaprika para arrancar sin descargarla
+	if snapshot := os.Getenv("COINPAPRIKA_SNAPSHOT"); snapshot != "" {
+		paprika := cotizadores.NewCoinPaprikaCotizador(nil, "", 0).ConLimitador(cotizadores.LimitadorPara("coinpaprika"))
+		paprika.ConfigurarIndice(cotizadores.TTLIndicePorDefecto, snapshot)
+		cotizadores.CotizadoresMap["coinpaprika"] = paprika
 	}
 
-	// Crear las instancias de los repositorios
-	repoUsuario := repositories.NewMySQLUsuarioRepository(db)
-	repoCripto := repositories.NewMySQLCryptoRepository(db)
+	// Crear las instancias de los repositorios del motor configurado
+	repos := nuevosRepositorios(db, baseDatos.Driver)
+	repoUsuario := repos.usuario
+	repoCripto := repos.cripto
+	repoExchange := repos.exchange
+	repoFiat := repos.fiat
+	repoPoliticas := repos.politicas
+	repoLiderazgo := repos.liderazgo
+	repoBackfill := repos.backfill
+	repoCuarentena := repos.cuarentena
 
 	// Crear las instancias de los servicios usando las interfaces
 	serviceUsuario := services.NewUsuarioService(repoUsuario, repoCripto)
+	serviceAnomalias := services.NewAnomaliasService(repoCuarentena, repoCripto, configuracionAnomalias())
 	serviceCripto := services.NewCryptoService(repoCripto, cotizadores.GetCotizador)
+	if os.Getenv("ANOMALIAS_FILTRO") != "0" {
+		serviceCripto.ConFiltroAnomalias(serviceAnomalias)
+	}
+	serviceCripto.ConFrescura(repoPoliticas, configuracionFrescura())
+	serviceCripto.ConCotizadorBatch(cotizadores.GetCotizadorBatch)
+	serviceExchange := services.NewExchangeService(repoExchange, repoCripto, cotizadores.NewCryptoYaCotizador(nil, "", 0).ConLimitador(cotizadores.LimitadorPara("criptoya")))
+	serviceArbitraje := services.NewArbitrajeService(serviceExchange, repoCripto)
+	serviceFiat := services.NewFiatService(repoFiat, repoCripto, cotizadores.NewCadenaCotizadorFiat(
+		cotizadores.NewDolarCotizadorFiat(nil, "", 0).ConLimitador(cotizadores.LimitadorPara("criptoya")),
+		cotizadores.NewFrankfurterCotizadorFiat(nil, "", 0).ConLimitador(cotizadores.LimitadorPara("frankfurter")),
+	))
+	configPoller := configuracionPoller()
+	servicePoller := services.NewPollerService(serviceCripto, repoCripto, repoUsuario, repoPoliticas, configPoller)
+	servicePoliticas := services.NewPoliticaRefrescoService(repoPoliticas, repoCripto, cotizadores.GetCotizador)
+	configLiderazgo := configuracionLiderazgo()
+	serviceBackfill := services.NewBackfillService(repoBackfill, repoCripto, cotizadores.GetCotizadorHistorico, services.ConfiguracionBackfillPorDefecto).
+		ConLease(repoLiderazgo, configLiderazgo.Instancia)
+	serviceCalidad := services.NewCalidadDatosService(repoCripto, repoPoliticas, configPoller, serviceBackfill)
+
+	// Comandos de línea, por ejemplo go run ./cmd backfill -moneda Bitcoin -desde 2024-01-01 -hasta 2024-03-31
+	if len(os.Args) > 1 {
+		if err := ejecutarComando(os.Args[1:], serviceBackfill); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Solo la instancia líder corre los trabajos programados, así varias réplicas no guardan cotizaciones
+	// repetidas. El refresco de cotizaciones se desactiva con POLLER_INTERVALO=0.
+	trabajos := []services.TrabajoProgramado{serviceBackfill}
+	if !configPoller.Desactivado {
+		if err := servicePoller.Validar(); err != nil {
+			log.Fatal(err)
+		}
+		trabajos = append(trabajos, servicePoller)
+	}
+	serviceLider := services.NewLiderService(repoLiderazgo, configLiderazgo, trabajos...)
 
 	//handlers/controllers
 	criptoHandler := controllers.NewCryptoController(serviceCripto)
 	usuarioHandler := controllers.NewUsuarioHandler(serviceUsuario)
+	exchangeHandler := controllers.NewExchangeController(serviceExchange)
+	arbitrajeHandler := controllers.NewArbitrajeController(serviceArbitraje)
+	fiatHandler := controllers.NewFiatController(serviceFiat)
+	pollerHandler := controllers.NewPollerController(servicePoller)
+	politicaHandler := controllers.NewPoliticaRefrescoController(servicePoliticas)
+	liderazgoHandler := controllers.NewLiderazgoController(serviceLider)
+	backfillHandler := controllers.NewBackfillController(serviceBackfill)
+	calidadHandler := controllers.NewCalidadDatosController(serviceCalidad)
+	cuarentenaHandler := controllers.NewCuarentenaController(serviceAnomalias)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	// Configurar tus rutas y controladores
@@ -166,6 +187,35 @@ func main() {
 	router.POST("/cotization", services.AuthMiddleware(), criptoHandler.RegistrarCotizacion)
 	router.POST("/cryptocurrencies/externa", services.AuthMiddleware(), criptoHandler.SaveMonedaConCotizacion)
 	router.POST("/cotization/externa", services.AuthMiddleware(), criptoHandler.SaveCotizacionExterna)
+	router.POST("/cotization/externa/batch", services.AuthMiddleware(), criptoHandler.RefrescarCotizaciones)
+	router.GET("/cotization/agregada", criptoHandler.GetCotizacionAgregada)
+	router.GET("/cotizadores/circuitos", criptoHandler.FindEstadoCircuitos)
+	router.GET("/cotizadores/uso", criptoHandler.FindUsoProveedores)
+	router.GET("/poller/estado", pollerHandler.FindEstado)
+	router.GET("/liderazgo", liderazgoHandler.FindEstado)
+
+	//carga de cotizaciones históricas
+	router.GET("/backfills", backfillHandler.FindAllBackfills)
+	router.GET("/backfills/:id", backfillHandler.FindBackfillByID)
+	router.POST("/backfills", services.AuthMiddleware(), backfillHandler.CrearBackfill)
+	router.POST("/backfills/:id/reanudar", services.AuthMiddleware(), backfillHandler.ReanudarBackfill)
+
+	//calidad de la serie de cotizaciones
+	router.GET("/calidad/huecos", calidadHandler.FindHuecos)
+	router.POST("/calidad/huecos/rellenar", services.AuthMiddleware(), calidadHandler.RellenarHuecos)
+
+	//cotizaciones sospechosas retenidas para revisión
+	router.GET("/cuarentena", cuarentenaHandler.FindAllCuarentena)
+	router.GET("/cuarentena/:id", cuarentenaHandler.FindCuarentenaByID)
+	router.POST("/cuarentena/:id/aprobar", services.AuthMiddleware(), cuarentenaHandler.AprobarCuarentena)
+	router.POST("/cuarentena/:id/rechazar", services.AuthMiddleware(), cuarentenaHandler.RechazarCuarentena)
+
+	//políticas de refresco por moneda
+	router.GET("/politicas", politicaHandler.FindAllPoliticas)
+	router.GET("/politicas/:id", politicaHandler.FindPoliticaByMonedaID)
+	router.POST("/politicas", services.AuthMiddleware(), politicaHandler.CrearPolitica)
+	router.PUT("/politicas/:id", services.AuthMiddleware(), politicaHandler.ActualizarPolitica)
+	router.DELETE("/politicas/:id", services.AuthMiddleware(), politicaHandler.BorrarPolitica)
 
 	router.GET("/cryptocurrencies/All", criptoHandler.FindAll)
 	router.GET("/cryptocurrencies/cryptocurrency/:id", criptoHandler.FindMonedaByID)
@@ -174,6 +224,164 @@ func main() {
 	router.GET("/cryptocurrencies/lastcotization/:nombre", criptoHandler.FindUltimaCotizacion)
 	router.PUT("/cryptocurrency/:id", criptoHandler.HandleUpdateCryptoByID)
 
+	//cotizaciones por exchange
+	router.GET("/exchanges", exchangeHandler.FindAllExchanges)
+	router.POST("/exchanges/cotizaciones", services.AuthMiddleware(), exchangeHandler.GuardarCotizacionesExchanges)
+	router.GET("/exchanges/cotizaciones", exchangeHandler.FindCotizacionesExchange)
+	router.GET("/arbitrajes", arbitrajeHandler.FindArbitrajesActuales)
+	router.POST("/arbitrajes", services.AuthMiddleware(), arbitrajeHandler.RefrescarArbitrajes)
+	router.GET("/arbitrajes/historial", arbitrajeHandler.FindHistorialArbitraje)
+
+	//monedas fiat y tipos de cambio
+	router.GET("/fiats", fiatHandler.FindAllFiats)
+	router.GET("/fiats/tasa", fiatHandler.GetTasaFiat)
+	router.GET("/cryptocurrencies/lastcotization/:nombre/convertida", fiatHandler.FindUltimaCotizacionConvertida)
+	router.GET("/cotizaciones/:id/convertida", fiatHandler.ConvertirCotizacion)
+
+	// Al recibir SIGINT o SIGTERM se deja de aceptar solicitudes y se espera a los trabajos programados
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serviceLider.Iniciar(ctx); err != nil {
+		log.Fatal(err)
+	}
+
 	// Iniciar el servidor HTTP
-	router.Run(":8080")
+	server := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Apagando el servidor")
+	apagado, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(apagado); err != nil {
+		log.Println("Error al apagar el servidor:", err)
+	}
+	serviceLider.Detener()
+}
+
+// configuracionPoller arma la configuración del poller a partir de ConfiguracionPollerPorDefecto y las variables
+// POLLER_INTERVALO, POLLER_ALCANCE (todas o seguidas), POLLER_API, POLLER_FIAT, POLLER_TRABAJADORES y POLLER_JITTER.
+func configuracionPoller() services.ConfiguracionPoller {
+	config := services.ConfiguracionPollerPorDefecto
+	config.Desactivado = os.Getenv("POLLER_INTERVALO") == "0"
+	if intervalo := os.Getenv("POLLER_INTERVALO"); intervalo != "" && !config.Desactivado {
+		duracion, err := time.ParseDuration(intervalo)
+		if err != nil {
+			log.Fatalf("POLLER_INTERVALO inválido: %s", err)
+		}
+		config.Intervalo = duracion
+	}
+	if alcance := os.Getenv("POLLER_ALCANCE"); alcance != "" {
+		config.Alcance = alcance
+	}
+	if api := os.Getenv("POLLER_API"); api != "" {
+		config.Api = api
+	}
+	if fiat := os.Getenv("POLLER_FIAT"); fiat != "" {
+		config.Fiat = fiat
+	}
+	if trabajadores := os.Getenv("POLLER_TRABAJADORES"); trabajadores != "" {
+		cantidad, err := strconv.Atoi(trabajadores)
+		if err != nil {
+			log.Fatalf("POLLER_TRABAJADORES inválido: %s", err)
+		}
+		config.Trabajadores = cantidad
+	}
+	if jitter := os.Getenv("POLLER_JITTER"); jitter != "" {
+		duracion, err := time.ParseDuration(jitter)
+		if err != nil {
+			log.Fatalf("POLLER_JITTER inválido: %s", err)
+		}
+		config.Jitter = duracion
+	}
+	return config
+}
+
+// configuracionLiderazgo arma la configuración de la elección de líder a partir de ConfiguracionLiderazgoPorDefecto
+// y las variables LIDER_INSTANCIA, LIDER_DURACION y LIDER_RENOVACION.
+func configuracionLiderazgo() services.ConfiguracionLiderazgo {
+	config := services.ConfiguracionLiderazgoPorDefecto
+	if instancia := os.Getenv("LIDER_INSTANCIA"); instancia != "" {
+		config.Instancia = instancia
+	}
+	if duracion := os.Getenv("LIDER_DURACION"); duracion != "" {
+		valor, err := time.ParseDuration(duracion)
+		if err != nil {
+			log.Fatalf("LIDER_DURACION inválido: %s", err)
+		}
+		config.Duracion = valor
+	}
+	if renovacion := os.Getenv("LIDER_RENOVACION"); renovacion != "" {
+		valor, err := time.ParseDuration(renovacion)
+		if err != nil {
+			log.Fatalf("LIDER_RENOVACION inválido: %s", err)
+		}
+		config.Renovacion = valor
+	}
+	return config
+}
+
+// configuracionAnomalias arma la configuración del filtro de anomalías a partir de ConfiguracionAnomaliasPorDefecto
+// y las variables ANOMALIAS_VENTANA, ANOMALIAS_SALTO, ANOMALIAS_ZSCORE, ANOMALIAS_CONFIRMACIONES y ANOMALIAS_MONEDAS,
+// esta última con umbrales por moneda como "Tether:1:0,Bitcoin:10:6". El filtro se desactiva con ANOMALIAS_FILTRO=0.
+func configuracionAnomalias() services.ConfiguracionAnomalias {
+	config := services.ConfiguracionAnomaliasPorDefecto
+	if ventana := os.Getenv("ANOMALIAS_VENTANA"); ventana != "" {
+		cantidad, err := strconv.Atoi(ventana)
+		if err != nil {
+			log.Fatalf("ANOMALIAS_VENTANA inválido: %s", err)
+		}
+		config.Ventana = cantidad
+	}
+	if salto := os.Getenv("ANOMALIAS_SALTO"); salto != "" {
+		valor, err := strconv.ParseFloat(salto, 64)
+		if err != nil {
+			log.Fatalf("ANOMALIAS_SALTO inválido: %s", err)
+		}
+		config.Umbral.SaltoMaximo = valor
+	}
+	if zScore := os.Getenv("ANOMALIAS_ZSCORE"); zScore != "" {
+		valor, err := strconv.ParseFloat(zScore, 64)
+		if err != nil {
+			log.Fatalf("ANOMALIAS_ZSCORE inválido: %s", err)
+		}
+		config.Umbral.ZScoreMaximo = valor
+	}
+	if confirmaciones := os.Getenv("ANOMALIAS_CONFIRMACIONES"); confirmaciones != "" {
+		cantidad, err := strconv.Atoi(confirmaciones)
+		if err != nil || cantidad < 0 {
+			log.Fatalf("ANOMALIAS_CONFIRMACIONES inválido: %s", confirmaciones)
+		}
+		config.Confirmaciones = cantidad
+	}
+	if monedas := os.Getenv("ANOMALIAS_MONEDAS"); monedas != "" {
+		umbrales, err := services.ParsearUmbralesMonedas(monedas)
+		if err != nil {
+			log.Fatalf("ANOMALIAS_MONEDAS inválido: %s", err)
+		}
+		config.PorMoneda = umbrales
+	}
+	return config
+}
+
+// configuracionFrescura arma la configuración de la frescura de la última cotización a partir de
+// ConfiguracionFrescuraPorDefecto y las variables FRESCURA_MAXIMA y FRESCURA_API.
+func configuracionFrescura() services.ConfiguracionFrescura {
+	config := services.ConfiguracionFrescuraPorDefecto
+	if maxima := os.Getenv("FRESCURA_MAXIMA"); maxima != "" {
+		duracion, err := time.ParseDuration(maxima)
+		if err != nil {
+			log.Fatalf("FRESCURA_MAXIMA inválido: %s", err)
+		}
+		config.Maxima = duracion
+	}
+	if api := os.Getenv("FRESCURA_API"); api != "" {
+		config.Api = api
+	}
+	return config
 }
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArbitrajeController struct {
+	serv *services.ArbitrajeService
+}
+
+func NewArbitrajeController(service *services.ArbitrajeService) *ArbitrajeController {
+	return &ArbitrajeController{serv: service}
+}
+
+// @Summary Current arbitrage opportunities
+// @Description Compare the lowest totalAsk with the highest totalBid across exchanges in the latest stored snapshot of every cryptocurrency, taken in the last 24 hours, ranked by percentage spread after fees. It only reads stored snapshots; POST /arbitrajes takes new ones
+// @Tags exchanges
+// @Produce json
+// @Param fiat query string false "Fiat currency, ARS by default"
+// @Param min_spread query number false "Minimum percentage spread, 0 by default"
+// @Param volumen query number false "Only use snapshots taken for this amount of the cryptocurrency, any by default"
+// @Success 200 {array} criptomonedas.Arbitraje
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /arbitrajes [get]
+func (c *ArbitrajeController) FindArbitrajesActuales(ctx *gin.Context) {
+	fiat, minSpread, volumen, ok := parametrosArbitraje(ctx)
+	if !ok {
+		return
+	}
+
+	arbitrajes, err := c.serv.FindArbitrajesActuales(fiat, minSpread, volumen)
+	c.responderArbitrajes(ctx, arbitrajes, err)
+}
+
+// @Summary Refresh arbitrage opportunities
+// @Description Query CriptoYa for every cryptocurrency, store the quotes and compare the lowest totalAsk with the highest totalBid across exchanges, ranked by percentage spread after fees. Makes one provider request per cryptocurrency
+// @Tags exchanges
+// @Produce json
+// @Param fiat query string false "Fiat currency, ARS by default"
+// @Param min_spread query number false "Minimum percentage spread, 0 by default"
+// @Param volumen query number false "Amount of the cryptocurrency the fee-inclusive prices are computed for, 0.1 by default"
+// @Success 200 {array} criptomonedas.Arbitraje
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /arbitrajes [post]
+func (c *ArbitrajeController) RefrescarArbitrajes(ctx *gin.Context) {
+	fiat, minSpread, volumen, ok := parametrosArbitraje(ctx)
+	if !ok {
+		return
+	}
+
+	arbitrajes, err := c.serv.RefrescarArbitrajes(ctx.Request.Context(), fiat, minSpread, volumen)
+	c.responderArbitrajes(ctx, arbitrajes, err)
+}
+
+// parametrosArbitraje lee fiat, min_spread y volumen de la query. Si alguno es inválido responde 400.
+func parametrosArbitraje(ctx *gin.Context) (string, float64, float64, bool) {
+	fiat := ctx.DefaultQuery("fiat", "ARS")
+	minSpread, err := strconv.ParseFloat(ctx.DefaultQuery("min_spread", "0"), 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "min_spread inválido"})
+		return "", 0, 0, false
+	}
+	volumen, ok := volumenDeQuery(ctx)
+	return fiat, minSpread, volumen, ok
+}
+
+func (c *ArbitrajeController) responderArbitrajes(ctx *gin.Context, arbitrajes []criptomonedas.Arbitraje, err error) {
+	if errors.Is(err, services.ErrVolumenInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular los arbitrajes"})
+		log.Printf("Error al calcular los arbitrajes: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, arbitrajes)
+}
+
+// @Summary Arbitrage history
+// @Description Rebuild the arbitrage spread of every stored exchange snapshot for a cryptocurrency, ranked by percentage spread after fees
+// @Tags exchanges
+// @Produce json
+// @Param nombre query string true "Cryptocurrency name"
+// @Param fiat query string false "Fiat currency, ARS by default"
+// @Param orden query string false "Order of the results: spread (default) or fecha, newest first"
+// @Param start_date query string false "Start Date in RFC3339 format, last 7 days by default"
+// @Param end_date query string false "End Date in RFC3339 format"
+// @Success 200 {array} criptomonedas.Arbitraje
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /arbitrajes/historial [get]
+func (c *ArbitrajeController) FindHistorialArbitraje(ctx *gin.Context) {
+	nombre := ctx.Query("nombre")
+	if nombre == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El nombre de la moneda es obligatorio"})
+		return
+	}
+	fiat := ctx.DefaultQuery("fiat", "ARS")
+	orden := ctx.DefaultQuery("orden", services.OrdenArbitrajeSpread)
+	if orden != services.OrdenArbitrajeSpread && orden != services.OrdenArbitrajeFecha {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "orden inválido, debe ser spread o fecha"})
+		return
+	}
+
+	var start, end *time.Time
+	if startDate := ctx.Query("start_date"); startDate != "" {
+		fecha, err := time.Parse(time.RFC3339, startDate)
+		if err == nil {
+			start = &fecha
+		}
+	}
+	if endDate := ctx.Query("end_date"); endDate != "" {
+		fecha, err := time.Parse(time.RFC3339, endDate)
+		if err == nil {
+			end = &fecha
+		}
+	}
+
+	arbitrajes, err := c.serv.FindHistorialArbitraje(nombre, fiat, orden, start, end)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener el historial de arbitrajes"})
+		log.Printf("Error al obtener el historial de arbitrajes: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, arbitrajes)
+}
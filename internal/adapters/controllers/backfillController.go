@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BackfillController struct {
+	serv *services.BackfillService
+}
+
+func NewBackfillController(service *services.BackfillService) *BackfillController {
+	return &BackfillController{serv: service}
+}
+
+// @Summary Request historical backfill
+// @Description Queue the load of daily or hourly historical quotes of a cryptocurrency over a date range from a provider that supports history (coinpaprika, coingecko). The backfill runs in the background on the leader instance; quotes already stored are not duplicated. Both dates are truncated to the resolution step. If an unfinished backfill of the same coin, provider, fiat and resolution already covers the range, it is returned instead.
+// @Tags backfills
+// @Accept json
+// @Produce json
+// @Param backfill body criptomonedas.Backfill true "Backfill: cripto_id or moneda, desde, hasta and optionally api, fiat and resolucion (diaria or horaria)"
+// @Success 202 {object} criptomonedas.Backfill
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /backfills [post]
+func (c *BackfillController) CrearBackfill(ctx *gin.Context) {
+	var backfill criptomonedas.Backfill
+	if err := ctx.ShouldBindJSON(&backfill); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos del backfill inválidos"})
+		return
+	}
+	creado, err := c.serv.CrearBackfill(backfill)
+	if errors.Is(err, services.ErrBackfillInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear el backfill"})
+		log.Printf("Error al crear el backfill: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, creado)
+}
+
+// @Summary List backfills
+// @Description List the historical backfills, newest first, with their progress
+// @Tags backfills
+// @Produce json
+// @Success 200 {array} criptomonedas.Backfill
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /backfills [get]
+func (c *BackfillController) FindAllBackfills(ctx *gin.Context) {
+	backfills, err := c.serv.FindAllBackfills()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener los backfills"})
+		log.Printf("Error al obtener los backfills: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, backfills)
+}
+
+// @Summary Get backfill
+// @Description Get a historical backfill and its progress
+// @Tags backfills
+// @Produce json
+// @Param id path int true "Backfill ID"
+// @Success 200 {object} criptomonedas.Backfill
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 404 {object} map[string]string "error": "Backfill no encontrado"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /backfills/{id} [get]
+func (c *BackfillController) FindBackfillByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	backfill, err := c.serv.FindBackfillByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener el backfill"})
+		log.Printf("Error al obtener el backfill: %s", err)
+		return
+	}
+	if backfill == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Backfill no encontrado"})
+		return
+	}
+	ctx.JSON(http.StatusOK, backfill)
+}
+
+// @Summary Resume backfill
+// @Description Put a failed backfill back in the queue; it continues from its cursor
+// @Tags backfills
+// @Produce json
+// @Param id path int true "Backfill ID"
+// @Success 202 {object} criptomonedas.Backfill
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 404 {object} map[string]string "error": "Backfill no encontrado"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /backfills/{id}/reanudar [post]
+func (c *BackfillController) ReanudarBackfill(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	backfill, err := c.serv.Reanudar(id)
+	if errors.Is(err, services.ErrBackfillInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al reanudar el backfill"})
+		log.Printf("Error al reanudar el backfill: %s", err)
+		return
+	}
+	if backfill == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Backfill no encontrado"})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, backfill)
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"primerProjecto/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalidadDatosController struct {
+	serv *services.CalidadDatosService
+}
+
+func NewCalidadDatosController(service *services.CalidadDatosService) *CalidadDatosController {
+	return &CalidadDatosController{serv: service}
+}
+
+// @Summary Quote series gaps
+// @Description Report, for each cryptocurrency, the intervals without quotes according to its expected refresh cadence: its refresh policy cron or the poller interval. Coins the poller does not refresh are skipped: those with a disabled policy, those nobody follows when the poller scope is seguidas, and all of them when the poller is disabled.
+// @Tags calidad
+// @Produce json
+// @Param nombre query string false "Cryptocurrency name, all of them if empty"
+// @Param start_date query string false "Start date (RFC3339), 24 hours before end_date by default"
+// @Param end_date query string false "End date (RFC3339), now by default"
+// @Success 200 {array} services.HuecosMoneda
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /calidad/huecos [get]
+func (c *CalidadDatosController) FindHuecos(ctx *gin.Context) {
+	filtro, ok := filtroHuecos(ctx)
+	if !ok {
+		return
+	}
+	c.responder(ctx, http.StatusOK, filtro, c.serv.BuscarHuecos)
+}
+
+// @Summary Fill quote series gaps
+// @Description Report the gaps like GET /calidad/huecos and queue an hourly backfill for every gap of at least one hour
+// @Tags calidad
+// @Produce json
+// @Param nombre query string false "Cryptocurrency name, all of them if empty"
+// @Param start_date query string false "Start date (RFC3339), 24 hours before end_date by default"
+// @Param end_date query string false "End date (RFC3339), now by default"
+// @Success 202 {array} services.HuecosMoneda
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /calidad/huecos/rellenar [post]
+func (c *CalidadDatosController) RellenarHuecos(ctx *gin.Context) {
+	filtro, ok := filtroHuecos(ctx)
+	if !ok {
+		return
+	}
+	c.responder(ctx, http.StatusAccepted, filtro, c.serv.RellenarHuecos)
+}
+
+func (c *CalidadDatosController) responder(ctx *gin.Context, status int, filtro services.FiltroHuecos, buscar func(services.FiltroHuecos) ([]services.HuecosMoneda, error)) {
+	huecos, err := buscar(filtro)
+	if errors.Is(err, services.ErrFiltroHuecosInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar los huecos de cotizaciones"})
+		log.Printf("Error al buscar los huecos de cotizaciones: %s", err)
+		return
+	}
+	ctx.JSON(status, huecos)
+}
+
+func filtroHuecos(ctx *gin.Context) (services.FiltroHuecos, bool) {
+	filtro := services.FiltroHuecos{Nombre: ctx.Query("nombre")}
+	if startDate := ctx.Query("start_date"); startDate != "" {
+		fecha, err := time.Parse(time.RFC3339, startDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_date inválido, debe ser RFC3339"})
+			return filtro, false
+		}
+		filtro.Desde = &fecha
+	}
+	if endDate := ctx.Query("end_date"); endDate != "" {
+		fecha, err := time.Parse(time.RFC3339, endDate)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "end_date inválido, debe ser RFC3339"})
+			return filtro, false
+		}
+		filtro.Hasta = &fecha
+	}
+	return filtro, true
+}
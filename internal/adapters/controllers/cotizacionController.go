@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"log"
+	"math"
 	"net/http"
+	"primerProjecto/internal/adapters/cotizadores"
 	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -54,6 +59,16 @@ func (c *CryptoController) FindAllByFilter(ctx *gin.Context) {
 			filter.MaxCotizacion = &max
 		}
 	}
+	if fiat := ctx.Query("fiat"); fiat != "" {
+		fiat = strings.ToUpper(fiat)
+		filter.Fiat = &fiat
+	}
+	if fuente := ctx.Query("fuente"); fuente != "" {
+		filter.Fuente = &fuente
+	}
+	if exchange := ctx.Query("exchange"); exchange != "" {
+		filter.Exchange = &exchange
+	}
 	if startDate := ctx.Query("start_date"); startDate != "" {
 		start, err := time.Parse(time.RFC3339, startDate)
 		if err == nil {
@@ -78,6 +93,7 @@ func (c *CryptoController) FindAllByFilter(ctx *gin.Context) {
 		pageNumber = 1 // Default page number
 	}
 	filter.PageNumber = pageNumber
+	filter.AgruparPorFuente = ctx.Query("agrupar") == "fuente"
 
 	monedas, summary, err := c.serv.FindAllByFilter(filter)
 	if err != nil {
@@ -92,31 +108,121 @@ func (c *CryptoController) FindAllByFilter(ctx *gin.Context) {
 }
 
 // @Summary Get latest quote by cryptocurrency name
-// @Description Get the latest quote for a given cryptocurrency name
+// @Description Get the latest quote for a given cryptocurrency name, with its age and a stale flag according to the freshness of the coin's refresh policy or the global one. With refresh=true a stale quote is fetched from the provider before answering; concurrent requests for the same coin share one fetch.
 // @Tags cryptocurrencies
 // @Accept json
 // @Produce json
 // @Param nombre path string true "Cryptocurrency name"
-// @Success 200 {object} criptomonedas.Cotizacion "Successful response with the latest quote"
+// @Param fiat query string false "Fiat currency, any fiat by default"
+// @Param refresh query bool false "Fetch from the provider if the stored quote is stale"
+// @Param volumen query number false "Amount of the cryptocurrency to quote when refreshing, 0.1 by default"
+// @Success 200 {object} criptomonedas.CotizacionVigente "Successful response with the latest quote"
+// @Failure 400 {object} gin.H "Volumen inválido"
+// @Failure 404 {object} gin.H "No quotes for the cryptocurrency"
 // @Failure 500 {object} gin.H "Internal Server Error"
 // @Router /cryptocurrencies/lastcotization/{nombre} [get]
 func (c CryptoController) FindUltimaCotizacion(ctx *gin.Context) {
 	nombre := ctx.Param("nombre")
+	refrescar, _ := strconv.ParseBool(ctx.DefaultQuery("refresh", "false"))
+	volumen, ok := volumenDeQuery(ctx)
+	if !ok {
+		return
+	}
 
-	Cotizacion, err := c.serv.FindUltimaCotizacion(nombre)
+	Cotizacion, err := c.serv.FindUltimaCotizacion(ctx.Request.Context(), nombre, ctx.Query("fiat"), refrescar, volumen)
+	if errors.Is(err, services.ErrVolumenInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener la criptomoneda"})
 		log.Printf("Error al obtener la criptomoneda: %s", err)
 		return
 	}
+	if Cotizacion == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No hay cotizaciones para la criptomoneda"})
+		return
+	}
 	ctx.JSON(http.StatusOK, Cotizacion)
 }
 
+// nombreCotizador arma el nombre del cotizador agregando el exchange y el lado si vienen en la query,
+// por ejemplo api=criptoya&exchange=letsbit&lado=bid queda como "criptoya:letsbit:bid". Solo CriptoYa cotiza
+// por exchange, así que con exchange o lado la api por defecto es criptoya y cualquier otra responde 400.
+func nombreCotizador(ctx *gin.Context) (string, bool) {
+	api := ctx.Query("api")
+	exchange := ctx.Query("exchange")
+	lado := ctx.Query("lado")
+	if exchange == "" && lado == "" {
+		// sin api se prueban los proveedores en el orden de fallback configurado
+		if api == "" {
+			api = "fallback"
+		}
+		return api, true
+	}
+	if api == "" {
+		api = "criptoya"
+	}
+	if api != "criptoya" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "exchange y lado solo se pueden usar con api=criptoya"})
+		return "", false
+	}
+	return api + ":" + exchange + ":" + lado, true
+}
+
+// volumenDeQuery lee el volumen a cotizar de la query, cero si no viene. Si no es un número responde 400.
+func volumenDeQuery(ctx *gin.Context) (float64, bool) {
+	valor := ctx.Query("volumen")
+	if valor == "" {
+		return 0, true
+	}
+	volumen, err := strconv.ParseFloat(valor, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Volumen inválido"})
+		return 0, false
+	}
+	return volumen, true
+}
+
+// @Summary Save external quote
+// @Description Query a provider and store the quote. Without api the providers are tried in the configured fallback order. With volumen, providers with an order book such as CriptoYa quote that size and return the fee-inclusive prices totalAsk and totalBid
+// @Tags cryptocurrencies
+// @Produce json
+// @Param nombre query string true "Cryptocurrency name"
+// @Param api query string false "Provider, fallback by default"
+// @Param exchange query string false "CriptoYa exchange; only with api=criptoya, which is the default when exchange or lado is set"
+// @Param lado query string false "ask or bid; only with api=criptoya"
+// @Param fiat query string false "Fiat currency, USD by default"
+// @Param volumen query number false "Amount of the cryptocurrency to trade, 0.1 by default"
+// @Success 200 {object} map[string]string "message"
+// @Success 202 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "error": "Volumen inválido"
+// @Failure 429 {object} map[string]string "error": "Too Many Requests"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cotization/externa [post]
 func (c CryptoController) SaveCotizacionExterna(ctx *gin.Context) {
 	monedaNombre := ctx.Query("nombre")
-	api := ctx.Query("api")
+	api, ok := nombreCotizador(ctx)
+	if !ok {
+		return
+	}
+	volumen, ok := volumenDeQuery(ctx)
+	if !ok {
+		return
+	}
 
-	err := c.serv.GuardarCotizacionExterna(monedaNombre, api)
+	err := c.serv.GuardarCotizacionExterna(ctx.Request.Context(), monedaNombre, api, ctx.Query("fiat"), volumen)
+	if errors.Is(err, services.ErrVolumenInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrCotizacionEnCuarentena) {
+		ctx.JSON(http.StatusAccepted, gin.H{"message": "La cotizacion quedo en cuarentena para revision", "motivo": err.Error()})
+		return
+	}
+	if responderLimiteExcedido(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar la cotizacion"})
 		log.Printf("Error al registrar la cotizacion: %s", err)
@@ -125,6 +231,77 @@ func (c CryptoController) SaveCotizacionExterna(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "cotizacion guardada correctamente"})
 }
 
+// responderLimiteExcedido responde 429 con Retry-After en segundos si el error es de un proveedor que no tiene
+// cupo para la solicitud. Devuelve false si el error es de otro tipo.
+func responderLimiteExcedido(ctx *gin.Context, err error) bool {
+	var limite *cotizadores.LimiteExcedidoError
+	if !errors.As(err, &limite) {
+		return false
+	}
+	espera := max(int(math.Ceil(time.Until(limite.ReintentoEn).Seconds())), 1)
+	ctx.Header("Retry-After", strconv.Itoa(espera))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{"error": limite.Error()})
+	return true
+}
+
+// @Summary Aggregated quote
+// @Description Query several providers concurrently and combine their prices, discarding outliers
+// @Tags cryptocurrencies
+// @Produce json
+// @Param nombre query string true "Cryptocurrency name"
+// @Param fiat query string false "Fiat currency, USD by default"
+// @Param metodo query string false "mediana or ponderado"
+// @Param umbral query number false "Maximum deviation from the median, as a fraction"
+// @Param volumen query number false "Amount of the cryptocurrency to trade, passed to every source, 0.1 by default"
+// @Success 200 {object} cotizadores.ReporteAgregado
+// @Failure 400 {object} gin.H "Volumen inválido"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /cotization/agregada [get]
+func (c CryptoController) GetCotizacionAgregada(ctx *gin.Context) {
+	monedaNombre := ctx.Query("nombre")
+	fiat := services.NormalizarFiat(ctx.Query("fiat"))
+	api := "agregado"
+	if metodo, umbral := ctx.Query("metodo"), ctx.Query("umbral"); metodo != "" || umbral != "" {
+		api += ":" + metodo + ":" + umbral
+	}
+	volumen, ok := volumenDeQuery(ctx)
+	if !ok {
+		return
+	}
+
+	reporte, err := c.serv.GetCotizacionAgregada(ctx.Request.Context(), api, monedaNombre, fiat, volumen)
+	if errors.Is(err, services.ErrVolumenInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener la cotizacion agregada", "fuentes": reporte.Fuentes})
+		log.Printf("Error al obtener la cotizacion agregada: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reporte)
+}
+
+// @Summary Circuit breaker state
+// @Description Get the circuit breaker state of every external quote provider
+// @Tags cryptocurrencies
+// @Produce json
+// @Success 200 {array} cotizadores.EstadoCircuito
+// @Router /cotizadores/circuitos [get]
+func (c CryptoController) FindEstadoCircuitos(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.serv.FindEstadoCircuitos())
+}
+
+// @Summary Provider usage
+// @Description Get the rate limit usage counters and daily budget of every external quote provider
+// @Tags cryptocurrencies
+// @Produce json
+// @Success 200 {array} cotizadores.UsoProveedor
+// @Router /cotizadores/uso [get]
+func (c CryptoController) FindUsoProveedores(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.serv.FindUsoProveedores())
+}
+
 // @Summary Find all cryptocurrencies by filter
 // @Description Find all cryptocurrencies by filter for a specific user
 // @Tags cryptocurrencies
@@ -134,6 +311,7 @@ func (c CryptoController) SaveCotizacionExterna(ctx *gin.Context) {
 // @Param nombre query string false "Nombre"
 // @Param min_cotizacion query number false "Minimum Cotizacion"
 // @Param max_cotizacion query number false "Maximum Cotizacion"
+// @Param fiat query string false "Fiat currency, the user's preferred fiat by default"
 // @Param start_date query string false "Start Date in RFC3339 format"
 // @Param end_date query string false "End Date in RFC3339 format"
 // @Param page_size query int true "Page Size"
@@ -167,6 +345,16 @@ func (c *CryptoController) FindAllByFilterUsuario(ctx *gin.Context) {
 			filter.MaxCotizacion = &max
 		}
 	}
+	if fiat := ctx.Query("fiat"); fiat != "" {
+		fiat = strings.ToUpper(fiat)
+		filter.Fiat = &fiat
+	}
+	if fuente := ctx.Query("fuente"); fuente != "" {
+		filter.Fuente = &fuente
+	}
+	if exchange := ctx.Query("exchange"); exchange != "" {
+		filter.Exchange = &exchange
+	}
 	if startDate := ctx.Query("start_date"); startDate != "" {
 		start, err := time.Parse(time.RFC3339, startDate)
 		if err == nil {
@@ -208,3 +396,32 @@ func (c *CryptoController) FindAllByFilterUsuario(ctx *gin.Context) {
 }
 
 
+
+// @Summary Refresh quotes of several cryptocurrencies
+// @Description Fetch the quotes of a list of cryptocurrencies, or all of them if the list is empty, and store them in one transaction. Providers that quote many coins at once (coinpaprika) are queried in a single round-trip; the others once per coin. The result reports success or failure per coin.
+// @Tags cryptocurrencies
+// @Accept json
+// @Produce json
+// @Param pedido body services.PedidoRefresco true "Coins, provider (coinpaprika by default) and fiat (USD by default)"
+// @Success 200 {object} services.ReporteRefresco
+// @Failure 400 {object} gin.H "Bad Request"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /cotization/externa/batch [post]
+func (c CryptoController) RefrescarCotizaciones(ctx *gin.Context) {
+	var pedido services.PedidoRefresco
+	if err := ctx.ShouldBindJSON(&pedido); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos del pedido inválidos"})
+		return
+	}
+	reporte, err := c.serv.RefrescarCotizaciones(ctx.Request.Context(), pedido)
+	if errors.Is(err, services.ErrRefrescoInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al refrescar las cotizaciones"})
+		log.Printf("Error al refrescar las cotizaciones: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reporte)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"primerProjecto/internal/entities/criptomonedas"
@@ -114,11 +115,46 @@ func (c *CryptoController) FindMondaByNombre(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, moneda)
 }
 
+// @Summary Register cryptocurrency with a quote
+// @Description Register the cryptocurrency and store a quote from a provider. Without api the providers are tried in the configured fallback order
+// @Tags cryptocurrencies
+// @Produce json
+// @Param nombre query string true "Cryptocurrency name"
+// @Param codigo query string false "Cryptocurrency code, needed by the providers that quote by code such as CriptoYa, Binance and Kraken"
+// @Param api query string false "Provider, fallback by default"
+// @Param exchange query string false "CriptoYa exchange; only with api=criptoya, which is the default when exchange or lado is set"
+// @Param lado query string false "ask or bid; only with api=criptoya"
+// @Param fiat query string false "Fiat currency, USD by default"
+// @Param volumen query number false "Amount of the cryptocurrency to trade, 0.1 by default"
+// @Success 200 {object} map[string]string "message": "Successful response with a message"
+// @Success 202 {object} map[string]string "message": "The coin was registered and its quote was quarantined"
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 429 {object} map[string]string "error": "Too Many Requests"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cryptocurrencies/externa [post]
 func (c CryptoController) SaveMonedaConCotizacion(ctx *gin.Context) {
 	monedaNombre := ctx.Query("nombre")
-	api := ctx.Query("api")
+	api, ok := nombreCotizador(ctx)
+	if !ok {
+		return
+	}
+	volumen, ok := volumenDeQuery(ctx)
+	if !ok {
+		return
+	}
 
-	err := c.serv.SaveMonedaConCotizacion(monedaNombre, api)
+	err := c.serv.SaveMonedaConCotizacion(ctx.Request.Context(), monedaNombre, ctx.Query("codigo"), api, ctx.Query("fiat"), volumen)
+	if errors.Is(err, services.ErrVolumenInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrCotizacionEnCuarentena) {
+		ctx.JSON(http.StatusAccepted, gin.H{"message": "La moneda se registro y su cotizacion quedo en cuarentena para revision", "motivo": err.Error()})
+		return
+	}
+	if responderLimiteExcedido(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar la moneda"})
 		log.Printf("Error al registrar la moneda: %s", err)
@@ -132,11 +168,13 @@ func (c CryptoController) SaveMonedaConCotizacion(ctx *gin.Context) {
 // @Description  Genera un archivo CSV con datos de criptomonedas de forma sincrónica
 // @Tags         csv
 // @Produce      text/csv
+// @Param        fuente   query     string  false  "Exportar solo la última cotización de esta fuente"
+// @Param        agrupar  query     string  false  "fuente para exportar la última cotización de cada fuente"
 // @Success      200  {file}  file
 // @Failure      500  {string}  string "Error al generar el archivo CSV"
 // @Router       /csv/sync/generate [get]
 func (c *CryptoController) DownloadCSV(ctx *gin.Context) {
-	data, err := c.serv.GenerateCSV()
+	data, err := c.serv.GenerateCSV(opcionesCSV(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al generar el archivo CSV"})
 		return
@@ -153,13 +191,23 @@ func (c *CryptoController) DownloadCSV(ctx *gin.Context) {
 // @Description  Inicia una tarea para generar un archivo CSV con datos de criptomonedas de forma asíncrona
 // @Tags         csv
 // @Produce      json
+// @Param        fuente   query     string  false  "Exportar solo la última cotización de esta fuente"
+// @Param        agrupar  query     string  false  "fuente para exportar la última cotización de cada fuente"
 // @Success      200  {string}  string "task_id"
 // @Router       /csv/async/generate [post]
 func (c *CryptoController) StartCSVTask(ctx *gin.Context) {
-	taskID := c.serv.StartCSVTask()
+	taskID := c.serv.StartCSVTask(opcionesCSV(ctx))
 	ctx.JSON(http.StatusOK, gin.H{"task_id": taskID})
 }
 
+// opcionesCSV lee el filtro y la agrupación por fuente de la query.
+func opcionesCSV(ctx *gin.Context) services.OpcionesCSV {
+	return services.OpcionesCSV{
+		Fuente:           ctx.Query("fuente"),
+		AgruparPorFuente: ctx.Query("agrupar") == "fuente",
+	}
+}
+
 // GetTaskStatus godoc
 // @Summary      Obtener el estado de una tarea de generación de CSV
 // @Description  Obtiene el estado de una tarea asíncrona de generación de CSV mediante el ID de la tarea
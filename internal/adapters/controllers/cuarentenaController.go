@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CuarentenaController struct {
+	serv *services.AnomaliasService
+}
+
+func NewCuarentenaController(service *services.AnomaliasService) *CuarentenaController {
+	return &CuarentenaController{serv: service}
+}
+
+// @Summary List quarantined quotes
+// @Description List the provider quotes held for review because they jumped too far from the recent history of the coin, newest first
+// @Tags cuarentena
+// @Produce json
+// @Param estado query string false "pendiente, aprobada or rechazada; all of them if empty"
+// @Success 200 {array} criptomonedas.CotizacionCuarentena
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cuarentena [get]
+func (c *CuarentenaController) FindAllCuarentena(ctx *gin.Context) {
+	estado := ctx.Query("estado")
+	switch estado {
+	case "", criptomonedas.CuarentenaPendiente, criptomonedas.CuarentenaAprobada, criptomonedas.CuarentenaRechazada:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Estado inválido, debe ser pendiente, aprobada o rechazada"})
+		return
+	}
+	cuarentenas, err := c.serv.FindAllCuarentena(estado)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las cotizaciones en cuarentena"})
+		log.Printf("Error al obtener las cotizaciones en cuarentena: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cuarentenas)
+}
+
+// @Summary Get quarantined quote
+// @Description Get a quarantined quote and the reason it was held
+// @Tags cuarentena
+// @Produce json
+// @Param id path int true "Quarantined quote ID"
+// @Success 200 {object} criptomonedas.CotizacionCuarentena
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 404 {object} map[string]string "error": "Cotización en cuarentena no encontrada"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cuarentena/{id} [get]
+func (c *CuarentenaController) FindCuarentenaByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	cuarentena, err := c.serv.FindCuarentenaByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener la cotización en cuarentena"})
+		log.Printf("Error al obtener la cotización en cuarentena: %s", err)
+		return
+	}
+	if cuarentena == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cotización en cuarentena no encontrada"})
+		return
+	}
+	ctx.JSON(http.StatusOK, cuarentena)
+}
+
+// @Summary Approve quarantined quote
+// @Description Store a pending quarantined quote as a regular quote of the coin; if it is the newest it becomes the latest quote
+// @Tags cuarentena
+// @Produce json
+// @Param id path int true "Quarantined quote ID"
+// @Success 200 {object} criptomonedas.CotizacionCuarentena
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 404 {object} map[string]string "error": "Cotización en cuarentena no encontrada"
+// @Failure 409 {object} map[string]string "error": "La cotización ya fue revisada"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cuarentena/{id}/aprobar [post]
+func (c *CuarentenaController) AprobarCuarentena(ctx *gin.Context) {
+	c.resolver(ctx, c.serv.Aprobar)
+}
+
+// @Summary Reject quarantined quote
+// @Description Discard a pending quarantined quote; it is kept in the review table as rejected
+// @Tags cuarentena
+// @Produce json
+// @Param id path int true "Quarantined quote ID"
+// @Success 200 {object} criptomonedas.CotizacionCuarentena
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 404 {object} map[string]string "error": "Cotización en cuarentena no encontrada"
+// @Failure 409 {object} map[string]string "error": "La cotización ya fue revisada"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cuarentena/{id}/rechazar [post]
+func (c *CuarentenaController) RechazarCuarentena(ctx *gin.Context) {
+	c.resolver(ctx, c.serv.Rechazar)
+}
+
+func (c *CuarentenaController) resolver(ctx *gin.Context, resolver func(id int) (*criptomonedas.CotizacionCuarentena, error)) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	cuarentena, err := resolver(id)
+	if errors.Is(err, services.ErrCuarentenaResuelta) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al revisar la cotización en cuarentena"})
+		log.Printf("Error al revisar la cotización en cuarentena: %s", err)
+		return
+	}
+	if cuarentena == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cotización en cuarentena no encontrada"})
+		return
+	}
+	ctx.JSON(http.StatusOK, cuarentena)
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeController struct {
+	serv *services.ExchangeService
+}
+
+func NewExchangeController(service *services.ExchangeService) *ExchangeController {
+	return &ExchangeController{serv: service}
+}
+
+// @Summary List exchanges
+// @Description List every exchange with stored quotes
+// @Tags exchanges
+// @Produce json
+// @Success 200 {array} criptomonedas.Exchange
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /exchanges [get]
+func (c *ExchangeController) FindAllExchanges(ctx *gin.Context) {
+	exchanges, err := c.serv.FindAllExchanges()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener los exchanges"})
+		log.Printf("Error al obtener los exchanges: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, exchanges)
+}
+
+// @Summary Save quotes from every exchange
+// @Description Query CriptoYa and store one quote row per exchange for the given cryptocurrency
+// @Tags exchanges
+// @Produce json
+// @Param nombre query string true "Cryptocurrency name"
+// @Param fiat query string false "Fiat currency, ARS by default"
+// @Param volumen query number false "Amount of the cryptocurrency the fee-inclusive prices are computed for, 0.1 by default"
+// @Success 200 {array} criptomonedas.CotizacionExchange
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /exchanges/cotizaciones [post]
+func (c *ExchangeController) GuardarCotizacionesExchanges(ctx *gin.Context) {
+	nombre := ctx.Query("nombre")
+	if nombre == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El nombre de la moneda es obligatorio"})
+		return
+	}
+	fiat := ctx.DefaultQuery("fiat", "ARS")
+	volumen, ok := volumenDeQuery(ctx)
+	if !ok {
+		return
+	}
+
+	cotizaciones, err := c.serv.GuardarCotizacionesExchanges(ctx.Request.Context(), nombre, fiat, volumen)
+	if errors.Is(err, services.ErrVolumenInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar las cotizaciones por exchange"})
+		log.Printf("Error al registrar las cotizaciones por exchange: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cotizaciones)
+}
+
+// @Summary Quote history per exchange
+// @Description Get the quote history of a cryptocurrency on one exchange, or on every exchange if none is given
+// @Tags exchanges
+// @Produce json
+// @Param nombre query string true "Cryptocurrency name"
+// @Param exchange query string false "Exchange name"
+// @Param fiat query string false "Fiat currency"
+// @Param start_date query string false "Start Date in RFC3339 format"
+// @Param end_date query string false "End Date in RFC3339 format"
+// @Param page_size query int false "Page Size"
+// @Param page_number query int false "Page Number"
+// @Success 200 {array} criptomonedas.CotizacionExchange
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /exchanges/cotizaciones [get]
+func (c *ExchangeController) FindCotizacionesExchange(ctx *gin.Context) {
+	var filter criptomonedas.CotizacionExchangeFilter
+
+	filter.Nombre = ctx.Query("nombre")
+	if filter.Nombre == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El nombre de la moneda es obligatorio"})
+		return
+	}
+	if exchange := ctx.Query("exchange"); exchange != "" {
+		filter.Exchange = &exchange
+	}
+	if fiat := ctx.Query("fiat"); fiat != "" {
+		filter.Fiat = &fiat
+	}
+	if startDate := ctx.Query("start_date"); startDate != "" {
+		start, err := time.Parse(time.RFC3339, startDate)
+		if err == nil {
+			filter.StartDate = &start
+		}
+	}
+	if endDate := ctx.Query("end_date"); endDate != "" {
+		end, err := time.Parse(time.RFC3339, endDate)
+		if err == nil {
+			filter.EndDate = &end
+		}
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 10 // Default page size
+	}
+	filter.PageSize = pageSize
+
+	pageNumber, err := strconv.Atoi(ctx.Query("page_number"))
+	if err != nil || pageNumber <= 0 {
+		pageNumber = 1 // Default page number
+	}
+	filter.PageNumber = pageNumber
+
+	cotizaciones, err := c.serv.FindCotizacionesExchange(filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las cotizaciones por exchange"})
+		log.Printf("Error al obtener las cotizaciones por exchange: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cotizaciones)
+}
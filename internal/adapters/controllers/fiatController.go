@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"primerProjecto/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FiatController struct {
+	serv *services.FiatService
+}
+
+func NewFiatController(service *services.FiatService) *FiatController {
+	return &FiatController{serv: service}
+}
+
+// @Summary List fiat currencies
+// @Description List every fiat currency quotes can be expressed in
+// @Tags fiat
+// @Produce json
+// @Success 200 {array} criptomonedas.MonedaFiat
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /fiats [get]
+func (c *FiatController) FindAllFiats(ctx *gin.Context) {
+	fiats, err := c.serv.FindAllFiats()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las monedas fiat"})
+		log.Printf("Error al obtener las monedas fiat: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, fiats)
+}
+
+// @Summary Fiat exchange rate
+// @Description Get the value of one unit of base expressed in destino, crossing through USD when needed
+// @Tags fiat
+// @Produce json
+// @Param base query string true "Base fiat currency"
+// @Param destino query string true "Target fiat currency"
+// @Param tipo_cambio query string false "oficial, mep or blue; oficial by default. Only changes ARS rates, the others are ECB reference rates"
+// @Success 200 {object} cotizadores.TasaFiat
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /fiats/tasa [get]
+func (c *FiatController) GetTasaFiat(ctx *gin.Context) {
+	base, destino := ctx.Query("base"), ctx.Query("destino")
+	if base == "" || destino == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "La base y el destino son obligatorios"})
+		return
+	}
+	tasa, err := c.serv.GetTasaFiat(ctx.Request.Context(), base, destino, ctx.Query("tipo_cambio"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener el tipo de cambio"})
+		log.Printf("Error al obtener el tipo de cambio: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, tasa)
+}
+
+// @Summary Latest quote converted to another fiat
+// @Description Get the latest stored quote of a cryptocurrency converted to the given fiat, recording the rate type used
+// @Tags fiat
+// @Produce json
+// @Param nombre path string true "Cryptocurrency name"
+// @Param fiat query string true "Target fiat currency"
+// @Param tipo_cambio query string false "oficial, mep or blue; oficial by default. Only changes ARS rates, the others are ECB reference rates"
+// @Success 200 {object} criptomonedas.CotizacionConvertida
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cryptocurrencies/lastcotization/{nombre}/convertida [get]
+func (c *FiatController) FindUltimaCotizacionConvertida(ctx *gin.Context) {
+	convertida, err := c.serv.FindUltimaCotizacionConvertida(ctx.Request.Context(), ctx.Param("nombre"), ctx.Query("fiat"), ctx.Query("tipo_cambio"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al convertir la cotizacion"})
+		log.Printf("Error al convertir la cotizacion: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convertida)
+}
+
+// @Summary Stored quote converted to another fiat
+// @Description Convert a stored quote to the given fiat, recording the rate type used
+// @Tags fiat
+// @Produce json
+// @Param id path int true "Quote ID"
+// @Param fiat query string true "Target fiat currency"
+// @Param tipo_cambio query string false "oficial, mep or blue; oficial by default. Only changes ARS rates, the others are ECB reference rates"
+// @Success 200 {object} criptomonedas.CotizacionConvertida
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /cotizaciones/{id}/convertida [get]
+func (c *FiatController) ConvertirCotizacion(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	convertida, err := c.serv.ConvertirCotizacionByID(ctx.Request.Context(), id, ctx.Query("fiat"), ctx.Query("tipo_cambio"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al convertir la cotizacion"})
+		log.Printf("Error al convertir la cotizacion: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convertida)
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+	"primerProjecto/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LiderazgoController struct {
+	serv *services.LiderService
+}
+
+func NewLiderazgoController(service *services.LiderService) *LiderazgoController {
+	return &LiderazgoController{serv: service}
+}
+
+// @Summary Scheduled jobs leadership
+// @Description Show whether this instance is the leader that runs the scheduled jobs and which instance holds the lease
+// @Tags cryptocurrencies
+// @Produce json
+// @Success 200 {object} services.EstadoLiderazgo
+// @Router /liderazgo [get]
+func (c *LiderazgoController) FindEstado(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.serv.Estado())
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PoliticaRefrescoController struct {
+	serv *services.PoliticaRefrescoService
+}
+
+func NewPoliticaRefrescoController(service *services.PoliticaRefrescoService) *PoliticaRefrescoController {
+	return &PoliticaRefrescoController{serv: service}
+}
+
+// @Summary List refresh policies
+// @Description List the refresh policy of every cryptocurrency that has one
+// @Tags politicas
+// @Produce json
+// @Success 200 {array} criptomonedas.PoliticaRefresco
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /politicas [get]
+func (c *PoliticaRefrescoController) FindAllPoliticas(ctx *gin.Context) {
+	politicas, err := c.serv.FindAllPoliticas()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener las políticas de refresco"})
+		log.Printf("Error al obtener las políticas de refresco: %s", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, politicas)
+}
+
+// @Summary Get refresh policy
+// @Description Get the refresh policy of a cryptocurrency
+// @Tags politicas
+// @Produce json
+// @Param id path int true "Cryptocurrency ID"
+// @Success 200 {object} criptomonedas.PoliticaRefresco
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 404 {object} map[string]string "error": "Política no encontrada"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /politicas/{id} [get]
+func (c *PoliticaRefrescoController) FindPoliticaByMonedaID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	politica, err := c.serv.FindPoliticaByMonedaID(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener la política de refresco"})
+		log.Printf("Error al obtener la política de refresco: %s", err)
+		return
+	}
+	if politica == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Política no encontrada"})
+		return
+	}
+	ctx.JSON(http.StatusOK, politica)
+}
+
+// @Summary Create refresh policy
+// @Description Create the refresh policy of a cryptocurrency: a cron expression, a preferred provider and fiat, and an enabled flag
+// @Tags politicas
+// @Accept json
+// @Produce json
+// @Param politica body criptomonedas.PoliticaRefresco true "Refresh policy"
+// @Success 201 {object} criptomonedas.PoliticaRefresco
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 409 {object} map[string]string "error": "La moneda ya tiene política"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /politicas [post]
+func (c *PoliticaRefrescoController) CrearPolitica(ctx *gin.Context) {
+	var politica criptomonedas.PoliticaRefresco
+	if err := ctx.ShouldBindJSON(&politica); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos de la política inválidos"})
+		return
+	}
+	existente, err := c.serv.FindPoliticaByMonedaID(politica.CriptoMoneda_ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar la política de refresco"})
+		log.Printf("Error al guardar la política de refresco: %s", err)
+		return
+	}
+	if existente != nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "La moneda ya tiene política, se modifica con PUT /politicas/{id}"})
+		return
+	}
+	c.guardar(ctx, politica, http.StatusCreated)
+}
+
+// @Summary Update refresh policy
+// @Description Replace the refresh policy of a cryptocurrency, creating it if it does not exist
+// @Tags politicas
+// @Accept json
+// @Produce json
+// @Param id path int true "Cryptocurrency ID"
+// @Param politica body criptomonedas.PoliticaRefresco true "Refresh policy"
+// @Success 200 {object} criptomonedas.PoliticaRefresco
+// @Failure 400 {object} map[string]string "error": "Bad Request"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /politicas/{id} [put]
+func (c *PoliticaRefrescoController) ActualizarPolitica(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	var politica criptomonedas.PoliticaRefresco
+	if err := ctx.ShouldBindJSON(&politica); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos de la política inválidos"})
+		return
+	}
+	politica.CriptoMoneda_ID = id
+	c.guardar(ctx, politica, http.StatusOK)
+}
+
+func (c *PoliticaRefrescoController) guardar(ctx *gin.Context, politica criptomonedas.PoliticaRefresco, status int) {
+	guardada, err := c.serv.SavePolitica(politica)
+	if errors.Is(err, services.ErrPoliticaInvalida) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar la política de refresco"})
+		log.Printf("Error al guardar la política de refresco: %s", err)
+		return
+	}
+	ctx.JSON(status, guardada)
+}
+
+// @Summary Delete refresh policy
+// @Description Delete the refresh policy of a cryptocurrency, which goes back to the global poller interval
+// @Tags politicas
+// @Produce json
+// @Param id path int true "Cryptocurrency ID"
+// @Success 200 {object} map[string]string "message": "Política borrada"
+// @Failure 400 {object} map[string]string "error": "ID inválido"
+// @Failure 404 {object} map[string]string "error": "Política no encontrada"
+// @Failure 500 {object} map[string]string "error": "Internal Server Error"
+// @Router /politicas/{id} [delete]
+func (c *PoliticaRefrescoController) BorrarPolitica(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	politica, err := c.serv.DeletePolitica(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error al borrar la política de refresco"})
+		log.Printf("Error al borrar la política de refresco: %s", err)
+		return
+	}
+	if politica == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Política no encontrada"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Política borrada"})
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+	"primerProjecto/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PollerController struct {
+	serv *services.PollerService
+}
+
+func NewPollerController(service *services.PollerService) *PollerController {
+	return &PollerController{serv: service}
+}
+
+// @Summary Background poller status
+// @Description Show the poller configuration, its runs and the last successful refresh and last error of each cryptocurrency
+// @Tags cryptocurrencies
+// @Produce json
+// @Success 200 {object} services.EstadoPoller
+// @Router /poller/estado [get]
+func (c *PollerController) FindEstado(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.serv.Estado())
+}
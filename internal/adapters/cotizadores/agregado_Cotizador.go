@@ -0,0 +1,198 @@
+package cotizadores
+
+import (
+	"context"
+	"fmt"
+	"math"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Métodos con los que el cotizador agregado combina los precios de sus fuentes.
+const (
+	MetodoMediana   = "mediana"
+	MetodoPonderado = "ponderado"
+)
+
+// UmbralDesvioPorDefecto es el desvío máximo respecto de la mediana, como fracción, para aceptar una fuente.
+const UmbralDesvioPorDefecto = 0.05
+
+// MinimoFuentesDescarte es la cantidad de precios necesaria para saber cuál se aleja de la mediana. Con dos, la
+// mediana es el promedio y las dos se alejan lo mismo.
+const MinimoFuentesDescarte = 3
+
+// FuenteAgregada es un cotizador registrado que participa del precio agregado.
+type FuenteAgregada struct {
+	// Nombre es el nombre con el que se busca en CotizadoresMap, con opciones incluidas.
+	Nombre string
+	// Peso se usa con el método ponderado. Un peso en cero cuenta como 1.
+	Peso float64
+}
+
+// ResultadoFuente indica qué devolvió cada fuente y si se usó para el precio final.
+type ResultadoFuente struct {
+	Nombre     string  `json:"nombre"`
+	Precio     float64 `json:"precio,omitempty"`
+	Peso       float64 `json:"peso"`
+	Usada      bool    `json:"usada"`
+	Descartada bool    `json:"descartada"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// ReporteAgregado es el detalle de una cotización agregada.
+type ReporteAgregado struct {
+	Precio  float64           `json:"precio"`
+	Metodo  string            `json:"metodo"`
+	Umbral  float64           `json:"umbral"`
+	Fecha   time.Time         `json:"fecha"`
+	Fuentes []ResultadoFuente `json:"fuentes"`
+}
+
+// AgregadoCotizador consulta varios cotizadores registrados en paralelo, descarta los que se
+// alejan de la mediana más que el umbral y combina el resto.
+type AgregadoCotizador struct {
+	fuentes []FuenteAgregada
+	metodo  string
+	umbral  float64
+}
+
+// NewAgregadoCotizador crea un cotizador agregado. Un umbral en cero desactiva el descarte de fuentes.
+func NewAgregadoCotizador(fuentes []FuenteAgregada, metodo string, umbral float64) *AgregadoCotizador {
+	if metodo == "" {
+		metodo = MetodoMediana
+	}
+	return &AgregadoCotizador{fuentes: fuentes, metodo: metodo, umbral: umbral}
+}
+
+// ConOpciones devuelve una copia con otro método y umbral: "<mediana|ponderado>[:<umbral>]".
+func (s *AgregadoCotizador) ConOpciones(opciones string) (Cotizador, error) {
+	metodo, umbral, _ := strings.Cut(opciones, ":")
+	copia := *s
+	if metodo != "" {
+		if metodo != MetodoMediana && metodo != MetodoPonderado {
+			return nil, fmt.Errorf("método %s no soportado por el cotizador agregado", metodo)
+		}
+		copia.metodo = metodo
+	}
+	if umbral != "" {
+		valor, err := strconv.ParseFloat(umbral, 64)
+		if err != nil || valor < 0 {
+			return nil, fmt.Errorf("umbral %s inválido para el cotizador agregado", umbral)
+		}
+		copia.umbral = valor
+	}
+	return &copia, nil
+}
+
+func (s *AgregadoCotizador) compuesto() {}
+
+// proveedores devuelve todas las fuentes, porque se consultan todas en cada cotización.
+func (s *AgregadoCotizador) proveedores() ([]string, bool) {
+	nombres := make([]string, 0, len(s.fuentes))
+	for _, fuente := range s.fuentes {
+		base, _, _ := strings.Cut(fuente.Nombre, ":")
+		nombres = append(nombres, base)
+	}
+	return nombres, false
+}
+
+func (s *AgregadoCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	reporte, err := s.agregar(ctx, moneda, codigo, fiat, volumen)
+	if err != nil {
+		return criptomonedas.Cotizacion{}, err
+	}
+	return criptomonedas.Cotizacion{
+		Cotizacion: reporte.Precio,
+		Fecha:      reporte.Fecha,
+		Fuente:     "agregado",
+	}, nil
+}
+
+func (s *AgregadoCotizador) GetCotizacionAgregada(ctx context.Context, moneda, codigo, fiat string, volumen float64) (ReporteAgregado, error) {
+	return s.agregar(ctx, moneda, codigo, fiat, volumen)
+}
+
+// agregar consulta las fuentes con el volumen pedido, que solo cambia el precio de las que tienen libro de órdenes.
+func (s *AgregadoCotizador) agregar(ctx context.Context, moneda, codigo, fiat string, volumen float64) (ReporteAgregado, error) {
+	resultados := make([]ResultadoFuente, len(s.fuentes))
+
+	var wg sync.WaitGroup
+	for i, fuente := range s.fuentes {
+		peso := fuente.Peso
+		if peso <= 0 {
+			peso = 1
+		}
+		resultados[i] = ResultadoFuente{Nombre: fuente.Nombre, Peso: peso}
+
+		wg.Add(1)
+		go func(resultado *ResultadoFuente) {
+			defer wg.Done()
+			cotizador, err := GetCotizador(resultado.Nombre)
+			if err != nil {
+				resultado.Error = err.Error()
+				return
+			}
+			if _, esCompuesto := cotizador.(cotizadorCompuesto); esCompuesto {
+				resultado.Error = "un cotizador compuesto no puede usarse como fuente"
+				return
+			}
+			cotizacion, err := cotizador.GetCotizacionExterna(ctx, moneda, codigo, fiat, volumen)
+			if err != nil {
+				resultado.Error = err.Error()
+				return
+			}
+			resultado.Precio = cotizacion.Cotizacion
+		}(&resultados[i])
+	}
+	wg.Wait()
+
+	reporte := ReporteAgregado{Metodo: s.metodo, Umbral: s.umbral, Fecha: time.Now(), Fuentes: resultados}
+
+	var precios []float64
+	for _, resultado := range resultados {
+		if resultado.Error == "" && resultado.Precio > 0 {
+			precios = append(precios, resultado.Precio)
+		}
+	}
+	if len(precios) == 0 {
+		return reporte, fmt.Errorf("ninguna fuente devolvió cotización para %s/%s", codigo, fiat)
+	}
+	sort.Float64s(precios)
+	referencia := mediana(precios)
+
+	var aceptados []float64
+	var suma, pesos float64
+	for i := range resultados {
+		resultado := &resultados[i]
+		if resultado.Error != "" || resultado.Precio <= 0 {
+			continue
+		}
+		if s.umbral > 0 && math.Abs(resultado.Precio-referencia)/referencia > s.umbral {
+			if len(precios) < MinimoFuentesDescarte {
+				return reporte, fmt.Errorf("las %d fuentes que cotizaron %s/%s difieren más de %.2f%% y hacen falta al menos %d para descartar la que se aleja",
+					len(precios), codigo, fiat, s.umbral*100, MinimoFuentesDescarte)
+			}
+			resultado.Descartada = true
+			continue
+		}
+		resultado.Usada = true
+		aceptados = append(aceptados, resultado.Precio)
+		suma += resultado.Precio * resultado.Peso
+		pesos += resultado.Peso
+	}
+	if len(aceptados) == 0 {
+		return reporte, fmt.Errorf("todas las fuentes se alejaron más de %.2f%% de la mediana", s.umbral*100)
+	}
+
+	if s.metodo == MetodoPonderado {
+		reporte.Precio = suma / pesos
+	} else {
+		sort.Float64s(aceptados)
+		reporte.Precio = mediana(aceptados)
+	}
+	return reporte, nil
+}
@@ -0,0 +1,115 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BinanceBaseURL es la URL base de la API pública de Binance.
+const BinanceBaseURL = "https://api.binance.com"
+
+// SimbolosBinance traduce nuestros códigos a los activos de Binance cuando difieren.
+var SimbolosBinance = map[string]string{}
+
+// FiatBinance traduce la moneda fiat al activo con el que Binance arma el par. Binance no lista
+// pares contra USD, así que el dólar se cotiza contra USDT.
+var FiatBinance = map[string]string{
+	"USD": "USDT",
+}
+
+type BinanceCotizador struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+
+	limitador *Limitador
+}
+
+// NewBinanceCotizador crea un cotizador de Binance. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewBinanceCotizador(client *http.Client, baseURL string, timeout time.Duration) *BinanceCotizador {
+	if baseURL == "" {
+		baseURL = BinanceBaseURL
+	}
+	return &BinanceCotizador{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+	}
+}
+
+// ConLimitador hace que cada solicitud a Binance pase por el limitador. Sin limitador no hay límite.
+func (s *BinanceCotizador) ConLimitador(limitador *Limitador) *BinanceCotizador {
+	s.limitador = limitador
+	return s
+}
+
+// BinanceTickerResponse es la respuesta de /api/v3/ticker/price. El precio viene como texto.
+type BinanceTickerResponse struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+// binanceError es el cuerpo que devuelve Binance en las respuestas con error.
+type binanceError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// BinanceSimbolo arma el par de Binance para un código y una moneda fiat, por ejemplo BTCUSDT.
+func BinanceSimbolo(codigo, fiat string) string {
+	activo := strings.ToUpper(codigo)
+	if simbolo, ok := SimbolosBinance[activo]; ok {
+		activo = simbolo
+	}
+	cotizado := strings.ToUpper(fiat)
+	if simbolo, ok := FiatBinance[cotizado]; ok {
+		cotizado = simbolo
+	}
+	return activo + cotizado
+}
+
+func (s *BinanceCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var cotizacion criptomonedas.Cotizacion
+	simbolo := BinanceSimbolo(codigo, fiat)
+
+	tickerURL := fmt.Sprintf("%s/api/v3/ticker/price?symbol=%s", s.baseURL, simbolo)
+	resp, err := httpGet(ctx, s.client, s.limitador, tickerURL)
+	if err != nil {
+		return cotizacion, fmt.Errorf("error al obtener la cotización: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiError binanceError
+		if json.NewDecoder(resp.Body).Decode(&apiError) == nil && apiError.Msg != "" {
+			return cotizacion, fmt.Errorf("error en la solicitud de cotización de %s: %s", simbolo, apiError.Msg)
+		}
+		return cotizacion, fmt.Errorf("error en la solicitud de cotización: %s", resp.Status)
+	}
+
+	var result BinanceTickerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return cotizacion, fmt.Errorf("error al decodificar la respuesta de cotización: %v", err)
+	}
+	precio, err := strconv.ParseFloat(result.Price, 64)
+	if err != nil {
+		return cotizacion, fmt.Errorf("precio %q inválido en la respuesta de Binance", result.Price)
+	}
+
+	cotizacion = criptomonedas.Cotizacion{
+		Cotizacion: precio,
+		Fecha:      time.Now(),
+		Fuente:     "binance",
+	}
+	return cotizacion, nil
+}
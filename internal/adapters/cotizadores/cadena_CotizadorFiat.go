@@ -0,0 +1,29 @@
+package cotizadores
+
+import (
+	"context"
+	"errors"
+)
+
+// CadenaCotizadorFiat consulta los proveedores en orden y devuelve la primera tasa que alguno informa. Sirve
+// para juntar proveedores que cotizan pares distintos, como el dólar de CriptoYa y las tasas del BCE.
+type CadenaCotizadorFiat []CotizadorFiat
+
+// NewCadenaCotizadorFiat crea la cadena con los proveedores en el orden en que se consultan.
+func NewCadenaCotizadorFiat(proveedores ...CotizadorFiat) CadenaCotizadorFiat {
+	return CadenaCotizadorFiat(proveedores)
+}
+
+// GetTasaFiat devuelve la tasa del primer proveedor que cotiza el par. Si ninguno lo hace devuelve los
+// errores de todos.
+func (c CadenaCotizadorFiat) GetTasaFiat(ctx context.Context, base, destino, tipo string) (TasaFiat, error) {
+	var errs []error
+	for _, proveedor := range c {
+		tasa, err := proveedor.GetTasaFiat(ctx, base, destino, tipo)
+		if err == nil {
+			return tasa, nil
+		}
+		errs = append(errs, err)
+	}
+	return TasaFiat{}, errors.Join(errs...)
+}
@@ -0,0 +1,263 @@
+package cotizadores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Estados posibles de un circuit breaker.
+const (
+	CircuitoCerrado     = "cerrado"
+	CircuitoAbierto     = "abierto"
+	CircuitoSemiAbierto = "semi-abierto"
+)
+
+// ConfiguracionBreaker define cuándo se abre un circuito y cuánto espera antes de probar de nuevo.
+type ConfiguracionBreaker struct {
+	// UmbralFallas es la cantidad de fallas consecutivas que abren el circuito.
+	UmbralFallas int
+	// Espera es el tiempo que el circuito queda abierto antes de dejar pasar una prueba.
+	Espera time.Duration
+}
+
+// BreakerPorDefecto es la configuración que reciben los proveedores sin configuración propia.
+var BreakerPorDefecto = ConfiguracionBreaker{UmbralFallas: 5, Espera: 30 * time.Second}
+
+// CircuitoAbiertoError se devuelve cuando se rechaza una consulta porque el circuito del proveedor está abierto.
+type CircuitoAbiertoError struct {
+	Proveedor   string
+	ReintentoEn time.Time
+}
+
+func (e *CircuitoAbiertoError) Error() string {
+	return fmt.Sprintf("el circuito del cotizador %s está abierto hasta %s", e.Proveedor, e.ReintentoEn.Format(time.RFC3339))
+}
+
+// EstadoCircuito es una foto del estado de un circuit breaker.
+type EstadoCircuito struct {
+	Proveedor          string     `json:"proveedor"`
+	Estado             string     `json:"estado"`
+	FallasConsecutivas int        `json:"fallas_consecutivas"`
+	UmbralFallas       int        `json:"umbral_fallas"`
+	UltimoError        string     `json:"ultimo_error,omitempty"`
+	AbiertoDesde       *time.Time `json:"abierto_desde,omitempty"`
+	ProximoIntento     *time.Time `json:"proximo_intento,omitempty"`
+}
+
+// CircuitBreaker corta las consultas a un proveedor luego de UmbralFallas fallas consecutivas.
+// Pasada la espera deja pasar una única consulta de prueba: si responde se cierra, si no vuelve a abrirse.
+type CircuitBreaker struct {
+	mu                 sync.Mutex
+	proveedor          string
+	config             ConfiguracionBreaker
+	estado             string
+	fallasConsecutivas int
+	ultimoError        string
+	abiertoDesde       time.Time
+	ahora              func() time.Time
+}
+
+func NewCircuitBreaker(proveedor string, config ConfiguracionBreaker) *CircuitBreaker {
+	if config.UmbralFallas <= 0 {
+		config.UmbralFallas = BreakerPorDefecto.UmbralFallas
+	}
+	if config.Espera <= 0 {
+		config.Espera = BreakerPorDefecto.Espera
+	}
+	return &CircuitBreaker{proveedor: proveedor, config: config, estado: CircuitoCerrado, ahora: time.Now}
+}
+
+// Permitir indica si se puede consultar al proveedor. En estado semi-abierto sólo deja pasar la prueba en curso.
+func (b *CircuitBreaker) Permitir() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.estado {
+	case CircuitoAbierto:
+		reintento := b.abiertoDesde.Add(b.config.Espera)
+		if b.ahora().Before(reintento) {
+			return &CircuitoAbiertoError{Proveedor: b.proveedor, ReintentoEn: reintento}
+		}
+		b.estado = CircuitoSemiAbierto
+		return nil
+	case CircuitoSemiAbierto:
+		return &CircuitoAbiertoError{Proveedor: b.proveedor, ReintentoEn: b.ahora()}
+	default:
+		return nil
+	}
+}
+
+func (b *CircuitBreaker) RegistrarExito() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.estado = CircuitoCerrado
+	b.fallasConsecutivas = 0
+	b.ultimoError = ""
+}
+
+func (b *CircuitBreaker) RegistrarFalla(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fallasConsecutivas++
+	b.ultimoError = err.Error()
+	if b.estado == CircuitoSemiAbierto || b.fallasConsecutivas >= b.config.UmbralFallas {
+		b.estado = CircuitoAbierto
+		b.abiertoDesde = b.ahora()
+	}
+}
+
+// Liberar deja el circuito como estaba cuando una prueba terminó sin resultado, por ejemplo porque
+// el que llamó canceló el contexto. La próxima consulta vuelve a ser la prueba.
+func (b *CircuitBreaker) Liberar() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.estado == CircuitoSemiAbierto {
+		b.estado = CircuitoAbierto
+	}
+}
+
+// abierto indica si Permitir rechazaría ahora la consulta, sin pasar el circuito a semi-abierto.
+func (b *CircuitBreaker) abierto() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.estado {
+	case CircuitoAbierto:
+		return b.ahora().Before(b.abiertoDesde.Add(b.config.Espera))
+	case CircuitoSemiAbierto:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *CircuitBreaker) Estado() EstadoCircuito {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	estado := EstadoCircuito{
+		Proveedor:          b.proveedor,
+		Estado:             b.estado,
+		FallasConsecutivas: b.fallasConsecutivas,
+		UmbralFallas:       b.config.UmbralFallas,
+		UltimoError:        b.ultimoError,
+	}
+	if b.estado != CircuitoCerrado {
+		abiertoDesde := b.abiertoDesde
+		proximo := b.abiertoDesde.Add(b.config.Espera)
+		estado.AbiertoDesde = &abiertoDesde
+		estado.ProximoIntento = &proximo
+	}
+	return estado
+}
+
+// breakerCotizador envuelve un cotizador y registra cada resultado en su circuit breaker.
+type breakerCotizador struct {
+	cotizador Cotizador
+	breaker   *CircuitBreaker
+}
+
+func (s *breakerCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	if err := s.breaker.Permitir(); err != nil {
+		return criptomonedas.Cotizacion{}, err
+	}
+	cotizacion, err := s.cotizador.GetCotizacionExterna(ctx, moneda, codigo, fiat, volumen)
+	s.breaker.registrar(ctx, err)
+	return cotizacion, err
+}
+
+// registrar anota el resultado de una consulta que el breaker dejó pasar.
+func (b *CircuitBreaker) registrar(ctx context.Context, err error) {
+	if err != nil {
+		// una cancelación del que llama no es culpa del proveedor, salvo que se haya agotado el timeout propio,
+		// y tampoco lo es quedarse sin cupo en el limitador
+		var limite *LimiteExcedidoError
+		if ctx.Err() != nil || errors.As(err, &limite) {
+			b.Liberar()
+			return
+		}
+		b.RegistrarFalla(err)
+		return
+	}
+	b.RegistrarExito()
+}
+
+// breakerHistorico envuelve un HistoricalCotizador con el mismo circuit breaker que sus cotizaciones actuales.
+type breakerHistorico struct {
+	historico HistoricalCotizador
+	breaker   *CircuitBreaker
+}
+
+func (s *breakerHistorico) GetCotizacionesHistoricas(ctx context.Context, moneda, codigo, fiat string, desde, hasta time.Time, resolucion string) ([]criptomonedas.Cotizacion, error) {
+	if err := s.breaker.Permitir(); err != nil {
+		return nil, err
+	}
+	cotizaciones, err := s.historico.GetCotizacionesHistoricas(ctx, moneda, codigo, fiat, desde, hasta, resolucion)
+	s.breaker.registrar(ctx, err)
+	return cotizaciones, err
+}
+
+// breakerBatch envuelve un BatchCotizador con el mismo circuit breaker que sus cotizaciones de a una.
+type breakerBatch struct {
+	batch   BatchCotizador
+	breaker *CircuitBreaker
+}
+
+func (s *breakerBatch) GetCotizacionesExternas(ctx context.Context, monedas []criptomonedas.CriptoMoneda, fiat string) (map[string]criptomonedas.Cotizacion, error) {
+	if err := s.breaker.Permitir(); err != nil {
+		return nil, err
+	}
+	cotizaciones, err := s.batch.GetCotizacionesExternas(ctx, monedas, fiat)
+	s.breaker.registrar(ctx, err)
+	return cotizaciones, err
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*CircuitBreaker{}
+)
+
+// ConfigurarBreaker reemplaza el circuit breaker de un proveedor con una configuración propia.
+func ConfigurarBreaker(proveedor string, config ConfiguracionBreaker) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers[proveedor] = NewCircuitBreaker(proveedor, config)
+}
+
+func breakerPara(proveedor string) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breaker, existe := breakers[proveedor]
+	if !existe {
+		breaker = NewCircuitBreaker(proveedor, BreakerPorDefecto)
+		breakers[proveedor] = breaker
+	}
+	return breaker
+}
+
+// EstadosCircuitos devuelve el estado de los circuitos de todos los proveedores registrados, aunque todavía no se
+// hayan consultado, y de los que se configuraron aparte.
+func EstadosCircuitos() []EstadoCircuito {
+	for nombre, cotizador := range CotizadoresMap {
+		if _, esCompuesto := cotizador.(cotizadorCompuesto); !esCompuesto {
+			breakerPara(nombre)
+		}
+	}
+
+	breakersMu.Lock()
+	lista := make([]*CircuitBreaker, 0, len(breakers))
+	for _, breaker := range breakers {
+		lista = append(lista, breaker)
+	}
+	breakersMu.Unlock()
+
+	estados := make([]EstadoCircuito, 0, len(lista))
+	for _, breaker := range lista {
+		estados = append(estados, breaker.Estado())
+	}
+	sort.Slice(estados, func(i, j int) bool { return estados[i].Proveedor < estados[j].Proveedor })
+	return estados
+}
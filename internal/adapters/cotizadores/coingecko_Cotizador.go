@@ -0,0 +1,181 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+	"time"
+)
+
+// CoinGeckoBaseURL es la URL base de la API pública de CoinGecko.
+const CoinGeckoBaseURL = "https://api.coingecko.com"
+
+// SimbolosCoinGecko traduce nuestros códigos a los IDs de CoinGecko. Los códigos que no están
+// se buscan por el nombre de la moneda como lo arma idCoinGecko.
+var SimbolosCoinGecko = map[string]string{
+	"BTC":   "bitcoin",
+	"ETH":   "ethereum",
+	"USDT":  "tether",
+	"USDC":  "usd-coin",
+	"DAI":   "dai",
+	"BNB":   "binancecoin",
+	"SOL":   "solana",
+	"XRP":   "ripple",
+	"ADA":   "cardano",
+	"DOGE":  "dogecoin",
+	"DOT":   "polkadot",
+	"LTC":   "litecoin",
+	"TRX":   "tron",
+	"MATIC": "matic-network",
+	"AVAX":  "avalanche-2",
+}
+
+// idCoinGecko devuelve el ID de CoinGecko de la moneda. Sin mapeo usa el nombre en minúsculas con las
+// palabras separadas por guiones, como "shiba-inu" para Shiba Inu, que coincide con el ID en la mayoría de
+// los casos.
+func idCoinGecko(moneda, codigo string) string {
+	if id, ok := SimbolosCoinGecko[strings.ToUpper(codigo)]; ok {
+		return id
+	}
+	return strings.Join(strings.Fields(strings.ToLower(moneda)), "-")
+}
+
+type CoinGeckoCotizador struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+
+	limitador *Limitador
+}
+
+// NewCoinGeckoCotizador crea un cotizador de CoinGecko. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewCoinGeckoCotizador(client *http.Client, baseURL string, timeout time.Duration) *CoinGeckoCotizador {
+	if baseURL == "" {
+		baseURL = CoinGeckoBaseURL
+	}
+	return &CoinGeckoCotizador{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+	}
+}
+
+// ConLimitador hace que cada solicitud a CoinGecko pase por el limitador. Sin limitador no hay límite.
+func (s *CoinGeckoCotizador) ConLimitador(limitador *Limitador) *CoinGeckoCotizador {
+	s.limitador = limitador
+	return s
+}
+
+// CoinGeckoResponse es la respuesta de /simple/price: precio por ID y por moneda fiat en minúsculas,
+// junto con last_updated_at en segundos Unix.
+type CoinGeckoResponse map[string]map[string]float64
+
+func (s *CoinGeckoCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var cotizacion criptomonedas.Cotizacion
+	id := idCoinGecko(moneda, codigo)
+	vs := strings.ToLower(fiat)
+
+	priceURL := fmt.Sprintf("%s/api/v3/simple/price?ids=%s&vs_currencies=%s&include_last_updated_at=true", s.baseURL, url.QueryEscape(id), url.QueryEscape(vs))
+	resp, err := httpGet(ctx, s.client, s.limitador, priceURL)
+	if err != nil {
+		return cotizacion, fmt.Errorf("error al obtener la cotización: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return cotizacion, fmt.Errorf("error en la solicitud de cotización: %s", resp.Status)
+	}
+
+	var result CoinGeckoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return cotizacion, fmt.Errorf("error al decodificar la respuesta de cotización: %v", err)
+	}
+
+	// CoinGecko responde {} cuando no conoce el ID, y sin la moneda fiat cuando no la cotiza
+	precios, ok := result[id]
+	if !ok {
+		return cotizacion, fmt.Errorf("no se encontró la criptomoneda %s en CoinGecko", moneda)
+	}
+	precio, ok := precios[vs]
+	if !ok {
+		return cotizacion, fmt.Errorf("no se encontró la cotización para la moneda fiat %s", fiat)
+	}
+
+	cotizacion = criptomonedas.Cotizacion{
+		Cotizacion: precio,
+		Fecha:      time.Now(),
+		Fuente:     "coingecko",
+	}
+	if ultima := int64(precios["last_updated_at"]); ultima > 0 {
+		fecha := time.Unix(ultima, 0)
+		cotizacion.FechaProveedor = &fecha
+	}
+	return cotizacion, nil
+}
+
+// CoinGeckoRangoResponse es la respuesta de /coins/{id}/market_chart/range: pares [milisegundos Unix, precio].
+type CoinGeckoRangoResponse struct {
+	Prices [][2]float64 `json:"prices"`
+}
+
+// GetCotizacionesHistoricas consulta /coins/{id}/market_chart/range. CoinGecko elige la granularidad según
+// el largo del rango (cada 5 minutos hasta un día, horaria hasta 90 días y diaria después), así que se
+// conserva el primer precio de cada hora o de cada día según la resolución pedida.
+func (s *CoinGeckoCotizador) GetCotizacionesHistoricas(ctx context.Context, moneda, codigo, fiat string, desde, hasta time.Time, resolucion string) ([]criptomonedas.Cotizacion, error) {
+	paso := PasoResolucion(resolucion)
+	if paso == 0 {
+		return nil, fmt.Errorf("resolución %s no soportada por coingecko", resolucion)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	id := idCoinGecko(moneda, codigo)
+	rangoURL := fmt.Sprintf("%s/api/v3/coins/%s/market_chart/range?vs_currency=%s&from=%d&to=%d",
+		s.baseURL, url.PathEscape(id), url.QueryEscape(strings.ToLower(fiat)), desde.Unix(), hasta.Unix())
+	resp, err := httpGet(ctx, s.client, s.limitador, rangoURL)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las cotizaciones históricas: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no se encontró la criptomoneda %s en CoinGecko", moneda)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error en la solicitud de cotizaciones históricas: %s", resp.Status)
+	}
+
+	var result CoinGeckoRangoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error al decodificar las cotizaciones históricas: %v", err)
+	}
+
+	var cotizaciones []criptomonedas.Cotizacion
+	var ultimoPaso time.Time
+	for _, punto := range result.Prices {
+		fecha := time.UnixMilli(int64(punto[0])).UTC()
+		if fecha.Before(desde) || fecha.After(hasta) {
+			continue
+		}
+		inicio := fecha.Truncate(paso)
+		if !ultimoPaso.IsZero() && !inicio.After(ultimoPaso) {
+			continue
+		}
+		ultimoPaso = inicio
+		cotizaciones = append(cotizaciones, criptomonedas.Cotizacion{
+			Cotizacion:     punto[1],
+			Fecha:          fecha,
+			Fuente:         "coingecko",
+			FechaProveedor: &fecha,
+		})
+	}
+	return cotizaciones, nil
+}
@@ -4,24 +4,205 @@ package cotizadores
 //go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+	"time"
 )
 
+// DefaultTimeout es el tiempo máximo que se espera la respuesta de un proveedor externo.
+const DefaultTimeout = 10 * time.Second
+
+// VolumenPorDefecto es la cantidad de la moneda que se cotiza cuando no se indica un volumen.
+const VolumenPorDefecto = 0.1
+
+// Cotizador consulta la cotización actual de una moneda. El volumen es la cantidad de la moneda que se quiere
+// operar, en cero toma VolumenPorDefecto. Los proveedores con libro de órdenes, como CriptoYa, cotizan ese
+// volumen e informan en la cotización el volumen usado y los precios efectivos con comisiones; los demás lo
+// ignoran porque su precio no depende de la cantidad.
 type Cotizador interface {
-	GetCotizacionExterna(moneda, codigo, fiat string) (criptomonedas.Cotizacion, error)
+	GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error)
+}
+
+// ExchangesCotizador lo implementan los proveedores que informan la cotización de varios exchanges
+// en una misma consulta, como CriptoYa. Los precios con comisiones corresponden al volumen pedido.
+type ExchangesCotizador interface {
+	GetExchanges(ctx context.Context, codigo, fiat string, volumen float64) (map[string]Exchange, error)
+}
+
+// CotizadorConfigurable lo implementan los cotizadores que aceptan opciones en su nombre,
+// por ejemplo "criptoya:letsbit:bid".
+type CotizadorConfigurable interface {
+	ConOpciones(opciones string) (Cotizador, error)
+}
+
+// CotizadorAgregado lo implementan los cotizadores que pueden informar qué fuentes usaron. El volumen se
+// pasa a las fuentes como en GetCotizacionExterna.
+type CotizadorAgregado interface {
+	GetCotizacionAgregada(ctx context.Context, moneda, codigo, fiat string, volumen float64) (ReporteAgregado, error)
+}
+
+// CotizadorFiat lo implementan los proveedores de tipos de cambio entre monedas fiat. El tipo
+// elige la cotización cuando hay más de una, por ejemplo oficial, MEP o blue para el peso argentino.
+type CotizadorFiat interface {
+	GetTasaFiat(ctx context.Context, base, destino, tipo string) (TasaFiat, error)
+}
+
+// Resoluciones de las cotizaciones históricas.
+const (
+	ResolucionDiaria  = "diaria"
+	ResolucionHoraria = "horaria"
+)
+
+// PasoResolucion devuelve el tiempo entre dos cotizaciones históricas de la resolución, o cero si no existe.
+func PasoResolucion(resolucion string) time.Duration {
+	switch resolucion {
+	case ResolucionDiaria:
+		return 24 * time.Hour
+	case ResolucionHoraria:
+		return time.Hour
+	}
+	return 0
+}
+
+// HistoricalCotizador lo implementan los proveedores que informan cotizaciones pasadas. Devuelve una
+// cotización por paso de la resolución entre desde y hasta inclusive, ordenadas por fecha, con Fecha y
+// FechaProveedor en el momento al que corresponde cada precio.
+type HistoricalCotizador interface {
+	GetCotizacionesHistoricas(ctx context.Context, moneda, codigo, fiat string, desde, hasta time.Time, resolucion string) ([]criptomonedas.Cotizacion, error)
+}
+
+// BatchCotizador lo implementan los proveedores que cotizan varias monedas en una misma consulta, como
+// CoinPaprika. Devuelve la cotización de cada moneda que el proveedor informó, por nombre de moneda; las que
+// no están en el mapa no se pudieron cotizar. El error es de la consulta entera.
+type BatchCotizador interface {
+	GetCotizacionesExternas(ctx context.Context, monedas []criptomonedas.CriptoMoneda, fiat string) (map[string]criptomonedas.Cotizacion, error)
 }
 
 var CotizadoresMap = map[string]Cotizador{
-	"coinpaprika": &CoinPaprikaCotizador{},
-	"criptoya":    &CryptoYaCotizador{},
-	// Agrega otros cotizadores aquí, como Cryptoya.
+	"coinpaprika": NewCoinPaprikaCotizador(nil, "", 0).ConLimitador(LimitadorPara("coinpaprika")),
+	"criptoya":    NewCryptoYaCotizador(nil, "", 0).ConLimitador(LimitadorPara("criptoya")),
+	"coingecko":   NewCoinGeckoCotizador(nil, "", 0).ConLimitador(LimitadorPara("coingecko")),
+	"binance":     NewBinanceCotizador(nil, "", 0).ConLimitador(LimitadorPara("binance")),
+	"kraken":      NewKrakenCotizador(nil, "", 0).ConLimitador(LimitadorPara("kraken")),
+	// el agregado necesita al menos MinimoFuentesDescarte precios para descartar una fuente desviada
+	"agregado": NewAgregadoCotizador([]FuenteAgregada{
+		{Nombre: "coinpaprika", Peso: 1},
+		{Nombre: "coingecko", Peso: 1},
+		{Nombre: "binance", Peso: 1},
+		{Nombre: "kraken", Peso: 1},
+		{Nombre: "criptoya:mediana:ask", Peso: 1},
+	}, MetodoMediana, UmbralDesvioPorDefecto),
+	"fallback": NewFallbackCotizador(OrdenFallbackPorDefecto),
+	// Agrega otros cotizadores aquí.
+}
+
+// cotizadorCompuesto lo implementan los cotizadores que sólo delegan en otros, como el agregado o el fallback.
+// No llevan circuit breaker propio porque ya lo tienen los proveedores que consultan.
+type cotizadorCompuesto interface {
+	compuesto()
+	// proveedores devuelve los proveedores que consultará la próxima cotización. El segundo valor es true
+	// si se prueban en orden hasta que uno responda y false si se consultan todos.
+	proveedores() ([]string, bool)
+}
+
+// ProveedoresConsultados devuelve los proveedores que consultará una cotización con el cotizador name, por
+// nombre base. Un proveedor se devuelve a sí mismo. El segundo valor es true si alcanza con uno de ellos,
+// como en el fallback, y false si se consultan todos, como en el agregado.
+func ProveedoresConsultados(name string) ([]string, bool) {
+	base, _, _ := strings.Cut(name, ":")
+	cotizador, err := GetCotizador(name)
+	if err != nil {
+		return []string{base}, false
+	}
+	if compuesto, ok := cotizador.(cotizadorCompuesto); ok {
+		return compuesto.proveedores()
+	}
+	return []string{base}, false
 }
 
+// GetCotizador busca el cotizador por nombre. El nombre puede llevar opciones separadas
+// por ":" que se pasan al cotizador si implementa CotizadorConfigurable. Los proveedores
+// se devuelven envueltos en el circuit breaker de su nombre base.
 func GetCotizador(name string) (Cotizador, error) {
+	base, opciones, _ := strings.Cut(name, ":")
+	cotizador, exists := CotizadoresMap[base]
+	if !exists {
+		return nil, fmt.Errorf("cotizador %s no soportado", name)
+	}
+	if opciones != "" {
+		configurable, ok := cotizador.(CotizadorConfigurable)
+		if !ok {
+			return nil, fmt.Errorf("el cotizador %s no acepta opciones", base)
+		}
+		var err error
+		cotizador, err = configurable.ConOpciones(opciones)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, esCompuesto := cotizador.(cotizadorCompuesto); esCompuesto {
+		return cotizador, nil
+	}
+	return &breakerCotizador{cotizador: cotizador, breaker: breakerPara(base)}, nil
+}
+
+// GetCotizadorHistorico busca por nombre un proveedor que informe cotizaciones históricas, envuelto en el
+// circuit breaker de su nombre.
+func GetCotizadorHistorico(name string) (HistoricalCotizador, error) {
 	cotizador, exists := CotizadoresMap[name]
 	if !exists {
 		return nil, fmt.Errorf("cotizador %s no soportado", name)
 	}
-	return cotizador, nil
+	historico, ok := cotizador.(HistoricalCotizador)
+	if !ok {
+		return nil, fmt.Errorf("el cotizador %s no informa cotizaciones históricas", name)
+	}
+	return &breakerHistorico{historico: historico, breaker: breakerPara(name)}, nil
+}
+
+// GetCotizadorBatch busca por nombre un proveedor que cotice varias monedas en una consulta, envuelto en el
+// circuit breaker de su nombre.
+func GetCotizadorBatch(name string) (BatchCotizador, error) {
+	cotizador, exists := CotizadoresMap[name]
+	if !exists {
+		return nil, fmt.Errorf("cotizador %s no soportado", name)
+	}
+	batch, ok := cotizador.(BatchCotizador)
+	if !ok {
+		return nil, fmt.Errorf("el cotizador %s no cotiza varias monedas en una consulta", name)
+	}
+	return &breakerBatch{batch: batch, breaker: breakerPara(name)}, nil
+}
+
+// httpGet realiza un GET atado al contexto recibido, de forma que la solicitud se corta si el contexto se cancela.
+// Si hay limitador, antes de salir espera su turno o devuelve LimiteExcedidoError.
+func httpGet(ctx context.Context, client *http.Client, limitador *Limitador, url string) (*http.Response, error) {
+	if limitador != nil {
+		if err := limitador.Esperar(ctx); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// valores por defecto compartidos por los constructores de cotizadores
+func defaultClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{}
+	}
+	return client
+}
+
+func defaultTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultTimeout
+	}
+	return timeout
 }
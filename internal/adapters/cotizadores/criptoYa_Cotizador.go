@@ -1,10 +1,14 @@
 package cotizadores
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,33 +32,168 @@ type CryptoYaQueryResponse struct { //
 	X4T          Exchange `json:"x4t"`
 }
 
-type CryptoYaCotizador struct{}
+// Exchanges devuelve los exchanges de la respuesta indexados por su nombre en la API.
+func (r CryptoYaQueryResponse) Exchanges() map[string]Exchange {
+	return map[string]Exchange{
+		"satoshitango": r.SatoshiTango,
+		"letsbit":      r.LetsBit,
+		"binancep2p":   r.BinanceP2P,
+		"fiwind":       r.FiWind,
+		"tiendacrypto": r.TiendaCrypto,
+		"calypso":      r.Calypso,
+		"banexcoin":    r.BanexCoin,
+		"bitsoalpha":   r.BitsoAlpha,
+		"x4t":          r.X4T,
+	}
+}
 
-func (s *CryptoYaCotizador) GetCotizacionExterna(moneda, codigo, fiat string) (criptomonedas.Cotizacion, error) {
+// Lado indica qué precio del exchange se toma.
+type Lado string
 
-	volumen := 0.1
-	var cotizacion criptomonedas.Cotizacion
+const (
+	LadoAsk      Lado = "ask"
+	LadoBid      Lado = "bid"
+	LadoTotalAsk Lado = "totalAsk"
+	LadoTotalBid Lado = "totalBid"
+)
+
+// Precio devuelve el valor del exchange para el lado pedido.
+func (e Exchange) Precio(lado Lado) float64 {
+	switch lado {
+	case LadoBid:
+		return e.Bid
+	case LadoTotalAsk:
+		return e.TotalAsk
+	case LadoTotalBid:
+		return e.TotalBid
+	default:
+		return e.Ask
+	}
+}
+
+// esCompra indica si el lado corresponde a lo que paga el usuario al comprar (ask).
+func (l Lado) esCompra() bool {
+	return l == LadoAsk || l == LadoTotalAsk
+}
+
+// Estrategias de agregación que se pueden usar en lugar de un exchange puntual.
+const (
+	// EstrategiaMejor toma el menor ask o el mayor bid entre todos los exchanges.
+	EstrategiaMejor = "mejor"
+	// EstrategiaMediana toma la mediana del lado elegido entre todos los exchanges.
+	EstrategiaMediana = "mediana"
+)
+
+// ExchangePorDefecto es el exchange que se usa si no se elige ninguno.
+const ExchangePorDefecto = "satoshitango"
+
+// CryptoYaBaseURL es la URL base de la API pública de CriptoYa.
+const CryptoYaBaseURL = "https://criptoya.com"
+
+type CryptoYaCotizador struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+
+	limitador *Limitador
+
+	// seleccion es un exchange (por ejemplo "letsbit") o una estrategia de agregación
+	seleccion string
+	lado      Lado
+}
+
+// NewCryptoYaCotizador crea un cotizador de CriptoYa. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewCryptoYaCotizador(client *http.Client, baseURL string, timeout time.Duration) *CryptoYaCotizador {
+	if baseURL == "" {
+		baseURL = CryptoYaBaseURL
+	}
+	return &CryptoYaCotizador{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+
+		seleccion: ExchangePorDefecto,
+		lado:      LadoAsk,
+	}
+}
+
+// ConLimitador hace que cada solicitud a CriptoYa pase por el limitador. Sin limitador no hay límite.
+func (s *CryptoYaCotizador) ConLimitador(limitador *Limitador) *CryptoYaCotizador {
+	s.limitador = limitador
+	return s
+}
+
+// ConOpciones devuelve una copia del cotizador que toma el precio según las opciones
+// "<exchange|mejor|mediana>[:<ask|bid|totalAsk|totalBid>]".
+func (s *CryptoYaCotizador) ConOpciones(opciones string) (Cotizador, error) {
+	seleccion, lado, _ := strings.Cut(opciones, ":")
+	copia := *s
+	if seleccion != "" {
+		seleccion = strings.ToLower(seleccion)
+		if _, existe := (CryptoYaQueryResponse{}).Exchanges()[seleccion]; !existe && seleccion != EstrategiaMejor && seleccion != EstrategiaMediana {
+			return nil, fmt.Errorf("exchange o estrategia %s no soportado por criptoya", seleccion)
+		}
+		copia.seleccion = seleccion
+	}
+	if lado != "" {
+		switch Lado(lado) {
+		case LadoAsk, LadoBid, LadoTotalAsk, LadoTotalBid:
+			copia.lado = Lado(lado)
+		default:
+			return nil, fmt.Errorf("lado %s no soportado por criptoya", lado)
+		}
+	}
+	return &copia, nil
+}
+
+// GetExchanges consulta CriptoYa y devuelve las cotizaciones de todos los exchanges. Los precios con comisiones
+// son los de operar el volumen pedido, VolumenPorDefecto si viene en cero.
+func (s *CryptoYaCotizador) GetExchanges(ctx context.Context, codigo, fiat string, volumen float64) (map[string]Exchange, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if volumen <= 0 {
+		volumen = VolumenPorDefecto
+	}
 
 	// Construir la URL del endpoint
-	url := fmt.Sprintf("https://criptoya.com/api/%s/%s/%.2f", codigo, fiat, volumen)
+	url := fmt.Sprintf("%s/api/%s/%s/%s", s.baseURL, codigo, fiat, strconv.FormatFloat(volumen, 'f', -1, 64))
 
-	resp, err := http.Get(url)
+	resp, err := httpGet(ctx, s.client, s.limitador, url)
 	if err != nil {
-		return cotizacion, fmt.Errorf("error al realizar la solicitud HTTP: %v", err)
+		return nil, fmt.Errorf("error al realizar la solicitud HTTP: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return cotizacion, fmt.Errorf("error en la solicitud: %s", resp.Status)
+		return nil, fmt.Errorf("error en la solicitud: %s", resp.Status)
 	}
 
 	var apiResponse CryptoYaQueryResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return cotizacion, fmt.Errorf("error al decodificar la respuesta JSON: %v", err)
+		return nil, fmt.Errorf("error al decodificar la respuesta JSON: %v", err)
+	}
+	return apiResponse.Exchanges(), nil
+}
+
+// GetCotizacionExterna cotiza el volumen pedido e informa, además del precio del lado elegido, el totalAsk y el
+// totalBid: lo que se paga y se recibe por unidad al operar ese volumen, comisiones incluidas.
+func (s *CryptoYaCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	var cotizacion criptomonedas.Cotizacion
+	if volumen <= 0 {
+		volumen = VolumenPorDefecto
 	}
 
-	// usando el primer exchange
-	price := apiResponse.SatoshiTango.Ask
+	exchanges, err := s.GetExchanges(ctx, codigo, fiat, volumen)
+	if err != nil {
+		return cotizacion, err
+	}
+
+	seleccion, err := s.seleccionarPrecio(exchanges)
+	if err != nil {
+		return cotizacion, fmt.Errorf("%v para %s/%s", err, codigo, fiat)
+	}
 
 	/* ESTO LO TIENE QUE HACER EL SERVICE AHORA.
 	// Buscar la criptomoneda por nombre
@@ -68,9 +207,105 @@ func (s *CryptoYaCotizador) GetCotizacionExterna(moneda, codigo, fiat string) (c
 	}
 	*/
 	cotizacion = criptomonedas.Cotizacion{
-		Cotizacion: price,
+		Cotizacion: seleccion.precio,
 		Fecha:      time.Now(),
+		Fuente:     "criptoya",
+		Exchange:   seleccion.exchange,
+		Volumen:    volumen,
+		TotalAsk:   seleccion.totalAsk,
+		TotalBid:   seleccion.totalBid,
+	}
+	if seleccion.time > 0 {
+		fecha := time.Unix(seleccion.time, 0)
+		cotizacion.FechaProveedor = &fecha
 	}
 
 	return cotizacion, nil
 }
+
+// precioSeleccionado es el precio elegido junto con el exchange que lo informó y sus precios con
+// comisiones. Con la mediana no hay un exchange único, time es el más viejo de los exchanges usados y
+// los precios con comisiones son también medianas.
+type precioSeleccionado struct {
+	precio   float64
+	exchange string
+	time     int64
+	totalAsk float64
+	totalBid float64
+}
+
+// seleccionarPrecio aplica el exchange o la estrategia configurada. Si el exchange elegido
+// no informa precio se devuelve un error en lugar de tomar el de otro exchange.
+func (s *CryptoYaCotizador) seleccionarPrecio(exchanges map[string]Exchange) (precioSeleccionado, error) {
+	if s.seleccion != EstrategiaMejor && s.seleccion != EstrategiaMediana {
+		exchange := exchanges[s.seleccion]
+		precio := exchange.Precio(s.lado)
+		if precio <= 0 {
+			return precioSeleccionado{}, fmt.Errorf("el exchange %s no informó %s", s.seleccion, s.lado)
+		}
+		return precioSeleccionado{precio: precio, exchange: s.seleccion, time: exchange.Time, totalAsk: exchange.TotalAsk, totalBid: exchange.TotalBid}, nil
+	}
+
+	var candidatos []precioSeleccionado
+	for nombre, exchange := range exchanges {
+		if precio := exchange.Precio(s.lado); precio > 0 {
+			candidatos = append(candidatos, precioSeleccionado{precio: precio, exchange: nombre, time: exchange.Time, totalAsk: exchange.TotalAsk, totalBid: exchange.TotalBid})
+		}
+	}
+	if len(candidatos) == 0 {
+		return precioSeleccionado{}, fmt.Errorf("ningún exchange informó %s", s.lado)
+	}
+	// a igual precio se ordena por nombre para que el exchange elegido no dependa del orden del mapa
+	sort.Slice(candidatos, func(i, j int) bool {
+		if candidatos[i].precio != candidatos[j].precio {
+			return candidatos[i].precio < candidatos[j].precio
+		}
+		return candidatos[i].exchange < candidatos[j].exchange
+	})
+
+	if s.seleccion == EstrategiaMediana {
+		precios := make([]float64, len(candidatos))
+		masViejo := candidatos[0].time
+		for i, candidato := range candidatos {
+			precios[i] = candidato.precio
+			if candidato.time > 0 && (masViejo <= 0 || candidato.time < masViejo) {
+				masViejo = candidato.time
+			}
+		}
+		return precioSeleccionado{
+			precio:   mediana(precios),
+			time:     masViejo,
+			totalAsk: medianaLado(exchanges, LadoTotalAsk),
+			totalBid: medianaLado(exchanges, LadoTotalBid),
+		}, nil
+	}
+	// el mejor precio para quien compra es el ask más bajo, para quien vende el bid más alto
+	if s.lado.esCompra() {
+		return candidatos[0], nil
+	}
+	return candidatos[len(candidatos)-1], nil
+}
+
+// medianaLado calcula la mediana del lado entre los exchanges que lo informan, o cero si ninguno lo informa.
+func medianaLado(exchanges map[string]Exchange, lado Lado) float64 {
+	var precios []float64
+	for _, exchange := range exchanges {
+		if precio := exchange.Precio(lado); precio > 0 {
+			precios = append(precios, precio)
+		}
+	}
+	if len(precios) == 0 {
+		return 0
+	}
+	sort.Float64s(precios)
+	return mediana(precios)
+}
+
+// mediana calcula la mediana de una lista ya ordenada.
+func mediana(ordenados []float64) float64 {
+	n := len(ordenados)
+	if n%2 == 1 {
+		return ordenados[n/2]
+	}
+	return (ordenados[n/2-1] + ordenados[n/2]) / 2
+}
@@ -0,0 +1,171 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tipos de cambio que informa CriptoYa para el dólar en Argentina.
+const (
+	TipoCambioOficial = "oficial"
+	TipoCambioMEP     = "mep"
+	TipoCambioBlue    = "blue"
+)
+
+// TipoCambioPorDefecto es el tipo que se usa si no se pide ninguno.
+const TipoCambioPorDefecto = TipoCambioOficial
+
+// TTLDolarPorDefecto es cuánto se reutiliza la última respuesta de /api/dolar antes de volver a consultarla.
+const TTLDolarPorDefecto = time.Minute
+
+// TasaFiat es el valor de una unidad de Base expresado en Destino.
+type TasaFiat struct {
+	Base    string    `json:"base"`
+	Destino string    `json:"destino"`
+	Tipo    string    `json:"tipo"`
+	Tasa    float64   `json:"tasa"`
+	Fecha   time.Time `json:"fecha"`
+}
+
+// Invertida devuelve la tasa de Destino a Base.
+func (t TasaFiat) Invertida() TasaFiat {
+	return TasaFiat{Base: t.Destino, Destino: t.Base, Tipo: t.Tipo, Tasa: 1 / t.Tasa, Fecha: t.Fecha}
+}
+
+type precioDolar struct {
+	Price     float64 `json:"price"`
+	Ask       float64 `json:"ask"`
+	Bid       float64 `json:"bid"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// CryptoYaDolarResponse es la parte que se usa de /api/dolar. El MEP se toma del bono AL30 en contado inmediato.
+type CryptoYaDolarResponse struct {
+	Oficial precioDolar `json:"oficial"`
+	Blue    precioDolar `json:"blue"`
+	Mep     struct {
+		Al30 struct {
+			CI precioDolar `json:"ci"`
+		} `json:"al30"`
+	} `json:"mep"`
+}
+
+// DolarCotizadorFiat informa el dólar oficial, MEP y blue en pesos argentinos usando CriptoYa.
+type DolarCotizadorFiat struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+	ttl     time.Duration
+
+	limitador *Limitador
+
+	mu         sync.Mutex
+	ultima     CryptoYaDolarResponse
+	consultada time.Time
+}
+
+// NewDolarCotizadorFiat crea el proveedor de dólar de CriptoYa. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewDolarCotizadorFiat(client *http.Client, baseURL string, timeout time.Duration) *DolarCotizadorFiat {
+	if baseURL == "" {
+		baseURL = CryptoYaBaseURL
+	}
+	return &DolarCotizadorFiat{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+		ttl:     TTLDolarPorDefecto,
+	}
+}
+
+// ConLimitador hace que cada solicitud a CriptoYa pase por el limitador. Sin limitador no hay límite.
+func (s *DolarCotizadorFiat) ConLimitador(limitador *Limitador) *DolarCotizadorFiat {
+	s.limitador = limitador
+	return s
+}
+
+// GetTasaFiat sólo conoce el par USD/ARS, en cualquiera de los dos sentidos.
+func (s *DolarCotizadorFiat) GetTasaFiat(ctx context.Context, base, destino, tipo string) (TasaFiat, error) {
+	base, destino = strings.ToUpper(base), strings.ToUpper(destino)
+	if tipo == "" {
+		tipo = TipoCambioPorDefecto
+	}
+	tipo = strings.ToLower(tipo)
+	if base == destino {
+		return TasaFiat{Base: base, Destino: destino, Tipo: tipo, Tasa: 1, Fecha: time.Now()}, nil
+	}
+	if !(base == "USD" && destino == "ARS") && !(base == "ARS" && destino == "USD") {
+		return TasaFiat{}, fmt.Errorf("el par %s/%s no está soportado por el dólar de criptoya", base, destino)
+	}
+
+	dolar, err := s.consultar(ctx)
+	if err != nil {
+		return TasaFiat{}, err
+	}
+
+	var precio precioDolar
+	var valor float64
+	switch tipo {
+	case TipoCambioOficial:
+		precio = dolar.Oficial
+		valor = precio.Price
+	case TipoCambioMEP:
+		precio = dolar.Mep.Al30.CI
+		valor = precio.Price
+	case TipoCambioBlue:
+		// se toma el precio al que se compra el dólar
+		precio = dolar.Blue
+		valor = precio.Ask
+	default:
+		return TasaFiat{}, fmt.Errorf("tipo de cambio %s no soportado", tipo)
+	}
+	if valor <= 0 {
+		return TasaFiat{}, fmt.Errorf("criptoya no informó el dólar %s", tipo)
+	}
+
+	tasa := TasaFiat{Base: "USD", Destino: "ARS", Tipo: tipo, Tasa: valor, Fecha: time.Unix(precio.Timestamp, 0)}
+	if base == "ARS" {
+		return tasa.Invertida(), nil
+	}
+	return tasa, nil
+}
+
+// consultar devuelve la última respuesta si es reciente o consulta /api/dolar.
+func (s *DolarCotizadorFiat) consultar(ctx context.Context) (CryptoYaDolarResponse, error) {
+	s.mu.Lock()
+	if !s.consultada.IsZero() && time.Since(s.consultada) < s.ttl {
+		ultima := s.ultima
+		s.mu.Unlock()
+		return ultima, nil
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp, err := httpGet(ctx, s.client, s.limitador, s.baseURL+"/api/dolar")
+	if err != nil {
+		return CryptoYaDolarResponse{}, fmt.Errorf("error al obtener el dólar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return CryptoYaDolarResponse{}, fmt.Errorf("error en la solicitud del dólar: %s", resp.Status)
+	}
+
+	var dolar CryptoYaDolarResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dolar); err != nil {
+		return CryptoYaDolarResponse{}, fmt.Errorf("error al decodificar la respuesta del dólar: %v", err)
+	}
+
+	s.mu.Lock()
+	s.ultima = dolar
+	s.consultada = time.Now()
+	s.mu.Unlock()
+	return dolar, nil
+}
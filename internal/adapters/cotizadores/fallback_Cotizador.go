@@ -0,0 +1,86 @@
+package cotizadores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+)
+
+// OrdenFallbackPorDefecto es el orden en que se prueban los proveedores si no se configura otro.
+var OrdenFallbackPorDefecto = []string{"coinpaprika", "criptoya"}
+
+// FallbackCotizador prueba los cotizadores en orden y devuelve la primera cotización que obtiene.
+// Los proveedores con el circuito abierto se saltean sin consultarlos.
+type FallbackCotizador struct {
+	orden []string
+}
+
+func NewFallbackCotizador(orden []string) *FallbackCotizador {
+	if len(orden) == 0 {
+		orden = OrdenFallbackPorDefecto
+	}
+	return &FallbackCotizador{orden: orden}
+}
+
+// ConOpciones devuelve una copia con otro orden de proveedores separados por coma, por ejemplo "criptoya,coinpaprika".
+func (s *FallbackCotizador) ConOpciones(opciones string) (Cotizador, error) {
+	var orden []string
+	for _, nombre := range strings.Split(opciones, ",") {
+		if nombre = strings.TrimSpace(nombre); nombre != "" {
+			orden = append(orden, nombre)
+		}
+	}
+	if len(orden) == 0 {
+		return nil, fmt.Errorf("el cotizador fallback necesita al menos un proveedor")
+	}
+	return &FallbackCotizador{orden: orden}, nil
+}
+
+func (s *FallbackCotizador) compuesto() {}
+
+// proveedores devuelve el orden de prueba sin los proveedores que se van a saltear por tener el circuito
+// abierto. Si están todos abiertos devuelve el orden completo.
+func (s *FallbackCotizador) proveedores() ([]string, bool) {
+	var cerrados []string
+	for _, nombre := range s.orden {
+		base, _, _ := strings.Cut(nombre, ":")
+		if !breakerPara(base).abierto() {
+			cerrados = append(cerrados, base)
+		}
+	}
+	if len(cerrados) == 0 {
+		return s.orden, true
+	}
+	return cerrados, true
+}
+
+func (s *FallbackCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	var errs []error
+	for _, nombre := range s.orden {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		cotizador, err := GetCotizador(nombre)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, esCompuesto := cotizador.(cotizadorCompuesto); esCompuesto {
+			errs = append(errs, fmt.Errorf("el cotizador %s no puede usarse dentro de un fallback", nombre))
+			continue
+		}
+		cotizacion, err := cotizador.GetCotizacionExterna(ctx, moneda, codigo, fiat, volumen)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", nombre, err))
+			continue
+		}
+		if cotizacion.Fuente == "" {
+			cotizacion.Fuente = nombre
+		}
+		return cotizacion, nil
+	}
+	return criptomonedas.Cotizacion{}, fmt.Errorf("ningún cotizador de %s pudo cotizar %s/%s: %w", strings.Join(s.orden, ","), codigo, fiat, errors.Join(errs...))
+}
@@ -0,0 +1,116 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const FrankfurterBaseURL = "https://api.frankfurter.app"
+
+// TTLFrankfurterPorDefecto es cuánto se reutiliza la tasa de un par. El BCE la publica una vez por día hábil.
+const TTLFrankfurterPorDefecto = time.Hour
+
+// FrankfurterResponse es la respuesta de /latest con una sola moneda destino.
+type FrankfurterResponse struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// FrankfurterCotizadorFiat informa las tasas de referencia del Banco Central Europeo entre dólar, euro, real y
+// las demás monedas que publica, usando Frankfurter. El BCE no publica el peso argentino, que queda para
+// DolarCotizadorFiat.
+type FrankfurterCotizadorFiat struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+	ttl     time.Duration
+
+	limitador *Limitador
+
+	mu     sync.Mutex
+	tasas  map[string]TasaFiat
+	fechas map[string]time.Time
+}
+
+// NewFrankfurterCotizadorFiat crea el proveedor de tasas del BCE. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewFrankfurterCotizadorFiat(client *http.Client, baseURL string, timeout time.Duration) *FrankfurterCotizadorFiat {
+	if baseURL == "" {
+		baseURL = FrankfurterBaseURL
+	}
+	return &FrankfurterCotizadorFiat{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+		ttl:     TTLFrankfurterPorDefecto,
+		tasas:   make(map[string]TasaFiat),
+		fechas:  make(map[string]time.Time),
+	}
+}
+
+// ConLimitador hace que cada solicitud a Frankfurter pase por el limitador. Sin limitador no hay límite.
+func (s *FrankfurterCotizadorFiat) ConLimitador(limitador *Limitador) *FrankfurterCotizadorFiat {
+	s.limitador = limitador
+	return s
+}
+
+// GetTasaFiat devuelve la tasa de referencia del par. El BCE publica una sola tasa por par, así que el tipo
+// se ignora y la tasa se informa como oficial.
+func (s *FrankfurterCotizadorFiat) GetTasaFiat(ctx context.Context, base, destino, tipo string) (TasaFiat, error) {
+	base, destino = strings.ToUpper(base), strings.ToUpper(destino)
+	if base == destino {
+		return TasaFiat{Base: base, Destino: destino, Tipo: TipoCambioOficial, Tasa: 1, Fecha: time.Now()}, nil
+	}
+	if base == "ARS" || destino == "ARS" {
+		return TasaFiat{}, fmt.Errorf("el par %s/%s no está soportado por frankfurter", base, destino)
+	}
+
+	par := base + "/" + destino
+	s.mu.Lock()
+	if consultada, ok := s.fechas[par]; ok && time.Since(consultada) < s.ttl {
+		tasa := s.tasas[par]
+		s.mu.Unlock()
+		return tasa, nil
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp, err := httpGet(ctx, s.client, s.limitador, fmt.Sprintf("%s/latest?from=%s&to=%s", s.baseURL, url.QueryEscape(base), url.QueryEscape(destino)))
+	if err != nil {
+		return TasaFiat{}, fmt.Errorf("error al obtener la tasa %s: %w", par, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return TasaFiat{}, fmt.Errorf("error en la solicitud de la tasa %s: %s", par, resp.Status)
+	}
+
+	var respuesta FrankfurterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&respuesta); err != nil {
+		return TasaFiat{}, fmt.Errorf("error al decodificar la respuesta de la tasa %s: %v", par, err)
+	}
+	valor, ok := respuesta.Rates[destino]
+	if !ok || valor <= 0 {
+		return TasaFiat{}, fmt.Errorf("frankfurter no informó la tasa %s", par)
+	}
+	fecha, err := time.Parse(time.DateOnly, respuesta.Date)
+	if err != nil {
+		return TasaFiat{}, fmt.Errorf("fecha inválida en la tasa %s: %v", par, err)
+	}
+
+	tasa := TasaFiat{Base: base, Destino: destino, Tipo: TipoCambioOficial, Tasa: valor, Fecha: fecha}
+	s.mu.Lock()
+	s.tasas[par] = tasa
+	s.fechas[par] = time.Now()
+	s.mu.Unlock()
+	return tasa, nil
+}
@@ -0,0 +1,257 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfiguracionGenerico describe un proveedor HTTP/JSON sin código propio.
+type ConfiguracionGenerico struct {
+	// Nombre es la clave con la que se registra en CotizadoresMap.
+	Nombre string `json:"nombre"`
+	// URL es la plantilla de la consulta, con {codigo} y {fiat} como marcadores.
+	URL string `json:"url"`
+	// Precio es la ruta al precio dentro del JSON, por ejemplo "data.ticker.last" o "result[0].price".
+	Precio string `json:"precio"`
+	// Headers se agregan a cada solicitud, por ejemplo una API key.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Simbolos traduce nuestros códigos a los del proveedor. Los que no están se usan tal cual.
+	Simbolos map[string]string `json:"simbolos,omitempty"`
+	// Fiats traduce la moneda fiat a la del proveedor, por ejemplo USD a USDT.
+	Fiats map[string]string `json:"fiats,omitempty"`
+	// Minusculas pasa el código y la fiat a minúsculas antes de armar la URL.
+	Minusculas bool `json:"minusculas,omitempty"`
+	// Timeout acepta el formato de time.ParseDuration. Vacío toma DefaultTimeout.
+	Timeout string `json:"timeout,omitempty"`
+	// Limite es opcional; sin él el proveedor no tiene límite de solicitudes.
+	Limite *ConfiguracionLimiteArchivo `json:"limite,omitempty"`
+}
+
+// ConfiguracionLimiteArchivo es ConfiguracionLimite tal como se escribe en el archivo.
+type ConfiguracionLimiteArchivo struct {
+	TasaPorSegundo    float64 `json:"tasa_por_segundo"`
+	Rafaga            int     `json:"rafaga"`
+	PresupuestoDiario int     `json:"presupuesto_diario"`
+	Esperar           bool    `json:"esperar"`
+}
+
+// ArchivoCotizadores es el formato del archivo de cotizadores genéricos.
+type ArchivoCotizadores struct {
+	Cotizadores []ConfiguracionGenerico `json:"cotizadores"`
+}
+
+// GenericoCotizador consulta un proveedor HTTP/JSON según su ConfiguracionGenerico.
+type GenericoCotizador struct {
+	client  *http.Client
+	config  ConfiguracionGenerico
+	ruta    []string
+	timeout time.Duration
+
+	limitador *Limitador
+}
+
+// NewGenericoCotizador valida la configuración y crea el cotizador. Un client nil toma el valor por defecto.
+func NewGenericoCotizador(client *http.Client, config ConfiguracionGenerico) (*GenericoCotizador, error) {
+	if config.Nombre == "" {
+		return nil, fmt.Errorf("el cotizador genérico no tiene nombre")
+	}
+	if !strings.Contains(config.URL, "{codigo}") {
+		return nil, fmt.Errorf("la URL del cotizador %s no tiene el marcador {codigo}", config.Nombre)
+	}
+	if _, err := url.Parse(strings.NewReplacer("{codigo}", "x", "{fiat}", "x").Replace(config.URL)); err != nil {
+		return nil, fmt.Errorf("URL inválida para el cotizador %s: %v", config.Nombre, err)
+	}
+	ruta, err := parsearRutaJSON(config.Precio)
+	if err != nil {
+		return nil, fmt.Errorf("ruta de precio inválida para el cotizador %s: %v", config.Nombre, err)
+	}
+	var timeout time.Duration
+	if config.Timeout != "" {
+		timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("timeout %s inválido para el cotizador %s", config.Timeout, config.Nombre)
+		}
+	}
+	return &GenericoCotizador{
+		client:  defaultClient(client),
+		config:  config,
+		ruta:    ruta,
+		timeout: defaultTimeout(timeout),
+	}, nil
+}
+
+// ConLimitador hace que cada solicitud pase por el limitador. Sin limitador no hay límite.
+func (s *GenericoCotizador) ConLimitador(limitador *Limitador) *GenericoCotizador {
+	s.limitador = limitador
+	return s
+}
+
+// URLPara arma la URL de la consulta reemplazando los marcadores con los símbolos del proveedor.
+func (s *GenericoCotizador) URLPara(codigo, fiat string) string {
+	simbolo := strings.ToUpper(codigo)
+	if mapeado, ok := s.config.Simbolos[simbolo]; ok {
+		simbolo = mapeado
+	}
+	moneda := strings.ToUpper(fiat)
+	if mapeado, ok := s.config.Fiats[moneda]; ok {
+		moneda = mapeado
+	}
+	if s.config.Minusculas {
+		simbolo = strings.ToLower(simbolo)
+		moneda = strings.ToLower(moneda)
+	}
+	return strings.NewReplacer(
+		"{codigo}", url.PathEscape(simbolo),
+		"{fiat}", url.PathEscape(moneda),
+	).Replace(s.config.URL)
+}
+
+func (s *GenericoCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var cotizacion criptomonedas.Cotizacion
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URLPara(codigo, fiat), nil)
+	if err != nil {
+		return cotizacion, fmt.Errorf("error al armar la solicitud a %s: %v", s.config.Nombre, err)
+	}
+	for clave, valor := range s.config.Headers {
+		req.Header.Set(clave, valor)
+	}
+	if s.limitador != nil {
+		if err := s.limitador.Esperar(ctx); err != nil {
+			return cotizacion, fmt.Errorf("error al obtener la cotización: %w", err)
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return cotizacion, fmt.Errorf("error al obtener la cotización: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return cotizacion, fmt.Errorf("error en la solicitud de cotización: %s", resp.Status)
+	}
+
+	var cuerpo interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&cuerpo); err != nil {
+		return cotizacion, fmt.Errorf("error al decodificar la respuesta de cotización: %v", err)
+	}
+	precio, err := extraerPrecio(cuerpo, s.ruta)
+	if err != nil {
+		return cotizacion, fmt.Errorf("no se encontró el precio de %s/%s en %s: %v", codigo, fiat, s.config.Nombre, err)
+	}
+
+	cotizacion = criptomonedas.Cotizacion{
+		Cotizacion: precio,
+		Fecha:      time.Now(),
+		Fuente:     s.config.Nombre,
+	}
+	return cotizacion, nil
+}
+
+// parsearRutaJSON separa "data.items[0].price" en ["data", "items", "0", "price"].
+func parsearRutaJSON(ruta string) ([]string, error) {
+	if strings.TrimSpace(ruta) == "" {
+		return nil, fmt.Errorf("la ruta está vacía")
+	}
+	ruta = strings.NewReplacer("[", ".", "]", "").Replace(ruta)
+	partes := strings.Split(strings.TrimPrefix(ruta, "."), ".")
+	for _, parte := range partes {
+		if parte == "" {
+			return nil, fmt.Errorf("la ruta %s tiene un segmento vacío", ruta)
+		}
+	}
+	return partes, nil
+}
+
+// extraerPrecio recorre el JSON decodificado siguiendo la ruta. El precio puede venir como número o como texto.
+func extraerPrecio(valor interface{}, ruta []string) (float64, error) {
+	for _, parte := range ruta {
+		switch nodo := valor.(type) {
+		case map[string]interface{}:
+			siguiente, ok := nodo[parte]
+			if !ok {
+				return 0, fmt.Errorf("no existe el campo %s", parte)
+			}
+			valor = siguiente
+		case []interface{}:
+			indice, err := strconv.Atoi(parte)
+			if err != nil || indice < 0 || indice >= len(nodo) {
+				return 0, fmt.Errorf("índice %s fuera de rango", parte)
+			}
+			valor = nodo[indice]
+		default:
+			return 0, fmt.Errorf("no se puede entrar en %s", parte)
+		}
+	}
+
+	switch precio := valor.(type) {
+	case float64:
+		return precio, nil
+	case string:
+		numero, err := strconv.ParseFloat(precio, 64)
+		if err != nil {
+			return 0, fmt.Errorf("el precio %q no es un número", precio)
+		}
+		return numero, nil
+	default:
+		return 0, fmt.Errorf("el valor encontrado no es un precio")
+	}
+}
+
+// CargarCotizadoresGenericos lee el archivo de configuración y registra cada cotizador en CotizadoresMap.
+// Se llama al arrancar; un nombre repetido o ya registrado es un error y no se registra nada.
+func CargarCotizadoresGenericos(ruta string) ([]string, error) {
+	data, err := os.ReadFile(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el archivo de cotizadores %s: %v", ruta, err)
+	}
+	var archivo ArchivoCotizadores
+	if err := json.Unmarshal(data, &archivo); err != nil {
+		return nil, fmt.Errorf("no se pudo decodificar el archivo de cotizadores %s: %v", ruta, err)
+	}
+
+	nuevos := make(map[string]*GenericoCotizador, len(archivo.Cotizadores))
+	nombres := make([]string, 0, len(archivo.Cotizadores))
+	for _, config := range archivo.Cotizadores {
+		if _, existe := CotizadoresMap[config.Nombre]; existe {
+			return nil, fmt.Errorf("el cotizador %s ya está registrado", config.Nombre)
+		}
+		if _, repetido := nuevos[config.Nombre]; repetido {
+			return nil, fmt.Errorf("el cotizador %s está repetido en %s", config.Nombre, ruta)
+		}
+		if strings.Contains(config.Nombre, ":") {
+			return nil, fmt.Errorf("el nombre del cotizador %s no puede tener \":\"", config.Nombre)
+		}
+		cotizador, err := NewGenericoCotizador(nil, config)
+		if err != nil {
+			return nil, err
+		}
+		nuevos[config.Nombre] = cotizador
+		nombres = append(nombres, config.Nombre)
+	}
+
+	for _, nombre := range nombres {
+		cotizador := nuevos[nombre]
+		if limite := cotizador.config.Limite; limite != nil {
+			ConfigurarLimite(nombre, ConfiguracionLimite{
+				TasaPorSegundo:    limite.TasaPorSegundo,
+				Rafaga:            limite.Rafaga,
+				PresupuestoDiario: limite.PresupuestoDiario,
+				Esperar:           limite.Esperar,
+			})
+			cotizador.ConLimitador(LimitadorPara(nombre))
+		}
+		CotizadoresMap[nombre] = cotizador
+	}
+	return nombres, nil
+}
@@ -0,0 +1,121 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KrakenBaseURL es la URL base de la API pública de Kraken.
+const KrakenBaseURL = "https://api.kraken.com"
+
+// SimbolosKraken traduce nuestros códigos a los activos de Kraken cuando difieren.
+var SimbolosKraken = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+type KrakenCotizador struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+
+	limitador *Limitador
+}
+
+// NewKrakenCotizador crea un cotizador de Kraken. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewKrakenCotizador(client *http.Client, baseURL string, timeout time.Duration) *KrakenCotizador {
+	if baseURL == "" {
+		baseURL = KrakenBaseURL
+	}
+	return &KrakenCotizador{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+	}
+}
+
+// ConLimitador hace que cada solicitud a Kraken pase por el limitador. Sin limitador no hay límite.
+func (s *KrakenCotizador) ConLimitador(limitador *Limitador) *KrakenCotizador {
+	s.limitador = limitador
+	return s
+}
+
+// KrakenTicker es la parte que se usa de cada par en /0/public/Ticker. C es el último
+// trade como [precio, volumen].
+type KrakenTicker struct {
+	A []string `json:"a"`
+	B []string `json:"b"`
+	C []string `json:"c"`
+}
+
+// KrakenTickerResponse es la respuesta de /0/public/Ticker. Kraken informa los errores
+// en Error aun con status 200, y devuelve el par con su nombre interno (XXBTZUSD).
+type KrakenTickerResponse struct {
+	Error  []string                `json:"error"`
+	Result map[string]KrakenTicker `json:"result"`
+}
+
+// KrakenPar arma el par de Kraken para un código y una moneda fiat, por ejemplo XBTUSD.
+func KrakenPar(codigo, fiat string) string {
+	activo := strings.ToUpper(codigo)
+	if simbolo, ok := SimbolosKraken[activo]; ok {
+		activo = simbolo
+	}
+	return activo + strings.ToUpper(fiat)
+}
+
+func (s *KrakenCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var cotizacion criptomonedas.Cotizacion
+	par := KrakenPar(codigo, fiat)
+
+	tickerURL := fmt.Sprintf("%s/0/public/Ticker?pair=%s", s.baseURL, par)
+	resp, err := httpGet(ctx, s.client, s.limitador, tickerURL)
+	if err != nil {
+		return cotizacion, fmt.Errorf("error al obtener la cotización: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return cotizacion, fmt.Errorf("error en la solicitud de cotización: %s", resp.Status)
+	}
+
+	var result KrakenTickerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return cotizacion, fmt.Errorf("error al decodificar la respuesta de cotización: %v", err)
+	}
+	if len(result.Error) > 0 {
+		return cotizacion, fmt.Errorf("error en la solicitud de cotización de %s: %s", par, strings.Join(result.Error, ", "))
+	}
+	if len(result.Result) != 1 {
+		return cotizacion, fmt.Errorf("no se encontró la cotización del par %s en Kraken", par)
+	}
+
+	var ticker KrakenTicker
+	for _, t := range result.Result {
+		ticker = t
+	}
+	if len(ticker.C) == 0 {
+		return cotizacion, fmt.Errorf("no se encontró el último precio del par %s en Kraken", par)
+	}
+	precio, err := strconv.ParseFloat(ticker.C[0], 64)
+	if err != nil {
+		return cotizacion, fmt.Errorf("precio %q inválido en la respuesta de Kraken", ticker.C[0])
+	}
+
+	cotizacion = criptomonedas.Cotizacion{
+		Cotizacion: precio,
+		Fecha:      time.Now(),
+		Fuente:     "kraken",
+	}
+	return cotizacion, nil
+}
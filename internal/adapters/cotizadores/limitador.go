@@ -0,0 +1,242 @@
+package cotizadores
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ConfiguracionLimite define la tasa sostenida, la ráfaga y el presupuesto diario de un proveedor.
+type ConfiguracionLimite struct {
+	// TasaPorSegundo es la cantidad de solicitudes por segundo que se reponen en el balde.
+	TasaPorSegundo float64
+	// Rafaga es la capacidad del balde, es decir cuántas solicitudes pueden salir juntas.
+	Rafaga int
+	// PresupuestoDiario es el máximo de solicitudes por día UTC. En cero no hay límite diario.
+	PresupuestoDiario int
+	// Esperar indica si las solicitudes que exceden la tasa esperan su turno en lugar de fallar enseguida.
+	Esperar bool
+}
+
+// LimitesPorDefecto son los límites con los que se registran los proveedores públicos.
+var LimitesPorDefecto = map[string]ConfiguracionLimite{
+	"coinpaprika": {TasaPorSegundo: 1, Rafaga: 5, PresupuestoDiario: 800, Esperar: true},
+	"criptoya":    {TasaPorSegundo: 2, Rafaga: 10, Esperar: true},
+	"coingecko":   {TasaPorSegundo: 0.5, Rafaga: 5, PresupuestoDiario: 300, Esperar: true},
+	"binance":     {TasaPorSegundo: 10, Rafaga: 20, Esperar: true},
+	"kraken":      {TasaPorSegundo: 1, Rafaga: 15, Esperar: true},
+	"frankfurter": {TasaPorSegundo: 1, Rafaga: 5, Esperar: true},
+}
+
+// Motivos por los que se rechaza una solicitud.
+const (
+	MotivoTasa              = "tasa"
+	MotivoPresupuestoDiario = "presupuesto_diario"
+)
+
+// LimiteExcedidoError se devuelve cuando una solicitud a un proveedor superaría su límite.
+type LimiteExcedidoError struct {
+	Proveedor   string
+	Motivo      string
+	ReintentoEn time.Time
+}
+
+func (e *LimiteExcedidoError) Error() string {
+	return fmt.Sprintf("límite de %s excedido para el cotizador %s, reintentar en %s", e.Motivo, e.Proveedor, e.ReintentoEn.Format(time.RFC3339))
+}
+
+// UsoProveedor son los contadores de uso de un proveedor.
+type UsoProveedor struct {
+	Proveedor         string  `json:"proveedor"`
+	Permitidas        int64   `json:"permitidas"`
+	Rechazadas        int64   `json:"rechazadas"`
+	Encoladas         int64   `json:"encoladas"`
+	UsadasHoy         int     `json:"usadas_hoy"`
+	PresupuestoDiario int     `json:"presupuesto_diario"`
+	TokensDisponibles float64 `json:"tokens_disponibles"`
+	Rafaga            int     `json:"rafaga"`
+	TasaPorSegundo    float64 `json:"tasa_por_segundo"`
+}
+
+// Limitador es un token bucket con presupuesto diario para las solicitudes a un proveedor.
+type Limitador struct {
+	mu        sync.Mutex
+	proveedor string
+	config    ConfiguracionLimite
+	tokens    float64
+	ultimo    time.Time
+	dia       string
+	usadasHoy int
+
+	permitidas int64
+	rechazadas int64
+	encoladas  int64
+}
+
+func NewLimitador(proveedor string, config ConfiguracionLimite) *Limitador {
+	l := &Limitador{proveedor: proveedor, ultimo: time.Now()}
+	l.aplicar(config)
+	return l
+}
+
+// Configurar cambia los límites sin perder los contadores.
+func (l *Limitador) Configurar(config ConfiguracionLimite) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.aplicar(config)
+}
+
+func (l *Limitador) aplicar(config ConfiguracionLimite) {
+	if config.TasaPorSegundo <= 0 {
+		config.TasaPorSegundo = 1
+	}
+	if config.Rafaga <= 0 {
+		config.Rafaga = 1
+	}
+	l.config = config
+	l.tokens = float64(config.Rafaga)
+}
+
+// Esperar reserva un lugar para una solicitud. Si el proveedor está configurado para encolar, espera
+// hasta que haya token o hasta que venza el contexto; si no, falla enseguida con LimiteExcedidoError.
+func (l *Limitador) Esperar(ctx context.Context) error {
+	l.mu.Lock()
+	ahora := time.Now()
+	l.reponer(ahora)
+
+	if l.config.PresupuestoDiario > 0 && l.usadasHoy >= l.config.PresupuestoDiario {
+		l.rechazadas++
+		l.mu.Unlock()
+		manana := time.Date(ahora.UTC().Year(), ahora.UTC().Month(), ahora.UTC().Day()+1, 0, 0, 0, 0, time.UTC)
+		return &LimiteExcedidoError{Proveedor: l.proveedor, Motivo: MotivoPresupuestoDiario, ReintentoEn: manana}
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		l.usadasHoy++
+		l.permitidas++
+		l.mu.Unlock()
+		return nil
+	}
+
+	espera := time.Duration((1 - l.tokens) / l.config.TasaPorSegundo * float64(time.Second))
+	reintento := ahora.Add(espera)
+	deadline, tieneDeadline := ctx.Deadline()
+	if !l.config.Esperar || (tieneDeadline && deadline.Before(reintento)) {
+		l.rechazadas++
+		l.mu.Unlock()
+		return &LimiteExcedidoError{Proveedor: l.proveedor, Motivo: MotivoTasa, ReintentoEn: reintento}
+	}
+
+	// se reserva el token ahora (el balde queda negativo) para respetar el orden de llegada
+	l.tokens--
+	l.usadasHoy++
+	l.encoladas++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(espera)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		l.mu.Lock()
+		l.permitidas++
+		l.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		// se devuelve la reserva para no castigar a los que siguen en la cola
+		l.mu.Lock()
+		l.tokens++
+		l.usadasHoy--
+		l.rechazadas++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reponer suma los tokens acumulados desde la última consulta y reinicia el contador diario al cambiar de día.
+func (l *Limitador) reponer(ahora time.Time) {
+	l.tokens += ahora.Sub(l.ultimo).Seconds() * l.config.TasaPorSegundo
+	if l.tokens > float64(l.config.Rafaga) {
+		l.tokens = float64(l.config.Rafaga)
+	}
+	l.ultimo = ahora
+
+	if dia := ahora.UTC().Format("2006-01-02"); dia != l.dia {
+		l.dia = dia
+		l.usadasHoy = 0
+	}
+}
+
+func (l *Limitador) Uso() UsoProveedor {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reponer(time.Now())
+	return UsoProveedor{
+		Proveedor:         l.proveedor,
+		Permitidas:        l.permitidas,
+		Rechazadas:        l.rechazadas,
+		Encoladas:         l.encoladas,
+		UsadasHoy:         l.usadasHoy,
+		PresupuestoDiario: l.config.PresupuestoDiario,
+		TokensDisponibles: l.tokens,
+		Rafaga:            l.config.Rafaga,
+		TasaPorSegundo:    l.config.TasaPorSegundo,
+	}
+}
+
+var (
+	limitadoresMu sync.Mutex
+	limitadores   = map[string]*Limitador{}
+)
+
+// LimitadorPara devuelve el limitador compartido de un proveedor, creándolo con LimitesPorDefecto si hace falta.
+func LimitadorPara(proveedor string) *Limitador {
+	limitadoresMu.Lock()
+	defer limitadoresMu.Unlock()
+	limitador, existe := limitadores[proveedor]
+	if !existe {
+		limitador = NewLimitador(proveedor, LimitesPorDefecto[proveedor])
+		limitadores[proveedor] = limitador
+	}
+	return limitador
+}
+
+// ConfigurarLimite cambia los límites de un proveedor. Los cotizadores que ya lo usan toman el cambio.
+func ConfigurarLimite(proveedor string, config ConfiguracionLimite) {
+	LimitadorPara(proveedor).Configurar(config)
+}
+
+// CupoDiarioRestante devuelve cuántas solicitudes le quedan hoy al proveedor. El segundo valor es false
+// si el proveedor no tiene limitador o no tiene presupuesto diario, es decir si no hay tope.
+func CupoDiarioRestante(proveedor string) (int, bool) {
+	limitadoresMu.Lock()
+	limitador, existe := limitadores[proveedor]
+	limitadoresMu.Unlock()
+	if !existe {
+		return 0, false
+	}
+	uso := limitador.Uso()
+	if uso.PresupuestoDiario <= 0 {
+		return 0, false
+	}
+	return max(uso.PresupuestoDiario-uso.UsadasHoy, 0), true
+}
+
+// UsoProveedores devuelve los contadores de todos los proveedores con limitador.
+func UsoProveedores() []UsoProveedor {
+	limitadoresMu.Lock()
+	lista := make([]*Limitador, 0, len(limitadores))
+	for _, limitador := range limitadores {
+		lista = append(lista, limitador)
+	}
+	limitadoresMu.Unlock()
+
+	usos := make([]UsoProveedor, 0, len(lista))
+	for _, limitador := range lista {
+		usos = append(usos, limitador.Uso())
+	}
+	sort.Slice(usos, func(i, j int) bool { return usos[i].Proveedor < usos[j].Proveedor })
+	return usos
+}
@@ -10,8 +10,11 @@
 package mock
 
 import (
+	context "context"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,16 +43,294 @@ func (m *MockCotizador) EXPECT() *MockCotizadorMockRecorder {
 }
 
 // GetCotizacionExterna mocks base method.
-func (m *MockCotizador) GetCotizacionExterna(moneda, codigo, fiat string) (criptomonedas.Cotizacion, error) {
+func (m *MockCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCotizacionExterna", moneda, codigo, fiat)
+	ret := m.ctrl.Call(m, "GetCotizacionExterna", ctx, moneda, codigo, fiat, volumen)
 	ret0, _ := ret[0].(criptomonedas.Cotizacion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCotizacionExterna indicates an expected call of GetCotizacionExterna.
-func (mr *MockCotizadorMockRecorder) GetCotizacionExterna(moneda, codigo, fiat any) *gomock.Call {
+func (mr *MockCotizadorMockRecorder) GetCotizacionExterna(ctx, moneda, codigo, fiat, volumen any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCotizacionExterna", reflect.TypeOf((*MockCotizador)(nil).GetCotizacionExterna), moneda, codigo, fiat)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCotizacionExterna", reflect.TypeOf((*MockCotizador)(nil).GetCotizacionExterna), ctx, moneda, codigo, fiat, volumen)
+}
+
+// MockExchangesCotizador is a mock of ExchangesCotizador interface.
+type MockExchangesCotizador struct {
+	ctrl     *gomock.Controller
+	recorder *MockExchangesCotizadorMockRecorder
+}
+
+// MockExchangesCotizadorMockRecorder is the mock recorder for MockExchangesCotizador.
+type MockExchangesCotizadorMockRecorder struct {
+	mock *MockExchangesCotizador
+}
+
+// NewMockExchangesCotizador creates a new mock instance.
+func NewMockExchangesCotizador(ctrl *gomock.Controller) *MockExchangesCotizador {
+	mock := &MockExchangesCotizador{ctrl: ctrl}
+	mock.recorder = &MockExchangesCotizadorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExchangesCotizador) EXPECT() *MockExchangesCotizadorMockRecorder {
+	return m.recorder
+}
+
+// GetExchanges mocks base method.
+func (m *MockExchangesCotizador) GetExchanges(ctx context.Context, codigo, fiat string, volumen float64) (map[string]cotizadores.Exchange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchanges", ctx, codigo, fiat, volumen)
+	ret0, _ := ret[0].(map[string]cotizadores.Exchange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchanges indicates an expected call of GetExchanges.
+func (mr *MockExchangesCotizadorMockRecorder) GetExchanges(ctx, codigo, fiat, volumen any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchanges", reflect.TypeOf((*MockExchangesCotizador)(nil).GetExchanges), ctx, codigo, fiat, volumen)
+}
+
+// MockCotizadorConfigurable is a mock of CotizadorConfigurable interface.
+type MockCotizadorConfigurable struct {
+	ctrl     *gomock.Controller
+	recorder *MockCotizadorConfigurableMockRecorder
+}
+
+// MockCotizadorConfigurableMockRecorder is the mock recorder for MockCotizadorConfigurable.
+type MockCotizadorConfigurableMockRecorder struct {
+	mock *MockCotizadorConfigurable
+}
+
+// NewMockCotizadorConfigurable creates a new mock instance.
+func NewMockCotizadorConfigurable(ctrl *gomock.Controller) *MockCotizadorConfigurable {
+	mock := &MockCotizadorConfigurable{ctrl: ctrl}
+	mock.recorder = &MockCotizadorConfigurableMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCotizadorConfigurable) EXPECT() *MockCotizadorConfigurableMockRecorder {
+	return m.recorder
+}
+
+// ConOpciones mocks base method.
+func (m *MockCotizadorConfigurable) ConOpciones(opciones string) (cotizadores.Cotizador, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConOpciones", opciones)
+	ret0, _ := ret[0].(cotizadores.Cotizador)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConOpciones indicates an expected call of ConOpciones.
+func (mr *MockCotizadorConfigurableMockRecorder) ConOpciones(opciones any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConOpciones", reflect.TypeOf((*MockCotizadorConfigurable)(nil).ConOpciones), opciones)
+}
+
+// MockCotizadorAgregado is a mock of CotizadorAgregado interface.
+type MockCotizadorAgregado struct {
+	ctrl     *gomock.Controller
+	recorder *MockCotizadorAgregadoMockRecorder
+}
+
+// MockCotizadorAgregadoMockRecorder is the mock recorder for MockCotizadorAgregado.
+type MockCotizadorAgregadoMockRecorder struct {
+	mock *MockCotizadorAgregado
+}
+
+// NewMockCotizadorAgregado creates a new mock instance.
+func NewMockCotizadorAgregado(ctrl *gomock.Controller) *MockCotizadorAgregado {
+	mock := &MockCotizadorAgregado{ctrl: ctrl}
+	mock.recorder = &MockCotizadorAgregadoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCotizadorAgregado) EXPECT() *MockCotizadorAgregadoMockRecorder {
+	return m.recorder
+}
+
+// GetCotizacionAgregada mocks base method.
+func (m *MockCotizadorAgregado) GetCotizacionAgregada(ctx context.Context, moneda, codigo, fiat string, volumen float64) (cotizadores.ReporteAgregado, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCotizacionAgregada", ctx, moneda, codigo, fiat, volumen)
+	ret0, _ := ret[0].(cotizadores.ReporteAgregado)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCotizacionAgregada indicates an expected call of GetCotizacionAgregada.
+func (mr *MockCotizadorAgregadoMockRecorder) GetCotizacionAgregada(ctx, moneda, codigo, fiat, volumen any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCotizacionAgregada", reflect.TypeOf((*MockCotizadorAgregado)(nil).GetCotizacionAgregada), ctx, moneda, codigo, fiat, volumen)
+}
+
+// MockCotizadorFiat is a mock of CotizadorFiat interface.
+type MockCotizadorFiat struct {
+	ctrl     *gomock.Controller
+	recorder *MockCotizadorFiatMockRecorder
+}
+
+// MockCotizadorFiatMockRecorder is the mock recorder for MockCotizadorFiat.
+type MockCotizadorFiatMockRecorder struct {
+	mock *MockCotizadorFiat
+}
+
+// NewMockCotizadorFiat creates a new mock instance.
+func NewMockCotizadorFiat(ctrl *gomock.Controller) *MockCotizadorFiat {
+	mock := &MockCotizadorFiat{ctrl: ctrl}
+	mock.recorder = &MockCotizadorFiatMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCotizadorFiat) EXPECT() *MockCotizadorFiatMockRecorder {
+	return m.recorder
+}
+
+// GetTasaFiat mocks base method.
+func (m *MockCotizadorFiat) GetTasaFiat(ctx context.Context, base, destino, tipo string) (cotizadores.TasaFiat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTasaFiat", ctx, base, destino, tipo)
+	ret0, _ := ret[0].(cotizadores.TasaFiat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTasaFiat indicates an expected call of GetTasaFiat.
+func (mr *MockCotizadorFiatMockRecorder) GetTasaFiat(ctx, base, destino, tipo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasaFiat", reflect.TypeOf((*MockCotizadorFiat)(nil).GetTasaFiat), ctx, base, destino, tipo)
+}
+
+// MockHistoricalCotizador is a mock of HistoricalCotizador interface.
+type MockHistoricalCotizador struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoricalCotizadorMockRecorder
+}
+
+// MockHistoricalCotizadorMockRecorder is the mock recorder for MockHistoricalCotizador.
+type MockHistoricalCotizadorMockRecorder struct {
+	mock *MockHistoricalCotizador
+}
+
+// NewMockHistoricalCotizador creates a new mock instance.
+func NewMockHistoricalCotizador(ctrl *gomock.Controller) *MockHistoricalCotizador {
+	mock := &MockHistoricalCotizador{ctrl: ctrl}
+	mock.recorder = &MockHistoricalCotizadorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoricalCotizador) EXPECT() *MockHistoricalCotizadorMockRecorder {
+	return m.recorder
+}
+
+// GetCotizacionesHistoricas mocks base method.
+func (m *MockHistoricalCotizador) GetCotizacionesHistoricas(ctx context.Context, moneda, codigo, fiat string, desde, hasta time.Time, resolucion string) ([]criptomonedas.Cotizacion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCotizacionesHistoricas", ctx, moneda, codigo, fiat, desde, hasta, resolucion)
+	ret0, _ := ret[0].([]criptomonedas.Cotizacion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCotizacionesHistoricas indicates an expected call of GetCotizacionesHistoricas.
+func (mr *MockHistoricalCotizadorMockRecorder) GetCotizacionesHistoricas(ctx, moneda, codigo, fiat, desde, hasta, resolucion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCotizacionesHistoricas", reflect.TypeOf((*MockHistoricalCotizador)(nil).GetCotizacionesHistoricas), ctx, moneda, codigo, fiat, desde, hasta, resolucion)
+}
+
+// MockBatchCotizador is a mock of BatchCotizador interface.
+type MockBatchCotizador struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchCotizadorMockRecorder
+}
+
+// MockBatchCotizadorMockRecorder is the mock recorder for MockBatchCotizador.
+type MockBatchCotizadorMockRecorder struct {
+	mock *MockBatchCotizador
+}
+
+// NewMockBatchCotizador creates a new mock instance.
+func NewMockBatchCotizador(ctrl *gomock.Controller) *MockBatchCotizador {
+	mock := &MockBatchCotizador{ctrl: ctrl}
+	mock.recorder = &MockBatchCotizadorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchCotizador) EXPECT() *MockBatchCotizadorMockRecorder {
+	return m.recorder
+}
+
+// GetCotizacionesExternas mocks base method.
+func (m *MockBatchCotizador) GetCotizacionesExternas(ctx context.Context, monedas []criptomonedas.CriptoMoneda, fiat string) (map[string]criptomonedas.Cotizacion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCotizacionesExternas", ctx, monedas, fiat)
+	ret0, _ := ret[0].(map[string]criptomonedas.Cotizacion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCotizacionesExternas indicates an expected call of GetCotizacionesExternas.
+func (mr *MockBatchCotizadorMockRecorder) GetCotizacionesExternas(ctx, monedas, fiat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCotizacionesExternas", reflect.TypeOf((*MockBatchCotizador)(nil).GetCotizacionesExternas), ctx, monedas, fiat)
+}
+
+// MockcotizadorCompuesto is a mock of cotizadorCompuesto interface.
+type MockcotizadorCompuesto struct {
+	ctrl     *gomock.Controller
+	recorder *MockcotizadorCompuestoMockRecorder
+}
+
+// MockcotizadorCompuestoMockRecorder is the mock recorder for MockcotizadorCompuesto.
+type MockcotizadorCompuestoMockRecorder struct {
+	mock *MockcotizadorCompuesto
+}
+
+// NewMockcotizadorCompuesto creates a new mock instance.
+func NewMockcotizadorCompuesto(ctrl *gomock.Controller) *MockcotizadorCompuesto {
+	mock := &MockcotizadorCompuesto{ctrl: ctrl}
+	mock.recorder = &MockcotizadorCompuestoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcotizadorCompuesto) EXPECT() *MockcotizadorCompuestoMockRecorder {
+	return m.recorder
+}
+
+// compuesto mocks base method.
+func (m *MockcotizadorCompuesto) compuesto() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "compuesto")
+}
+
+// compuesto indicates an expected call of compuesto.
+func (mr *MockcotizadorCompuestoMockRecorder) compuesto() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "compuesto", reflect.TypeOf((*MockcotizadorCompuesto)(nil).compuesto))
+}
+
+// proveedores mocks base method.
+func (m *MockcotizadorCompuesto) proveedores() ([]string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "proveedores")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// proveedores indicates an expected call of proveedores.
+func (mr *MockcotizadorCompuestoMockRecorder) proveedores() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "proveedores", reflect.TypeOf((*MockcotizadorCompuesto)(nil).proveedores))
 }
@@ -1,61 +1,79 @@
 package cotizadores
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
 	"time"
 )
 
-type CoinPaprikaCotizador struct{}
+// CoinPaprikaBaseURL es la URL base de la API pública de CoinPaprika.
+const CoinPaprikaBaseURL = "https://api.coinpaprika.com"
 
-type CoinpaprikaResponse struct {
-	Name   string `json:"name"`
-	Quotes map[string]struct {
-		Price float64 `json:"price"`
-	} `json:"quotes"`
+type CoinPaprikaCotizador struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+	indice  *coinPaprikaIndice
+
+	limitador *Limitador
 }
 
-func (s *CoinPaprikaCotizador) GetCotizacionExterna(moneda, codigo, fiat string) (criptomonedas.Cotizacion, error) {
-	// Paso 1: Buscar el ID de la criptomoneda en CoinPaprika
-	coinListURL := "https://api.coinpaprika.com/v1/coins"
-	var cotizacion criptomonedas.Cotizacion
-	resp, err := http.Get(coinListURL)
-	if err != nil {
-		return cotizacion, fmt.Errorf("error al obtener la lista de monedas: %v", err)
+// NewCoinPaprikaCotizador crea un cotizador de CoinPaprika. Un client nil, una baseURL vacía
+// o un timeout en cero toman los valores por defecto.
+func NewCoinPaprikaCotizador(client *http.Client, baseURL string, timeout time.Duration) *CoinPaprikaCotizador {
+	if baseURL == "" {
+		baseURL = CoinPaprikaBaseURL
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return cotizacion, fmt.Errorf("error en la solicitud de lista de monedas: %s", resp.Status)
+	return &CoinPaprikaCotizador{
+		client:  defaultClient(client),
+		baseURL: baseURL,
+		timeout: defaultTimeout(timeout),
+		indice:  newCoinPaprikaIndice(TTLIndicePorDefecto, ""),
 	}
+}
 
-	var coins []struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&coins); err != nil {
-		return cotizacion, fmt.Errorf("error al decodificar la lista de monedas: %v", err)
-	}
+// ConfigurarIndice cambia el TTL de la lista de monedas en memoria y el archivo donde se guarda
+// para arrancar sin descargarla. Con rutaSnapshot vacía no se usa disco. Debe llamarse antes de cotizar.
+func (s *CoinPaprikaCotizador) ConfigurarIndice(ttl time.Duration, rutaSnapshot string) {
+	s.indice = newCoinPaprikaIndice(ttl, rutaSnapshot)
+}
 
-	var coinID string
-	for _, coin := range coins {
-		if coin.Name == moneda {
-			coinID = coin.ID
-			break
-		}
-	}
+// ConLimitador hace que cada solicitud a CoinPaprika pase por el limitador. Sin limitador no hay límite.
+func (s *CoinPaprikaCotizador) ConLimitador(limitador *Limitador) *CoinPaprikaCotizador {
+	s.limitador = limitador
+	return s
+}
 
-	if coinID == "" {
-		return cotizacion, fmt.Errorf("no se encontró la criptomoneda %s en CoinPaprika", moneda)
+type CoinpaprikaResponse struct {
+	Name        string `json:"name"`
+	LastUpdated string `json:"last_updated"`
+	Quotes      map[string]struct {
+		Price float64 `json:"price"`
+	} `json:"quotes"`
+}
+
+func (s *CoinPaprikaCotizador) GetCotizacionExterna(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	// Paso 1: Buscar el ID de la criptomoneda en el índice en memoria de CoinPaprika
+	var cotizacion criptomonedas.Cotizacion
+	coinID, err := s.indice.buscar(ctx, s.descargarMonedas, moneda, codigo)
+	if err != nil {
+		return cotizacion, err
 	}
 
-	// Paso 2: Usar el ID para obtener la cotización más reciente
-	tickerURL := fmt.Sprintf("https://api.coinpaprika.com/v1/tickers/%s", coinID)
-	resp, err = http.Get(tickerURL)
+	// Paso 2: Usar el ID para obtener la cotización más reciente; sin quotes CoinPaprika solo responde USD
+	fiat = strings.ToUpper(fiat)
+	tickerURL := fmt.Sprintf("%s/v1/tickers/%s?quotes=%s", s.baseURL, coinID, url.QueryEscape(fiat))
+	resp, err := httpGet(ctx, s.client, s.limitador, tickerURL)
 	if err != nil {
-		return cotizacion, fmt.Errorf("error al obtener la cotización: %v", err)
+		return cotizacion, fmt.Errorf("error al obtener la cotización: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -87,7 +105,141 @@ func (s *CoinPaprikaCotizador) GetCotizacionExterna(moneda, codigo, fiat string)
 	cotizacion = criptomonedas.Cotizacion{ //service tiene que buscar el id de la cripto para esto
 		Cotizacion: quote.Price,
 		Fecha:      time.Now(),
+		Fuente:     "coinpaprika",
+	}
+	if ultima, err := time.Parse(time.RFC3339, result.LastUpdated); err == nil {
+		cotizacion.FechaProveedor = &ultima
 	}
 
 	return cotizacion, nil
 }
+
+// CoinPaprikaTicker es una entrada de /v1/tickers.
+type CoinPaprikaTicker struct {
+	ID string `json:"id"`
+	CoinpaprikaResponse
+}
+
+// GetCotizacionesExternas cotiza todas las monedas con una sola consulta a /v1/tickers. Los ids de las monedas
+// salen del índice en memoria; las que CoinPaprika no conoce o no cotiza en la fiat quedan fuera del resultado.
+func (s *CoinPaprikaCotizador) GetCotizacionesExternas(ctx context.Context, monedas []criptomonedas.CriptoMoneda, fiat string) (map[string]criptomonedas.Cotizacion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	ids := make(map[string]string, len(monedas))
+	var errBusqueda error
+	for _, moneda := range monedas {
+		coinID, err := s.indice.buscar(ctx, s.descargarMonedas, moneda.Nombre, moneda.Codigo)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			errBusqueda = err
+			continue
+		}
+		ids[coinID] = moneda.Nombre
+	}
+	if len(ids) == 0 {
+		// ninguna moneda encontrada puede ser que la lista no se pudo bajar
+		return nil, errBusqueda
+	}
+
+	tickersURL := fmt.Sprintf("%s/v1/tickers?quotes=%s", s.baseURL, url.QueryEscape(strings.ToUpper(fiat)))
+	resp, err := httpGet(ctx, s.client, s.limitador, tickersURL)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las cotizaciones: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error en la solicitud de cotizaciones: %s", resp.Status)
+	}
+
+	var result []CoinPaprikaTicker
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error al decodificar las cotizaciones: %v", err)
+	}
+
+	ahora := time.Now()
+	cotizaciones := make(map[string]criptomonedas.Cotizacion, len(ids))
+	for _, ticker := range result {
+		nombre, pedida := ids[ticker.ID]
+		if !pedida {
+			continue
+		}
+		quote, ok := ticker.Quotes[strings.ToUpper(fiat)]
+		if !ok {
+			continue
+		}
+		cotizacion := criptomonedas.Cotizacion{
+			Cotizacion: quote.Price,
+			Fecha:      ahora,
+			Fuente:     "coinpaprika",
+		}
+		if ultima, err := time.Parse(time.RFC3339, ticker.LastUpdated); err == nil {
+			cotizacion.FechaProveedor = &ultima
+		}
+		cotizaciones[nombre] = cotizacion
+	}
+	return cotizaciones, nil
+}
+
+// intervalosCoinPaprika traduce las resoluciones al parámetro interval de /v1/tickers/{id}/historical.
+var intervalosCoinPaprika = map[string]string{
+	ResolucionDiaria:  "1d",
+	ResolucionHoraria: "1h",
+}
+
+// CoinPaprikaHistorico es una entrada de /v1/tickers/{id}/historical.
+type CoinPaprikaHistorico struct {
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+}
+
+// GetCotizacionesHistoricas consulta los tickers históricos de CoinPaprika. CoinPaprika sólo cotiza el
+// historial en USD y BTC.
+func (s *CoinPaprikaCotizador) GetCotizacionesHistoricas(ctx context.Context, moneda, codigo, fiat string, desde, hasta time.Time, resolucion string) ([]criptomonedas.Cotizacion, error) {
+	intervalo, ok := intervalosCoinPaprika[resolucion]
+	if !ok {
+		return nil, fmt.Errorf("resolución %s no soportada por coinpaprika", resolucion)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	coinID, err := s.indice.buscar(ctx, s.descargarMonedas, moneda, codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	historicoURL := fmt.Sprintf("%s/v1/tickers/%s/historical?start=%s&end=%s&interval=%s&quote=%s&limit=5000",
+		s.baseURL, coinID, desde.UTC().Format(time.RFC3339), hasta.UTC().Format(time.RFC3339), intervalo, url.QueryEscape(strings.ToLower(fiat)))
+	resp, err := httpGet(ctx, s.client, s.limitador, historicoURL)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las cotizaciones históricas: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error en la solicitud de cotizaciones históricas: %s", resp.Status)
+	}
+
+	var result []CoinPaprikaHistorico
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error al decodificar las cotizaciones históricas: %v", err)
+	}
+
+	cotizaciones := make([]criptomonedas.Cotizacion, 0, len(result))
+	for _, punto := range result {
+		if punto.Timestamp.Before(desde) || punto.Timestamp.After(hasta) {
+			continue
+		}
+		fecha := punto.Timestamp
+		cotizaciones = append(cotizaciones, criptomonedas.Cotizacion{
+			Cotizacion:     punto.Price,
+			Fecha:          fecha,
+			Fuente:         "coinpaprika",
+			FechaProveedor: &fecha,
+		})
+	}
+	return cotizaciones, nil
+}
@@ -0,0 +1,271 @@
+package cotizadores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TTLIndicePorDefecto es cada cuánto se vuelve a descargar la lista de monedas de CoinPaprika.
+const TTLIndicePorDefecto = time.Hour
+
+// refrescoMinimoIndice evita descargar la lista otra vez si se pide una moneda desconocida apenas refrescada.
+const refrescoMinimoIndice = time.Minute
+
+// CoinPaprikaMoneda es una entrada de /v1/coins.
+type CoinPaprikaMoneda struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Rank     int    `json:"rank"`
+	IsActive bool   `json:"is_active"`
+}
+
+// snapshotIndice es el formato en disco del índice, para arrancar sin descargar la lista.
+type snapshotIndice struct {
+	Actualizado time.Time           `json:"actualizado"`
+	Monedas     []CoinPaprikaMoneda `json:"monedas"`
+}
+
+// refrescoIndice es una descarga en curso que comparten todos los que la esperan.
+type refrescoIndice struct {
+	listo chan struct{}
+	err   error
+}
+
+// coinPaprikaIndice guarda en memoria la lista de monedas indexada por nombre y por símbolo.
+type coinPaprikaIndice struct {
+	mu          sync.RWMutex
+	porNombre   map[string]string
+	porSimbolo  map[string]string
+	actualizado time.Time
+	ttl         time.Duration
+	snapshot    string
+	cargado     bool
+
+	refrescoMu sync.Mutex
+	enCurso    *refrescoIndice
+}
+
+func newCoinPaprikaIndice(ttl time.Duration, snapshot string) *coinPaprikaIndice {
+	if ttl <= 0 {
+		ttl = TTLIndicePorDefecto
+	}
+	return &coinPaprikaIndice{ttl: ttl, snapshot: snapshot}
+}
+
+// buscar devuelve el ID de CoinPaprika para la moneda, primero por nombre y después por símbolo.
+// Refresca el índice si venció o si la moneda no aparece y la lista no se bajó hace poco.
+func (i *coinPaprikaIndice) buscar(ctx context.Context, descargar func(context.Context) ([]CoinPaprikaMoneda, error), moneda, codigo string) (string, error) {
+	i.cargarSnapshot()
+
+	if i.vencido() {
+		if err := i.refrescar(ctx, descargar); err != nil {
+			if !i.tieneDatos() {
+				return "", err
+			}
+			log.Println("Error al refrescar el índice de CoinPaprika, se usa el anterior:", err)
+		}
+	}
+
+	if id, ok := i.lookup(moneda, codigo); ok {
+		return id, nil
+	}
+
+	if i.edad() > refrescoMinimoIndice {
+		if err := i.refrescar(ctx, descargar); err != nil {
+			return "", err
+		}
+		if id, ok := i.lookup(moneda, codigo); ok {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("no se encontró la criptomoneda %s en CoinPaprika", moneda)
+}
+
+func (i *coinPaprikaIndice) lookup(moneda, codigo string) (string, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if id, ok := i.porNombre[moneda]; ok {
+		return id, true
+	}
+	if id, ok := i.porNombre[strings.ToLower(moneda)]; ok {
+		return id, true
+	}
+	if codigo != "" {
+		if id, ok := i.porSimbolo[strings.ToUpper(codigo)]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (i *coinPaprikaIndice) vencido() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.porNombre == nil || time.Since(i.actualizado) > i.ttl
+}
+
+func (i *coinPaprikaIndice) tieneDatos() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.porNombre) > 0
+}
+
+func (i *coinPaprikaIndice) edad() time.Duration {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return time.Since(i.actualizado)
+}
+
+// refrescar descarga la lista una sola vez aunque la pidan varias consultas a la vez.
+func (i *coinPaprikaIndice) refrescar(ctx context.Context, descargar func(context.Context) ([]CoinPaprikaMoneda, error)) error {
+	i.refrescoMu.Lock()
+	if r := i.enCurso; r != nil {
+		i.refrescoMu.Unlock()
+		select {
+		case <-r.listo:
+			return r.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	r := &refrescoIndice{listo: make(chan struct{})}
+	i.enCurso = r
+	i.refrescoMu.Unlock()
+
+	monedas, err := descargar(ctx)
+	if err == nil {
+		i.reemplazar(monedas, time.Now())
+		i.guardarSnapshot(monedas)
+	}
+
+	r.err = err
+	close(r.listo)
+	i.refrescoMu.Lock()
+	i.enCurso = nil
+	i.refrescoMu.Unlock()
+	return err
+}
+
+// reemplazar arma los mapas nuevos. Si dos monedas comparten símbolo gana la de mejor ranking activa.
+func (i *coinPaprikaIndice) reemplazar(monedas []CoinPaprikaMoneda, actualizado time.Time) {
+	porNombre := make(map[string]string, len(monedas)*2)
+	porSimbolo := make(map[string]string, len(monedas))
+	rankSimbolo := make(map[string]CoinPaprikaMoneda, len(monedas))
+	for _, moneda := range monedas {
+		if _, existe := porNombre[moneda.Name]; !existe {
+			porNombre[moneda.Name] = moneda.ID
+		}
+		if minuscula := strings.ToLower(moneda.Name); minuscula != moneda.Name {
+			if _, existe := porNombre[minuscula]; !existe {
+				porNombre[minuscula] = moneda.ID
+			}
+		}
+		simbolo := strings.ToUpper(moneda.Symbol)
+		if actual, existe := rankSimbolo[simbolo]; !existe || mejorRanking(moneda, actual) {
+			rankSimbolo[simbolo] = moneda
+			porSimbolo[simbolo] = moneda.ID
+		}
+	}
+
+	i.mu.Lock()
+	i.porNombre = porNombre
+	i.porSimbolo = porSimbolo
+	i.actualizado = actualizado
+	i.mu.Unlock()
+}
+
+func mejorRanking(a, b CoinPaprikaMoneda) bool {
+	if a.IsActive != b.IsActive {
+		return a.IsActive
+	}
+	if a.Rank == 0 || b.Rank == 0 {
+		return a.Rank != 0
+	}
+	return a.Rank < b.Rank
+}
+
+// cargarSnapshot lee el snapshot en disco la primera vez que se usa el índice.
+func (i *coinPaprikaIndice) cargarSnapshot() {
+	i.mu.Lock()
+	if i.cargado {
+		i.mu.Unlock()
+		return
+	}
+	i.cargado = true
+	i.mu.Unlock()
+
+	if i.snapshot == "" {
+		return
+	}
+	data, err := os.ReadFile(i.snapshot)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println("Error al leer el snapshot del índice de CoinPaprika:", err)
+		}
+		return
+	}
+	var snapshot snapshotIndice
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		log.Println("Error al decodificar el snapshot del índice de CoinPaprika:", err)
+		return
+	}
+	i.reemplazar(snapshot.Monedas, snapshot.Actualizado)
+}
+
+func (i *coinPaprikaIndice) guardarSnapshot(monedas []CoinPaprikaMoneda) {
+	if i.snapshot == "" {
+		return
+	}
+	data, err := json.Marshal(snapshotIndice{Actualizado: time.Now(), Monedas: monedas})
+	if err != nil {
+		log.Println("Error al codificar el snapshot del índice de CoinPaprika:", err)
+		return
+	}
+	// se escribe en un temporal y se renombra para no dejar un snapshot a medias
+	tmp, err := os.CreateTemp(filepath.Dir(i.snapshot), filepath.Base(i.snapshot)+".*")
+	if err != nil {
+		log.Println("Error al guardar el snapshot del índice de CoinPaprika:", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		log.Println("Error al guardar el snapshot del índice de CoinPaprika:", err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		log.Println("Error al guardar el snapshot del índice de CoinPaprika:", err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), i.snapshot); err != nil {
+		log.Println("Error al guardar el snapshot del índice de CoinPaprika:", err)
+	}
+}
+
+// descargarMonedas baja la lista completa de /v1/coins.
+func (s *CoinPaprikaCotizador) descargarMonedas(ctx context.Context) ([]CoinPaprikaMoneda, error) {
+	resp, err := httpGet(ctx, s.client, s.limitador, s.baseURL+"/v1/coins")
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la lista de monedas: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error en la solicitud de lista de monedas: %s", resp.Status)
+	}
+
+	var monedas []CoinPaprikaMoneda
+	if err := json.NewDecoder(resp.Body).Decode(&monedas); err != nil {
+		return nil, fmt.Errorf("error al decodificar la lista de monedas: %v", err)
+	}
+	return monedas, nil
+}
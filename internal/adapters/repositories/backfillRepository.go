@@ -0,0 +1,109 @@
+package repositories
+
+//go:generate echo $GOPACKAGE/$GOFILE
+//go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+type MySQLBackfillRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLBackfillRepository(db *sql.DB) *MySQLBackfillRepository {
+	return &MySQLBackfillRepository{db: db}
+}
+
+type BackfillRepository interface {
+	SaveBackfill(backfill criptomonedas.Backfill) (int, error)
+	UpdateBackfill(backfill criptomonedas.Backfill) error
+	FindBackfillByID(id int) (*criptomonedas.Backfill, error)
+	FindAllBackfills() ([]criptomonedas.Backfill, error)
+	// FindBackfillsPendientes devuelve los backfills pendientes o que quedaron corriendo, del más viejo al más nuevo.
+	FindBackfillsPendientes() ([]criptomonedas.Backfill, error)
+}
+
+const selectBackfills = `
+	SELECT b.id, b.cripto_id, m.nombre, b.api, b.fiat, b.resolucion, b.desde, b.hasta, b.cursor_fecha,
+		b.estado, b.guardadas, b.omitidas, b.error, b.creado, b.actualizado
+	FROM backfills b
+	JOIN monedas m ON m.id = b.cripto_id`
+
+func escanearBackfill(scanner interface{ Scan(dest ...any) error }) (criptomonedas.Backfill, error) {
+	var backfill criptomonedas.Backfill
+	err := scanner.Scan(&backfill.Id, &backfill.CriptoMoneda_ID, &backfill.Moneda, &backfill.Api, &backfill.Fiat, &backfill.Resolucion,
+		&backfill.Desde, &backfill.Hasta, &backfill.Cursor, &backfill.Estado, &backfill.Guardadas, &backfill.Omitidas,
+		&backfill.Error, &backfill.Creado, &backfill.Actualizado)
+	return backfill, err
+}
+
+func (r *MySQLBackfillRepository) SaveBackfill(backfill criptomonedas.Backfill) (int, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO backfills (cripto_id, api, fiat, resolucion, desde, hasta, cursor_fecha, estado, guardadas, omitidas, error, creado, actualizado)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		backfill.CriptoMoneda_ID, backfill.Api, fiatOPorDefecto(backfill.Fiat), backfill.Resolucion, backfill.Desde, backfill.Hasta,
+		backfill.Cursor, backfill.Estado, backfill.Guardadas, backfill.Omitidas, backfill.Error, backfill.Creado, backfill.Actualizado,
+	)
+	if err != nil {
+		log.Println("Error al guardar el backfill:", err)
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// UpdateBackfill guarda el progreso del backfill: cursor, estado, contadores y error.
+func (r *MySQLBackfillRepository) UpdateBackfill(backfill criptomonedas.Backfill) error {
+	_, err := r.db.Exec(`
+		UPDATE backfills SET cursor_fecha = ?, estado = ?, guardadas = ?, omitidas = ?, error = ?, actualizado = ?
+		WHERE id = ?`,
+		backfill.Cursor, backfill.Estado, backfill.Guardadas, backfill.Omitidas, backfill.Error, backfill.Actualizado, backfill.Id,
+	)
+	if err != nil {
+		log.Println("Error al actualizar el backfill:", err)
+		return err
+	}
+	return nil
+}
+
+// FindBackfillByID devuelve nil sin error si el backfill no existe.
+func (r *MySQLBackfillRepository) FindBackfillByID(id int) (*criptomonedas.Backfill, error) {
+	backfill, err := escanearBackfill(r.db.QueryRow(selectBackfills+" WHERE b.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+func (r *MySQLBackfillRepository) FindAllBackfills() ([]criptomonedas.Backfill, error) {
+	return r.buscar(selectBackfills + " ORDER BY b.id DESC")
+}
+
+func (r *MySQLBackfillRepository) FindBackfillsPendientes() ([]criptomonedas.Backfill, error) {
+	return r.buscar(selectBackfills+" WHERE b.estado IN (?, ?) ORDER BY b.id", criptomonedas.BackfillPendiente, criptomonedas.BackfillCorriendo)
+}
+
+func (r *MySQLBackfillRepository) buscar(query string, args ...interface{}) ([]criptomonedas.Backfill, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener los backfills:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var backfills []criptomonedas.Backfill
+	for rows.Next() {
+		backfill, err := escanearBackfill(rows)
+		if err != nil {
+			return nil, err
+		}
+		backfills = append(backfills, backfill)
+	}
+	return backfills, rows.Err()
+}
@@ -13,7 +13,9 @@ import (
 )
 
 func (r *MySQLCryptoRepository) SaveCotizacion(cripto criptomonedas.Cotizacion) error {
-	_, err := r.db.Exec("INSERT INTO cotizaciones (cripto_id, cotizacion, fecha) VALUES (?, ?, ?)", cripto.CriptoMoneda_ID, cripto.Cotizacion, cripto.Fecha)
+	_, err := r.db.Exec("INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		cripto.CriptoMoneda_ID, cripto.Cotizacion, fiatOPorDefecto(cripto.Fiat), cripto.Fecha, cripto.Fuente, cripto.Exchange, cripto.FechaProveedor,
+		cripto.Volumen, cripto.TotalAsk, cripto.TotalBid)
 	if err != nil {
 		log.Println("Error al guardar cotizacion:", err)
 		return err
@@ -21,23 +23,83 @@ func (r *MySQLCryptoRepository) SaveCotizacion(cripto criptomonedas.Cotizacion)
 	return nil
 }
 
+// SaveCotizaciones guarda todas las cotizaciones en una transacción: se guardan todas o ninguna.
+func (r *MySQLCryptoRepository) SaveCotizaciones(cotizaciones []criptomonedas.Cotizacion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, cotizacion := range cotizaciones {
+		_, err := tx.Exec("INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			cotizacion.CriptoMoneda_ID, cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha, cotizacion.Fuente, cotizacion.Exchange, cotizacion.FechaProveedor,
+			cotizacion.Volumen, cotizacion.TotalAsk, cotizacion.TotalBid)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizaciones:", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveCotizacionesHistoricas guarda en una transacción las cotizaciones que todavía no existen para la misma
+// moneda, fiat, fuente y fecha, y devuelve cuántas guardó. Así volver a cargar un rango no duplica cotizaciones.
+func (r *MySQLCryptoRepository) SaveCotizacionesHistoricas(cotizaciones []criptomonedas.Cotizacion) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	guardadas := 0
+	for _, cotizacion := range cotizaciones {
+		result, err := tx.Exec(`
+			INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid)
+			SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ? FROM DUAL
+			WHERE NOT EXISTS (
+				SELECT 1 FROM cotizaciones WHERE cripto_id = ? AND fiat = ? AND fuente = ? AND fecha = ?
+			)`,
+			cotizacion.CriptoMoneda_ID, cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha, cotizacion.Fuente, cotizacion.Exchange, cotizacion.FechaProveedor,
+			cotizacion.Volumen, cotizacion.TotalAsk, cotizacion.TotalBid,
+			cotizacion.CriptoMoneda_ID, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fuente, cotizacion.Fecha,
+		)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizacion histórica:", err)
+			return 0, err
+		}
+		filas, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		guardadas += int(filas)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return guardadas, nil
+}
+
 func (r *MySQLCryptoRepository) FindByCotizacionID(id int) (*criptomonedas.Cotizacion, error) {
 	query := `
-	SELECT c.id, c.cripto_id, c.cotizacion, c.fecha , c.manual , c.usuario_id
+	SELECT c.id, c.cripto_id, c.cotizacion, c.fiat, c.fecha , c.manual , c.usuario_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid
 	FROM cotizaciones c
 	WHERE c.id = ?
 `
 	row := r.db.QueryRow(query, id)
 	moneda := criptomonedas.Cotizacion{}
 	var fecha string
+	var fechaProveedor sql.NullTime
 
-	err := row.Scan(&moneda.Id, &moneda.CriptoMoneda_ID, &moneda.Cotizacion, &fecha, &moneda.Manual, &moneda.UsuarioId)
+	err := row.Scan(&moneda.Id, &moneda.CriptoMoneda_ID, &moneda.Cotizacion, &moneda.Fiat, &fecha, &moneda.Manual, &moneda.UsuarioId, &moneda.Fuente, &moneda.Exchange, &fechaProveedor, &moneda.Volumen, &moneda.TotalAsk, &moneda.TotalBid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("no se encontro moneda con id %d", id)
 			return nil, err
 		}
 	}
+	moneda.FechaProveedor = fechaOpcional(fechaProveedor)
 	if fecha == "" {
 		log.Println("La fecha está vacía")
 		return &moneda, nil
@@ -52,7 +114,7 @@ func (r *MySQLCryptoRepository) FindByCotizacionID(id int) (*criptomonedas.Cotiz
 }
 
 func (r *MySQLCryptoRepository) FindAllCotizaciones() ([]*criptomonedas.Cotizacion, error) {
-	query := "SELECT id, cripto_id, cotizacion, fecha FROM cotizaciones"
+	query := "SELECT id, cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid FROM cotizaciones"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		log.Println("Error al ejecutar la consulta:", err)
@@ -65,12 +127,14 @@ func (r *MySQLCryptoRepository) FindAllCotizaciones() ([]*criptomonedas.Cotizaci
 	for rows.Next() {
 		var cotizacion criptomonedas.Cotizacion
 		var fecha string
+		var fechaProveedor sql.NullTime
 
-		err := rows.Scan(&cotizacion.Id, &cotizacion.CriptoMoneda_ID, &cotizacion.Cotizacion, &fecha)
+		err := rows.Scan(&cotizacion.Id, &cotizacion.CriptoMoneda_ID, &cotizacion.Cotizacion, &cotizacion.Fiat, &fecha, &cotizacion.Fuente, &cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid)
 		if err != nil {
 			log.Println("Error al escanear fila:", err)
 			continue
 		}
+		cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
 
 		// Convertir fecha a time.Time si es necesario
 		cotizacion.Fecha, err = time.Parse("2006-01-02 15:04:05", fecha)
@@ -91,8 +155,8 @@ func (r *MySQLCryptoRepository) FindAllCotizaciones() ([]*criptomonedas.Cotizaci
 }
 
 func (r *MySQLCryptoRepository) UpdateCotizacion(id int, cotizacion criptomonedas.Cotizacion) error {
-	query := "UPDATE cotizaciones SET cotizacion = ?, fecha = ? WHERE id = ?"
-	_, err := r.db.Exec(query, cotizacion.Cotizacion, cotizacion.Fecha, id)
+	query := "UPDATE cotizaciones SET cotizacion = ?, fiat = ?, fecha = ? WHERE id = ?"
+	_, err := r.db.Exec(query, cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha, id)
 	if err != nil {
 		log.Println("Error al actualizar la moneda:", err)
 		return err
@@ -103,43 +167,62 @@ func (r *MySQLCryptoRepository) UpdateCotizacion(id int, cotizacion criptomoneda
 func (r *MySQLCryptoRepository) FindAllByFilter(filter criptomonedas.CriptoMonedaFilter) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error) {
 	query := `
         SELECT 
-            c.id, c.cotizacion, c.fecha, c.cripto_id 
+            c.id, c.cotizacion, c.fiat, c.fecha, c.cripto_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid 
         FROM 
             cotizaciones c
         JOIN 
             monedas cm ON c.cripto_id = cm.id 
         WHERE 
             1=1`
+	condiciones := ""
 	args := []interface{}{}
 
 	appliedFilters := make(map[string]interface{})
 
 	if filter.Nombre != nil {
-		query += " AND cm.nombre LIKE ?"
+		condiciones += " AND cm.nombre LIKE ?"
 		args = append(args, "%"+*filter.Nombre+"%")
 		appliedFilters["Nombre"] = *filter.Nombre
 	}
 	if filter.MinCotizacion != nil {
-		query += " AND c.cotizacion >= ?"
+		condiciones += " AND c.cotizacion >= ?"
 		args = append(args, *filter.MinCotizacion)
 		appliedFilters["MinCotizacion"] = *filter.MinCotizacion
 	}
 	if filter.MaxCotizacion != nil {
-		query += " AND c.cotizacion <= ?"
+		condiciones += " AND c.cotizacion <= ?"
 		args = append(args, *filter.MaxCotizacion)
 		appliedFilters["MaxCotizacion"] = *filter.MaxCotizacion
 	}
+	if filter.Fiat != nil {
+		condiciones += " AND c.fiat = ?"
+		args = append(args, *filter.Fiat)
+		appliedFilters["Fiat"] = *filter.Fiat
+	}
+	if filter.Fuente != nil {
+		condiciones += " AND c.fuente = ?"
+		args = append(args, *filter.Fuente)
+		appliedFilters["Fuente"] = *filter.Fuente
+	}
+	if filter.Exchange != nil {
+		condiciones += " AND c.exchange = ?"
+		args = append(args, *filter.Exchange)
+		appliedFilters["Exchange"] = *filter.Exchange
+	}
 	if filter.StartDate != nil {
-		query += " AND c.fecha >= ?"
+		condiciones += " AND c.fecha >= ?"
 		args = append(args, *filter.StartDate)
 		appliedFilters["StartDate"] = *filter.StartDate
 	}
 	if filter.EndDate != nil {
-		query += " AND c.fecha <= ?"
+		condiciones += " AND c.fecha <= ?"
 		args = append(args, *filter.EndDate)
 		appliedFilters["EndDate"] = *filter.EndDate
 	}
 
+	query += condiciones
+	filtroArgs := args
+
 	// Add pagination
 	query += " LIMIT ? OFFSET ?"
 	args = append(args, filter.PageSize, filter.PageSize*(filter.PageNumber-1))
@@ -155,9 +238,11 @@ func (r *MySQLCryptoRepository) FindAllByFilter(filter criptomonedas.CriptoMoned
 		var cotizacion criptomonedas.Cotizacion
 		var cripto criptomonedas.CriptoMoneda
 		var fechaString string
-		if err := rows.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &fechaString, &cripto.Id); err != nil {
+		var fechaProveedor sql.NullTime
+		if err := rows.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &cotizacion.Fiat, &fechaString, &cripto.Id, &cotizacion.Fuente, &cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid); err != nil {
 			return nil, criptomonedas.Summary{}, err
 		}
+		cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
 		cotizacion.Fecha, err = time.Parse("2006-01-02 15:04:05", fechaString)
 		if err != nil {
 			log.Println("Error al convertir fecha:", err)
@@ -175,11 +260,50 @@ func (r *MySQLCryptoRepository) FindAllByFilter(filter criptomonedas.CriptoMoned
 		PageSize:   filter.PageSize,
 	}
 
+	if filter.AgruparPorFuente {
+		summary.PorFuente, err = r.resumirPorFuente(condiciones, filtroArgs)
+		if err != nil {
+			return nil, criptomonedas.Summary{}, err
+		}
+	}
+
 	return cotizaciones, summary, nil
 
 }
 
-// FindUltimaCotizacion retrieves the latest quotation for a given cryptocurrency name.
+// resumirPorFuente agrupa por fuente todas las cotizaciones que cumplen las condiciones, sin paginar.
+func (r *MySQLCryptoRepository) resumirPorFuente(condiciones string, args []interface{}) ([]criptomonedas.ResumenFuente, error) {
+	query := `
+        SELECT 
+            c.fuente, COUNT(*), MIN(c.cotizacion), MAX(c.cotizacion), AVG(c.cotizacion), MAX(c.fecha) 
+        FROM 
+            cotizaciones c
+        JOIN 
+            monedas cm ON c.cripto_id = cm.id 
+        WHERE 
+            1=1` + condiciones + `
+        GROUP BY c.fuente
+        ORDER BY c.fuente`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resumenes []criptomonedas.ResumenFuente
+	for rows.Next() {
+		var resumen criptomonedas.ResumenFuente
+		if err := rows.Scan(&resumen.Fuente, &resumen.Cantidad, &resumen.Minima, &resumen.Maxima, &resumen.Promedio, &resumen.Ultima); err != nil {
+			return nil, err
+		}
+		resumenes = append(resumenes, resumen)
+	}
+	return resumenes, rows.Err()
+}
+
+// FindUltimaCotizacion retrieves the latest quotation for a given cryptocurrency name in the given fiat.
+// An empty fiat returns the latest quotation in any fiat. It returns nil without error if there is none.
 // @Summary Retrieve the latest quotation for a given cryptocurrency name
 // @Description Retrieves the most recent quotation for a cryptocurrency identified by its name.
 // @Tags cryptocurrencies
@@ -191,44 +315,133 @@ func (r *MySQLCryptoRepository) FindAllByFilter(filter criptomonedas.CriptoMoned
 // @Failure 404 {object} map[string]string "error": "Not Found"
 // @Failure 500 {object} map[string]string "error": "Internal Server Error"
 // @Router /cryptocurrencies/latest [get]
-func (r *MySQLCryptoRepository) FindUltimaCotizacion(nombre string) (*criptomonedas.Cotizacion, error) {
+func (r *MySQLCryptoRepository) FindUltimaCotizacion(nombre, fiat string) (*criptomonedas.Cotizacion, error) {
 	query := `
 		SELECT
-		c.id, c.cotizacion, c.fecha, c.cripto_id
+		c.id, c.cotizacion, c.fiat, c.fecha, c.cripto_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid
 	FROM
 		cotizaciones c
 	JOIN
 		monedas cm ON c.cripto_id = cm.id
 	WHERE
 		cm.nombre = ?
+		AND (? = '' OR c.fiat = ?)
 	ORDER BY
 		c.fecha DESC
 	LIMIT 1
 `
 
-	row := r.db.QueryRow(query, nombre)
+	row := r.db.QueryRow(query, nombre, fiat, fiat)
 	cotizacion := criptomonedas.Cotizacion{}
 
-	var fechaString string
-	err := row.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &fechaString, &cotizacion.CriptoMoneda_ID)
+	var fechaProveedor sql.NullTime
+	err := row.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &cotizacion.Fiat, &cotizacion.Fecha, &cotizacion.CriptoMoneda_ID, &cotizacion.Fuente, &cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("no se encontro moneda con nombre %s", nombre)
+			return nil, nil
+		}
+		log.Println("Error al obtener la última cotización:", err)
+		return nil, err
+	}
+	cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
+	return &cotizacion, nil
+}
+
+// FindFechasCotizaciones devuelve en orden las fechas de las cotizaciones de la moneda en la fiat entre desde y
+// hasta inclusive, de cualquier fuente.
+func (r *MySQLCryptoRepository) FindFechasCotizaciones(criptoId int, fiat string, desde, hasta time.Time) ([]time.Time, error) {
+	rows, err := r.db.Query(`
+		SELECT fecha FROM cotizaciones
+		WHERE cripto_id = ? AND fiat = ? AND fecha BETWEEN ? AND ?
+		ORDER BY fecha`, criptoId, fiatOPorDefecto(fiat), desde, hasta)
+	if err != nil {
+		log.Println("Error al obtener las fechas de las cotizaciones:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fechas []time.Time
+	for rows.Next() {
+		var fecha time.Time
+		if err := rows.Scan(&fecha); err != nil {
 			return nil, err
 		}
-		// Convertir fecha a time.Time
-		cotizacion.Fecha, err = time.Parse("2006-01-02 15:04:05", fechaString)
-		if err != nil {
-			log.Println("Error al convertir fecha:", err)
+		fechas = append(fechas, fecha)
+	}
+	return fechas, rows.Err()
+}
+
+// FindCotizacionesRecientes devuelve las últimas cotizaciones de la moneda en la fiat, de la más nueva a la
+// más vieja, sin contar las manuales.
+func (r *MySQLCryptoRepository) FindCotizacionesRecientes(criptoId int, fiat string, limite int) ([]criptomonedas.Cotizacion, error) {
+	rows, err := r.db.Query(`
+		SELECT id, cotizacion, fiat, fecha, cripto_id, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid
+		FROM cotizaciones
+		WHERE cripto_id = ? AND fiat = ? AND manual = FALSE
+		ORDER BY fecha DESC, id DESC
+		LIMIT ?`, criptoId, fiatOPorDefecto(fiat), limite)
+	if err != nil {
+		log.Println("Error al obtener las cotizaciones recientes:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.Cotizacion
+	for rows.Next() {
+		var cotizacion criptomonedas.Cotizacion
+		var fechaProveedor sql.NullTime
+		if err := rows.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &cotizacion.Fiat, &cotizacion.Fecha, &cotizacion.CriptoMoneda_ID, &cotizacion.Fuente, &cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid); err != nil {
+			return nil, err
 		}
+		cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
+		cotizaciones = append(cotizaciones, cotizacion)
 	}
-	return &cotizacion, nil
+	return cotizaciones, rows.Err()
+}
+
+// FindUltimasCotizacionesPorFuente devuelve la última cotización de la moneda de cada fuente, ordenadas por fuente.
+// Con fiat vacía se toma la última en cualquier fiat.
+func (r *MySQLCryptoRepository) FindUltimasCotizacionesPorFuente(nombre, fiat string) ([]criptomonedas.Cotizacion, error) {
+	query := `
+	SELECT id, cotizacion, fiat, fecha, cripto_id, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid
+	FROM (
+		SELECT
+			c.id, c.cotizacion, c.fiat, c.fecha, c.cripto_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid,
+			ROW_NUMBER() OVER (PARTITION BY c.fuente ORDER BY c.fecha DESC, c.id DESC) AS orden
+		FROM
+			cotizaciones c
+		JOIN
+			monedas cm ON c.cripto_id = cm.id
+		WHERE
+			cm.nombre = ?
+			AND (? = '' OR c.fiat = ?)
+	) ultimas
+	WHERE orden = 1
+	ORDER BY fuente
+`
+	rows, err := r.db.Query(query, nombre, fiat, fiat)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.Cotizacion
+	for rows.Next() {
+		var cotizacion criptomonedas.Cotizacion
+		var fechaProveedor sql.NullTime
+		if err := rows.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &cotizacion.Fiat, &cotizacion.Fecha, &cotizacion.CriptoMoneda_ID, &cotizacion.Fuente, &cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid); err != nil {
+			return nil, err
+		}
+		cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
+		cotizaciones = append(cotizaciones, cotizacion)
+	}
+	return cotizaciones, rows.Err()
 }
 
 func (r *MySQLCryptoRepository) FindAllByFilterForUser(filter criptomonedas.CriptoMonedaFilter, usuarioId int) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error) {
 	query := `
         SELECT 
-            c.id, c.cotizacion, c.fecha, c.cripto_id,
+            c.id, c.cotizacion, c.fiat, c.fecha, c.cripto_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid,
             JSON_ARRAYAGG(c.cotizacion) AS cotizaciones_valores,
 			JSON_ARRAYAGG(c.fecha) AS cotizaciones_fechas, 
 			JSON_ARRAYAGG(cm.nombre) AS cripto_nombres  
@@ -238,14 +451,32 @@ func (r *MySQLCryptoRepository) FindAllByFilterForUser(filter criptomonedas.Crip
             monedas cm ON c.cripto_id = cm.id 
         JOIN
             usuario_moneda um ON um.moneda_id = cm.id
+        JOIN
+            usuarios u ON u.id = um.usuario_id
         WHERE 
             um.usuario_id = ?`
 	args := []interface{}{usuarioId}
 
+	// sin fiat en el filtro se muestran las cotizaciones en la fiat preferida del usuario
+	if filter.Fiat != nil {
+		query += " AND c.fiat = ?"
+		args = append(args, *filter.Fiat)
+	} else {
+		query += " AND c.fiat = u.fiat_preferida"
+	}
+
 	if filter.Nombre != nil {
 		query += " AND cm.nombre LIKE ?"
 		args = append(args, "%"+*filter.Nombre+"%")
 	}
+	if filter.Fuente != nil {
+		query += " AND c.fuente = ?"
+		args = append(args, *filter.Fuente)
+	}
+	if filter.Exchange != nil {
+		query += " AND c.exchange = ?"
+		args = append(args, *filter.Exchange)
+	}
 	if filter.MinCotizacion != nil {
 		query += " AND c.cotizacion >= ?"
 		args = append(args, *filter.MinCotizacion)
@@ -264,7 +495,7 @@ func (r *MySQLCryptoRepository) FindAllByFilterForUser(filter criptomonedas.Crip
 	}
 
 	// Add pagination
-	query += " GROUP BY c.id, c.cotizacion, c.fecha, c.cripto_id"
+	query += " GROUP BY c.id, c.cotizacion, c.fiat, c.fecha, c.cripto_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid"
 	query += " LIMIT ? OFFSET ?"
 	args = append(args, filter.PageSize, filter.PageSize*(filter.PageNumber-1))
 
@@ -280,11 +511,13 @@ func (r *MySQLCryptoRepository) FindAllByFilterForUser(filter criptomonedas.Crip
 	for rows.Next() {
 		var cotizacion criptomonedas.Cotizacion
 		var fechaString string
+		var fechaProveedor sql.NullTime
 		var cotizacionesValoresJSON, cotizacionesFechasJSON, criptoNombresJSON string
 
-		if err := rows.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &fechaString, &cotizacion.CriptoMoneda_ID, &cotizacionesValoresJSON, &cotizacionesFechasJSON, &criptoNombresJSON); err != nil {
+		if err := rows.Scan(&cotizacion.Id, &cotizacion.Cotizacion, &cotizacion.Fiat, &fechaString, &cotizacion.CriptoMoneda_ID, &cotizacion.Fuente, &cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid, &cotizacionesValoresJSON, &cotizacionesFechasJSON, &criptoNombresJSON); err != nil {
 			return nil, criptomonedas.Summary{}, err
 		}
+		cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
 
 		cotizacion.Fecha, err = time.Parse(time.RFC3339, fechaString)
 		if err != nil {
@@ -355,19 +588,23 @@ func (r *MySQLCryptoRepository) GuardarCotizacionManual(usuarioId int, cotizacio
 	var cotizacionCompleta criptomonedas.Cotizacion = criptomonedas.Cotizacion{
 		Id:              cotizacion.Id,
 		Cotizacion:      cotizacion.Cotizacion,
+		Fiat:            fiatOPorDefecto(cotizacion.Fiat),
 		Fecha:           cotizacion.Fecha,
 		CriptoMoneda_ID: cotizacion.CriptoMoneda_ID,
 		Manual:          true,
 		UsuarioId:       &usuarioId,
+		Fuente:          criptomonedas.FuenteManual,
 	}
 
 	// Inserta la cotización completa en la base de datos
 	result, err := r.db.Exec(
-		"INSERT INTO cotizaciones (cripto_id, cotizacion, fecha, manual, usuario_id) VALUES (?, ?, ?, TRUE, ?)",
+		"INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, manual, usuario_id, fuente) VALUES (?, ?, ?, ?, TRUE, ?, ?)",
 		cotizacionCompleta.CriptoMoneda_ID,
 		cotizacionCompleta.Cotizacion,
+		cotizacionCompleta.Fiat,
 		cotizacionCompleta.Fecha,
 		usuarioId,
+		cotizacionCompleta.Fuente,
 	)
 	if err != nil {
 		log.Println("Error al guardar cripto:", err)
@@ -385,7 +622,7 @@ func (r *MySQLCryptoRepository) GuardarCotizacionManual(usuarioId int, cotizacio
 
 func (r *MySQLCryptoRepository) ActualizarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error) {
 	// Construye la consulta SQL
-	query := "UPDATE cotizaciones SET cripto_id = ?, cotizacion = ?, fecha = ?, manual = TRUE, usuario_id = ? WHERE id = ?"
+	query := "UPDATE cotizaciones SET cripto_id = ?, cotizacion = ?, fiat = ?, fecha = ?, manual = TRUE, usuario_id = ?, fuente = ?, exchange = '', fecha_proveedor = NULL, volumen = 0, total_ask = 0, total_bid = 0 WHERE id = ?"
 
 	// Imprime la consulta SQL con los parámetros
 	fmt.Printf("Ejecutando consulta SQL: %s\n", query)
@@ -398,12 +635,18 @@ func (r *MySQLCryptoRepository) ActualizarCotizacionManual(usuarioId int, cotiza
 	)
 
 	// Ejecuta la consulta SQL
+	cotizacion.Fiat = fiatOPorDefecto(cotizacion.Fiat)
+	cotizacion.Fuente = criptomonedas.FuenteManual
+	cotizacion.Exchange = ""
+	cotizacion.FechaProveedor = nil
 	_, err := r.db.Exec(
 		query,
 		cotizacion.CriptoMoneda_ID,
 		cotizacion.Cotizacion,
+		cotizacion.Fiat,
 		cotizacion.Fecha,
 		usuarioId,
+		cotizacion.Fuente,
 		cotizacion.Id,
 	)
 	if err != nil {
@@ -423,3 +666,19 @@ func (r *MySQLCryptoRepository) BorrarCotizacionManual(cotizacion criptomonedas.
 
 	return nil
 }
+
+// fiatOPorDefecto devuelve la fiat recibida o FiatPorDefecto si viene vacía.
+func fiatOPorDefecto(fiat string) string {
+	if fiat == "" {
+		return criptomonedas.FiatPorDefecto
+	}
+	return fiat
+}
+
+// fechaOpcional devuelve nil para las columnas de fecha en NULL.
+func fechaOpcional(fecha sql.NullTime) *time.Time {
+	if !fecha.Valid {
+		return nil
+	}
+	return &fecha.Time
+}
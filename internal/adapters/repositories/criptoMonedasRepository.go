@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"log"
 	"primerProjecto/internal/entities/criptomonedas"
+	"time"
 )
 
 type MySQLCryptoRepository struct {
@@ -20,6 +21,7 @@ func NewMySQLCryptoRepository(db *sql.DB) *MySQLCryptoRepository {
 type CryptoRepository interface {
 	SaveMoneda(cripto criptomonedas.CriptoMoneda) error
 	FindAllMonedas() ([]*criptomonedas.CriptoMoneda, error)
+	FindMonedasSeguidas() ([]*criptomonedas.CriptoMoneda, error)
 	FindCryptoByName(name string) (*criptomonedas.CriptoMoneda, error)
 	FindCryptoByCode(codigo string) (*criptomonedas.CriptoMoneda, error)
 	FindByMonedaID(id int) (*criptomonedas.CriptoMoneda, error)
@@ -27,12 +29,17 @@ type CryptoRepository interface {
 
 	//cotizaciones
 	SaveCotizacion(cripto criptomonedas.Cotizacion) error
+	SaveCotizaciones(cotizaciones []criptomonedas.Cotizacion) error
+	SaveCotizacionesHistoricas(cotizaciones []criptomonedas.Cotizacion) (guardadas int, err error)
 	FindByCotizacionID(id int) (*criptomonedas.Cotizacion, error)
 	FindAllCotizaciones() ([]*criptomonedas.Cotizacion, error)
 	UpdateCotizacion(id int, cotizacion criptomonedas.Cotizacion) error
 	FindAllByFilter(filter criptomonedas.CriptoMonedaFilter) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error)
 	FindAllByFilterForUser(filter criptomonedas.CriptoMonedaFilter, usuarioId int) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error)
-	FindUltimaCotizacion(nombre string) (*criptomonedas.Cotizacion, error)
+	FindUltimaCotizacion(nombre, fiat string) (*criptomonedas.Cotizacion, error)
+	FindFechasCotizaciones(criptoId int, fiat string, desde, hasta time.Time) ([]time.Time, error)
+	FindCotizacionesRecientes(criptoId int, fiat string, limite int) ([]criptomonedas.Cotizacion, error)
+	FindUltimasCotizacionesPorFuente(nombre, fiat string) ([]criptomonedas.Cotizacion, error)
 	BorrarCotizacionManual(cotizacion criptomonedas.Cotizacion) error
 	GuardarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error)
 	ActualizarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error)
@@ -91,6 +98,31 @@ func (r *MySQLCryptoRepository) FindAllMonedas() ([]*criptomonedas.CriptoMoneda,
 	return monedas, nil
 }
 
+// FindMonedasSeguidas devuelve las monedas que sigue al menos un usuario.
+func (r *MySQLCryptoRepository) FindMonedasSeguidas() ([]*criptomonedas.CriptoMoneda, error) {
+	query := `
+	SELECT DISTINCT m.id, m.nombre, m.codigo
+	FROM monedas m
+	JOIN usuario_moneda um ON um.moneda_id = m.id
+	ORDER BY m.id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Println("Error al buscar las monedas seguidas:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var monedas []*criptomonedas.CriptoMoneda
+	for rows.Next() {
+		moneda := &criptomonedas.CriptoMoneda{}
+		if err := rows.Scan(&moneda.Id, &moneda.Nombre, &moneda.Codigo); err != nil {
+			return nil, err
+		}
+		monedas = append(monedas, moneda)
+	}
+	return monedas, rows.Err()
+}
+
 func (r *MySQLCryptoRepository) UpdateMoneda(id int, moneda criptomonedas.CriptoMoneda) error {
 	query := "UPDATE monedas SET nombre = ? WHERE id = ?"
 	_, err := r.db.Exec(query, moneda.Nombre, id)
@@ -0,0 +1,129 @@
+package repositories
+
+//go:generate echo $GOPACKAGE/$GOFILE
+//go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+type MySQLCuarentenaRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLCuarentenaRepository(db *sql.DB) *MySQLCuarentenaRepository {
+	return &MySQLCuarentenaRepository{db: db}
+}
+
+type CuarentenaRepository interface {
+	SaveCuarentena(cuarentena criptomonedas.CotizacionCuarentena) (int, error)
+	FindCuarentenaByID(id int) (*criptomonedas.CotizacionCuarentena, error)
+	// FindAllCuarentena devuelve las cotizaciones en cuarentena del estado pedido, o de todos con estado vacío,
+	// de la más nueva a la más vieja.
+	FindAllCuarentena(estado string) ([]criptomonedas.CotizacionCuarentena, error)
+	// ResolverCuarentena pasa una cotización pendiente a aprobada o rechazada; al aprobarla la guarda en
+	// cotizaciones en la misma transacción. Devuelve false si la cotización no existe o ya no estaba pendiente.
+	ResolverCuarentena(id int, estado string, revisada time.Time) (bool, error)
+}
+
+const selectCuarentena = `
+	SELECT q.id, q.cripto_id, m.nombre, q.cotizacion, q.fiat, q.fecha, q.fuente, q.exchange, q.fecha_proveedor,
+		q.volumen, q.total_ask, q.total_bid, q.referencia, q.salto, q.z_score, q.motivo, q.estado, q.revisada
+	FROM cotizaciones_cuarentena q
+	JOIN monedas m ON m.id = q.cripto_id`
+
+func escanearCuarentena(scanner interface{ Scan(dest ...any) error }) (criptomonedas.CotizacionCuarentena, error) {
+	var cuarentena criptomonedas.CotizacionCuarentena
+	var fechaProveedor, revisada sql.NullTime
+	err := scanner.Scan(&cuarentena.Id, &cuarentena.CriptoMoneda_ID, &cuarentena.Moneda, &cuarentena.Cotizacion, &cuarentena.Fiat,
+		&cuarentena.Fecha, &cuarentena.Fuente, &cuarentena.Exchange, &fechaProveedor, &cuarentena.Volumen, &cuarentena.TotalAsk,
+		&cuarentena.TotalBid, &cuarentena.Referencia, &cuarentena.Salto,
+		&cuarentena.ZScore, &cuarentena.Motivo, &cuarentena.Estado, &revisada)
+	cuarentena.FechaProveedor = fechaOpcional(fechaProveedor)
+	cuarentena.Revisada = fechaOpcional(revisada)
+	return cuarentena, err
+}
+
+func (r *MySQLCuarentenaRepository) SaveCuarentena(cuarentena criptomonedas.CotizacionCuarentena) (int, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO cotizaciones_cuarentena (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid,
+			referencia, salto, z_score, motivo, estado)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		cuarentena.CriptoMoneda_ID, cuarentena.Cotizacion, fiatOPorDefecto(cuarentena.Fiat), cuarentena.Fecha, cuarentena.Fuente,
+		cuarentena.Exchange, cuarentena.FechaProveedor, cuarentena.Volumen, cuarentena.TotalAsk, cuarentena.TotalBid, cuarentena.Referencia, cuarentena.Salto, cuarentena.ZScore, cuarentena.Motivo,
+		cuarentena.Estado,
+	)
+	if err != nil {
+		log.Println("Error al guardar la cotización en cuarentena:", err)
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// FindCuarentenaByID devuelve nil sin error si la cotización no existe.
+func (r *MySQLCuarentenaRepository) FindCuarentenaByID(id int) (*criptomonedas.CotizacionCuarentena, error) {
+	cuarentena, err := escanearCuarentena(r.db.QueryRow(selectCuarentena+" WHERE q.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cuarentena, nil
+}
+
+func (r *MySQLCuarentenaRepository) FindAllCuarentena(estado string) ([]criptomonedas.CotizacionCuarentena, error) {
+	rows, err := r.db.Query(selectCuarentena+" WHERE (? = '' OR q.estado = ?) ORDER BY q.id DESC", estado, estado)
+	if err != nil {
+		log.Println("Error al obtener las cotizaciones en cuarentena:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cuarentenas []criptomonedas.CotizacionCuarentena
+	for rows.Next() {
+		cuarentena, err := escanearCuarentena(rows)
+		if err != nil {
+			return nil, err
+		}
+		cuarentenas = append(cuarentenas, cuarentena)
+	}
+	return cuarentenas, rows.Err()
+}
+
+func (r *MySQLCuarentenaRepository) ResolverCuarentena(id int, estado string, revisada time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec("UPDATE cotizaciones_cuarentena SET estado = ?, revisada = ? WHERE id = ? AND estado = ?",
+		estado, revisada, id, criptomonedas.CuarentenaPendiente)
+	if err != nil {
+		tx.Rollback()
+		log.Println("Error al resolver la cotización en cuarentena:", err)
+		return false, err
+	}
+	filas, err := result.RowsAffected()
+	if err != nil || filas == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	if estado == criptomonedas.CuarentenaAprobada {
+		_, err = tx.Exec(`
+			INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid)
+			SELECT cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid
+			FROM cotizaciones_cuarentena WHERE id = ?`, id)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar la cotización aprobada:", err)
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,141 @@
+package repositories
+
+//go:generate echo $GOPACKAGE/$GOFILE
+//go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+type MySQLExchangeRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLExchangeRepository(db *sql.DB) *MySQLExchangeRepository {
+	return &MySQLExchangeRepository{db: db}
+}
+
+type ExchangeRepository interface {
+	FindAllExchanges() ([]criptomonedas.Exchange, error)
+	SaveCotizacionesExchange(cotizaciones []criptomonedas.CotizacionExchange) error
+	FindCotizacionesExchange(filter criptomonedas.CotizacionExchangeFilter) ([]criptomonedas.CotizacionExchange, error)
+}
+
+func (r *MySQLExchangeRepository) FindAllExchanges() ([]criptomonedas.Exchange, error) {
+	rows, err := r.db.Query("SELECT id, nombre FROM exchanges ORDER BY nombre")
+	if err != nil {
+		log.Println("Error al obtener los exchanges:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exchanges []criptomonedas.Exchange
+	for rows.Next() {
+		var exchange criptomonedas.Exchange
+		if err := rows.Scan(&exchange.Id, &exchange.Nombre); err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
+
+// SaveCotizacionesExchange guarda las cotizaciones de una misma consulta en una transacción,
+// dando de alta los exchanges que todavía no existan.
+func (r *MySQLExchangeRepository) SaveCotizacionesExchange(cotizaciones []criptomonedas.CotizacionExchange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, cotizacion := range cotizaciones {
+		// LAST_INSERT_ID(id) hace que LastInsertId devuelva el id existente cuando el exchange ya estaba cargado
+		result, err := tx.Exec("INSERT INTO exchanges (nombre) VALUES (?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)", cotizacion.Exchange)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar exchange:", err)
+			return err
+		}
+		exchangeId, err := result.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec(`INSERT INTO cotizaciones_exchange (cripto_id, exchange_id, fiat, ask, total_ask, bid, total_bid, volumen, fecha_proveedor, fecha)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			cotizacion.CriptoMoneda_ID, exchangeId, cotizacion.Fiat, cotizacion.Ask, cotizacion.TotalAsk,
+			cotizacion.Bid, cotizacion.TotalBid, cotizacion.Volumen, cotizacion.FechaProveedor, cotizacion.Fecha)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizacion de exchange:", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *MySQLExchangeRepository) FindCotizacionesExchange(filter criptomonedas.CotizacionExchangeFilter) ([]criptomonedas.CotizacionExchange, error) {
+	query := `
+        SELECT
+            ce.id, ce.cripto_id, e.nombre, ce.fiat, ce.ask, ce.total_ask, ce.bid, ce.total_bid, ce.volumen, ce.fecha_proveedor, ce.fecha
+        FROM
+            cotizaciones_exchange ce
+        JOIN
+            exchanges e ON ce.exchange_id = e.id
+        JOIN
+            monedas cm ON ce.cripto_id = cm.id
+        WHERE
+            cm.nombre = ?`
+	args := []interface{}{filter.Nombre}
+
+	if filter.Exchange != nil {
+		query += " AND e.nombre = ?"
+		args = append(args, *filter.Exchange)
+	}
+	if filter.Fiat != nil {
+		query += " AND ce.fiat = ?"
+		args = append(args, *filter.Fiat)
+	}
+	if filter.StartDate != nil {
+		query += " AND ce.fecha >= ?"
+		args = append(args, *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query += " AND ce.fecha <= ?"
+		args = append(args, *filter.EndDate)
+	}
+
+	query += " ORDER BY ce.fecha DESC, e.nombre"
+	// sin PageSize se devuelven todas las filas, por ejemplo para reconstruir cada consulta completa
+	if filter.PageSize > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.PageSize, filter.PageSize*(filter.PageNumber-1))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener cotizaciones de exchange:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.CotizacionExchange
+	for rows.Next() {
+		var c criptomonedas.CotizacionExchange
+		if err := rows.Scan(&c.Id, &c.CriptoMoneda_ID, &c.Exchange, &c.Fiat, &c.Ask, &c.TotalAsk, &c.Bid, &c.TotalBid, &c.Volumen, &c.FechaProveedor, &c.Fecha); err != nil {
+			return nil, err
+		}
+		cotizaciones = append(cotizaciones, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cotizaciones, nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+//go:generate echo $GOPACKAGE/$GOFILE
+//go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+type MySQLFiatRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLFiatRepository(db *sql.DB) *MySQLFiatRepository {
+	return &MySQLFiatRepository{db: db}
+}
+
+type FiatRepository interface {
+	FindAllFiats() ([]criptomonedas.MonedaFiat, error)
+	FindFiatByCodigo(codigo string) (*criptomonedas.MonedaFiat, error)
+}
+
+func (r *MySQLFiatRepository) FindAllFiats() ([]criptomonedas.MonedaFiat, error) {
+	rows, err := r.db.Query("SELECT codigo, nombre FROM monedas_fiat ORDER BY codigo")
+	if err != nil {
+		log.Println("Error al obtener las monedas fiat:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fiats []criptomonedas.MonedaFiat
+	for rows.Next() {
+		var fiat criptomonedas.MonedaFiat
+		if err := rows.Scan(&fiat.Codigo, &fiat.Nombre); err != nil {
+			return nil, err
+		}
+		fiats = append(fiats, fiat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return fiats, nil
+}
+
+// FindFiatByCodigo devuelve nil sin error si la moneda no está cargada.
+func (r *MySQLFiatRepository) FindFiatByCodigo(codigo string) (*criptomonedas.MonedaFiat, error) {
+	var fiat criptomonedas.MonedaFiat
+	err := r.db.QueryRow("SELECT codigo, nombre FROM monedas_fiat WHERE codigo = ?", codigo).Scan(&fiat.Codigo, &fiat.Nombre)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Println("Error al buscar la moneda fiat:", err)
+		return nil, err
+	}
+	return &fiat, nil
+}
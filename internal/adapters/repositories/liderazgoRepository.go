@@ -0,0 +1,80 @@
+package repositories
+
+//go:generate echo $GOPACKAGE/$GOFILE
+//go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+type MySQLLiderazgoRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLLiderazgoRepository(db *sql.DB) *MySQLLiderazgoRepository {
+	return &MySQLLiderazgoRepository{db: db}
+}
+
+type LiderazgoRepository interface {
+	AdquirirLiderazgo(ctx context.Context, nombre, instancia string, duracion time.Duration) (criptomonedas.Liderazgo, error)
+	FindLiderazgo(nombre string) (*criptomonedas.Liderazgo, error)
+	LiberarLiderazgo(nombre, instancia string) error
+}
+
+// AdquirirLiderazgo toma el lease si está libre o vencido, o lo renueva si ya era de la instancia, y devuelve
+// el lease como quedó. La instancia es líder si el lease devuelto es suyo. Los vencimientos se calculan con el
+// reloj de la base para que no dependan del reloj de cada instancia. Las consultas se cortan cuando se cancela
+// ctx, así una base que no responde no deja al líder esperando.
+func (r *MySQLLiderazgoRepository) AdquirirLiderazgo(ctx context.Context, nombre, instancia string, duracion time.Duration) (criptomonedas.Liderazgo, error) {
+	// instancia se asigna antes que vence, así la condición de vence ya ve al nuevo dueño
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO liderazgos (nombre, instancia, vence)
+		VALUES (?, ?, NOW(6) + INTERVAL ? MICROSECOND)
+		ON DUPLICATE KEY UPDATE
+			instancia = IF(vence < NOW(6) OR instancia = VALUES(instancia), VALUES(instancia), instancia),
+			vence = IF(instancia = VALUES(instancia), VALUES(vence), vence)`,
+		nombre, instancia, duracion.Microseconds(),
+	)
+	if err != nil {
+		log.Println("Error al adquirir el liderazgo:", err)
+		return criptomonedas.Liderazgo{}, err
+	}
+	liderazgo, err := r.buscarLiderazgo(ctx, nombre)
+	if err != nil || liderazgo == nil {
+		return criptomonedas.Liderazgo{}, err
+	}
+	return *liderazgo, nil
+}
+
+// FindLiderazgo devuelve nil sin error si nadie tomó el lease todavía.
+func (r *MySQLLiderazgoRepository) FindLiderazgo(nombre string) (*criptomonedas.Liderazgo, error) {
+	return r.buscarLiderazgo(context.Background(), nombre)
+}
+
+func (r *MySQLLiderazgoRepository) buscarLiderazgo(ctx context.Context, nombre string) (*criptomonedas.Liderazgo, error) {
+	var liderazgo criptomonedas.Liderazgo
+	err := r.db.QueryRowContext(ctx, "SELECT nombre, instancia, vence FROM liderazgos WHERE nombre = ?", nombre).Scan(
+		&liderazgo.Nombre, &liderazgo.Instancia, &liderazgo.Vence,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &liderazgo, nil
+}
+
+// LiberarLiderazgo suelta el lease si es de la instancia, para que otra lo tome sin esperar a que venza.
+func (r *MySQLLiderazgoRepository) LiberarLiderazgo(nombre, instancia string) error {
+	_, err := r.db.Exec("DELETE FROM liderazgos WHERE nombre = ? AND instancia = ?", nombre, instancia)
+	if err != nil {
+		log.Println("Error al liberar el liderazgo:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./backfillRepository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./backfillRepository.go -destination=./mock/backfillRepository.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBackfillRepository is a mock of BackfillRepository interface.
+type MockBackfillRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackfillRepositoryMockRecorder
+}
+
+// MockBackfillRepositoryMockRecorder is the mock recorder for MockBackfillRepository.
+type MockBackfillRepositoryMockRecorder struct {
+	mock *MockBackfillRepository
+}
+
+// NewMockBackfillRepository creates a new mock instance.
+func NewMockBackfillRepository(ctrl *gomock.Controller) *MockBackfillRepository {
+	mock := &MockBackfillRepository{ctrl: ctrl}
+	mock.recorder = &MockBackfillRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackfillRepository) EXPECT() *MockBackfillRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAllBackfills mocks base method.
+func (m *MockBackfillRepository) FindAllBackfills() ([]criptomonedas.Backfill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllBackfills")
+	ret0, _ := ret[0].([]criptomonedas.Backfill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllBackfills indicates an expected call of FindAllBackfills.
+func (mr *MockBackfillRepositoryMockRecorder) FindAllBackfills() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllBackfills", reflect.TypeOf((*MockBackfillRepository)(nil).FindAllBackfills))
+}
+
+// FindBackfillByID mocks base method.
+func (m *MockBackfillRepository) FindBackfillByID(id int) (*criptomonedas.Backfill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBackfillByID", id)
+	ret0, _ := ret[0].(*criptomonedas.Backfill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBackfillByID indicates an expected call of FindBackfillByID.
+func (mr *MockBackfillRepositoryMockRecorder) FindBackfillByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBackfillByID", reflect.TypeOf((*MockBackfillRepository)(nil).FindBackfillByID), id)
+}
+
+// FindBackfillsPendientes mocks base method.
+func (m *MockBackfillRepository) FindBackfillsPendientes() ([]criptomonedas.Backfill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBackfillsPendientes")
+	ret0, _ := ret[0].([]criptomonedas.Backfill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBackfillsPendientes indicates an expected call of FindBackfillsPendientes.
+func (mr *MockBackfillRepositoryMockRecorder) FindBackfillsPendientes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBackfillsPendientes", reflect.TypeOf((*MockBackfillRepository)(nil).FindBackfillsPendientes))
+}
+
+// SaveBackfill mocks base method.
+func (m *MockBackfillRepository) SaveBackfill(backfill criptomonedas.Backfill) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBackfill", backfill)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBackfill indicates an expected call of SaveBackfill.
+func (mr *MockBackfillRepositoryMockRecorder) SaveBackfill(backfill any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBackfill", reflect.TypeOf((*MockBackfillRepository)(nil).SaveBackfill), backfill)
+}
+
+// UpdateBackfill mocks base method.
+func (m *MockBackfillRepository) UpdateBackfill(backfill criptomonedas.Backfill) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBackfill", backfill)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBackfill indicates an expected call of UpdateBackfill.
+func (mr *MockBackfillRepositoryMockRecorder) UpdateBackfill(backfill any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBackfill", reflect.TypeOf((*MockBackfillRepository)(nil).UpdateBackfill), backfill)
+}
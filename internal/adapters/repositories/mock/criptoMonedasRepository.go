@@ -12,6 +12,7 @@ package mock
 import (
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -174,6 +175,21 @@ func (mr *MockCryptoRepositoryMockRecorder) FindByMonedaID(id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByMonedaID", reflect.TypeOf((*MockCryptoRepository)(nil).FindByMonedaID), id)
 }
 
+// FindCotizacionesRecientes mocks base method.
+func (m *MockCryptoRepository) FindCotizacionesRecientes(criptoId int, fiat string, limite int) ([]criptomonedas.Cotizacion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCotizacionesRecientes", criptoId, fiat, limite)
+	ret0, _ := ret[0].([]criptomonedas.Cotizacion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCotizacionesRecientes indicates an expected call of FindCotizacionesRecientes.
+func (mr *MockCryptoRepositoryMockRecorder) FindCotizacionesRecientes(criptoId, fiat, limite any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCotizacionesRecientes", reflect.TypeOf((*MockCryptoRepository)(nil).FindCotizacionesRecientes), criptoId, fiat, limite)
+}
+
 // FindCryptoByCode mocks base method.
 func (m *MockCryptoRepository) FindCryptoByCode(codigo string) (*criptomonedas.CriptoMoneda, error) {
 	m.ctrl.T.Helper()
@@ -204,19 +220,64 @@ func (mr *MockCryptoRepositoryMockRecorder) FindCryptoByName(name any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCryptoByName", reflect.TypeOf((*MockCryptoRepository)(nil).FindCryptoByName), name)
 }
 
+// FindFechasCotizaciones mocks base method.
+func (m *MockCryptoRepository) FindFechasCotizaciones(criptoId int, fiat string, desde, hasta time.Time) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFechasCotizaciones", criptoId, fiat, desde, hasta)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFechasCotizaciones indicates an expected call of FindFechasCotizaciones.
+func (mr *MockCryptoRepositoryMockRecorder) FindFechasCotizaciones(criptoId, fiat, desde, hasta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFechasCotizaciones", reflect.TypeOf((*MockCryptoRepository)(nil).FindFechasCotizaciones), criptoId, fiat, desde, hasta)
+}
+
+// FindMonedasSeguidas mocks base method.
+func (m *MockCryptoRepository) FindMonedasSeguidas() ([]*criptomonedas.CriptoMoneda, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMonedasSeguidas")
+	ret0, _ := ret[0].([]*criptomonedas.CriptoMoneda)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMonedasSeguidas indicates an expected call of FindMonedasSeguidas.
+func (mr *MockCryptoRepositoryMockRecorder) FindMonedasSeguidas() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMonedasSeguidas", reflect.TypeOf((*MockCryptoRepository)(nil).FindMonedasSeguidas))
+}
+
 // FindUltimaCotizacion mocks base method.
-func (m *MockCryptoRepository) FindUltimaCotizacion(nombre string) (*criptomonedas.Cotizacion, error) {
+func (m *MockCryptoRepository) FindUltimaCotizacion(nombre, fiat string) (*criptomonedas.Cotizacion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindUltimaCotizacion", nombre)
+	ret := m.ctrl.Call(m, "FindUltimaCotizacion", nombre, fiat)
 	ret0, _ := ret[0].(*criptomonedas.Cotizacion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindUltimaCotizacion indicates an expected call of FindUltimaCotizacion.
-func (mr *MockCryptoRepositoryMockRecorder) FindUltimaCotizacion(nombre any) *gomock.Call {
+func (mr *MockCryptoRepositoryMockRecorder) FindUltimaCotizacion(nombre, fiat any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUltimaCotizacion", reflect.TypeOf((*MockCryptoRepository)(nil).FindUltimaCotizacion), nombre)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUltimaCotizacion", reflect.TypeOf((*MockCryptoRepository)(nil).FindUltimaCotizacion), nombre, fiat)
+}
+
+// FindUltimasCotizacionesPorFuente mocks base method.
+func (m *MockCryptoRepository) FindUltimasCotizacionesPorFuente(nombre, fiat string) ([]criptomonedas.Cotizacion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUltimasCotizacionesPorFuente", nombre, fiat)
+	ret0, _ := ret[0].([]criptomonedas.Cotizacion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUltimasCotizacionesPorFuente indicates an expected call of FindUltimasCotizacionesPorFuente.
+func (mr *MockCryptoRepositoryMockRecorder) FindUltimasCotizacionesPorFuente(nombre, fiat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUltimasCotizacionesPorFuente", reflect.TypeOf((*MockCryptoRepository)(nil).FindUltimasCotizacionesPorFuente), nombre, fiat)
 }
 
 // GuardarCotizacionManual mocks base method.
@@ -248,6 +309,35 @@ func (mr *MockCryptoRepositoryMockRecorder) SaveCotizacion(cripto any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCotizacion", reflect.TypeOf((*MockCryptoRepository)(nil).SaveCotizacion), cripto)
 }
 
+// SaveCotizaciones mocks base method.
+func (m *MockCryptoRepository) SaveCotizaciones(cotizaciones []criptomonedas.Cotizacion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCotizaciones", cotizaciones)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCotizaciones indicates an expected call of SaveCotizaciones.
+func (mr *MockCryptoRepositoryMockRecorder) SaveCotizaciones(cotizaciones any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCotizaciones", reflect.TypeOf((*MockCryptoRepository)(nil).SaveCotizaciones), cotizaciones)
+}
+
+// SaveCotizacionesHistoricas mocks base method.
+func (m *MockCryptoRepository) SaveCotizacionesHistoricas(cotizaciones []criptomonedas.Cotizacion) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCotizacionesHistoricas", cotizaciones)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveCotizacionesHistoricas indicates an expected call of SaveCotizacionesHistoricas.
+func (mr *MockCryptoRepositoryMockRecorder) SaveCotizacionesHistoricas(cotizaciones any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCotizacionesHistoricas", reflect.TypeOf((*MockCryptoRepository)(nil).SaveCotizacionesHistoricas), cotizaciones)
+}
+
 // SaveMoneda mocks base method.
 func (m *MockCryptoRepository) SaveMoneda(cripto criptomonedas.CriptoMoneda) error {
 	m.ctrl.T.Helper()
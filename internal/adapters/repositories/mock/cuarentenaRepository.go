@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./cuarentenaRepository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./cuarentenaRepository.go -destination=./mock/cuarentenaRepository.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCuarentenaRepository is a mock of CuarentenaRepository interface.
+type MockCuarentenaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCuarentenaRepositoryMockRecorder
+}
+
+// MockCuarentenaRepositoryMockRecorder is the mock recorder for MockCuarentenaRepository.
+type MockCuarentenaRepositoryMockRecorder struct {
+	mock *MockCuarentenaRepository
+}
+
+// NewMockCuarentenaRepository creates a new mock instance.
+func NewMockCuarentenaRepository(ctrl *gomock.Controller) *MockCuarentenaRepository {
+	mock := &MockCuarentenaRepository{ctrl: ctrl}
+	mock.recorder = &MockCuarentenaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCuarentenaRepository) EXPECT() *MockCuarentenaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAllCuarentena mocks base method.
+func (m *MockCuarentenaRepository) FindAllCuarentena(estado string) ([]criptomonedas.CotizacionCuarentena, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllCuarentena", estado)
+	ret0, _ := ret[0].([]criptomonedas.CotizacionCuarentena)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllCuarentena indicates an expected call of FindAllCuarentena.
+func (mr *MockCuarentenaRepositoryMockRecorder) FindAllCuarentena(estado any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllCuarentena", reflect.TypeOf((*MockCuarentenaRepository)(nil).FindAllCuarentena), estado)
+}
+
+// FindCuarentenaByID mocks base method.
+func (m *MockCuarentenaRepository) FindCuarentenaByID(id int) (*criptomonedas.CotizacionCuarentena, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCuarentenaByID", id)
+	ret0, _ := ret[0].(*criptomonedas.CotizacionCuarentena)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCuarentenaByID indicates an expected call of FindCuarentenaByID.
+func (mr *MockCuarentenaRepositoryMockRecorder) FindCuarentenaByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCuarentenaByID", reflect.TypeOf((*MockCuarentenaRepository)(nil).FindCuarentenaByID), id)
+}
+
+// ResolverCuarentena mocks base method.
+func (m *MockCuarentenaRepository) ResolverCuarentena(id int, estado string, revisada time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolverCuarentena", id, estado, revisada)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolverCuarentena indicates an expected call of ResolverCuarentena.
+func (mr *MockCuarentenaRepositoryMockRecorder) ResolverCuarentena(id, estado, revisada any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolverCuarentena", reflect.TypeOf((*MockCuarentenaRepository)(nil).ResolverCuarentena), id, estado, revisada)
+}
+
+// SaveCuarentena mocks base method.
+func (m *MockCuarentenaRepository) SaveCuarentena(cuarentena criptomonedas.CotizacionCuarentena) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCuarentena", cuarentena)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveCuarentena indicates an expected call of SaveCuarentena.
+func (mr *MockCuarentenaRepositoryMockRecorder) SaveCuarentena(cuarentena any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCuarentena", reflect.TypeOf((*MockCuarentenaRepository)(nil).SaveCuarentena), cuarentena)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./exchangesRepository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./exchangesRepository.go -destination=./mock/exchangesRepository.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockExchangeRepository is a mock of ExchangeRepository interface.
+type MockExchangeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExchangeRepositoryMockRecorder
+}
+
+// MockExchangeRepositoryMockRecorder is the mock recorder for MockExchangeRepository.
+type MockExchangeRepositoryMockRecorder struct {
+	mock *MockExchangeRepository
+}
+
+// NewMockExchangeRepository creates a new mock instance.
+func NewMockExchangeRepository(ctrl *gomock.Controller) *MockExchangeRepository {
+	mock := &MockExchangeRepository{ctrl: ctrl}
+	mock.recorder = &MockExchangeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExchangeRepository) EXPECT() *MockExchangeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAllExchanges mocks base method.
+func (m *MockExchangeRepository) FindAllExchanges() ([]criptomonedas.Exchange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllExchanges")
+	ret0, _ := ret[0].([]criptomonedas.Exchange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllExchanges indicates an expected call of FindAllExchanges.
+func (mr *MockExchangeRepositoryMockRecorder) FindAllExchanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllExchanges", reflect.TypeOf((*MockExchangeRepository)(nil).FindAllExchanges))
+}
+
+// FindCotizacionesExchange mocks base method.
+func (m *MockExchangeRepository) FindCotizacionesExchange(filter criptomonedas.CotizacionExchangeFilter) ([]criptomonedas.CotizacionExchange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCotizacionesExchange", filter)
+	ret0, _ := ret[0].([]criptomonedas.CotizacionExchange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCotizacionesExchange indicates an expected call of FindCotizacionesExchange.
+func (mr *MockExchangeRepositoryMockRecorder) FindCotizacionesExchange(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCotizacionesExchange", reflect.TypeOf((*MockExchangeRepository)(nil).FindCotizacionesExchange), filter)
+}
+
+// SaveCotizacionesExchange mocks base method.
+func (m *MockExchangeRepository) SaveCotizacionesExchange(cotizaciones []criptomonedas.CotizacionExchange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCotizacionesExchange", cotizaciones)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCotizacionesExchange indicates an expected call of SaveCotizacionesExchange.
+func (mr *MockExchangeRepositoryMockRecorder) SaveCotizacionesExchange(cotizaciones any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCotizacionesExchange", reflect.TypeOf((*MockExchangeRepository)(nil).SaveCotizacionesExchange), cotizaciones)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./fiatRepository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./fiatRepository.go -destination=./mock/fiatRepository.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFiatRepository is a mock of FiatRepository interface.
+type MockFiatRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFiatRepositoryMockRecorder
+}
+
+// MockFiatRepositoryMockRecorder is the mock recorder for MockFiatRepository.
+type MockFiatRepositoryMockRecorder struct {
+	mock *MockFiatRepository
+}
+
+// NewMockFiatRepository creates a new mock instance.
+func NewMockFiatRepository(ctrl *gomock.Controller) *MockFiatRepository {
+	mock := &MockFiatRepository{ctrl: ctrl}
+	mock.recorder = &MockFiatRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFiatRepository) EXPECT() *MockFiatRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAllFiats mocks base method.
+func (m *MockFiatRepository) FindAllFiats() ([]criptomonedas.MonedaFiat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllFiats")
+	ret0, _ := ret[0].([]criptomonedas.MonedaFiat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllFiats indicates an expected call of FindAllFiats.
+func (mr *MockFiatRepositoryMockRecorder) FindAllFiats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllFiats", reflect.TypeOf((*MockFiatRepository)(nil).FindAllFiats))
+}
+
+// FindFiatByCodigo mocks base method.
+func (m *MockFiatRepository) FindFiatByCodigo(codigo string) (*criptomonedas.MonedaFiat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFiatByCodigo", codigo)
+	ret0, _ := ret[0].(*criptomonedas.MonedaFiat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFiatByCodigo indicates an expected call of FindFiatByCodigo.
+func (mr *MockFiatRepositoryMockRecorder) FindFiatByCodigo(codigo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFiatByCodigo", reflect.TypeOf((*MockFiatRepository)(nil).FindFiatByCodigo), codigo)
+}
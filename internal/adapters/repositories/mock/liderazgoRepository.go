@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./liderazgoRepository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./liderazgoRepository.go -destination=./mock/liderazgoRepository.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLiderazgoRepository is a mock of LiderazgoRepository interface.
+type MockLiderazgoRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLiderazgoRepositoryMockRecorder
+}
+
+// MockLiderazgoRepositoryMockRecorder is the mock recorder for MockLiderazgoRepository.
+type MockLiderazgoRepositoryMockRecorder struct {
+	mock *MockLiderazgoRepository
+}
+
+// NewMockLiderazgoRepository creates a new mock instance.
+func NewMockLiderazgoRepository(ctrl *gomock.Controller) *MockLiderazgoRepository {
+	mock := &MockLiderazgoRepository{ctrl: ctrl}
+	mock.recorder = &MockLiderazgoRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLiderazgoRepository) EXPECT() *MockLiderazgoRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdquirirLiderazgo mocks base method.
+func (m *MockLiderazgoRepository) AdquirirLiderazgo(ctx context.Context, nombre, instancia string, duracion time.Duration) (criptomonedas.Liderazgo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdquirirLiderazgo", ctx, nombre, instancia, duracion)
+	ret0, _ := ret[0].(criptomonedas.Liderazgo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdquirirLiderazgo indicates an expected call of AdquirirLiderazgo.
+func (mr *MockLiderazgoRepositoryMockRecorder) AdquirirLiderazgo(ctx, nombre, instancia, duracion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdquirirLiderazgo", reflect.TypeOf((*MockLiderazgoRepository)(nil).AdquirirLiderazgo), ctx, nombre, instancia, duracion)
+}
+
+// FindLiderazgo mocks base method.
+func (m *MockLiderazgoRepository) FindLiderazgo(nombre string) (*criptomonedas.Liderazgo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLiderazgo", nombre)
+	ret0, _ := ret[0].(*criptomonedas.Liderazgo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLiderazgo indicates an expected call of FindLiderazgo.
+func (mr *MockLiderazgoRepositoryMockRecorder) FindLiderazgo(nombre any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLiderazgo", reflect.TypeOf((*MockLiderazgoRepository)(nil).FindLiderazgo), nombre)
+}
+
+// LiberarLiderazgo mocks base method.
+func (m *MockLiderazgoRepository) LiberarLiderazgo(nombre, instancia string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiberarLiderazgo", nombre, instancia)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LiberarLiderazgo indicates an expected call of LiberarLiderazgo.
+func (mr *MockLiderazgoRepositoryMockRecorder) LiberarLiderazgo(nombre, instancia any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiberarLiderazgo", reflect.TypeOf((*MockLiderazgoRepository)(nil).LiberarLiderazgo), nombre, instancia)
+}
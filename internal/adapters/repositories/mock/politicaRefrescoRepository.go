@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./politicaRefrescoRepository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./politicaRefrescoRepository.go -destination=./mock/politicaRefrescoRepository.go -package mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPoliticaRefrescoRepository is a mock of PoliticaRefrescoRepository interface.
+type MockPoliticaRefrescoRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPoliticaRefrescoRepositoryMockRecorder
+}
+
+// MockPoliticaRefrescoRepositoryMockRecorder is the mock recorder for MockPoliticaRefrescoRepository.
+type MockPoliticaRefrescoRepositoryMockRecorder struct {
+	mock *MockPoliticaRefrescoRepository
+}
+
+// NewMockPoliticaRefrescoRepository creates a new mock instance.
+func NewMockPoliticaRefrescoRepository(ctrl *gomock.Controller) *MockPoliticaRefrescoRepository {
+	mock := &MockPoliticaRefrescoRepository{ctrl: ctrl}
+	mock.recorder = &MockPoliticaRefrescoRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPoliticaRefrescoRepository) EXPECT() *MockPoliticaRefrescoRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeletePolitica mocks base method.
+func (m *MockPoliticaRefrescoRepository) DeletePolitica(criptoId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePolitica", criptoId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePolitica indicates an expected call of DeletePolitica.
+func (mr *MockPoliticaRefrescoRepositoryMockRecorder) DeletePolitica(criptoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePolitica", reflect.TypeOf((*MockPoliticaRefrescoRepository)(nil).DeletePolitica), criptoId)
+}
+
+// FindAllPoliticas mocks base method.
+func (m *MockPoliticaRefrescoRepository) FindAllPoliticas() ([]criptomonedas.PoliticaRefresco, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllPoliticas")
+	ret0, _ := ret[0].([]criptomonedas.PoliticaRefresco)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllPoliticas indicates an expected call of FindAllPoliticas.
+func (mr *MockPoliticaRefrescoRepositoryMockRecorder) FindAllPoliticas() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllPoliticas", reflect.TypeOf((*MockPoliticaRefrescoRepository)(nil).FindAllPoliticas))
+}
+
+// FindPoliticaByMonedaID mocks base method.
+func (m *MockPoliticaRefrescoRepository) FindPoliticaByMonedaID(criptoId int) (*criptomonedas.PoliticaRefresco, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPoliticaByMonedaID", criptoId)
+	ret0, _ := ret[0].(*criptomonedas.PoliticaRefresco)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPoliticaByMonedaID indicates an expected call of FindPoliticaByMonedaID.
+func (mr *MockPoliticaRefrescoRepositoryMockRecorder) FindPoliticaByMonedaID(criptoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPoliticaByMonedaID", reflect.TypeOf((*MockPoliticaRefrescoRepository)(nil).FindPoliticaByMonedaID), criptoId)
+}
+
+// SavePolitica mocks base method.
+func (m *MockPoliticaRefrescoRepository) SavePolitica(politica criptomonedas.PoliticaRefresco) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePolitica", politica)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePolitica indicates an expected call of SavePolitica.
+func (mr *MockPoliticaRefrescoRepositoryMockRecorder) SavePolitica(politica any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePolitica", reflect.TypeOf((*MockPoliticaRefrescoRepository)(nil).SavePolitica), politica)
+}
@@ -0,0 +1,96 @@
+package repositories
+
+//go:generate echo $GOPACKAGE/$GOFILE
+//go:generate mockgen -source=./$GOFILE -destination=./mock/$GOFILE -package mock
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+type MySQLPoliticaRefrescoRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLPoliticaRefrescoRepository(db *sql.DB) *MySQLPoliticaRefrescoRepository {
+	return &MySQLPoliticaRefrescoRepository{db: db}
+}
+
+type PoliticaRefrescoRepository interface {
+	FindAllPoliticas() ([]criptomonedas.PoliticaRefresco, error)
+	FindPoliticaByMonedaID(criptoId int) (*criptomonedas.PoliticaRefresco, error)
+	SavePolitica(politica criptomonedas.PoliticaRefresco) error
+	DeletePolitica(criptoId int) error
+}
+
+const selectPoliticas = `
+	SELECT p.cripto_id, m.nombre, p.cron, p.api, p.fiat, p.habilitada, p.frescura, p.actualizada
+	FROM politicas_refresco p
+	JOIN monedas m ON m.id = p.cripto_id`
+
+func (r *MySQLPoliticaRefrescoRepository) FindAllPoliticas() ([]criptomonedas.PoliticaRefresco, error) {
+	rows, err := r.db.Query(selectPoliticas + " ORDER BY p.cripto_id")
+	if err != nil {
+		log.Println("Error al obtener las políticas de refresco:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var politicas []criptomonedas.PoliticaRefresco
+	for rows.Next() {
+		var politica criptomonedas.PoliticaRefresco
+		if err := rows.Scan(&politica.CriptoMoneda_ID, &politica.Moneda, &politica.Cron, &politica.Api, &politica.Fiat, &politica.Habilitada, &politica.Frescura, &politica.Actualizada); err != nil {
+			return nil, err
+		}
+		politicas = append(politicas, politica)
+	}
+	return politicas, rows.Err()
+}
+
+// FindPoliticaByMonedaID devuelve nil sin error si la moneda no tiene política.
+func (r *MySQLPoliticaRefrescoRepository) FindPoliticaByMonedaID(criptoId int) (*criptomonedas.PoliticaRefresco, error) {
+	var politica criptomonedas.PoliticaRefresco
+	err := r.db.QueryRow(selectPoliticas+" WHERE p.cripto_id = ?", criptoId).Scan(
+		&politica.CriptoMoneda_ID, &politica.Moneda, &politica.Cron, &politica.Api, &politica.Fiat, &politica.Habilitada, &politica.Frescura, &politica.Actualizada,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &politica, nil
+}
+
+// SavePolitica crea la política de la moneda o reemplaza la que tenía.
+func (r *MySQLPoliticaRefrescoRepository) SavePolitica(politica criptomonedas.PoliticaRefresco) error {
+	_, err := r.db.Exec(`
+		INSERT INTO politicas_refresco (cripto_id, cron, api, fiat, habilitada, frescura, actualizada)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE cron = VALUES(cron), api = VALUES(api), fiat = VALUES(fiat),
+			habilitada = VALUES(habilitada), frescura = VALUES(frescura), actualizada = VALUES(actualizada)`,
+		politica.CriptoMoneda_ID, politica.Cron, politica.Api, fiatOPorDefecto(politica.Fiat), politica.Habilitada, politica.Frescura, politica.Actualizada,
+	)
+	if err != nil {
+		log.Println("Error al guardar la política de refresco:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *MySQLPoliticaRefrescoRepository) DeletePolitica(criptoId int) error {
+	result, err := r.db.Exec("DELETE FROM politicas_refresco WHERE cripto_id = ?", criptoId)
+	if err != nil {
+		return fmt.Errorf("error al borrar la política de refresco: %w", err)
+	}
+	filas, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("la moneda %d no tiene política de refresco", criptoId)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+// PostgresBackfillRepository implementa BackfillRepository sobre PostgreSQL.
+type PostgresBackfillRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresBackfillRepository(db *sql.DB) *PostgresBackfillRepository {
+	return &PostgresBackfillRepository{db: db}
+}
+
+func (r *PostgresBackfillRepository) SaveBackfill(backfill criptomonedas.Backfill) (int, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO backfills (cripto_id, api, fiat, resolucion, desde, hasta, cursor_fecha, estado, guardadas, omitidas, error, creado, actualizado)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id`,
+		backfill.CriptoMoneda_ID, backfill.Api, fiatOPorDefecto(backfill.Fiat), backfill.Resolucion, backfill.Desde, backfill.Hasta,
+		backfill.Cursor, backfill.Estado, backfill.Guardadas, backfill.Omitidas, backfill.Error, backfill.Creado, backfill.Actualizado,
+	).Scan(&id)
+	if err != nil {
+		log.Println("Error al guardar el backfill:", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateBackfill guarda el progreso del backfill: cursor, estado, contadores y error.
+func (r *PostgresBackfillRepository) UpdateBackfill(backfill criptomonedas.Backfill) error {
+	_, err := r.db.Exec(`
+		UPDATE backfills SET cursor_fecha = $1, estado = $2, guardadas = $3, omitidas = $4, error = $5, actualizado = $6
+		WHERE id = $7`,
+		backfill.Cursor, backfill.Estado, backfill.Guardadas, backfill.Omitidas, backfill.Error, backfill.Actualizado, backfill.Id,
+	)
+	if err != nil {
+		log.Println("Error al actualizar el backfill:", err)
+		return err
+	}
+	return nil
+}
+
+// FindBackfillByID devuelve nil sin error si el backfill no existe.
+func (r *PostgresBackfillRepository) FindBackfillByID(id int) (*criptomonedas.Backfill, error) {
+	backfill, err := escanearBackfill(r.db.QueryRow(selectBackfills+" WHERE b.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+func (r *PostgresBackfillRepository) FindAllBackfills() ([]criptomonedas.Backfill, error) {
+	return r.buscar(selectBackfills + " ORDER BY b.id DESC")
+}
+
+func (r *PostgresBackfillRepository) FindBackfillsPendientes() ([]criptomonedas.Backfill, error) {
+	return r.buscar(selectBackfills+" WHERE b.estado IN ($1, $2) ORDER BY b.id", criptomonedas.BackfillPendiente, criptomonedas.BackfillCorriendo)
+}
+
+func (r *PostgresBackfillRepository) buscar(query string, args ...interface{}) ([]criptomonedas.Backfill, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener los backfills:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var backfills []criptomonedas.Backfill
+	for rows.Next() {
+		backfill, err := escanearBackfill(rows)
+		if err != nil {
+			return nil, err
+		}
+		backfills = append(backfills, backfill)
+	}
+	return backfills, rows.Err()
+}
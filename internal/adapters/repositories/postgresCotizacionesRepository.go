@@ -0,0 +1,409 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+const insertCotizacionPostgres = "INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+
+func argsCotizacionPostgres(cotizacion criptomonedas.Cotizacion) []interface{} {
+	return []interface{}{cotizacion.CriptoMoneda_ID, cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha, cotizacion.Fuente,
+		cotizacion.Exchange, cotizacion.FechaProveedor, cotizacion.Volumen, cotizacion.TotalAsk, cotizacion.TotalBid}
+}
+
+func (r *PostgresCryptoRepository) SaveCotizacion(cripto criptomonedas.Cotizacion) error {
+	_, err := r.db.Exec(insertCotizacionPostgres, argsCotizacionPostgres(cripto)...)
+	if err != nil {
+		log.Println("Error al guardar cotizacion:", err)
+		return err
+	}
+	return nil
+}
+
+// SaveCotizaciones guarda todas las cotizaciones en una transacción: se guardan todas o ninguna.
+func (r *PostgresCryptoRepository) SaveCotizaciones(cotizaciones []criptomonedas.Cotizacion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, cotizacion := range cotizaciones {
+		if _, err := tx.Exec(insertCotizacionPostgres, argsCotizacionPostgres(cotizacion)...); err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizaciones:", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveCotizacionesHistoricas guarda en una transacción las cotizaciones que todavía no existen para la misma
+// moneda, fiat, fuente y fecha, y devuelve cuántas guardó. Así volver a cargar un rango no duplica cotizaciones.
+func (r *PostgresCryptoRepository) SaveCotizacionesHistoricas(cotizaciones []criptomonedas.Cotizacion) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	guardadas := 0
+	for _, cotizacion := range cotizaciones {
+		// en un INSERT ... SELECT Postgres no deduce los tipos de los marcadores de las columnas, así que van casteados
+		result, err := tx.Exec(`
+			INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid)
+			SELECT $1::INTEGER, $2::NUMERIC, $3::VARCHAR, $4::TIMESTAMPTZ, $5::VARCHAR, $6::VARCHAR, $7::TIMESTAMPTZ, $8::NUMERIC, $9::NUMERIC, $10::NUMERIC
+			WHERE NOT EXISTS (
+				SELECT 1 FROM cotizaciones WHERE cripto_id = $1 AND fiat = $3 AND fuente = $5 AND fecha = $4
+			)`, argsCotizacionPostgres(cotizacion)...)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizacion histórica:", err)
+			return 0, err
+		}
+		filas, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		guardadas += int(filas)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return guardadas, nil
+}
+
+// FindByCotizacionID devuelve sql.ErrNoRows si la cotización no existe.
+func (r *PostgresCryptoRepository) FindByCotizacionID(id int) (*criptomonedas.Cotizacion, error) {
+	var manual bool
+	var usuarioId *int
+	cotizacion, err := escanearCotizacion(r.db.QueryRow("SELECT "+columnasCotizacion+", c.manual, c.usuario_id FROM cotizaciones c WHERE c.id = $1", id), &manual, &usuarioId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no se encontro moneda con id %d", id)
+		}
+		return nil, err
+	}
+	cotizacion.Manual = manual
+	cotizacion.UsuarioId = usuarioId
+	return &cotizacion, nil
+}
+
+func (r *PostgresCryptoRepository) FindAllCotizaciones() ([]*criptomonedas.Cotizacion, error) {
+	cotizaciones, err := r.buscarCotizaciones("SELECT " + columnasCotizacion + " FROM cotizaciones c ORDER BY c.id")
+	if err != nil {
+		return nil, err
+	}
+	punteros := make([]*criptomonedas.Cotizacion, len(cotizaciones))
+	for i := range cotizaciones {
+		punteros[i] = &cotizaciones[i]
+	}
+	return punteros, nil
+}
+
+func (r *PostgresCryptoRepository) buscarCotizaciones(query string, args ...interface{}) ([]criptomonedas.Cotizacion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener las cotizaciones:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.Cotizacion
+	for rows.Next() {
+		cotizacion, err := escanearCotizacion(rows)
+		if err != nil {
+			return nil, err
+		}
+		cotizaciones = append(cotizaciones, cotizacion)
+	}
+	return cotizaciones, rows.Err()
+}
+
+func (r *PostgresCryptoRepository) UpdateCotizacion(id int, cotizacion criptomonedas.Cotizacion) error {
+	_, err := r.db.Exec("UPDATE cotizaciones SET cotizacion = $1, fiat = $2, fecha = $3 WHERE id = $4",
+		cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha, id)
+	if err != nil {
+		log.Println("Error al actualizar la moneda:", err)
+		return err
+	}
+	return nil
+}
+
+// condicionesFiltroPostgres arma las condiciones del filtro sobre cotizaciones c y monedas cm, con los
+// argumentos a continuación de los que ya tiene args. El nombre se busca con ILIKE para que, como en MySQL, no
+// importen las mayúsculas.
+func condicionesFiltroPostgres(filter criptomonedas.CriptoMonedaFilter, args *argsPostgres) string {
+	condiciones := ""
+	if filter.Nombre != nil {
+		condiciones += " AND cm.nombre ILIKE " + args.agregar("%"+*filter.Nombre+"%")
+	}
+	if filter.MinCotizacion != nil {
+		condiciones += " AND c.cotizacion >= " + args.agregar(*filter.MinCotizacion)
+	}
+	if filter.MaxCotizacion != nil {
+		condiciones += " AND c.cotizacion <= " + args.agregar(*filter.MaxCotizacion)
+	}
+	if filter.Fuente != nil {
+		condiciones += " AND c.fuente = " + args.agregar(*filter.Fuente)
+	}
+	if filter.Exchange != nil {
+		condiciones += " AND c.exchange = " + args.agregar(*filter.Exchange)
+	}
+	if filter.StartDate != nil {
+		condiciones += " AND c.fecha >= " + args.agregar(*filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		condiciones += " AND c.fecha <= " + args.agregar(*filter.EndDate)
+	}
+	return condiciones
+}
+
+func (r *PostgresCryptoRepository) FindAllByFilter(filter criptomonedas.CriptoMonedaFilter) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error) {
+	var args argsPostgres
+	condiciones := condicionesFiltroPostgres(filter, &args)
+	if filter.Fiat != nil {
+		condiciones += " AND c.fiat = " + args.agregar(*filter.Fiat)
+	}
+	// el resumen usa las mismas condiciones sin la paginación
+	argsResumen := append([]interface{}{}, args...)
+
+	query := "SELECT " + columnasCotizacion + " FROM cotizaciones c JOIN monedas cm ON c.cripto_id = cm.id WHERE 1=1" + condiciones +
+		" LIMIT " + args.agregar(filter.PageSize) + " OFFSET " + args.agregar(filter.PageSize*(filter.PageNumber-1))
+	cotizaciones, err := r.buscarCotizaciones(query, args...)
+	if err != nil {
+		return nil, criptomonedas.Summary{}, err
+	}
+
+	summary := criptomonedas.Summary{
+		TotalResults: len(cotizaciones),
+		PageNumber:   filter.PageNumber,
+		PageSize:     filter.PageSize,
+	}
+	if filter.AgruparPorFuente {
+		summary.PorFuente, err = r.resumirPorFuente(condiciones, argsResumen)
+		if err != nil {
+			return nil, criptomonedas.Summary{}, err
+		}
+	}
+	return cotizaciones, summary, nil
+}
+
+// resumirPorFuente agrupa por fuente todas las cotizaciones que cumplen las condiciones, sin paginar.
+func (r *PostgresCryptoRepository) resumirPorFuente(condiciones string, args []interface{}) ([]criptomonedas.ResumenFuente, error) {
+	rows, err := r.db.Query(`
+		SELECT c.fuente, COUNT(*), MIN(c.cotizacion), MAX(c.cotizacion), AVG(c.cotizacion), MAX(c.fecha)
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		WHERE 1=1`+condiciones+`
+		GROUP BY c.fuente
+		ORDER BY c.fuente`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resumenes []criptomonedas.ResumenFuente
+	for rows.Next() {
+		var resumen criptomonedas.ResumenFuente
+		if err := rows.Scan(&resumen.Fuente, &resumen.Cantidad, &resumen.Minima, &resumen.Maxima, &resumen.Promedio, &resumen.Ultima); err != nil {
+			return nil, err
+		}
+		resumenes = append(resumenes, resumen)
+	}
+	return resumenes, rows.Err()
+}
+
+// FindUltimaCotizacion devuelve la última cotización de la moneda en la fiat, o en cualquier fiat si viene vacía.
+// Devuelve nil sin error si no hay ninguna.
+func (r *PostgresCryptoRepository) FindUltimaCotizacion(nombre, fiat string) (*criptomonedas.Cotizacion, error) {
+	cotizacion, err := escanearCotizacion(r.db.QueryRow(`
+		SELECT `+columnasCotizacion+`
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		WHERE cm.nombre = $1 AND ($2::VARCHAR = '' OR c.fiat = $2)
+		ORDER BY c.fecha DESC
+		LIMIT 1`, nombre, fiat))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Println("Error al obtener la última cotización:", err)
+		return nil, err
+	}
+	return &cotizacion, nil
+}
+
+// FindFechasCotizaciones devuelve en orden las fechas de las cotizaciones de la moneda en la fiat entre desde y
+// hasta inclusive, de cualquier fuente.
+func (r *PostgresCryptoRepository) FindFechasCotizaciones(criptoId int, fiat string, desde, hasta time.Time) ([]time.Time, error) {
+	rows, err := r.db.Query(`
+		SELECT fecha FROM cotizaciones
+		WHERE cripto_id = $1 AND fiat = $2 AND fecha BETWEEN $3 AND $4
+		ORDER BY fecha`, criptoId, fiatOPorDefecto(fiat), desde, hasta)
+	if err != nil {
+		log.Println("Error al obtener las fechas de las cotizaciones:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fechas []time.Time
+	for rows.Next() {
+		var fecha time.Time
+		if err := rows.Scan(&fecha); err != nil {
+			return nil, err
+		}
+		fechas = append(fechas, fecha)
+	}
+	return fechas, rows.Err()
+}
+
+// FindCotizacionesRecientes devuelve las últimas cotizaciones de la moneda en la fiat, de la más nueva a la
+// más vieja, sin contar las manuales.
+func (r *PostgresCryptoRepository) FindCotizacionesRecientes(criptoId int, fiat string, limite int) ([]criptomonedas.Cotizacion, error) {
+	return r.buscarCotizaciones(`
+		SELECT `+columnasCotizacion+`
+		FROM cotizaciones c
+		WHERE c.cripto_id = $1 AND c.fiat = $2 AND c.manual = FALSE
+		ORDER BY c.fecha DESC, c.id DESC
+		LIMIT $3`, criptoId, fiatOPorDefecto(fiat), limite)
+}
+
+// FindUltimasCotizacionesPorFuente devuelve la última cotización de la moneda de cada fuente, ordenadas por fuente.
+// Con fiat vacía se toma la última en cualquier fiat.
+func (r *PostgresCryptoRepository) FindUltimasCotizacionesPorFuente(nombre, fiat string) ([]criptomonedas.Cotizacion, error) {
+	return r.buscarCotizaciones(`
+	SELECT DISTINCT ON (c.fuente) `+columnasCotizacion+`
+	FROM cotizaciones c
+	JOIN monedas cm ON c.cripto_id = cm.id
+	WHERE cm.nombre = $1 AND ($2::VARCHAR = '' OR c.fiat = $2)
+	ORDER BY c.fuente, c.fecha DESC, c.id DESC`, nombre, fiat)
+}
+
+// FindAllByFilterForUser devuelve las cotizaciones de las monedas que sigue el usuario, en la fiat del filtro o
+// en su fiat preferida. El resumen lleva los valores, fechas y monedas de la primera cotización.
+func (r *PostgresCryptoRepository) FindAllByFilterForUser(filter criptomonedas.CriptoMonedaFilter, usuarioId int) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error) {
+	args := argsPostgres{usuarioId}
+	query := `
+		SELECT ` + columnasCotizacion + `,
+			json_agg(c.cotizacion), json_agg(c.fecha), json_agg(cm.nombre)
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		JOIN usuario_moneda um ON um.moneda_id = cm.id
+		JOIN usuarios u ON u.id = um.usuario_id
+		WHERE um.usuario_id = $1`
+
+	// sin fiat en el filtro se muestran las cotizaciones en la fiat preferida del usuario
+	if filter.Fiat != nil {
+		query += " AND c.fiat = " + args.agregar(*filter.Fiat)
+	} else {
+		query += " AND c.fiat = u.fiat_preferida"
+	}
+	query += condicionesFiltroPostgres(filter, &args)
+	// agrupar por la clave de cotizaciones deja seleccionar el resto de sus columnas
+	query += " GROUP BY c.id LIMIT " + args.agregar(filter.PageSize) + " OFFSET " + args.agregar(filter.PageSize*(filter.PageNumber-1))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, criptomonedas.Summary{}, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.Cotizacion
+	var summary criptomonedas.Summary
+	for rows.Next() {
+		var valoresJSON, fechasJSON, nombresJSON string
+		cotizacion, err := escanearCotizacion(rows, &valoresJSON, &fechasJSON, &nombresJSON)
+		if err != nil {
+			return nil, criptomonedas.Summary{}, err
+		}
+		cotizaciones = append(cotizaciones, cotizacion)
+
+		if len(cotizaciones) == 1 {
+			if err := json.Unmarshal([]byte(valoresJSON), &summary.CotizacionesValores); err != nil {
+				return nil, criptomonedas.Summary{}, fmt.Errorf("error al parsear cotizaciones valores JSON: %w", err)
+			}
+			if err := json.Unmarshal([]byte(fechasJSON), &summary.CotizacionesFechas); err != nil {
+				return nil, criptomonedas.Summary{}, fmt.Errorf("error al parsear cotizaciones fechas JSON: %w", err)
+			}
+			if err := json.Unmarshal([]byte(nombresJSON), &summary.CriptoNombres); err != nil {
+				return nil, criptomonedas.Summary{}, fmt.Errorf("error al parsear cripto nombres JSON: %w", err)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, criptomonedas.Summary{}, err
+	}
+
+	summary.PageNumber = filter.PageNumber
+	summary.PageSize = filter.PageSize
+	summary.TotalResults = len(cotizaciones)
+	return cotizaciones, summary, nil
+}
+
+func (r *PostgresCryptoRepository) BorrarCotizacionById(cotizacionId int) error {
+	result, err := r.db.Exec("DELETE FROM cotizaciones WHERE id = $1", cotizacionId)
+	if err != nil {
+		return fmt.Errorf("error al ejecutar la consulta DELETE: %w", err)
+	}
+	filas, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("no se borró ninguna cotización con id %v", cotizacionId)
+	}
+	return nil
+}
+
+// GuardarCotizacionManual devuelve la cotización con el id que le asignó la base, que Postgres devuelve con
+// RETURNING porque el driver no implementa LastInsertId.
+func (r *PostgresCryptoRepository) GuardarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error) {
+	cotizacionCompleta := criptomonedas.Cotizacion{
+		Cotizacion:      cotizacion.Cotizacion,
+		Fiat:            fiatOPorDefecto(cotizacion.Fiat),
+		Fecha:           cotizacion.Fecha,
+		CriptoMoneda_ID: cotizacion.CriptoMoneda_ID,
+		Manual:          true,
+		UsuarioId:       &usuarioId,
+		Fuente:          criptomonedas.FuenteManual,
+	}
+	err := r.db.QueryRow("INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, manual, usuario_id, fuente) VALUES ($1, $2, $3, $4, TRUE, $5, $6) RETURNING id",
+		cotizacionCompleta.CriptoMoneda_ID, cotizacionCompleta.Cotizacion, cotizacionCompleta.Fiat, cotizacionCompleta.Fecha, usuarioId, cotizacionCompleta.Fuente,
+	).Scan(&cotizacionCompleta.Id)
+	if err != nil {
+		log.Println("Error al guardar cripto:", err)
+		return criptomonedas.Cotizacion{}, err
+	}
+	return cotizacionCompleta, nil
+}
+
+func (r *PostgresCryptoRepository) ActualizarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error) {
+	cotizacion.Fiat = fiatOPorDefecto(cotizacion.Fiat)
+	cotizacion.Fuente = criptomonedas.FuenteManual
+	cotizacion.Exchange = ""
+	cotizacion.FechaProveedor = nil
+	_, err := r.db.Exec(`
+		UPDATE cotizaciones SET cripto_id = $1, cotizacion = $2, fiat = $3, fecha = $4, manual = TRUE, usuario_id = $5, fuente = $6,
+			exchange = '', fecha_proveedor = NULL, volumen = 0, total_ask = 0, total_bid = 0
+		WHERE id = $7`,
+		cotizacion.CriptoMoneda_ID, cotizacion.Cotizacion, cotizacion.Fiat, cotizacion.Fecha, usuarioId, cotizacion.Fuente, cotizacion.Id)
+	if err != nil {
+		log.Println("Error al actualizar cotización:", err)
+		return criptomonedas.Cotizacion{}, err
+	}
+	return cotizacion, nil
+}
+
+func (r *PostgresCryptoRepository) BorrarCotizacionManual(cotizacion criptomonedas.Cotizacion) error {
+	_, err := r.db.Exec("DELETE FROM cotizaciones WHERE id = $1", cotizacion.Id)
+	if err != nil {
+		log.Println("Error al borrar la cotización:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+// PostgresCryptoRepository implementa CryptoRepository sobre PostgreSQL. Las fechas son TIMESTAMPTZ, así que se
+// comparan como instantes sin importar la zona con la que lleguen.
+type PostgresCryptoRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresCryptoRepository(db *sql.DB) *PostgresCryptoRepository {
+	return &PostgresCryptoRepository{db: db}
+}
+
+// SaveMoneda guarda la moneda con el id indicado, o con uno nuevo si viene en cero. Con un id explícito también
+// adelanta la secuencia de monedas, para que los ids que genere después no choquen con el guardado.
+func (r *PostgresCryptoRepository) SaveMoneda(cripto criptomonedas.CriptoMoneda) error {
+	if cripto.Id == 0 {
+		_, err := r.db.Exec("INSERT INTO monedas (nombre, codigo) VALUES ($1, $2)", cripto.Nombre, cripto.Codigo)
+		if err != nil {
+			log.Println("Error al guardar cripto:", err)
+			return err
+		}
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO monedas (id, nombre, codigo) VALUES ($1, $2, $3)", cripto.Id, cripto.Nombre, cripto.Codigo); err != nil {
+		tx.Rollback()
+		log.Println("Error al guardar cripto:", err)
+		return err
+	}
+	if _, err := tx.Exec("SELECT setval(pg_get_serial_sequence('monedas', 'id'), (SELECT MAX(id) FROM monedas))"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindByMonedaID devuelve sql.ErrNoRows si la moneda no existe.
+func (r *PostgresCryptoRepository) FindByMonedaID(id int) (*criptomonedas.CriptoMoneda, error) {
+	var moneda criptomonedas.CriptoMoneda
+	err := r.db.QueryRow("SELECT id, nombre, codigo FROM monedas WHERE id = $1", id).Scan(&moneda.Id, &moneda.Nombre, &moneda.Codigo)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no se encontro moneda con id %d", id)
+		}
+		return nil, err
+	}
+	return &moneda, nil
+}
+
+func (r *PostgresCryptoRepository) FindAllMonedas() ([]*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMonedas("SELECT id, nombre, codigo FROM monedas ORDER BY id")
+}
+
+// FindMonedasSeguidas devuelve las monedas que sigue al menos un usuario.
+func (r *PostgresCryptoRepository) FindMonedasSeguidas() ([]*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMonedas(`
+	SELECT DISTINCT m.id, m.nombre, m.codigo
+	FROM monedas m
+	JOIN usuario_moneda um ON um.moneda_id = m.id
+	ORDER BY m.id`)
+}
+
+func (r *PostgresCryptoRepository) buscarMonedas(query string, args ...interface{}) ([]*criptomonedas.CriptoMoneda, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener las monedas:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var monedas []*criptomonedas.CriptoMoneda
+	for rows.Next() {
+		moneda := &criptomonedas.CriptoMoneda{}
+		if err := rows.Scan(&moneda.Id, &moneda.Nombre, &moneda.Codigo); err != nil {
+			return nil, err
+		}
+		monedas = append(monedas, moneda)
+	}
+	return monedas, rows.Err()
+}
+
+func (r *PostgresCryptoRepository) UpdateMoneda(id int, moneda criptomonedas.CriptoMoneda) error {
+	_, err := r.db.Exec("UPDATE monedas SET nombre = $1 WHERE id = $2", moneda.Nombre, id)
+	if err != nil {
+		log.Println("Error al actualizar la moneda:", err)
+		return err
+	}
+	return nil
+}
+
+// FindCryptoByName devuelve nil sin error si la moneda no existe.
+func (r *PostgresCryptoRepository) FindCryptoByName(name string) (*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMoneda("SELECT id, nombre, codigo FROM monedas WHERE nombre = $1", name)
+}
+
+// FindCryptoByCode devuelve nil sin error si la moneda no existe.
+func (r *PostgresCryptoRepository) FindCryptoByCode(codigo string) (*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMoneda("SELECT id, nombre, codigo FROM monedas WHERE codigo = $1", codigo)
+}
+
+func (r *PostgresCryptoRepository) buscarMoneda(query string, args ...interface{}) (*criptomonedas.CriptoMoneda, error) {
+	var cripto criptomonedas.CriptoMoneda
+	err := r.db.QueryRow(query, args...).Scan(&cripto.Id, &cripto.Nombre, &cripto.Codigo)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cripto, nil
+}
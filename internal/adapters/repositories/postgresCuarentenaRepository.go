@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+// PostgresCuarentenaRepository implementa CuarentenaRepository sobre PostgreSQL.
+type PostgresCuarentenaRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresCuarentenaRepository(db *sql.DB) *PostgresCuarentenaRepository {
+	return &PostgresCuarentenaRepository{db: db}
+}
+
+func (r *PostgresCuarentenaRepository) SaveCuarentena(cuarentena criptomonedas.CotizacionCuarentena) (int, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO cotizaciones_cuarentena (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid,
+			referencia, salto, z_score, motivo, estado)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`,
+		cuarentena.CriptoMoneda_ID, cuarentena.Cotizacion, fiatOPorDefecto(cuarentena.Fiat), cuarentena.Fecha, cuarentena.Fuente,
+		cuarentena.Exchange, cuarentena.FechaProveedor, cuarentena.Volumen, cuarentena.TotalAsk, cuarentena.TotalBid, cuarentena.Referencia,
+		cuarentena.Salto, cuarentena.ZScore, cuarentena.Motivo, cuarentena.Estado,
+	).Scan(&id)
+	if err != nil {
+		log.Println("Error al guardar la cotización en cuarentena:", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// FindCuarentenaByID devuelve nil sin error si la cotización no existe.
+func (r *PostgresCuarentenaRepository) FindCuarentenaByID(id int) (*criptomonedas.CotizacionCuarentena, error) {
+	cuarentena, err := escanearCuarentena(r.db.QueryRow(selectCuarentena+" WHERE q.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cuarentena, nil
+}
+
+func (r *PostgresCuarentenaRepository) FindAllCuarentena(estado string) ([]criptomonedas.CotizacionCuarentena, error) {
+	rows, err := r.db.Query(selectCuarentena+" WHERE ($1::VARCHAR = '' OR q.estado = $1) ORDER BY q.id DESC", estado)
+	if err != nil {
+		log.Println("Error al obtener las cotizaciones en cuarentena:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cuarentenas []criptomonedas.CotizacionCuarentena
+	for rows.Next() {
+		cuarentena, err := escanearCuarentena(rows)
+		if err != nil {
+			return nil, err
+		}
+		cuarentenas = append(cuarentenas, cuarentena)
+	}
+	return cuarentenas, rows.Err()
+}
+
+func (r *PostgresCuarentenaRepository) ResolverCuarentena(id int, estado string, revisada time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec("UPDATE cotizaciones_cuarentena SET estado = $1, revisada = $2 WHERE id = $3 AND estado = $4",
+		estado, revisada, id, criptomonedas.CuarentenaPendiente)
+	if err != nil {
+		tx.Rollback()
+		log.Println("Error al resolver la cotización en cuarentena:", err)
+		return false, err
+	}
+	filas, err := result.RowsAffected()
+	if err != nil || filas == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	if estado == criptomonedas.CuarentenaAprobada {
+		_, err = tx.Exec(`
+			INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid)
+			SELECT cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid
+			FROM cotizaciones_cuarentena WHERE id = $1`, id)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar la cotización aprobada:", err)
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+// PostgresFiatRepository implementa FiatRepository sobre PostgreSQL. FindAllFiats no tiene argumentos, así que
+// es la consulta de MySQL.
+type PostgresFiatRepository struct {
+	*MySQLFiatRepository
+}
+
+func NewPostgresFiatRepository(db *sql.DB) *PostgresFiatRepository {
+	return &PostgresFiatRepository{MySQLFiatRepository: NewMySQLFiatRepository(db)}
+}
+
+// FindFiatByCodigo devuelve nil sin error si la moneda no está cargada.
+func (r *PostgresFiatRepository) FindFiatByCodigo(codigo string) (*criptomonedas.MonedaFiat, error) {
+	var fiat criptomonedas.MonedaFiat
+	err := r.db.QueryRow("SELECT codigo, nombre FROM monedas_fiat WHERE codigo = $1", codigo).Scan(&fiat.Codigo, &fiat.Nombre)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Println("Error al buscar la moneda fiat:", err)
+		return nil, err
+	}
+	return &fiat, nil
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+// PostgresLiderazgoRepository implementa LiderazgoRepository sobre PostgreSQL.
+type PostgresLiderazgoRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLiderazgoRepository(db *sql.DB) *PostgresLiderazgoRepository {
+	return &PostgresLiderazgoRepository{db: db}
+}
+
+// AdquirirLiderazgo toma el lease si está libre o vencido, o lo renueva si ya era de la instancia, y devuelve
+// el lease como quedó. La instancia es líder si el lease devuelto es suyo. Los vencimientos se calculan con el
+// reloj de la base para que no dependan del reloj de cada instancia.
+func (r *PostgresLiderazgoRepository) AdquirirLiderazgo(ctx context.Context, nombre, instancia string, duracion time.Duration) (criptomonedas.Liderazgo, error) {
+	// el WHERE del DO UPDATE ve la fila anterior, así que un lease vigente de otra instancia no se toca
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO liderazgos (nombre, instancia, vence)
+		VALUES ($1, $2, NOW() + $3::BIGINT * INTERVAL '1 microsecond')
+		ON CONFLICT (nombre) DO UPDATE SET instancia = EXCLUDED.instancia, vence = EXCLUDED.vence
+		WHERE liderazgos.vence < NOW() OR liderazgos.instancia = EXCLUDED.instancia`,
+		nombre, instancia, duracion.Microseconds(),
+	)
+	if err != nil {
+		log.Println("Error al adquirir el liderazgo:", err)
+		return criptomonedas.Liderazgo{}, err
+	}
+	liderazgo, err := r.buscarLiderazgo(ctx, nombre)
+	if err != nil || liderazgo == nil {
+		return criptomonedas.Liderazgo{}, err
+	}
+	return *liderazgo, nil
+}
+
+// FindLiderazgo devuelve nil sin error si nadie tomó el lease todavía.
+func (r *PostgresLiderazgoRepository) FindLiderazgo(nombre string) (*criptomonedas.Liderazgo, error) {
+	return r.buscarLiderazgo(context.Background(), nombre)
+}
+
+func (r *PostgresLiderazgoRepository) buscarLiderazgo(ctx context.Context, nombre string) (*criptomonedas.Liderazgo, error) {
+	var liderazgo criptomonedas.Liderazgo
+	err := r.db.QueryRowContext(ctx, "SELECT nombre, instancia, vence FROM liderazgos WHERE nombre = $1", nombre).Scan(
+		&liderazgo.Nombre, &liderazgo.Instancia, &liderazgo.Vence,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &liderazgo, nil
+}
+
+// LiberarLiderazgo suelta el lease si es de la instancia, para que otra lo tome sin esperar a que venza.
+func (r *PostgresLiderazgoRepository) LiberarLiderazgo(nombre, instancia string) error {
+	_, err := r.db.Exec("DELETE FROM liderazgos WHERE nombre = $1 AND instancia = $2", nombre, instancia)
+	if err != nil {
+		log.Println("Error al liberar el liderazgo:", err)
+		return err
+	}
+	return nil
+}
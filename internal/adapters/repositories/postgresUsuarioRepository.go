@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"strings"
+)
+
+// PostgresUsuarioRepository implementa UsuarioRepository sobre PostgreSQL.
+type PostgresUsuarioRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUsuarioRepository(db *sql.DB) *PostgresUsuarioRepository {
+	return &PostgresUsuarioRepository{db: db}
+}
+
+// SaveUsuario devuelve el id del usuario nuevo, que Postgres devuelve con RETURNING.
+func (r *PostgresUsuarioRepository) SaveUsuario(usuario criptomonedas.Usuario) (int, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO usuarios (nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo, fiat_preferida)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento, usuario.CodigoUsuario, usuario.Email, usuario.TipoDocumento,
+		usuario.Fecha_registro, usuario.Esta_activo, fiatOPorDefecto(usuario.FiatPreferida),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *PostgresUsuarioRepository) UpdateUsuarioById(id int, usuario criptomonedas.Usuario) error {
+	_, err := r.db.Exec(`
+		UPDATE usuarios
+		SET nombre = $1, apellidos = $2, fecha_nacimiento = $3, codigo_usuario = $4, email = $5, tipo_documento = $6, fecha_registro = $7, esta_activo = $8, fiat_preferida = $9
+		WHERE id = $10`,
+		usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento, usuario.CodigoUsuario, usuario.Email, usuario.TipoDocumento,
+		usuario.Fecha_registro, usuario.Esta_activo, fiatOPorDefecto(usuario.FiatPreferida), id,
+	)
+	if err != nil {
+		log.Println("Error al actualizar el Usuario:", err)
+		return err
+	}
+	return nil
+}
+
+// FindUsuarioById devuelve nil sin error si el usuario no existe.
+func (r *PostgresUsuarioRepository) FindUsuarioById(id int) (*criptomonedas.Usuario, error) {
+	var usuario criptomonedas.Usuario
+	err := r.db.QueryRow(`
+		SELECT id, nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo, fiat_preferida
+		FROM usuarios
+		WHERE id = $1`, id).Scan(
+		&usuario.Id, &usuario.Nombre, &usuario.Apellidos, &usuario.Fecha_Nacimiento,
+		&usuario.CodigoUsuario, &usuario.Email, &usuario.TipoDocumento,
+		&usuario.Fecha_registro, &usuario.Esta_activo, &usuario.FiatPreferida,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+func (r *PostgresUsuarioRepository) FindMonedasByUsuarioID(id int) ([]int, error) {
+	return r.buscarIds("SELECT moneda_id FROM usuario_moneda WHERE usuario_id = $1 ORDER BY moneda_id", id)
+}
+
+func (r *PostgresUsuarioRepository) FindUsuariosByMonedaID(id int) ([]int, error) {
+	return r.buscarIds("SELECT usuario_id FROM usuario_moneda WHERE moneda_id = $1 ORDER BY usuario_id", id)
+}
+
+func (r *PostgresUsuarioRepository) buscarIds(query string, id int) ([]int, error) {
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *PostgresUsuarioRepository) PatchUsuarioByID(id int, updates map[string]interface{}) error {
+	setParts := []string{}
+	var args argsPostgres
+	for key, value := range updates {
+		setParts = append(setParts, key+" = "+args.agregar(value))
+	}
+	query := "UPDATE usuarios SET " + strings.Join(setParts, ", ") + " WHERE id = " + args.agregar(id)
+	_, err := r.db.Exec(query, args...)
+	return err
+}
+
+func (r *PostgresUsuarioRepository) AgregarMonedaFavorita(idUsuario, idMoneda int) ([]int, error) {
+	_, err := r.db.Exec("INSERT INTO usuario_moneda (usuario_id, moneda_id) VALUES ($1, $2)", idUsuario, idMoneda)
+	if err != nil {
+		log.Printf("Error al asociar usuario con moneda: %s", err)
+		return []int{}, err
+	}
+	return []int{idUsuario, idMoneda}, nil
+}
+
+func (r *PostgresUsuarioRepository) UpdateMonedasDeInteres(usuarioId int, monedas []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM usuario_moneda WHERE usuario_id = $1", usuarioId); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, monedaId := range monedas {
+		if _, err := tx.Exec("INSERT INTO usuario_moneda (usuario_id, moneda_id) VALUES ($1, $2)", usuarioId, monedaId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresUsuarioRepository) DeleteMonedasDeInteres(usuarioId int) error {
+	_, err := r.db.Exec("DELETE FROM usuario_moneda WHERE usuario_id = $1", usuarioId)
+	return err
+}
+
+func (r *PostgresUsuarioRepository) RegistrarAuditoria(usuarioId, cotizacionID int, logOperacion string) error {
+	_, err := r.db.Exec("INSERT INTO auditoria_cotizacion (usuario_id, cotizacion_id, log) VALUES ($1, $2, $3)", usuarioId, cotizacionID, logOperacion)
+	if err != nil {
+		log.Println("Error al registrar auditoría:", err)
+		return err
+	}
+	return nil
+}
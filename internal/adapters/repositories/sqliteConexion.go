@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// AbrirSQLite abre la base SQLite del archivo dsn, o una base en memoria con :memory:, con las claves foráneas
+// activadas. Usa una sola conexión porque SQLite no admite escrituras concurrentes y cada conexión a :memory:
+// sería una base distinta.
+func AbrirSQLite(dsn string) (*sql.DB, error) {
+	if !strings.Contains(dsn, "_foreign_keys") {
+		separador := "?"
+		if strings.Contains(dsn, "?") {
+			separador = "&"
+		}
+		dsn += separador + "_foreign_keys=on"
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
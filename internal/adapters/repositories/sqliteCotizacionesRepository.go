@@ -0,0 +1,469 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+// columnasCotizacion son las columnas que lee escanearCotizacion, de la tabla cotizaciones con alias c.
+const columnasCotizacion = "c.id, c.cotizacion, c.fiat, c.fecha, c.cripto_id, c.fuente, c.exchange, c.fecha_proveedor, c.volumen, c.total_ask, c.total_bid"
+
+func escanearCotizacion(scanner interface{ Scan(dest ...any) error }, extra ...any) (criptomonedas.Cotizacion, error) {
+	var cotizacion criptomonedas.Cotizacion
+	var fechaProveedor sql.NullTime
+	dest := []any{&cotizacion.Id, &cotizacion.Cotizacion, &cotizacion.Fiat, &cotizacion.Fecha, &cotizacion.CriptoMoneda_ID, &cotizacion.Fuente,
+		&cotizacion.Exchange, &fechaProveedor, &cotizacion.Volumen, &cotizacion.TotalAsk, &cotizacion.TotalBid}
+	err := scanner.Scan(append(dest, extra...)...)
+	cotizacion.FechaProveedor = fechaOpcional(fechaProveedor)
+	return cotizacion, err
+}
+
+const insertCotizacionSQLite = "INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func argsCotizacionSQLite(cotizacion criptomonedas.Cotizacion) []interface{} {
+	return []interface{}{cotizacion.CriptoMoneda_ID, cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha.UTC(), cotizacion.Fuente,
+		cotizacion.Exchange, fechaUTC(cotizacion.FechaProveedor), cotizacion.Volumen, cotizacion.TotalAsk, cotizacion.TotalBid}
+}
+
+func (r *SQLiteCryptoRepository) SaveCotizacion(cripto criptomonedas.Cotizacion) error {
+	_, err := r.db.Exec(insertCotizacionSQLite, argsCotizacionSQLite(cripto)...)
+	if err != nil {
+		log.Println("Error al guardar cotizacion:", err)
+		return err
+	}
+	return nil
+}
+
+// SaveCotizaciones guarda todas las cotizaciones en una transacción: se guardan todas o ninguna.
+func (r *SQLiteCryptoRepository) SaveCotizaciones(cotizaciones []criptomonedas.Cotizacion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, cotizacion := range cotizaciones {
+		if _, err := tx.Exec(insertCotizacionSQLite, argsCotizacionSQLite(cotizacion)...); err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizaciones:", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveCotizacionesHistoricas guarda en una transacción las cotizaciones que todavía no existen para la misma
+// moneda, fiat, fuente y fecha, y devuelve cuántas guardó. Así volver a cargar un rango no duplica cotizaciones.
+func (r *SQLiteCryptoRepository) SaveCotizacionesHistoricas(cotizaciones []criptomonedas.Cotizacion) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	guardadas := 0
+	for _, cotizacion := range cotizaciones {
+		args := append(argsCotizacionSQLite(cotizacion), cotizacion.CriptoMoneda_ID, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fuente, cotizacion.Fecha.UTC())
+		result, err := tx.Exec(`
+			INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, fuente, exchange, fecha_proveedor, volumen, total_ask, total_bid)
+			SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM cotizaciones WHERE cripto_id = ? AND fiat = ? AND fuente = ? AND fecha = ?
+			)`, args...)
+		if err != nil {
+			tx.Rollback()
+			log.Println("Error al guardar cotizacion histórica:", err)
+			return 0, err
+		}
+		filas, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		guardadas += int(filas)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return guardadas, nil
+}
+
+// FindByCotizacionID devuelve sql.ErrNoRows si la cotización no existe.
+func (r *SQLiteCryptoRepository) FindByCotizacionID(id int) (*criptomonedas.Cotizacion, error) {
+	var manual bool
+	var usuarioId *int
+	cotizacion, err := escanearCotizacion(r.db.QueryRow("SELECT "+columnasCotizacion+", c.manual, c.usuario_id FROM cotizaciones c WHERE c.id = ?", id), &manual, &usuarioId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no se encontro moneda con id %d", id)
+		}
+		return nil, err
+	}
+	cotizacion.Manual = manual
+	cotizacion.UsuarioId = usuarioId
+	return &cotizacion, nil
+}
+
+func (r *SQLiteCryptoRepository) FindAllCotizaciones() ([]*criptomonedas.Cotizacion, error) {
+	cotizaciones, err := r.buscarCotizaciones("SELECT " + columnasCotizacion + " FROM cotizaciones c ORDER BY c.id")
+	if err != nil {
+		return nil, err
+	}
+	punteros := make([]*criptomonedas.Cotizacion, len(cotizaciones))
+	for i := range cotizaciones {
+		punteros[i] = &cotizaciones[i]
+	}
+	return punteros, nil
+}
+
+func (r *SQLiteCryptoRepository) buscarCotizaciones(query string, args ...interface{}) ([]criptomonedas.Cotizacion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener las cotizaciones:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.Cotizacion
+	for rows.Next() {
+		cotizacion, err := escanearCotizacion(rows)
+		if err != nil {
+			return nil, err
+		}
+		cotizaciones = append(cotizaciones, cotizacion)
+	}
+	return cotizaciones, rows.Err()
+}
+
+func (r *SQLiteCryptoRepository) UpdateCotizacion(id int, cotizacion criptomonedas.Cotizacion) error {
+	_, err := r.db.Exec("UPDATE cotizaciones SET cotizacion = ?, fiat = ?, fecha = ? WHERE id = ?",
+		cotizacion.Cotizacion, fiatOPorDefecto(cotizacion.Fiat), cotizacion.Fecha.UTC(), id)
+	if err != nil {
+		log.Println("Error al actualizar la moneda:", err)
+		return err
+	}
+	return nil
+}
+
+// condicionesFiltroSQLite arma las condiciones del filtro sobre cotizaciones c y monedas cm.
+func condicionesFiltroSQLite(filter criptomonedas.CriptoMonedaFilter) (string, []interface{}) {
+	condiciones := ""
+	args := []interface{}{}
+	if filter.Nombre != nil {
+		condiciones += " AND cm.nombre LIKE ?"
+		args = append(args, "%"+*filter.Nombre+"%")
+	}
+	if filter.MinCotizacion != nil {
+		condiciones += " AND c.cotizacion >= ?"
+		args = append(args, *filter.MinCotizacion)
+	}
+	if filter.MaxCotizacion != nil {
+		condiciones += " AND c.cotizacion <= ?"
+		args = append(args, *filter.MaxCotizacion)
+	}
+	if filter.Fuente != nil {
+		condiciones += " AND c.fuente = ?"
+		args = append(args, *filter.Fuente)
+	}
+	if filter.Exchange != nil {
+		condiciones += " AND c.exchange = ?"
+		args = append(args, *filter.Exchange)
+	}
+	if filter.StartDate != nil {
+		condiciones += " AND c.fecha >= ?"
+		args = append(args, filter.StartDate.UTC())
+	}
+	if filter.EndDate != nil {
+		condiciones += " AND c.fecha <= ?"
+		args = append(args, filter.EndDate.UTC())
+	}
+	return condiciones, args
+}
+
+func (r *SQLiteCryptoRepository) FindAllByFilter(filter criptomonedas.CriptoMonedaFilter) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error) {
+	condiciones, args := condicionesFiltroSQLite(filter)
+	if filter.Fiat != nil {
+		condiciones += " AND c.fiat = ?"
+		args = append(args, *filter.Fiat)
+	}
+
+	query := "SELECT " + columnasCotizacion + " FROM cotizaciones c JOIN monedas cm ON c.cripto_id = cm.id WHERE 1=1" + condiciones +
+		" LIMIT ? OFFSET ?"
+	cotizaciones, err := r.buscarCotizaciones(query, append(args, filter.PageSize, filter.PageSize*(filter.PageNumber-1))...)
+	if err != nil {
+		return nil, criptomonedas.Summary{}, err
+	}
+
+	summary := criptomonedas.Summary{
+		TotalResults: len(cotizaciones),
+		PageNumber:   filter.PageNumber,
+		PageSize:     filter.PageSize,
+	}
+	if filter.AgruparPorFuente {
+		summary.PorFuente, err = r.resumirPorFuente(condiciones, args)
+		if err != nil {
+			return nil, criptomonedas.Summary{}, err
+		}
+	}
+	return cotizaciones, summary, nil
+}
+
+// resumirPorFuente agrupa por fuente todas las cotizaciones que cumplen las condiciones, sin paginar.
+func (r *SQLiteCryptoRepository) resumirPorFuente(condiciones string, args []interface{}) ([]criptomonedas.ResumenFuente, error) {
+	rows, err := r.db.Query(`
+		SELECT c.fuente, COUNT(*), MIN(c.cotizacion), MAX(c.cotizacion), AVG(c.cotizacion), MAX(c.fecha)
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		WHERE 1=1`+condiciones+`
+		GROUP BY c.fuente
+		ORDER BY c.fuente`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resumenes []criptomonedas.ResumenFuente
+	for rows.Next() {
+		var resumen criptomonedas.ResumenFuente
+		// MAX no conserva el tipo de la columna, así que la fecha vuelve como texto
+		var ultima string
+		if err := rows.Scan(&resumen.Fuente, &resumen.Cantidad, &resumen.Minima, &resumen.Maxima, &resumen.Promedio, &ultima); err != nil {
+			return nil, err
+		}
+		if resumen.Ultima, err = parsearFechaSQLite(ultima); err != nil {
+			return nil, err
+		}
+		resumenes = append(resumenes, resumen)
+	}
+	return resumenes, rows.Err()
+}
+
+// FindUltimaCotizacion devuelve la última cotización de la moneda en la fiat, o en cualquier fiat si viene vacía.
+// Devuelve nil sin error si no hay ninguna.
+func (r *SQLiteCryptoRepository) FindUltimaCotizacion(nombre, fiat string) (*criptomonedas.Cotizacion, error) {
+	cotizacion, err := escanearCotizacion(r.db.QueryRow(`
+		SELECT `+columnasCotizacion+`
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		WHERE cm.nombre = ? AND (? = '' OR c.fiat = ?)
+		ORDER BY c.fecha DESC
+		LIMIT 1`, nombre, fiat, fiat))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Println("Error al obtener la última cotización:", err)
+		return nil, err
+	}
+	return &cotizacion, nil
+}
+
+// FindFechasCotizaciones devuelve en orden las fechas de las cotizaciones de la moneda en la fiat entre desde y
+// hasta inclusive, de cualquier fuente.
+func (r *SQLiteCryptoRepository) FindFechasCotizaciones(criptoId int, fiat string, desde, hasta time.Time) ([]time.Time, error) {
+	rows, err := r.db.Query(`
+		SELECT fecha FROM cotizaciones
+		WHERE cripto_id = ? AND fiat = ? AND fecha BETWEEN ? AND ?
+		ORDER BY fecha`, criptoId, fiatOPorDefecto(fiat), desde.UTC(), hasta.UTC())
+	if err != nil {
+		log.Println("Error al obtener las fechas de las cotizaciones:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fechas []time.Time
+	for rows.Next() {
+		var fecha time.Time
+		if err := rows.Scan(&fecha); err != nil {
+			return nil, err
+		}
+		fechas = append(fechas, fecha)
+	}
+	return fechas, rows.Err()
+}
+
+// FindCotizacionesRecientes devuelve las últimas cotizaciones de la moneda en la fiat, de la más nueva a la
+// más vieja, sin contar las manuales.
+func (r *SQLiteCryptoRepository) FindCotizacionesRecientes(criptoId int, fiat string, limite int) ([]criptomonedas.Cotizacion, error) {
+	return r.buscarCotizaciones(`
+		SELECT `+columnasCotizacion+`
+		FROM cotizaciones c
+		WHERE c.cripto_id = ? AND c.fiat = ? AND c.manual = FALSE
+		ORDER BY c.fecha DESC, c.id DESC
+		LIMIT ?`, criptoId, fiatOPorDefecto(fiat), limite)
+}
+
+// FindUltimasCotizacionesPorFuente devuelve la última cotización de la moneda de cada fuente, ordenadas por fuente.
+// Con fiat vacía se toma la última en cualquier fiat.
+func (r *SQLiteCryptoRepository) FindUltimasCotizacionesPorFuente(nombre, fiat string) ([]criptomonedas.Cotizacion, error) {
+	return r.buscarCotizaciones(`
+	SELECT `+columnasCotizacion+`
+	FROM (
+		SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.fuente ORDER BY c.fecha DESC, c.id DESC) AS orden
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		WHERE cm.nombre = ? AND (? = '' OR c.fiat = ?)
+	) c
+	WHERE c.orden = 1
+	ORDER BY c.fuente`, nombre, fiat, fiat)
+}
+
+// FindAllByFilterForUser devuelve las cotizaciones de las monedas que sigue el usuario, en la fiat del filtro o
+// en su fiat preferida. El resumen lleva los valores, fechas y monedas de la primera cotización.
+func (r *SQLiteCryptoRepository) FindAllByFilterForUser(filter criptomonedas.CriptoMonedaFilter, usuarioId int) ([]criptomonedas.Cotizacion, criptomonedas.Summary, error) {
+	query := `
+		SELECT ` + columnasCotizacion + `,
+			json_group_array(c.cotizacion), json_group_array(c.fecha), json_group_array(cm.nombre)
+		FROM cotizaciones c
+		JOIN monedas cm ON c.cripto_id = cm.id
+		JOIN usuario_moneda um ON um.moneda_id = cm.id
+		JOIN usuarios u ON u.id = um.usuario_id
+		WHERE um.usuario_id = ?`
+	args := []interface{}{usuarioId}
+
+	// sin fiat en el filtro se muestran las cotizaciones en la fiat preferida del usuario
+	if filter.Fiat != nil {
+		query += " AND c.fiat = ?"
+		args = append(args, *filter.Fiat)
+	} else {
+		query += " AND c.fiat = u.fiat_preferida"
+	}
+	condiciones, argsFiltro := condicionesFiltroSQLite(filter)
+	query += condiciones + " GROUP BY c.id LIMIT ? OFFSET ?"
+	args = append(append(args, argsFiltro...), filter.PageSize, filter.PageSize*(filter.PageNumber-1))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, criptomonedas.Summary{}, err
+	}
+	defer rows.Close()
+
+	var cotizaciones []criptomonedas.Cotizacion
+	var summary criptomonedas.Summary
+	for rows.Next() {
+		var valoresJSON, fechasJSON, nombresJSON string
+		cotizacion, err := escanearCotizacion(rows, &valoresJSON, &fechasJSON, &nombresJSON)
+		if err != nil {
+			return nil, criptomonedas.Summary{}, err
+		}
+		cotizaciones = append(cotizaciones, cotizacion)
+
+		if len(cotizaciones) == 1 {
+			if err := json.Unmarshal([]byte(valoresJSON), &summary.CotizacionesValores); err != nil {
+				return nil, criptomonedas.Summary{}, fmt.Errorf("error al parsear cotizaciones valores JSON: %w", err)
+			}
+			if err := json.Unmarshal([]byte(fechasJSON), &summary.CotizacionesFechas); err != nil {
+				return nil, criptomonedas.Summary{}, fmt.Errorf("error al parsear cotizaciones fechas JSON: %w", err)
+			}
+			if err := json.Unmarshal([]byte(nombresJSON), &summary.CriptoNombres); err != nil {
+				return nil, criptomonedas.Summary{}, fmt.Errorf("error al parsear cripto nombres JSON: %w", err)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, criptomonedas.Summary{}, err
+	}
+
+	summary.PageNumber = filter.PageNumber
+	summary.PageSize = filter.PageSize
+	summary.TotalResults = len(cotizaciones)
+	return cotizaciones, summary, nil
+}
+
+func (r *SQLiteCryptoRepository) BorrarCotizacionById(cotizacionId int) error {
+	result, err := r.db.Exec("DELETE FROM cotizaciones WHERE id = ?", cotizacionId)
+	if err != nil {
+		return fmt.Errorf("error al ejecutar la consulta DELETE: %w", err)
+	}
+	filas, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("no se borró ninguna cotización con id %v", cotizacionId)
+	}
+	return nil
+}
+
+func (r *SQLiteCryptoRepository) GuardarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error) {
+	cotizacionCompleta := criptomonedas.Cotizacion{
+		Cotizacion:      cotizacion.Cotizacion,
+		Fiat:            fiatOPorDefecto(cotizacion.Fiat),
+		Fecha:           cotizacion.Fecha,
+		CriptoMoneda_ID: cotizacion.CriptoMoneda_ID,
+		Manual:          true,
+		UsuarioId:       &usuarioId,
+		Fuente:          criptomonedas.FuenteManual,
+	}
+	result, err := r.db.Exec("INSERT INTO cotizaciones (cripto_id, cotizacion, fiat, fecha, manual, usuario_id, fuente) VALUES (?, ?, ?, ?, TRUE, ?, ?)",
+		cotizacionCompleta.CriptoMoneda_ID, cotizacionCompleta.Cotizacion, cotizacionCompleta.Fiat, cotizacionCompleta.Fecha.UTC(), usuarioId, cotizacionCompleta.Fuente)
+	if err != nil {
+		log.Println("Error al guardar cripto:", err)
+		return criptomonedas.Cotizacion{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return criptomonedas.Cotizacion{}, err
+	}
+	cotizacionCompleta.Id = int(id)
+	return cotizacionCompleta, nil
+}
+
+func (r *SQLiteCryptoRepository) ActualizarCotizacionManual(usuarioId int, cotizacion criptomonedas.Cotizacion) (criptomonedas.Cotizacion, error) {
+	cotizacion.Fiat = fiatOPorDefecto(cotizacion.Fiat)
+	cotizacion.Fuente = criptomonedas.FuenteManual
+	cotizacion.Exchange = ""
+	cotizacion.FechaProveedor = nil
+	_, err := r.db.Exec(`
+		UPDATE cotizaciones SET cripto_id = ?, cotizacion = ?, fiat = ?, fecha = ?, manual = TRUE, usuario_id = ?, fuente = ?,
+			exchange = '', fecha_proveedor = NULL, volumen = 0, total_ask = 0, total_bid = 0
+		WHERE id = ?`,
+		cotizacion.CriptoMoneda_ID, cotizacion.Cotizacion, cotizacion.Fiat, cotizacion.Fecha.UTC(), usuarioId, cotizacion.Fuente, cotizacion.Id)
+	if err != nil {
+		log.Println("Error al actualizar cotización:", err)
+		return criptomonedas.Cotizacion{}, err
+	}
+	return cotizacion, nil
+}
+
+func (r *SQLiteCryptoRepository) BorrarCotizacionManual(cotizacion criptomonedas.Cotizacion) error {
+	_, err := r.db.Exec("DELETE FROM cotizaciones WHERE id = ?", cotizacion.Id)
+	if err != nil {
+		log.Println("Error al borrar la cotización:", err)
+		return err
+	}
+	return nil
+}
+
+// fechaUTC pasa a UTC una fecha opcional. SQLite guarda las fechas como texto, así que todas tienen que estar en
+// la misma zona para que compararlas y ordenarlas funcione.
+func fechaUTC(fecha *time.Time) *time.Time {
+	if fecha == nil {
+		return nil
+	}
+	utc := fecha.UTC()
+	return &utc
+}
+
+// formatosFechaSQLite son los formatos en los que el driver guarda las fechas y en los que SQLite devuelve
+// CURRENT_TIMESTAMP.
+var formatosFechaSQLite = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parsearFechaSQLite lee una fecha que SQLite devolvió como texto, por ejemplo el resultado de MAX sobre una
+// columna DATETIME.
+func parsearFechaSQLite(valor string) (time.Time, error) {
+	for _, formato := range formatosFechaSQLite {
+		if fecha, err := time.ParseInLocation(formato, valor, time.UTC); err == nil {
+			return fecha.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("fecha %q con formato desconocido", valor)
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+// SQLiteCryptoRepository implementa CryptoRepository sobre SQLite, para desarrollo local y pruebas de integración
+// con un archivo o con :memory:. Las fechas se guardan en UTC porque SQLite las compara como texto.
+type SQLiteCryptoRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCryptoRepository(db *sql.DB) *SQLiteCryptoRepository {
+	return &SQLiteCryptoRepository{db: db}
+}
+
+// SaveMoneda guarda la moneda con el id indicado, o con uno nuevo si viene en cero.
+func (r *SQLiteCryptoRepository) SaveMoneda(cripto criptomonedas.CriptoMoneda) error {
+	_, err := r.db.Exec("INSERT INTO monedas (id, nombre, codigo) VALUES (NULLIF(?, 0), ?, ?)", cripto.Id, cripto.Nombre, cripto.Codigo)
+	if err != nil {
+		log.Println("Error al guardar cripto:", err)
+		return err
+	}
+	return nil
+}
+
+// FindByMonedaID devuelve sql.ErrNoRows si la moneda no existe.
+func (r *SQLiteCryptoRepository) FindByMonedaID(id int) (*criptomonedas.CriptoMoneda, error) {
+	var moneda criptomonedas.CriptoMoneda
+	err := r.db.QueryRow("SELECT id, nombre, codigo FROM monedas WHERE id = ?", id).Scan(&moneda.Id, &moneda.Nombre, &moneda.Codigo)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("no se encontro moneda con id %d", id)
+		}
+		return nil, err
+	}
+	return &moneda, nil
+}
+
+func (r *SQLiteCryptoRepository) FindAllMonedas() ([]*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMonedas("SELECT id, nombre, codigo FROM monedas ORDER BY id")
+}
+
+// FindMonedasSeguidas devuelve las monedas que sigue al menos un usuario.
+func (r *SQLiteCryptoRepository) FindMonedasSeguidas() ([]*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMonedas(`
+	SELECT DISTINCT m.id, m.nombre, m.codigo
+	FROM monedas m
+	JOIN usuario_moneda um ON um.moneda_id = m.id
+	ORDER BY m.id`)
+}
+
+func (r *SQLiteCryptoRepository) buscarMonedas(query string, args ...interface{}) ([]*criptomonedas.CriptoMoneda, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error al obtener las monedas:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var monedas []*criptomonedas.CriptoMoneda
+	for rows.Next() {
+		moneda := &criptomonedas.CriptoMoneda{}
+		if err := rows.Scan(&moneda.Id, &moneda.Nombre, &moneda.Codigo); err != nil {
+			return nil, err
+		}
+		monedas = append(monedas, moneda)
+	}
+	return monedas, rows.Err()
+}
+
+func (r *SQLiteCryptoRepository) UpdateMoneda(id int, moneda criptomonedas.CriptoMoneda) error {
+	_, err := r.db.Exec("UPDATE monedas SET nombre = ? WHERE id = ?", moneda.Nombre, id)
+	if err != nil {
+		log.Println("Error al actualizar la moneda:", err)
+		return err
+	}
+	return nil
+}
+
+// FindCryptoByName devuelve nil sin error si la moneda no existe.
+func (r *SQLiteCryptoRepository) FindCryptoByName(name string) (*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMoneda("SELECT id, nombre, codigo FROM monedas WHERE nombre = ?", name)
+}
+
+// FindCryptoByCode devuelve nil sin error si la moneda no existe.
+func (r *SQLiteCryptoRepository) FindCryptoByCode(codigo string) (*criptomonedas.CriptoMoneda, error) {
+	return r.buscarMoneda("SELECT id, nombre, codigo FROM monedas WHERE codigo = ?", codigo)
+}
+
+func (r *SQLiteCryptoRepository) buscarMoneda(query string, args ...interface{}) (*criptomonedas.CriptoMoneda, error) {
+	var cripto criptomonedas.CriptoMoneda
+	err := r.db.QueryRow(query, args...).Scan(&cripto.Id, &cripto.Nombre, &cripto.Codigo)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cripto, nil
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"database/sql"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+// SQLiteCuarentenaRepository implementa CuarentenaRepository sobre SQLite. Las consultas son las de MySQL; solo
+// cambia que las fechas se guardan en UTC, porque al aprobar una cotización pasa a cotizaciones, donde SQLite
+// las ordena como texto.
+type SQLiteCuarentenaRepository struct {
+	*MySQLCuarentenaRepository
+}
+
+func NewSQLiteCuarentenaRepository(db *sql.DB) *SQLiteCuarentenaRepository {
+	return &SQLiteCuarentenaRepository{MySQLCuarentenaRepository: NewMySQLCuarentenaRepository(db)}
+}
+
+func (r *SQLiteCuarentenaRepository) SaveCuarentena(cuarentena criptomonedas.CotizacionCuarentena) (int, error) {
+	cuarentena.Fecha = cuarentena.Fecha.UTC()
+	cuarentena.FechaProveedor = fechaUTC(cuarentena.FechaProveedor)
+	return r.MySQLCuarentenaRepository.SaveCuarentena(cuarentena)
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+// SQLiteLiderazgoRepository implementa LiderazgoRepository sobre SQLite. Como todas las instancias que comparten un
+// archivo corren en la misma máquina, los vencimientos se calculan con el reloj del proceso.
+type SQLiteLiderazgoRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteLiderazgoRepository(db *sql.DB) *SQLiteLiderazgoRepository {
+	return &SQLiteLiderazgoRepository{db: db}
+}
+
+// AdquirirLiderazgo toma el lease si está libre o vencido, o lo renueva si ya era de la instancia, y devuelve
+// el lease como quedó. La instancia es líder si el lease devuelto es suyo.
+func (r *SQLiteLiderazgoRepository) AdquirirLiderazgo(ctx context.Context, nombre, instancia string, duracion time.Duration) (criptomonedas.Liderazgo, error) {
+	ahora := time.Now().UTC()
+	// en SQLite las expresiones del DO UPDATE ven los valores anteriores de la fila, así que las dos
+	// condiciones son la misma
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO liderazgos (nombre, instancia, vence)
+		VALUES (?, ?, ?)
+		ON CONFLICT (nombre) DO UPDATE SET
+			instancia = CASE WHEN vence < ? OR instancia = excluded.instancia THEN excluded.instancia ELSE instancia END,
+			vence = CASE WHEN vence < ? OR instancia = excluded.instancia THEN excluded.vence ELSE vence END`,
+		nombre, instancia, ahora.Add(duracion), ahora, ahora,
+	)
+	if err != nil {
+		log.Println("Error al adquirir el liderazgo:", err)
+		return criptomonedas.Liderazgo{}, err
+	}
+	liderazgo, err := r.buscarLiderazgo(ctx, nombre)
+	if err != nil || liderazgo == nil {
+		return criptomonedas.Liderazgo{}, err
+	}
+	return *liderazgo, nil
+}
+
+// FindLiderazgo devuelve nil sin error si nadie tomó el lease todavía.
+func (r *SQLiteLiderazgoRepository) FindLiderazgo(nombre string) (*criptomonedas.Liderazgo, error) {
+	return r.buscarLiderazgo(context.Background(), nombre)
+}
+
+func (r *SQLiteLiderazgoRepository) buscarLiderazgo(ctx context.Context, nombre string) (*criptomonedas.Liderazgo, error) {
+	var liderazgo criptomonedas.Liderazgo
+	err := r.db.QueryRowContext(ctx, "SELECT nombre, instancia, vence FROM liderazgos WHERE nombre = ?", nombre).Scan(
+		&liderazgo.Nombre, &liderazgo.Instancia, &liderazgo.Vence,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &liderazgo, nil
+}
+
+// LiberarLiderazgo suelta el lease si es de la instancia, para que otra lo tome sin esperar a que venza.
+func (r *SQLiteLiderazgoRepository) LiberarLiderazgo(nombre, instancia string) error {
+	_, err := r.db.Exec("DELETE FROM liderazgos WHERE nombre = ? AND instancia = ?", nombre, instancia)
+	if err != nil {
+		log.Println("Error al liberar el liderazgo:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+)
+
+// SQLitePoliticaRefrescoRepository implementa PoliticaRefrescoRepository sobre SQLite.
+type SQLitePoliticaRefrescoRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePoliticaRefrescoRepository(db *sql.DB) *SQLitePoliticaRefrescoRepository {
+	return &SQLitePoliticaRefrescoRepository{db: db}
+}
+
+func (r *SQLitePoliticaRefrescoRepository) FindAllPoliticas() ([]criptomonedas.PoliticaRefresco, error) {
+	rows, err := r.db.Query(selectPoliticas + " ORDER BY p.cripto_id")
+	if err != nil {
+		log.Println("Error al obtener las políticas de refresco:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var politicas []criptomonedas.PoliticaRefresco
+	for rows.Next() {
+		var politica criptomonedas.PoliticaRefresco
+		if err := rows.Scan(&politica.CriptoMoneda_ID, &politica.Moneda, &politica.Cron, &politica.Api, &politica.Fiat, &politica.Habilitada, &politica.Frescura, &politica.Actualizada); err != nil {
+			return nil, err
+		}
+		politicas = append(politicas, politica)
+	}
+	return politicas, rows.Err()
+}
+
+// FindPoliticaByMonedaID devuelve nil sin error si la moneda no tiene política.
+func (r *SQLitePoliticaRefrescoRepository) FindPoliticaByMonedaID(criptoId int) (*criptomonedas.PoliticaRefresco, error) {
+	var politica criptomonedas.PoliticaRefresco
+	err := r.db.QueryRow(selectPoliticas+" WHERE p.cripto_id = ?", criptoId).Scan(
+		&politica.CriptoMoneda_ID, &politica.Moneda, &politica.Cron, &politica.Api, &politica.Fiat, &politica.Habilitada, &politica.Frescura, &politica.Actualizada,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &politica, nil
+}
+
+// SavePolitica crea la política de la moneda o reemplaza la que tenía.
+func (r *SQLitePoliticaRefrescoRepository) SavePolitica(politica criptomonedas.PoliticaRefresco) error {
+	_, err := r.db.Exec(`
+		INSERT INTO politicas_refresco (cripto_id, cron, api, fiat, habilitada, frescura, actualizada)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (cripto_id) DO UPDATE SET cron = excluded.cron, api = excluded.api, fiat = excluded.fiat,
+			habilitada = excluded.habilitada, frescura = excluded.frescura, actualizada = excluded.actualizada`,
+		politica.CriptoMoneda_ID, politica.Cron, politica.Api, fiatOPorDefecto(politica.Fiat), politica.Habilitada, politica.Frescura, politica.Actualizada.UTC(),
+	)
+	if err != nil {
+		log.Println("Error al guardar la política de refresco:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SQLitePoliticaRefrescoRepository) DeletePolitica(criptoId int) error {
+	result, err := r.db.Exec("DELETE FROM politicas_refresco WHERE cripto_id = ?", criptoId)
+	if err != nil {
+		return fmt.Errorf("error al borrar la política de refresco: %w", err)
+	}
+	filas, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("la moneda %d no tiene política de refresco", criptoId)
+	}
+	return nil
+}
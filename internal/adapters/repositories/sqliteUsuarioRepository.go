@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"primerProjecto/internal/entities/criptomonedas"
+	"strings"
+)
+
+// SQLiteUsuarioRepository implementa UsuarioRepository sobre SQLite.
+type SQLiteUsuarioRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUsuarioRepository(db *sql.DB) *SQLiteUsuarioRepository {
+	return &SQLiteUsuarioRepository{db: db}
+}
+
+func (r *SQLiteUsuarioRepository) SaveUsuario(usuario criptomonedas.Usuario) (int, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO usuarios (nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo, fiat_preferida)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento.UTC(), usuario.CodigoUsuario, usuario.Email, usuario.TipoDocumento,
+		usuario.Fecha_registro.UTC(), usuario.Esta_activo, fiatOPorDefecto(usuario.FiatPreferida),
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (r *SQLiteUsuarioRepository) UpdateUsuarioById(id int, usuario criptomonedas.Usuario) error {
+	_, err := r.db.Exec(`
+		UPDATE usuarios
+		SET nombre = ?, apellidos = ?, fecha_nacimiento = ?, codigo_usuario = ?, email = ?, tipo_documento = ?, fecha_registro = ?, esta_activo = ?, fiat_preferida = ?
+		WHERE id = ?`,
+		usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento.UTC(), usuario.CodigoUsuario, usuario.Email, usuario.TipoDocumento,
+		usuario.Fecha_registro.UTC(), usuario.Esta_activo, fiatOPorDefecto(usuario.FiatPreferida), id,
+	)
+	if err != nil {
+		log.Println("Error al actualizar el Usuario:", err)
+		return err
+	}
+	return nil
+}
+
+// FindUsuarioById devuelve nil sin error si el usuario no existe.
+func (r *SQLiteUsuarioRepository) FindUsuarioById(id int) (*criptomonedas.Usuario, error) {
+	var usuario criptomonedas.Usuario
+	err := r.db.QueryRow(`
+		SELECT id, nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo, fiat_preferida
+		FROM usuarios
+		WHERE id = ?`, id).Scan(
+		&usuario.Id, &usuario.Nombre, &usuario.Apellidos, &usuario.Fecha_Nacimiento,
+		&usuario.CodigoUsuario, &usuario.Email, &usuario.TipoDocumento,
+		&usuario.Fecha_registro, &usuario.Esta_activo, &usuario.FiatPreferida,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+func (r *SQLiteUsuarioRepository) FindMonedasByUsuarioID(id int) ([]int, error) {
+	return r.buscarIds("SELECT moneda_id FROM usuario_moneda WHERE usuario_id = ? ORDER BY moneda_id", id)
+}
+
+func (r *SQLiteUsuarioRepository) FindUsuariosByMonedaID(id int) ([]int, error) {
+	return r.buscarIds("SELECT usuario_id FROM usuario_moneda WHERE moneda_id = ? ORDER BY usuario_id", id)
+}
+
+func (r *SQLiteUsuarioRepository) buscarIds(query string, id int) ([]int, error) {
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *SQLiteUsuarioRepository) PatchUsuarioByID(id int, updates map[string]interface{}) error {
+	setParts := []string{}
+	args := []interface{}{}
+	for key, value := range updates {
+		setParts = append(setParts, key+" = ?")
+		args = append(args, value)
+	}
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE usuarios SET %s WHERE id = ?", strings.Join(setParts, ", "))
+	_, err := r.db.Exec(query, args...)
+	return err
+}
+
+func (r *SQLiteUsuarioRepository) AgregarMonedaFavorita(idUsuario, idMoneda int) ([]int, error) {
+	_, err := r.db.Exec("INSERT INTO usuario_moneda (usuario_id, moneda_id) VALUES (?, ?)", idUsuario, idMoneda)
+	if err != nil {
+		log.Printf("Error al asociar usuario con moneda: %s", err)
+		return []int{}, err
+	}
+	return []int{idUsuario, idMoneda}, nil
+}
+
+func (r *SQLiteUsuarioRepository) UpdateMonedasDeInteres(usuarioId int, monedas []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM usuario_moneda WHERE usuario_id = ?", usuarioId); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, monedaId := range monedas {
+		if _, err := tx.Exec("INSERT INTO usuario_moneda (usuario_id, moneda_id) VALUES (?, ?)", usuarioId, monedaId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteUsuarioRepository) DeleteMonedasDeInteres(usuarioId int) error {
+	_, err := r.db.Exec("DELETE FROM usuario_moneda WHERE usuario_id = ?", usuarioId)
+	return err
+}
+
+func (r *SQLiteUsuarioRepository) RegistrarAuditoria(usuarioId, cotizacionID int, logOperacion string) error {
+	_, err := r.db.Exec("INSERT INTO auditoria_cotizacion (usuario_id, cotizacion_id, log) VALUES (?, ?, ?)", usuarioId, cotizacionID, logOperacion)
+	if err != nil {
+		log.Println("Error al registrar auditoría:", err)
+		return err
+	}
+	return nil
+}
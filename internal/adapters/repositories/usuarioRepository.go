@@ -33,9 +33,9 @@ type UsuarioRepository interface {
 }
 
 func (r *MySQLUsuarioRepository) SaveUsuario(usuario criptomonedas.Usuario) (int, error) {
-	query := `INSERT INTO usuarios (nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-	result, err := r.db.Exec(query, usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento, usuario.CodigoUsuario, usuario.Email, usuario.TipoDocumento, usuario.Fecha_registro, usuario.Esta_activo)
+	query := `INSERT INTO usuarios (nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo, fiat_preferida)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento, usuario.CodigoUsuario, usuario.Email, usuario.TipoDocumento, usuario.Fecha_registro, usuario.Esta_activo, fiatOPorDefecto(usuario.FiatPreferida))
 	if err != nil {
 		return 0, err
 	}
@@ -49,11 +49,11 @@ func (r *MySQLUsuarioRepository) SaveUsuario(usuario criptomonedas.Usuario) (int
 func (r *MySQLUsuarioRepository) UpdateUsuarioById(id int, usuario criptomonedas.Usuario) error {
 	query := `
 		UPDATE usuarios
-		SET nombre = ?, apellidos = ?, fecha_nacimiento = ?, codigo_usuario = ?, email = ?, tipo_documento = ?, fecha_registro = ?, esta_activo = ?
+		SET nombre = ?, apellidos = ?, fecha_nacimiento = ?, codigo_usuario = ?, email = ?, tipo_documento = ?, fecha_registro = ?, esta_activo = ?, fiat_preferida = ?
 		WHERE id = ?`
 	_, err := r.db.Exec(query,
 		usuario.Nombre, usuario.Apellidos, usuario.Fecha_Nacimiento, usuario.CodigoUsuario,
-		usuario.Email, usuario.TipoDocumento, usuario.Fecha_registro, usuario.Esta_activo, id,
+		usuario.Email, usuario.TipoDocumento, usuario.Fecha_registro, usuario.Esta_activo, fiatOPorDefecto(usuario.FiatPreferida), id,
 	)
 	if err != nil {
 		log.Println("Error al actualizar el Usuario:", err)
@@ -64,14 +64,14 @@ func (r *MySQLUsuarioRepository) UpdateUsuarioById(id int, usuario criptomonedas
 
 func (r *MySQLUsuarioRepository) FindUsuarioById(id int) (*criptomonedas.Usuario, error) {
 	query := `
-		SELECT id, nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo
+		SELECT id, nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento, fecha_registro, esta_activo, fiat_preferida
 		FROM usuarios
 		WHERE id = ?`
 	var usuario criptomonedas.Usuario
 	err := r.db.QueryRow(query, id).Scan(
 		&usuario.Id, &usuario.Nombre, &usuario.Apellidos, &usuario.Fecha_Nacimiento,
 		&usuario.CodigoUsuario, &usuario.Email, &usuario.TipoDocumento,
-		&usuario.Fecha_registro, &usuario.Esta_activo,
+		&usuario.Fecha_registro, &usuario.Esta_activo, &usuario.FiatPreferida,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -116,7 +116,7 @@ func (r *MySQLUsuarioRepository) FindUsuariosByMonedaID(id int) ([]int, error) {
 	var usuariosId []int
 	for rows.Next() {
 		var usuarioId int
-		if err := rows.Scan(&usuariosId); err != nil {
+		if err := rows.Scan(&usuarioId); err != nil {
 			return nil, err
 		}
 		usuariosId = append(usuariosId, usuarioId)
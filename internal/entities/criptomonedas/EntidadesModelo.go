@@ -2,6 +2,15 @@ package criptomonedas
 
 import "time"
 
+// FiatPorDefecto es la moneda fiat que se usa cuando no se indica otra.
+const FiatPorDefecto = "USD"
+
+// Fuentes de cotización que no corresponden a un cotizador.
+const (
+	FuenteManual      = "manual"
+	FuenteImportacion = "importacion"
+)
+
 // CriptoMoneda representa una criptomoneda.
 // @Description Estructura que define una criptomoneda.
 type CriptoMoneda struct {
@@ -33,6 +42,10 @@ type Cotizacion struct {
 	// @example 50000.00
 	Cotizacion float64 `json:"cotizacion"`
 
+	// Fiat es la moneda en la que está expresada la cotización.
+	// @example USD
+	Fiat string `json:"fiat"`
+
 	// Fecha es la fecha y hora en que se registró la cotización.
 	// @example 2024-07-29T12:00:00Z
 	Fecha time.Time `json:"fecha"`
@@ -44,6 +57,30 @@ type Cotizacion struct {
 	// UsuarioId es el identificador del usuario que ingresó la cotización.
 	// @example 42
 	UsuarioId *int `json:"usuario_id,omitempty"`
+
+	// Fuente es el origen de la cotización: el nombre del cotizador, manual o importacion.
+	// @example coinpaprika
+	Fuente string `json:"fuente"`
+
+	// Exchange es el exchange del que salió el precio, si el proveedor lo informa.
+	// @example letsbit
+	Exchange string `json:"exchange,omitempty"`
+
+	// FechaProveedor es la fecha que informó el proveedor para el precio, si la informa.
+	// @example 2024-07-29T11:59:30Z
+	FechaProveedor *time.Time `json:"fecha_proveedor,omitempty"`
+
+	// Volumen es la cantidad de la moneda para la que se cotizó. En cero el precio no depende de la cantidad.
+	// @example 2
+	Volumen float64 `json:"volumen,omitempty"`
+
+	// TotalAsk es el precio efectivo de compra por unidad para el volumen, con comisiones, si el proveedor lo informa.
+	// @example 61100.00
+	TotalAsk float64 `json:"totalAsk,omitempty"`
+
+	// TotalBid es el precio efectivo de venta por unidad para el volumen, con comisiones, si el proveedor lo informa.
+	// @example 59900.00
+	TotalBid float64 `json:"totalBid,omitempty"`
 }
 
 // CotizacionCompleta representa una cotización completa de criptomoneda.
@@ -61,6 +98,10 @@ type CotizacionCompletas struct {
 	// @example 50000.00
 	Cotizacion float64 `json:"cotizacion"`
 
+	// Fiat es la moneda en la que está expresada la cotización.
+	// @example USD
+	Fiat string `json:"fiat"`
+
 	// Fecha es la fecha y hora en que se registró la cotización.
 	// @example 2024-07-29T12:00:00Z
 	Fecha time.Time `json:"fecha"`
@@ -72,6 +113,437 @@ type CotizacionCompletas struct {
 	// UsuarioId es el identificador del usuario que ingresó la cotización.
 	// @example 42
 	UsuarioId *int `json:"usuario_id,omitempty"`
+
+	// Fuente es el origen de la cotización: el nombre del cotizador, manual o importacion.
+	// @example coinpaprika
+	Fuente string `json:"fuente"`
+
+	// Exchange es el exchange del que salió el precio, si el proveedor lo informa.
+	// @example letsbit
+	Exchange string `json:"exchange,omitempty"`
+
+	// FechaProveedor es la fecha que informó el proveedor para el precio, si la informa.
+	// @example 2024-07-29T11:59:30Z
+	FechaProveedor *time.Time `json:"fecha_proveedor,omitempty"`
+}
+
+// Exchange representa un exchange del que se obtienen cotizaciones.
+// @Description Estructura que define un exchange.
+type Exchange struct {
+	// ID es el identificador único del exchange.
+	// @example 1
+	Id int `json:"id"`
+
+	// Nombre es el nombre del exchange tal como lo informa el proveedor.
+	// @example letsbit
+	Nombre string `json:"nombre"`
+}
+
+// CotizacionExchange representa la cotización de una criptomoneda en un exchange puntual.
+// @Description Estructura que define la cotización de una criptomoneda en un exchange.
+type CotizacionExchange struct {
+	// ID es el identificador único de la cotización.
+	// @example 123
+	Id int `json:"id"`
+
+	// CriptoMoneda_ID es el identificador de la criptomoneda asociada.
+	// @example 1
+	CriptoMoneda_ID int `json:"cripto_id"`
+
+	// Exchange es el nombre del exchange que informó la cotización.
+	// @example letsbit
+	Exchange string `json:"exchange"`
+
+	// Fiat es la moneda en la que está expresada la cotización.
+	// @example ARS
+	Fiat string `json:"fiat"`
+
+	// Ask es el precio de compra sin comisiones.
+	// @example 61000.00
+	Ask float64 `json:"ask"`
+
+	// TotalAsk es el precio de compra con comisiones.
+	// @example 61100.00
+	TotalAsk float64 `json:"totalAsk"`
+
+	// Bid es el precio de venta sin comisiones.
+	// @example 60000.00
+	Bid float64 `json:"bid"`
+
+	// TotalBid es el precio de venta con comisiones.
+	// @example 59900.00
+	TotalBid float64 `json:"totalBid"`
+
+	// Volumen es la cantidad de la moneda para la que se calcularon los precios con comisiones.
+	// @example 0.1
+	Volumen float64 `json:"volumen"`
+
+	// FechaProveedor es la fecha que informó el exchange para la cotización.
+	// @example 2024-07-29T12:00:00Z
+	FechaProveedor time.Time `json:"fecha_proveedor"`
+
+	// Fecha es la fecha y hora en que se registró la cotización.
+	// @example 2024-07-29T12:00:05Z
+	Fecha time.Time `json:"fecha"`
+}
+
+// CotizacionExchangeFilter representa los filtros para buscar cotizaciones por exchange.
+// @Description Estructura que define los filtros para buscar cotizaciones por exchange.
+type CotizacionExchangeFilter struct {
+	// Nombre es el nombre de la criptomoneda.
+	// @example Bitcoin
+	Nombre string
+
+	// Exchange es el nombre del exchange. Si es nil se devuelven todos los exchanges.
+	// @example letsbit
+	Exchange *string
+
+	// Fiat es la moneda de la cotización.
+	// @example ARS
+	Fiat *string
+
+	// StartDate es la fecha de inicio del periodo de búsqueda.
+	// @example 2024-01-01T00:00:00Z
+	StartDate *time.Time
+
+	// EndDate es la fecha de fin del periodo de búsqueda.
+	// @example 2024-12-31T23:59:59Z
+	EndDate *time.Time
+
+	// PageSize es el tamaño de la página de resultados. En cero no se pagina.
+	// @example 10
+	PageSize int
+
+	// PageNumber es el número de la página de resultados.
+	// @example 1
+	PageNumber int
+}
+
+// Arbitraje representa la diferencia entre comprar en un exchange y vender en otro.
+// @Description Estructura que define una oportunidad de arbitraje entre exchanges.
+type Arbitraje struct {
+	// CriptoMoneda_ID es el identificador de la criptomoneda.
+	// @example 1
+	CriptoMoneda_ID int `json:"cripto_id"`
+
+	// Moneda es el nombre de la criptomoneda.
+	// @example Bitcoin
+	Moneda string `json:"moneda"`
+
+	// Fiat es la moneda en la que están expresados los precios.
+	// @example ARS
+	Fiat string `json:"fiat"`
+
+	// ExchangeCompra es el exchange con el menor precio de compra con comisiones.
+	// @example letsbit
+	ExchangeCompra string `json:"exchange_compra"`
+
+	// PrecioCompra es el totalAsk del exchange de compra.
+	// @example 61100.00
+	PrecioCompra float64 `json:"precio_compra"`
+
+	// ExchangeVenta es el exchange con el mayor precio de venta con comisiones.
+	// @example fiwind
+	ExchangeVenta string `json:"exchange_venta"`
+
+	// PrecioVenta es el totalBid del exchange de venta.
+	// @example 61500.00
+	PrecioVenta float64 `json:"precio_venta"`
+
+	// Spread es la ganancia por unidad luego de comisiones.
+	// @example 400.00
+	Spread float64 `json:"spread"`
+
+	// SpreadPorcentaje es el spread expresado como porcentaje del precio de compra.
+	// @example 0.65
+	SpreadPorcentaje float64 `json:"spread_porcentaje"`
+
+	// Fecha es la fecha de las cotizaciones usadas.
+	// @example 2024-07-29T12:00:00Z
+	Fecha time.Time `json:"fecha"`
+}
+
+// MonedaFiat representa una moneda fiat en la que se pueden expresar cotizaciones.
+// @Description Estructura que define una moneda fiat.
+type MonedaFiat struct {
+	// Codigo es el código ISO 4217 de la moneda.
+	// @example ARS
+	Codigo string `json:"codigo"`
+
+	// Nombre es el nombre de la moneda.
+	// @example Peso argentino
+	Nombre string `json:"nombre"`
+}
+
+// CotizacionVigente es la última cotización guardada de una moneda junto con su antigüedad.
+// @Description Estructura que define la última cotización y si está vencida según la frescura de la moneda.
+type CotizacionVigente struct {
+	Cotizacion
+
+	// Antiguedad es el tiempo desde la fecha del precio, en segundos. Se toma la fecha del proveedor si la informó.
+	// @example 120
+	Antiguedad int64 `json:"antiguedad_segundos"`
+
+	// FrescuraMaxima es la antigüedad a partir de la cual la cotización se considera vencida, en segundos.
+	// @example 900
+	FrescuraMaxima int64 `json:"frescura_maxima_segundos"`
+
+	// Vencida indica que la antigüedad supera la frescura máxima, o que la moneda no tiene cotizaciones.
+	// @example false
+	Vencida bool `json:"stale"`
+
+	// Refrescada indica que la cotización se acaba de pedir al proveedor porque la guardada estaba vencida.
+	// @example false
+	Refrescada bool `json:"refrescada"`
+
+	// ErrorRefresco es el motivo por el que no se pudo refrescar; en ese caso se devuelve la cotización guardada.
+	ErrorRefresco string `json:"error_refresco,omitempty"`
+}
+
+// CotizacionConvertida representa una cotización guardada expresada en otra moneda fiat.
+// @Description Estructura que define una cotización convertida a otra moneda fiat.
+type CotizacionConvertida struct {
+	// ID es el identificador de la cotización guardada.
+	// @example 123
+	Id int `json:"id"`
+
+	// CriptoMoneda_ID es el identificador de la criptomoneda asociada.
+	// @example 1
+	CriptoMoneda_ID int `json:"cripto_id"`
+
+	// Cotizacion es el valor convertido a la fiat pedida.
+	// @example 65000000.00
+	Cotizacion float64 `json:"cotizacion"`
+
+	// Fiat es la moneda a la que se convirtió la cotización.
+	// @example ARS
+	Fiat string `json:"fiat"`
+
+	// CotizacionOriginal es el valor guardado antes de convertir.
+	// @example 50000.00
+	CotizacionOriginal float64 `json:"cotizacion_original"`
+
+	// FiatOriginal es la moneda en la que estaba guardada la cotización.
+	// @example USD
+	FiatOriginal string `json:"fiat_original"`
+
+	// TipoCambio es el tipo de cambio usado para convertir.
+	// @example blue
+	TipoCambio string `json:"tipo_cambio"`
+
+	// Tasa es el valor de una unidad de FiatOriginal en Fiat.
+	// @example 1300.00
+	Tasa float64 `json:"tasa"`
+
+	// FechaTasa es la fecha que informó el proveedor para la tasa.
+	// @example 2024-07-29T12:00:00Z
+	FechaTasa time.Time `json:"fecha_tasa"`
+
+	// Fecha es la fecha y hora de la cotización guardada.
+	// @example 2024-07-29T12:00:00Z
+	Fecha time.Time `json:"fecha"`
+}
+
+// PoliticaRefresco representa cuándo y cómo se refresca automáticamente la cotización de una criptomoneda.
+// @Description Estructura que define la política de refresco de una criptomoneda.
+type PoliticaRefresco struct {
+	// CriptoMoneda_ID es el identificador de la criptomoneda. Cada moneda tiene a lo sumo una política.
+	// @example 1
+	CriptoMoneda_ID int `json:"cripto_id"`
+
+	// Moneda es el nombre de la criptomoneda, solo de lectura.
+	// @example Bitcoin
+	Moneda string `json:"moneda,omitempty"`
+
+	// Cron es la expresión de cinco campos (minuto hora día mes día-de-semana) que indica cuándo refrescar.
+	// @example */15 * * * *
+	Cron string `json:"cron"`
+
+	// Api es el cotizador preferido, con las mismas opciones que POST /cotization/externa.
+	// @example coinpaprika
+	Api string `json:"api"`
+
+	// Fiat es la moneda en la que se guarda la cotización.
+	// @example USD
+	Fiat string `json:"fiat"`
+
+	// Habilitada indica si la moneda se refresca automáticamente. Deshabilitada no la refresca ni el intervalo global.
+	// @example true
+	Habilitada bool `json:"habilitada"`
+
+	// Frescura es la antigüedad máxima de la última cotización antes de considerarla vencida, como duración de Go.
+	// Vacía usa la frescura global.
+	// @example 30m
+	Frescura string `json:"frescura,omitempty"`
+
+	// Actualizada es la fecha de la última modificación de la política.
+	// @example 2024-07-29T12:00:00Z
+	Actualizada time.Time `json:"actualizada"`
+}
+
+// Liderazgo representa el lease con el que una instancia del servicio corre los trabajos programados.
+// @Description Estructura que define quién es el líder de un grupo de instancias y hasta cuándo.
+type Liderazgo struct {
+	// Nombre identifica el grupo de trabajos que se disputan las instancias.
+	// @example trabajos-programados
+	Nombre string `json:"nombre"`
+
+	// Instancia es el identificador de la instancia que tiene el lease.
+	// @example servidor-1-4321
+	Instancia string `json:"instancia"`
+
+	// Vence es el momento en que el lease expira si el líder no lo renueva.
+	// @example 2024-07-29T12:00:30Z
+	Vence time.Time `json:"vence"`
+}
+
+// Estados de un backfill.
+const (
+	BackfillPendiente  = "pendiente"
+	BackfillCorriendo  = "corriendo"
+	BackfillCompletado = "completado"
+	BackfillFallido    = "fallido"
+)
+
+// Backfill representa la carga de cotizaciones históricas de una criptomoneda en un rango de fechas.
+// @Description Estructura que define un trabajo de backfill y su progreso.
+type Backfill struct {
+	// Id es el identificador del trabajo.
+	// @example 1
+	Id int `json:"id"`
+
+	// CriptoMoneda_ID es el identificador de la criptomoneda.
+	// @example 1
+	CriptoMoneda_ID int `json:"cripto_id"`
+
+	// Moneda es el nombre de la criptomoneda, solo de lectura.
+	// @example Bitcoin
+	Moneda string `json:"moneda,omitempty"`
+
+	// Api es el proveedor que informa la historia, por ejemplo coinpaprika o coingecko.
+	// @example coinpaprika
+	Api string `json:"api"`
+
+	// Fiat es la moneda en la que se guardan las cotizaciones.
+	// @example USD
+	Fiat string `json:"fiat"`
+
+	// Resolucion es diaria u horaria.
+	// @example diaria
+	Resolucion string `json:"resolucion"`
+
+	// Desde es el inicio del rango, inclusive.
+	// @example 2024-01-01T00:00:00Z
+	Desde time.Time `json:"desde"`
+
+	// Hasta es el fin del rango, inclusive.
+	// @example 2024-02-01T00:00:00Z
+	Hasta time.Time `json:"hasta"`
+
+	// Cursor indica hasta dónde se completó el rango. Un backfill interrumpido sigue desde acá.
+	// @example 2024-01-15T00:00:00Z
+	Cursor time.Time `json:"cursor"`
+
+	// Estado es pendiente, corriendo, completado o fallido.
+	// @example corriendo
+	Estado string `json:"estado"`
+
+	// Guardadas es la cantidad de cotizaciones nuevas guardadas.
+	// @example 14
+	Guardadas int `json:"guardadas"`
+
+	// Omitidas es la cantidad de cotizaciones que ya estaban guardadas de una corrida anterior.
+	// @example 0
+	Omitidas int `json:"omitidas"`
+
+	// Error es el motivo por el que falló, si falló.
+	Error string `json:"error,omitempty"`
+
+	// Creado es la fecha en que se pidió el backfill.
+	// @example 2024-07-29T12:00:00Z
+	Creado time.Time `json:"creado"`
+
+	// Actualizado es la fecha del último avance.
+	// @example 2024-07-29T12:00:05Z
+	Actualizado time.Time `json:"actualizado"`
+}
+
+// Estados de una cotización en cuarentena.
+const (
+	CuarentenaPendiente = "pendiente"
+	CuarentenaAprobada  = "aprobada"
+	CuarentenaRechazada = "rechazada"
+)
+
+// CotizacionCuarentena representa una cotización externa que se apartó demasiado de la historia reciente de la
+// moneda y espera revisión antes de guardarse como cotización.
+// @Description Estructura que define una cotización sospechosa y el motivo por el que se retuvo.
+type CotizacionCuarentena struct {
+	// Id es el identificador de la cotización en cuarentena.
+	// @example 1
+	Id int `json:"id"`
+
+	// CriptoMoneda_ID es el identificador de la criptomoneda.
+	// @example 1
+	CriptoMoneda_ID int `json:"cripto_id"`
+
+	// Moneda es el nombre de la criptomoneda, solo de lectura.
+	// @example Bitcoin
+	Moneda string `json:"moneda,omitempty"`
+
+	// Cotizacion es el precio informado por el proveedor.
+	// @example 650000.00
+	Cotizacion float64 `json:"cotizacion"`
+
+	// Fiat es la moneda en la que está expresado el precio.
+	// @example USD
+	Fiat string `json:"fiat"`
+
+	// Fecha es la fecha en que se obtuvo la cotización.
+	// @example 2024-07-29T12:00:00Z
+	Fecha time.Time `json:"fecha"`
+
+	// Fuente es el proveedor que informó el precio.
+	// @example coinpaprika
+	Fuente string `json:"fuente"`
+
+	// Exchange es el exchange del que salió el precio, si el proveedor lo informa.
+	// @example letsbit
+	Exchange string `json:"exchange,omitempty"`
+
+	// FechaProveedor es el momento del precio según el proveedor, si lo informa.
+	// @example 2024-07-29T11:59:30Z
+	FechaProveedor *time.Time `json:"fecha_proveedor,omitempty"`
+
+	// Volumen, TotalAsk y TotalBid son los de la cotización del proveedor; se guardan con ella si se aprueba.
+	// @example 2
+	Volumen  float64 `json:"volumen,omitempty"`
+	TotalAsk float64 `json:"totalAsk,omitempty"`
+	TotalBid float64 `json:"totalBid,omitempty"`
+
+	// Referencia es la última cotización guardada con la que se comparó.
+	// @example 65000.00
+	Referencia float64 `json:"referencia"`
+
+	// Salto es la variación porcentual respecto de la referencia.
+	// @example 900
+	Salto float64 `json:"salto"`
+
+	// ZScore es cuántos desvíos estándar se aleja el precio de la media reciente, o cero si no hay historia suficiente.
+	// @example 42.5
+	ZScore float64 `json:"z_score"`
+
+	// Motivo explica qué umbral superó.
+	// @example salto de 900.00% respecto de 65000.00, máximo 20.00%
+	Motivo string `json:"motivo"`
+
+	// Estado es pendiente, aprobada o rechazada. Al aprobarla se guarda como cotización.
+	// @example pendiente
+	Estado string `json:"estado"`
+
+	// Revisada es la fecha en que se aprobó o rechazó.
+	// @example 2024-07-29T12:30:00Z
+	Revisada *time.Time `json:"revisada,omitempty"`
 }
 
 // TipoDocumento representa un tipo de documento.
@@ -126,6 +598,10 @@ type Usuario struct {
 	// Esta_activo indica si el usuario está activo.
 	// @example true
 	Esta_activo bool `json:"esta_activo"`
+
+	// FiatPreferida es la moneda fiat en la que el usuario ve sus cotizaciones si no pide otra.
+	// @example ARS
+	FiatPreferida string `json:"fiat_preferida"`
 }
 
 // CriptoMonedaFilter representa los filtros para buscar criptomonedas.
@@ -143,6 +619,22 @@ type CriptoMonedaFilter struct {
 	// @example 60000.00
 	MaxCotizacion *float64
 
+	// Fiat es la moneda de la cotización. En la búsqueda de un usuario, si es nil se usa su fiat preferida.
+	// @example ARS
+	Fiat *string
+
+	// Fuente es el origen de la cotización, por ejemplo coinpaprika o manual.
+	// @example criptoya
+	Fuente *string
+
+	// Exchange es el exchange del que salió el precio.
+	// @example letsbit
+	Exchange *string
+
+	// AgruparPorFuente agrega al resumen la cantidad y los precios de cada fuente.
+	// @example true
+	AgruparPorFuente bool
+
 	// StartDate es la fecha de inicio del periodo de búsqueda.
 	// @example 2024-01-01T00:00:00Z
 	StartDate *time.Time
@@ -186,6 +678,37 @@ type Summary struct {
 	// CriptoNombres es una lista de los nombres de las criptomonedas.
 	// @example ["Bitcoin", "Ethereum", "Ripple"]
 	CriptoNombres []string `json:"criptoNombres"`
+
+	// PorFuente es el resumen de cada fuente, solo si se pidió agrupar por fuente.
+	PorFuente []ResumenFuente `json:"porFuente,omitempty"`
+}
+
+// ResumenFuente representa las cotizaciones de una fuente dentro de una búsqueda.
+// @Description Estructura que resume las cotizaciones de una fuente.
+type ResumenFuente struct {
+	// Fuente es el origen de las cotizaciones.
+	// @example coinpaprika
+	Fuente string `json:"fuente"`
+
+	// Cantidad es el número de cotizaciones de la fuente que cumplen el filtro.
+	// @example 42
+	Cantidad int `json:"cantidad"`
+
+	// Minima es la menor cotización de la fuente.
+	// @example 49000.00
+	Minima float64 `json:"minima"`
+
+	// Maxima es la mayor cotización de la fuente.
+	// @example 51000.00
+	Maxima float64 `json:"maxima"`
+
+	// Promedio es el promedio de las cotizaciones de la fuente.
+	// @example 50000.00
+	Promedio float64 `json:"promedio"`
+
+	// Ultima es la fecha de la cotización más reciente de la fuente.
+	// @example 2024-07-29T12:00:00Z
+	Ultima time.Time `json:"ultima"`
 }
 
 // @description Estructura de la solicitud para crear un nuevo usuario con sus criptomonedas favoritas.
@@ -0,0 +1,414 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Las migraciones de cada motor están en un directorio con su nombre. Cada una es un par de archivos
+// NNNN_nombre.up.sql y NNNN_nombre.down.sql numerados desde 0001 sin saltos.
+//
+//go:embed mysql/*.sql sqlite/*.sql postgres/*.sql
+var archivos embed.FS
+
+// Motores con migraciones, que son también el nombre de su directorio.
+const (
+	DialectoMySQL    = "mysql"
+	DialectoSQLite   = "sqlite"
+	DialectoPostgres = "postgres"
+)
+
+// ErrEsquemaDesactualizado indica que la base no tiene aplicadas todas las migraciones que conoce el binario.
+var ErrEsquemaDesactualizado = errors.New("el esquema de la base no está actualizado")
+
+// ErrMigracionSucia indica que una migración falló a mitad de camino. Hay que revisar la base a mano y borrar la
+// fila de la versión en schema_migrations antes de volver a migrar.
+var ErrMigracionSucia = errors.New("una migración quedó a medias")
+
+// Migracion es un cambio del esquema con las sentencias para aplicarlo y para revertirlo.
+type Migracion struct {
+	Version int
+	Nombre  string
+	Up      []string
+	Down    []string
+}
+
+// EstadoMigracion indica si una migración está aplicada en la base y desde cuándo.
+type EstadoMigracion struct {
+	Version  int
+	Nombre   string
+	Aplicada *time.Time
+	// Sucia indica que la migración empezó a aplicarse o revertirse y no terminó.
+	Sucia bool
+}
+
+// Migrador aplica y revierte las migraciones embebidas y lleva registro de las aplicadas en schema_migrations.
+// Como en MySQL los cambios de esquema no son transaccionales, en todos los motores cada migración se marca como sucia antes de
+// correrla y se limpia al terminar; si falla queda sucia y el migrador no sigue hasta que se corrija.
+type Migrador struct {
+	db          *sql.DB
+	dialecto    string
+	migraciones []Migracion
+}
+
+func NewMigrador(db *sql.DB, dialecto string) (*Migrador, error) {
+	migraciones, err := Cargar(archivos, dialecto)
+	if err != nil {
+		return nil, fmt.Errorf("no hay migraciones para %s: %w", dialecto, err)
+	}
+	if len(migraciones) == 0 {
+		return nil, fmt.Errorf("no hay migraciones para %s", dialecto)
+	}
+	return &Migrador{db: db, dialecto: dialecto, migraciones: migraciones}, nil
+}
+
+var archivoMigracion = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Cargar lee las migraciones del directorio dir de fsys ordenadas por versión. Cada versión tiene que tener
+// su up y su down, y las versiones tienen que ser consecutivas desde 1.
+func Cargar(fsys fs.FS, dir string) ([]Migracion, error) {
+	entradas, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	porVersion := make(map[int]*Migracion)
+	for _, entrada := range entradas {
+		partes := archivoMigracion.FindStringSubmatch(entrada.Name())
+		if entrada.IsDir() || partes == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(partes[1])
+		migracion, existe := porVersion[version]
+		if !existe {
+			migracion = &Migracion{Version: version, Nombre: partes[2]}
+			porVersion[version] = migracion
+		} else if migracion.Nombre != partes[2] {
+			return nil, fmt.Errorf("la migración %d tiene dos nombres: %s y %s", version, migracion.Nombre, partes[2])
+		}
+		contenido, err := fs.ReadFile(fsys, path.Join(dir, entrada.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if partes[3] == "up" {
+			migracion.Up = Sentencias(string(contenido))
+		} else {
+			migracion.Down = Sentencias(string(contenido))
+		}
+	}
+
+	migraciones := make([]Migracion, 0, len(porVersion))
+	for _, migracion := range porVersion {
+		migraciones = append(migraciones, *migracion)
+	}
+	sort.Slice(migraciones, func(i, j int) bool { return migraciones[i].Version < migraciones[j].Version })
+	for i, migracion := range migraciones {
+		if migracion.Version != i+1 {
+			return nil, fmt.Errorf("falta la migración %d", i+1)
+		}
+		if len(migracion.Up) == 0 || len(migracion.Down) == 0 {
+			return nil, fmt.Errorf("la migración %d_%s necesita sentencias up y down", migracion.Version, migracion.Nombre)
+		}
+	}
+	return migraciones, nil
+}
+
+// Sentencias separa un script en sentencias. Cada sentencia termina con un punto y coma al final de una línea;
+// los comentarios de línea que quedan solos se descartan.
+func Sentencias(script string) []string {
+	var sentencias []string
+	var actual []string
+	agregar := func() {
+		sentencia := strings.TrimSpace(strings.Join(actual, "\n"))
+		actual = nil
+		if sentencia != "" && !soloComentarios(sentencia) {
+			sentencias = append(sentencias, sentencia)
+		}
+	}
+	for _, linea := range strings.Split(script, "\n") {
+		linea = strings.TrimRight(linea, " \t\r")
+		if strings.HasSuffix(linea, ";") {
+			actual = append(actual, strings.TrimSuffix(linea, ";"))
+			agregar()
+			continue
+		}
+		actual = append(actual, linea)
+	}
+	agregar()
+	return sentencias
+}
+
+func soloComentarios(sentencia string) bool {
+	for _, linea := range strings.Split(sentencia, "\n") {
+		linea = strings.TrimSpace(linea)
+		if linea != "" && !strings.HasPrefix(linea, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// Migraciones devuelve las migraciones que conoce el migrador, de la más vieja a la más nueva.
+func (m *Migrador) Migraciones() []Migracion {
+	return m.migraciones
+}
+
+type registroMigracion struct {
+	aplicada time.Time
+	sucia    bool
+}
+
+// aplicadas crea schema_migrations si no existe y devuelve las versiones registradas.
+func (m *Migrador) aplicadas(ctx context.Context) (map[int]registroMigracion, error) {
+	tipoFecha := "DATETIME"
+	if m.dialecto == DialectoPostgres {
+		tipoFecha = "TIMESTAMPTZ"
+	}
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			nombre VARCHAR(255) NOT NULL,
+			sucia BOOLEAN NOT NULL,
+			aplicada `+tipoFecha+` NOT NULL
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo crear schema_migrations: %w", err)
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version, sucia, aplicada FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aplicadas := make(map[int]registroMigracion)
+	for rows.Next() {
+		var version int
+		var registro registroMigracion
+		if err := rows.Scan(&version, &registro.sucia, &registro.aplicada); err != nil {
+			return nil, err
+		}
+		aplicadas[version] = registro
+	}
+	return aplicadas, rows.Err()
+}
+
+// Estado devuelve todas las migraciones conocidas con su estado en la base, más las versiones registradas en la
+// base que este binario no conoce, con nombre vacío.
+func (m *Migrador) Estado(ctx context.Context) ([]EstadoMigracion, error) {
+	aplicadas, err := m.aplicadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var estados []EstadoMigracion
+	for _, migracion := range m.migraciones {
+		estado := EstadoMigracion{Version: migracion.Version, Nombre: migracion.Nombre}
+		if registro, ok := aplicadas[migracion.Version]; ok {
+			estado.Aplicada = &registro.aplicada
+			estado.Sucia = registro.sucia
+			delete(aplicadas, migracion.Version)
+		}
+		estados = append(estados, estado)
+	}
+	for version, registro := range aplicadas {
+		estados = append(estados, EstadoMigracion{Version: version, Aplicada: &registro.aplicada, Sucia: registro.sucia})
+	}
+	sort.Slice(estados, func(i, j int) bool { return estados[i].Version < estados[j].Version })
+	return estados, nil
+}
+
+// Verificar devuelve un error que envuelve ErrMigracionSucia o ErrEsquemaDesactualizado si la base no está en
+// la última versión que conoce el binario, o si tiene versiones que el binario no conoce.
+func (m *Migrador) Verificar(ctx context.Context) error {
+	estados, err := m.Estado(ctx)
+	if err != nil {
+		return err
+	}
+	var pendientes []string
+	for _, estado := range estados {
+		switch {
+		case estado.Sucia:
+			return fmt.Errorf("%w: versión %d", ErrMigracionSucia, estado.Version)
+		case estado.Nombre == "":
+			return fmt.Errorf("%w: la base tiene la versión %d que este binario no conoce", ErrEsquemaDesactualizado, estado.Version)
+		case estado.Aplicada == nil:
+			pendientes = append(pendientes, fmt.Sprintf("%d_%s", estado.Version, estado.Nombre))
+		}
+	}
+	if len(pendientes) > 0 {
+		return fmt.Errorf("%w: faltan aplicar %s", ErrEsquemaDesactualizado, strings.Join(pendientes, ", "))
+	}
+	return nil
+}
+
+// Subir aplica en orden las migraciones pendientes y devuelve las que aplicó. Si una falla se detiene y la deja
+// sucia; las anteriores quedan aplicadas.
+func (m *Migrador) Subir(ctx context.Context) ([]Migracion, error) {
+	aplicadas, err := m.aplicadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarLimpias(aplicadas); err != nil {
+		return nil, err
+	}
+	var subidas []Migracion
+	for _, migracion := range m.migraciones {
+		if _, ok := aplicadas[migracion.Version]; ok {
+			continue
+		}
+		if err := m.correr(ctx, migracion, migracion.Up, true); err != nil {
+			return subidas, err
+		}
+		subidas = append(subidas, migracion)
+	}
+	return subidas, nil
+}
+
+// Bajar revierte las últimas pasos migraciones aplicadas, de la más nueva a la más vieja, y devuelve las que
+// revirtió.
+func (m *Migrador) Bajar(ctx context.Context, pasos int) ([]Migracion, error) {
+	if pasos <= 0 {
+		return nil, errors.New("la cantidad de migraciones a revertir tiene que ser mayor a cero")
+	}
+	aplicadas, err := m.aplicadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarLimpias(aplicadas); err != nil {
+		return nil, err
+	}
+	var bajadas []Migracion
+	for i := len(m.migraciones) - 1; i >= 0 && len(bajadas) < pasos; i-- {
+		migracion := m.migraciones[i]
+		if _, ok := aplicadas[migracion.Version]; !ok {
+			continue
+		}
+		if err := m.correr(ctx, migracion, migracion.Down, false); err != nil {
+			return bajadas, err
+		}
+		bajadas = append(bajadas, migracion)
+	}
+	return bajadas, nil
+}
+
+func verificarLimpias(aplicadas map[int]registroMigracion) error {
+	for version, registro := range aplicadas {
+		if registro.sucia {
+			return fmt.Errorf("%w: versión %d", ErrMigracionSucia, version)
+		}
+	}
+	return nil
+}
+
+// correr ejecuta las sentencias en una misma conexión, así las variables de sesión que use un script valen
+// para todo el script. Al subir registra la versión como sucia antes de empezar y la limpia al terminar; al
+// bajar la marca sucia y borra el registro al terminar.
+func (m *Migrador) correr(ctx context.Context, migracion Migracion, sentencias []string, subir bool) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if subir {
+		_, err = conn.ExecContext(ctx, m.marcadores("INSERT INTO schema_migrations (version, nombre, sucia, aplicada) VALUES (?, ?, ?, ?)"),
+			migracion.Version, migracion.Nombre, true, time.Now().UTC())
+	} else {
+		_, err = conn.ExecContext(ctx, m.marcadores("UPDATE schema_migrations SET sucia = ? WHERE version = ?"), true, migracion.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo registrar la migración %d_%s: %w", migracion.Version, migracion.Nombre, err)
+	}
+
+	for _, sentencia := range sentencias {
+		sentencia, err := m.siFalta(ctx, conn, sentencia)
+		if err != nil {
+			return fmt.Errorf("la migración %d_%s falló y quedó sucia: %w", migracion.Version, migracion.Nombre, err)
+		}
+		if sentencia == "" {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, sentencia); err != nil {
+			return fmt.Errorf("la migración %d_%s falló y quedó sucia: %w", migracion.Version, migracion.Nombre, err)
+		}
+	}
+
+	if subir {
+		_, err = conn.ExecContext(ctx, m.marcadores("UPDATE schema_migrations SET sucia = ?, aplicada = ? WHERE version = ?"),
+			false, time.Now().UTC(), migracion.Version)
+	} else {
+		_, err = conn.ExecContext(ctx, m.marcadores("DELETE FROM schema_migrations WHERE version = ?"), migracion.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo registrar la migración %d_%s: %w", migracion.Version, migracion.Nombre, err)
+	}
+	return nil
+}
+
+// Las migraciones agregan con ALTER TABLE ... ADD COLUMN IF NOT EXISTS y CREATE INDEX IF NOT EXISTS las columnas e
+// índices que no tenían las bases creadas antes de las migraciones. MySQL no acepta ninguna de las dos formas y
+// SQLite no acepta la primera, así que siFalta las resuelve consultando el esquema.
+var (
+	agregarColumna = regexp.MustCompile(`(?is)^((?:\s*--[^\n]*\n)*\s*ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN)\s+IF\s+NOT\s+EXISTS\s+((\w+)\s.*)$`)
+	crearIndice    = regexp.MustCompile(`(?is)^((?:\s*--[^\n]*\n)*\s*CREATE\s+(?:UNIQUE\s+)?INDEX)\s+IF\s+NOT\s+EXISTS\s+((\w+)\s+ON\s+(\w+).*)$`)
+)
+
+// siFalta devuelve la sentencia a ejecutar en el motor del migrador: sin IF NOT EXISTS si la columna o el índice
+// faltan, o vacía si ya existen. Las demás sentencias, y todas en Postgres, vuelven sin cambios.
+func (m *Migrador) siFalta(ctx context.Context, conn *sql.Conn, sentencia string) (string, error) {
+	var consulta, sinCondicion string
+	var args []interface{}
+	if partes := agregarColumna.FindStringSubmatch(sentencia); partes != nil {
+		switch m.dialecto {
+		case DialectoMySQL:
+			consulta = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?"
+		case DialectoSQLite:
+			consulta = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
+		default:
+			return sentencia, nil
+		}
+		args = []interface{}{partes[2], partes[4]}
+		sinCondicion = partes[1] + " " + partes[3]
+	} else if partes := crearIndice.FindStringSubmatch(sentencia); partes != nil && m.dialecto == DialectoMySQL {
+		consulta = "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?"
+		args = []interface{}{partes[4], partes[3]}
+		sinCondicion = partes[1] + " " + partes[2]
+	} else {
+		return sentencia, nil
+	}
+
+	var existe int
+	if err := conn.QueryRowContext(ctx, consulta, args...).Scan(&existe); err != nil {
+		return "", err
+	}
+	if existe > 0 {
+		return "", nil
+	}
+	return sinCondicion, nil
+}
+
+// marcadores pasa los ? de una consulta a $1, $2, ... en Postgres, que no acepta ?.
+func (m *Migrador) marcadores(query string) string {
+	if m.dialecto != DialectoPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS auditoria_cotizacion;
+DROP TABLE IF EXISTS usuario_moneda;
+DROP TABLE IF EXISTS cotizaciones;
+DROP TABLE IF EXISTS usuarios;
+DROP TABLE IF EXISTS monedas;
@@ -0,0 +1,74 @@
+-- Tablas de monedas, usuarios y cotizaciones. Usan IF NOT EXISTS para que una base creada antes de las
+-- migraciones se pueda adoptar corriendo migrate up; las columnas e índices que esas bases no tienen se agregan
+-- aparte con IF NOT EXISTS, que el migrador resuelve en MySQL consultando information_schema.
+CREATE TABLE IF NOT EXISTS monedas (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL,
+	codigo VARCHAR(20) NOT NULL DEFAULT ''
+);
+
+-- las bases creadas antes de las migraciones no tenían la columna codigo
+ALTER TABLE monedas ADD COLUMN IF NOT EXISTS codigo VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS usuarios (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL,
+	apellidos VARCHAR(100) NOT NULL,
+	fecha_nacimiento DATE NOT NULL,
+	codigo_usuario VARCHAR(100) NOT NULL UNIQUE,
+	email VARCHAR(255) NOT NULL UNIQUE,
+	tipo_documento ENUM('DNI', 'pasaporte', 'cedula') NOT NULL,
+	fecha_registro DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	esta_activo BOOLEAN NOT NULL DEFAULT TRUE,
+	fiat_preferida VARCHAR(10) NOT NULL DEFAULT 'USD'
+);
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS fiat_preferida VARCHAR(10) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS cotizaciones (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	cripto_id INT NOT NULL,
+	cotizacion DECIMAL(24, 8) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	fecha DATETIME NOT NULL,
+	manual BOOLEAN NOT NULL DEFAULT FALSE,
+	usuario_id INT DEFAULT NULL,
+	fuente VARCHAR(50) NOT NULL DEFAULT '',
+	exchange VARCHAR(50) NOT NULL DEFAULT '',
+	fecha_proveedor DATETIME DEFAULT NULL,
+	volumen DECIMAL(20, 8) NOT NULL DEFAULT 0,
+	total_ask DECIMAL(20, 2) NOT NULL DEFAULT 0,
+	total_bid DECIMAL(20, 2) NOT NULL DEFAULT 0,
+	FOREIGN KEY (cripto_id) REFERENCES monedas(id),
+	FOREIGN KEY (usuario_id) REFERENCES usuarios(id)
+);
+-- la primera versión de cotizaciones solo tenía id, cripto_id, cotizacion, fecha, manual y usuario_id
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS fiat VARCHAR(10) NOT NULL DEFAULT 'USD';
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS fuente VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS exchange VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS fecha_proveedor DATETIME DEFAULT NULL;
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS volumen DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS total_ask DECIMAL(20, 2) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS total_bid DECIMAL(20, 2) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_cotizaciones_fiat ON cotizaciones (cripto_id, fiat, fecha);
+CREATE INDEX IF NOT EXISTS idx_cotizaciones_fuente ON cotizaciones (fuente, fecha);
+
+-- las cotizaciones manuales anteriores a la columna fuente se pueden identificar igual
+UPDATE cotizaciones SET fuente = 'manual' WHERE manual = TRUE AND fuente = '';
+
+CREATE TABLE IF NOT EXISTS usuario_moneda (
+	usuario_id INT NOT NULL,
+	moneda_id INT NOT NULL,
+	PRIMARY KEY (usuario_id, moneda_id),
+	FOREIGN KEY (usuario_id) REFERENCES usuarios(id),
+	FOREIGN KEY (moneda_id) REFERENCES monedas(id)
+);
+
+CREATE TABLE IF NOT EXISTS auditoria_cotizacion (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	usuario_id INT,
+	cotizacion_id INT,
+	log TEXT NOT NULL,
+	created_at DATETIME DEFAULT NOW(),
+	FOREIGN KEY (usuario_id) REFERENCES usuarios(id) ON DELETE SET NULL,
+	FOREIGN KEY (cotizacion_id) REFERENCES cotizaciones(id) ON DELETE SET NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cotizaciones_exchange;
+DROP TABLE IF EXISTS exchanges;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS exchanges (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL UNIQUE
+);
+
+-- el volumen por defecto es el fijo de 0.1 con el que se pedían las cotizaciones por exchange
+CREATE TABLE IF NOT EXISTS cotizaciones_exchange (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	cripto_id INT NOT NULL,
+	exchange_id INT NOT NULL,
+	fiat VARCHAR(10) NOT NULL,
+	ask DECIMAL(20, 2) NOT NULL,
+	total_ask DECIMAL(20, 2) NOT NULL,
+	bid DECIMAL(20, 2) NOT NULL,
+	total_bid DECIMAL(20, 2) NOT NULL,
+	volumen DECIMAL(20, 8) NOT NULL DEFAULT 0.1,
+	fecha_proveedor DATETIME NOT NULL,
+	fecha DATETIME NOT NULL,
+	FOREIGN KEY (cripto_id) REFERENCES monedas(id),
+	FOREIGN KEY (exchange_id) REFERENCES exchanges(id),
+	INDEX idx_cotizaciones_exchange_serie (cripto_id, exchange_id, fiat, fecha)
+);
+-- las cotizaciones por exchange anteriores a la columna se pidieron con el volumen fijo de 0.1
+ALTER TABLE cotizaciones_exchange ADD COLUMN IF NOT EXISTS volumen DECIMAL(20, 8) NOT NULL DEFAULT 0.1;
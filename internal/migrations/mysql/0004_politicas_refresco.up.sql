@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS politicas_refresco (
+	cripto_id INT PRIMARY KEY,
+	cron VARCHAR(100) NOT NULL,
+	api VARCHAR(100) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	habilitada BOOLEAN NOT NULL DEFAULT TRUE,
+	frescura VARCHAR(20) NOT NULL DEFAULT '',
+	actualizada DATETIME NOT NULL,
+	FOREIGN KEY (cripto_id) REFERENCES monedas(id) ON DELETE CASCADE
+);
+ALTER TABLE politicas_refresco ADD COLUMN IF NOT EXISTS frescura VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+-- Trabajos de carga de cotizaciones históricas y su avance
+CREATE TABLE IF NOT EXISTS backfills (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	cripto_id INT NOT NULL,
+	api VARCHAR(100) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	resolucion VARCHAR(20) NOT NULL,
+	desde DATETIME NOT NULL,
+	hasta DATETIME NOT NULL,
+	cursor_fecha DATETIME NOT NULL,
+	estado VARCHAR(20) NOT NULL,
+	guardadas INT NOT NULL DEFAULT 0,
+	omitidas INT NOT NULL DEFAULT 0,
+	error VARCHAR(500) NOT NULL DEFAULT '',
+	creado DATETIME NOT NULL,
+	actualizado DATETIME NOT NULL,
+	FOREIGN KEY (cripto_id) REFERENCES monedas(id) ON DELETE CASCADE,
+	INDEX idx_backfills_estado (estado)
+);
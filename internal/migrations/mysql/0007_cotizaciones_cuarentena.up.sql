@@ -0,0 +1,25 @@
+-- Cotizaciones de proveedores que se apartaron de la historia reciente y esperan revisión
+CREATE TABLE IF NOT EXISTS cotizaciones_cuarentena (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	cripto_id INT NOT NULL,
+	cotizacion DECIMAL(24, 8) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	fecha DATETIME NOT NULL,
+	fuente VARCHAR(50) NOT NULL DEFAULT '',
+	exchange VARCHAR(50) NOT NULL DEFAULT '',
+	fecha_proveedor DATETIME DEFAULT NULL,
+	volumen DECIMAL(20, 8) NOT NULL DEFAULT 0,
+	total_ask DECIMAL(20, 2) NOT NULL DEFAULT 0,
+	total_bid DECIMAL(20, 2) NOT NULL DEFAULT 0,
+	referencia DECIMAL(24, 8) NOT NULL,
+	salto DOUBLE NOT NULL,
+	z_score DOUBLE NOT NULL,
+	motivo VARCHAR(500) NOT NULL,
+	estado VARCHAR(20) NOT NULL,
+	revisada DATETIME DEFAULT NULL,
+	FOREIGN KEY (cripto_id) REFERENCES monedas(id) ON DELETE CASCADE,
+	INDEX idx_cuarentena_estado (estado)
+);
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS volumen DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS total_ask DECIMAL(20, 2) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS total_bid DECIMAL(20, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Falla si quedaron cotizaciones que no entran en DECIMAL(10, 2)
+ALTER TABLE cotizaciones_cuarentena MODIFY COLUMN referencia DECIMAL(10, 2) NOT NULL;
+ALTER TABLE cotizaciones_cuarentena MODIFY COLUMN cotizacion DECIMAL(10, 2) NOT NULL;
+ALTER TABLE cotizaciones MODIFY COLUMN cotizacion DECIMAL(10, 2) NOT NULL;
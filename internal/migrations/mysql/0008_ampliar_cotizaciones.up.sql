@@ -0,0 +1,5 @@
+-- Las cotizaciones eran DECIMAL(10, 2): BTC/ARS supera 100.000.000 y las monedas chicas se redondeaban a 0.00.
+-- Las bases creadas con esa precisión se amplían acá; las nuevas ya la tienen desde 0001 y 0007.
+ALTER TABLE cotizaciones MODIFY COLUMN cotizacion DECIMAL(24, 8) NOT NULL;
+ALTER TABLE cotizaciones_cuarentena MODIFY COLUMN cotizacion DECIMAL(24, 8) NOT NULL;
+ALTER TABLE cotizaciones_cuarentena MODIFY COLUMN referencia DECIMAL(24, 8) NOT NULL;
@@ -0,0 +1,66 @@
+-- Tablas de monedas, usuarios y cotizaciones. Las fechas son TIMESTAMPTZ, así que se guardan como instantes y
+-- no dependen de la zona de la conexión; los valores permitidos que en MySQL son ENUM se controlan con CHECK.
+CREATE TABLE IF NOT EXISTS monedas (
+	id SERIAL PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL,
+	codigo VARCHAR(20) NOT NULL DEFAULT ''
+);
+-- las bases creadas antes de las migraciones no tenían la columna codigo
+ALTER TABLE monedas ADD COLUMN IF NOT EXISTS codigo VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS usuarios (
+	id SERIAL PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL,
+	apellidos VARCHAR(100) NOT NULL,
+	fecha_nacimiento DATE NOT NULL,
+	codigo_usuario VARCHAR(100) NOT NULL UNIQUE,
+	email VARCHAR(255) NOT NULL UNIQUE,
+	tipo_documento VARCHAR(20) NOT NULL CHECK (tipo_documento IN ('DNI', 'pasaporte', 'cedula')),
+	fecha_registro TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	esta_activo BOOLEAN NOT NULL DEFAULT TRUE,
+	fiat_preferida VARCHAR(10) NOT NULL DEFAULT 'USD'
+);
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS fiat_preferida VARCHAR(10) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS cotizaciones (
+	id SERIAL PRIMARY KEY,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id),
+	cotizacion NUMERIC(24, 8) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	fecha TIMESTAMPTZ NOT NULL,
+	manual BOOLEAN NOT NULL DEFAULT FALSE,
+	usuario_id INTEGER DEFAULT NULL REFERENCES usuarios(id),
+	fuente VARCHAR(50) NOT NULL DEFAULT '',
+	exchange VARCHAR(50) NOT NULL DEFAULT '',
+	fecha_proveedor TIMESTAMPTZ DEFAULT NULL,
+	volumen NUMERIC(20, 8) NOT NULL DEFAULT 0,
+	total_ask NUMERIC(20, 2) NOT NULL DEFAULT 0,
+	total_bid NUMERIC(20, 2) NOT NULL DEFAULT 0
+);
+-- la primera versión de cotizaciones solo tenía id, cripto_id, cotizacion, fecha, manual y usuario_id
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS fiat VARCHAR(10) NOT NULL DEFAULT 'USD';
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS fuente VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS exchange VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS fecha_proveedor TIMESTAMPTZ DEFAULT NULL;
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS volumen NUMERIC(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS total_ask NUMERIC(20, 2) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones ADD COLUMN IF NOT EXISTS total_bid NUMERIC(20, 2) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_cotizaciones_fiat ON cotizaciones (cripto_id, fiat, fecha);
+CREATE INDEX IF NOT EXISTS idx_cotizaciones_fuente ON cotizaciones (fuente, fecha);
+
+-- las cotizaciones manuales anteriores a la columna fuente se pueden identificar igual
+UPDATE cotizaciones SET fuente = 'manual' WHERE manual = TRUE AND fuente = '';
+
+CREATE TABLE IF NOT EXISTS usuario_moneda (
+	usuario_id INTEGER NOT NULL REFERENCES usuarios(id),
+	moneda_id INTEGER NOT NULL REFERENCES monedas(id),
+	PRIMARY KEY (usuario_id, moneda_id)
+);
+
+CREATE TABLE IF NOT EXISTS auditoria_cotizacion (
+	id SERIAL PRIMARY KEY,
+	usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+	cotizacion_id INTEGER REFERENCES cotizaciones(id) ON DELETE SET NULL,
+	log TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS monedas_fiat (
+	codigo VARCHAR(10) PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL
+);
+
+INSERT INTO monedas_fiat (codigo, nombre) VALUES
+	('USD', 'Dólar estadounidense'),
+	('ARS', 'Peso argentino'),
+	('EUR', 'Euro'),
+	('BRL', 'Real brasileño')
+ON CONFLICT (codigo) DO NOTHING;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS exchanges (
+	id SERIAL PRIMARY KEY,
+	nombre VARCHAR(100) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS cotizaciones_exchange (
+	id SERIAL PRIMARY KEY,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id),
+	exchange_id INTEGER NOT NULL REFERENCES exchanges(id),
+	fiat VARCHAR(10) NOT NULL,
+	ask NUMERIC(20, 2) NOT NULL,
+	total_ask NUMERIC(20, 2) NOT NULL,
+	bid NUMERIC(20, 2) NOT NULL,
+	total_bid NUMERIC(20, 2) NOT NULL,
+	volumen NUMERIC(20, 8) NOT NULL DEFAULT 0.1,
+	fecha_proveedor TIMESTAMPTZ NOT NULL,
+	fecha TIMESTAMPTZ NOT NULL
+);
+-- las cotizaciones por exchange anteriores a la columna se pidieron con el volumen fijo de 0.1
+ALTER TABLE cotizaciones_exchange ADD COLUMN IF NOT EXISTS volumen NUMERIC(20, 8) NOT NULL DEFAULT 0.1;
+CREATE INDEX IF NOT EXISTS idx_cotizaciones_exchange_serie ON cotizaciones_exchange (cripto_id, exchange_id, fiat, fecha);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS politicas_refresco (
+	cripto_id INTEGER PRIMARY KEY REFERENCES monedas(id) ON DELETE CASCADE,
+	cron VARCHAR(100) NOT NULL,
+	api VARCHAR(100) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	habilitada BOOLEAN NOT NULL DEFAULT TRUE,
+	frescura VARCHAR(20) NOT NULL DEFAULT '',
+	actualizada TIMESTAMPTZ NOT NULL
+);
+ALTER TABLE politicas_refresco ADD COLUMN IF NOT EXISTS frescura VARCHAR(20) NOT NULL DEFAULT '';
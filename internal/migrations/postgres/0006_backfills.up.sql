@@ -0,0 +1,18 @@
+-- Trabajos de carga de cotizaciones históricas y su avance
+CREATE TABLE IF NOT EXISTS backfills (
+	id SERIAL PRIMARY KEY,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id) ON DELETE CASCADE,
+	api VARCHAR(100) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	resolucion VARCHAR(20) NOT NULL,
+	desde TIMESTAMPTZ NOT NULL,
+	hasta TIMESTAMPTZ NOT NULL,
+	cursor_fecha TIMESTAMPTZ NOT NULL,
+	estado VARCHAR(20) NOT NULL,
+	guardadas INTEGER NOT NULL DEFAULT 0,
+	omitidas INTEGER NOT NULL DEFAULT 0,
+	error VARCHAR(500) NOT NULL DEFAULT '',
+	creado TIMESTAMPTZ NOT NULL,
+	actualizado TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_backfills_estado ON backfills (estado);
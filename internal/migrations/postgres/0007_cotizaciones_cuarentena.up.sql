@@ -0,0 +1,24 @@
+-- Cotizaciones de proveedores que se apartaron de la historia reciente y esperan revisión
+CREATE TABLE IF NOT EXISTS cotizaciones_cuarentena (
+	id SERIAL PRIMARY KEY,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id) ON DELETE CASCADE,
+	cotizacion NUMERIC(24, 8) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	fecha TIMESTAMPTZ NOT NULL,
+	fuente VARCHAR(50) NOT NULL DEFAULT '',
+	exchange VARCHAR(50) NOT NULL DEFAULT '',
+	fecha_proveedor TIMESTAMPTZ DEFAULT NULL,
+	volumen NUMERIC(20, 8) NOT NULL DEFAULT 0,
+	total_ask NUMERIC(20, 2) NOT NULL DEFAULT 0,
+	total_bid NUMERIC(20, 2) NOT NULL DEFAULT 0,
+	referencia NUMERIC(24, 8) NOT NULL,
+	salto DOUBLE PRECISION NOT NULL,
+	z_score DOUBLE PRECISION NOT NULL,
+	motivo VARCHAR(500) NOT NULL,
+	estado VARCHAR(20) NOT NULL,
+	revisada TIMESTAMPTZ DEFAULT NULL
+);
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS volumen NUMERIC(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS total_ask NUMERIC(20, 2) NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS total_bid NUMERIC(20, 2) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_cuarentena_estado ON cotizaciones_cuarentena (estado);
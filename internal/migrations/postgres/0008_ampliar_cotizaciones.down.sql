@@ -0,0 +1,4 @@
+-- Falla si quedaron cotizaciones que no entran en NUMERIC(10, 2)
+ALTER TABLE cotizaciones_cuarentena ALTER COLUMN referencia TYPE NUMERIC(10, 2);
+ALTER TABLE cotizaciones_cuarentena ALTER COLUMN cotizacion TYPE NUMERIC(10, 2);
+ALTER TABLE cotizaciones ALTER COLUMN cotizacion TYPE NUMERIC(10, 2);
@@ -0,0 +1,5 @@
+-- Las cotizaciones eran NUMERIC(10, 2): BTC/ARS supera 100.000.000 y las monedas chicas se redondeaban a 0.00.
+-- Las bases creadas con esa precisión se amplían acá; las nuevas ya la tienen desde 0001 y 0007.
+ALTER TABLE cotizaciones ALTER COLUMN cotizacion TYPE NUMERIC(24, 8);
+ALTER TABLE cotizaciones_cuarentena ALTER COLUMN cotizacion TYPE NUMERIC(24, 8);
+ALTER TABLE cotizaciones_cuarentena ALTER COLUMN referencia TYPE NUMERIC(24, 8);
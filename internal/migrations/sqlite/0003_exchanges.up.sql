@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS exchanges (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	nombre VARCHAR(100) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS cotizaciones_exchange (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id),
+	exchange_id INTEGER NOT NULL REFERENCES exchanges(id),
+	fiat VARCHAR(10) NOT NULL,
+	ask REAL NOT NULL,
+	total_ask REAL NOT NULL,
+	bid REAL NOT NULL,
+	total_bid REAL NOT NULL,
+	volumen REAL NOT NULL DEFAULT 0.1,
+	fecha_proveedor DATETIME NOT NULL,
+	fecha DATETIME NOT NULL
+);
+-- las cotizaciones por exchange anteriores a la columna se pidieron con el volumen fijo de 0.1
+ALTER TABLE cotizaciones_exchange ADD COLUMN IF NOT EXISTS volumen REAL NOT NULL DEFAULT 0.1;
+CREATE INDEX IF NOT EXISTS idx_cotizaciones_exchange_serie ON cotizaciones_exchange (cripto_id, exchange_id, fiat, fecha);
@@ -0,0 +1,6 @@
+-- Lease con el que las instancias eligen quién corre los trabajos programados
+CREATE TABLE IF NOT EXISTS liderazgos (
+	nombre VARCHAR(100) PRIMARY KEY,
+	instancia VARCHAR(255) NOT NULL,
+	vence DATETIME NOT NULL
+);
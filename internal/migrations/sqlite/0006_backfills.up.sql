@@ -0,0 +1,18 @@
+-- Trabajos de carga de cotizaciones históricas y su avance
+CREATE TABLE IF NOT EXISTS backfills (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id) ON DELETE CASCADE,
+	api VARCHAR(100) NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	resolucion VARCHAR(20) NOT NULL,
+	desde DATETIME NOT NULL,
+	hasta DATETIME NOT NULL,
+	cursor_fecha DATETIME NOT NULL,
+	estado VARCHAR(20) NOT NULL,
+	guardadas INTEGER NOT NULL DEFAULT 0,
+	omitidas INTEGER NOT NULL DEFAULT 0,
+	error VARCHAR(500) NOT NULL DEFAULT '',
+	creado DATETIME NOT NULL,
+	actualizado DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_backfills_estado ON backfills (estado);
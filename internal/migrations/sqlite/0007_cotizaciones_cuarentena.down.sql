@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cotizaciones_cuarentena;
@@ -0,0 +1,24 @@
+-- Cotizaciones de proveedores que se apartaron de la historia reciente y esperan revisión
+CREATE TABLE IF NOT EXISTS cotizaciones_cuarentena (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	cripto_id INTEGER NOT NULL REFERENCES monedas(id) ON DELETE CASCADE,
+	cotizacion REAL NOT NULL,
+	fiat VARCHAR(10) NOT NULL DEFAULT 'USD',
+	fecha DATETIME NOT NULL,
+	fuente VARCHAR(50) NOT NULL DEFAULT '',
+	exchange VARCHAR(50) NOT NULL DEFAULT '',
+	fecha_proveedor DATETIME DEFAULT NULL,
+	volumen REAL NOT NULL DEFAULT 0,
+	total_ask REAL NOT NULL DEFAULT 0,
+	total_bid REAL NOT NULL DEFAULT 0,
+	referencia REAL NOT NULL,
+	salto REAL NOT NULL,
+	z_score REAL NOT NULL,
+	motivo VARCHAR(500) NOT NULL,
+	estado VARCHAR(20) NOT NULL,
+	revisada DATETIME DEFAULT NULL
+);
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS volumen REAL NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS total_ask REAL NOT NULL DEFAULT 0;
+ALTER TABLE cotizaciones_cuarentena ADD COLUMN IF NOT EXISTS total_bid REAL NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_cuarentena_estado ON cotizaciones_cuarentena (estado);
@@ -0,0 +1,3 @@
+-- En SQLite las cotizaciones ya son REAL y no tienen precisión fija; la migración existe para que las versiones
+-- coincidan con las de MySQL y PostgreSQL.
+SELECT 1;
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCotizacionEnCuarentena indica que la cotización no se guardó porque quedó en cuarentena esperando revisión.
+var ErrCotizacionEnCuarentena = errors.New("cotización en cuarentena")
+
+// ErrCuarentenaResuelta indica que la cotización en cuarentena ya se aprobó o rechazó.
+var ErrCuarentenaResuelta = errors.New("la cotización en cuarentena ya fue revisada")
+
+// UmbralAnomalia define cuánto puede apartarse una cotización de la historia reciente antes de ir a cuarentena.
+type UmbralAnomalia struct {
+	// SaltoMaximo es la variación porcentual máxima respecto de la última cotización guardada. Cero no la controla.
+	SaltoMaximo float64
+	// ZScoreMaximo es la cantidad máxima de desvíos estándar respecto de la media de la ventana. Cero no lo controla.
+	ZScoreMaximo float64
+}
+
+// ConfiguracionAnomalias define la ventana de historia con la que se compara cada cotización y los umbrales.
+type ConfiguracionAnomalias struct {
+	// Ventana es la cantidad de cotizaciones recientes con las que se calculan la media y el desvío.
+	Ventana int
+	// MinimoHistoria es la cantidad de cotizaciones recientes a partir de la cual se controla el z-score.
+	// Con menos historia solo se controla el salto.
+	MinimoHistoria int
+	// Umbral es el que se usa para las monedas que no están en PorMoneda.
+	Umbral UmbralAnomalia
+	// PorMoneda reemplaza el umbral de las monedas indicadas por nombre, por ejemplo para una stablecoin.
+	PorMoneda map[string]UmbralAnomalia
+	// Confirmaciones es la cantidad de cotizaciones pendientes en cuarentena que tienen que coincidir con una
+	// sospechosa para aceptarla: el precio se movió de verdad y la referencia guardada quedó vieja. Cero no
+	// acepta ninguna.
+	Confirmaciones int
+	// ToleranciaConfirmacion es la diferencia porcentual máxima con cada pendiente para que cuente como confirmación.
+	ToleranciaConfirmacion float64
+}
+
+// ConfiguracionAnomaliasPorDefecto es la configuración que se usa para los valores que no se indican.
+var ConfiguracionAnomaliasPorDefecto = ConfiguracionAnomalias{
+	Ventana:        30,
+	MinimoHistoria: 10,
+	Umbral:         UmbralAnomalia{SaltoMaximo: 20, ZScoreMaximo: 8},
+
+	Confirmaciones:         3,
+	ToleranciaConfirmacion: 2,
+}
+
+// AnomaliasService revisa las cotizaciones de los proveedores antes de guardarlas. Una cotización que salta
+// más que el umbral respecto de la última guardada, o se aleja demasiados desvíos de la media de la ventana, se
+// guarda en cuarentena en lugar de en cotizaciones, así no pasa a ser la última cotización de la moneda hasta
+// que alguien la apruebe.
+type AnomaliasService struct {
+	repo       repositories.CuarentenaRepository
+	repoCripto repositories.CryptoRepository
+	config     ConfiguracionAnomalias
+}
+
+func NewAnomaliasService(repo repositories.CuarentenaRepository, repoCripto repositories.CryptoRepository, config ConfiguracionAnomalias) *AnomaliasService {
+	if config.Ventana <= 0 {
+		config.Ventana = ConfiguracionAnomaliasPorDefecto.Ventana
+	}
+	if config.MinimoHistoria <= 0 {
+		config.MinimoHistoria = ConfiguracionAnomaliasPorDefecto.MinimoHistoria
+	}
+	if config.ToleranciaConfirmacion <= 0 {
+		config.ToleranciaConfirmacion = ConfiguracionAnomaliasPorDefecto.ToleranciaConfirmacion
+	}
+	return &AnomaliasService{repo: repo, repoCripto: repoCripto, config: config}
+}
+
+// umbralDe devuelve el umbral de la moneda, sin distinguir mayúsculas en el nombre.
+func (s *AnomaliasService) umbralDe(moneda string) UmbralAnomalia {
+	for nombre, umbral := range s.config.PorMoneda {
+		if strings.EqualFold(nombre, moneda) {
+			return umbral
+		}
+	}
+	return s.config.Umbral
+}
+
+// Evaluar compara la cotización con las recientes de la misma moneda y fiat. Devuelve la cotización armada para
+// la cuarentena si supera algún umbral, o nil si se puede guardar. Sin historia no hay con qué comparar y se acepta.
+// También se acepta si la confirman las últimas pendientes en cuarentena, ver confirmada.
+func (s *AnomaliasService) Evaluar(moneda string, cotizacion criptomonedas.Cotizacion) (*criptomonedas.CotizacionCuarentena, error) {
+	recientes, err := s.repoCripto.FindCotizacionesRecientes(cotizacion.CriptoMoneda_ID, cotizacion.Fiat, s.config.Ventana)
+	if err != nil {
+		return nil, err
+	}
+	if len(recientes) == 0 {
+		return nil, nil
+	}
+
+	umbral := s.umbralDe(moneda)
+	referencia := recientes[0].Cotizacion
+	var salto float64
+	if referencia != 0 {
+		salto = (cotizacion.Cotizacion - referencia) / referencia * 100
+	}
+	var zScore float64
+	if len(recientes) >= s.config.MinimoHistoria {
+		media, desvio := mediaYDesvio(recientes)
+		if desvio > 0 {
+			zScore = (cotizacion.Cotizacion - media) / desvio
+		}
+	}
+
+	var motivos []string
+	if umbral.SaltoMaximo > 0 && math.Abs(salto) > umbral.SaltoMaximo {
+		motivos = append(motivos, fmt.Sprintf("salto de %.2f%% respecto de %.2f, máximo %.2f%%", salto, referencia, umbral.SaltoMaximo))
+	}
+	if umbral.ZScoreMaximo > 0 && math.Abs(zScore) > umbral.ZScoreMaximo {
+		motivos = append(motivos, fmt.Sprintf("z-score de %.2f en las últimas %d cotizaciones, máximo %.2f", zScore, len(recientes), umbral.ZScoreMaximo))
+	}
+	if len(motivos) == 0 {
+		return nil, nil
+	}
+	confirmada, err := s.confirmada(cotizacion)
+	if err != nil || confirmada {
+		return nil, err
+	}
+
+	return &criptomonedas.CotizacionCuarentena{
+		CriptoMoneda_ID: cotizacion.CriptoMoneda_ID,
+		Moneda:          moneda,
+		Cotizacion:      cotizacion.Cotizacion,
+		Fiat:            cotizacion.Fiat,
+		Fecha:           cotizacion.Fecha,
+		Fuente:          cotizacion.Fuente,
+		Exchange:        cotizacion.Exchange,
+		FechaProveedor:  cotizacion.FechaProveedor,
+		Volumen:         cotizacion.Volumen,
+		TotalAsk:        cotizacion.TotalAsk,
+		TotalBid:        cotizacion.TotalBid,
+		Referencia:      referencia,
+		Salto:           salto,
+		ZScore:          zScore,
+		Motivo:          strings.Join(motivos, "; "),
+		Estado:          criptomonedas.CuarentenaPendiente,
+	}, nil
+}
+
+// confirmada indica si las últimas Confirmaciones cotizaciones pendientes en cuarentena de la misma moneda y fiat
+// están a menos de ToleranciaConfirmacion de la nueva. Cuando el precio se mueve más que el umbral, la referencia
+// sigue siendo la última cotización guardada y sin esto todas las siguientes irían a cuarentena. Las pendientes
+// quedan para revisión.
+func (s *AnomaliasService) confirmada(cotizacion criptomonedas.Cotizacion) (bool, error) {
+	if s.config.Confirmaciones <= 0 {
+		return false, nil
+	}
+	pendientes, err := s.repo.FindAllCuarentena(criptomonedas.CuarentenaPendiente)
+	if err != nil {
+		return false, err
+	}
+
+	coincidencias := 0
+	// las pendientes vienen de la más nueva a la más vieja
+	for _, pendiente := range pendientes {
+		if pendiente.CriptoMoneda_ID != cotizacion.CriptoMoneda_ID || pendiente.Fiat != cotizacion.Fiat {
+			continue
+		}
+		if pendiente.Cotizacion == 0 || math.Abs(cotizacion.Cotizacion-pendiente.Cotizacion)/pendiente.Cotizacion*100 > s.config.ToleranciaConfirmacion {
+			return false, nil
+		}
+		coincidencias++
+		if coincidencias == s.config.Confirmaciones {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func mediaYDesvio(cotizaciones []criptomonedas.Cotizacion) (float64, float64) {
+	var suma float64
+	for _, cotizacion := range cotizaciones {
+		suma += cotizacion.Cotizacion
+	}
+	media := suma / float64(len(cotizaciones))
+	var cuadrados float64
+	for _, cotizacion := range cotizaciones {
+		cuadrados += (cotizacion.Cotizacion - media) * (cotizacion.Cotizacion - media)
+	}
+	return media, math.Sqrt(cuadrados / float64(len(cotizaciones)))
+}
+
+// Filtrar evalúa la cotización y, si es sospechosa, la guarda en cuarentena y devuelve un error que envuelve
+// ErrCotizacionEnCuarentena. Con nil la cotización se puede guardar.
+func (s *AnomaliasService) Filtrar(moneda string, cotizacion criptomonedas.Cotizacion) error {
+	cuarentena, err := s.Evaluar(moneda, cotizacion)
+	if err != nil {
+		return fmt.Errorf("no se pudo revisar la cotización de %s: %w", moneda, err)
+	}
+	if cuarentena == nil {
+		return nil
+	}
+	id, err := s.repo.SaveCuarentena(*cuarentena)
+	if err != nil {
+		return fmt.Errorf("no se pudo guardar la cotización de %s en cuarentena: %w", moneda, err)
+	}
+	return fmt.Errorf("%w: cotización %d de %s, %s", ErrCotizacionEnCuarentena, id, moneda, cuarentena.Motivo)
+}
+
+// FindAllCuarentena devuelve las cotizaciones en cuarentena del estado pedido, o de todos con estado vacío.
+func (s *AnomaliasService) FindAllCuarentena(estado string) ([]criptomonedas.CotizacionCuarentena, error) {
+	return s.repo.FindAllCuarentena(estado)
+}
+
+// FindCuarentenaByID devuelve nil si la cotización no existe.
+func (s *AnomaliasService) FindCuarentenaByID(id int) (*criptomonedas.CotizacionCuarentena, error) {
+	return s.repo.FindCuarentenaByID(id)
+}
+
+// Aprobar guarda la cotización en cuarentena como cotización de la moneda. Si es la más nueva pasa a ser la
+// última. Devuelve nil si no existe y ErrCuarentenaResuelta si ya se había revisado.
+func (s *AnomaliasService) Aprobar(id int) (*criptomonedas.CotizacionCuarentena, error) {
+	return s.resolver(id, criptomonedas.CuarentenaAprobada)
+}
+
+// Rechazar descarta la cotización en cuarentena. Devuelve nil si no existe y ErrCuarentenaResuelta si ya se
+// había revisado.
+func (s *AnomaliasService) Rechazar(id int) (*criptomonedas.CotizacionCuarentena, error) {
+	return s.resolver(id, criptomonedas.CuarentenaRechazada)
+}
+
+func (s *AnomaliasService) resolver(id int, estado string) (*criptomonedas.CotizacionCuarentena, error) {
+	resuelta, err := s.repo.ResolverCuarentena(id, estado, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	cuarentena, err := s.repo.FindCuarentenaByID(id)
+	if err != nil || cuarentena == nil {
+		return nil, err
+	}
+	if !resuelta {
+		return nil, fmt.Errorf("%w: la cotización %d está %s", ErrCuarentenaResuelta, id, cuarentena.Estado)
+	}
+	return cuarentena, nil
+}
+
+// ParsearUmbralesMonedas lee umbrales por moneda con el formato "Bitcoin:10:6,Tether:1:0", donde cada entrada es
+// nombre, salto máximo en porcentaje y z-score máximo. Un valor en cero no se controla.
+func ParsearUmbralesMonedas(valor string) (map[string]UmbralAnomalia, error) {
+	umbrales := make(map[string]UmbralAnomalia)
+	for _, entrada := range strings.Split(valor, ",") {
+		entrada = strings.TrimSpace(entrada)
+		if entrada == "" {
+			continue
+		}
+		partes := strings.Split(entrada, ":")
+		if len(partes) != 3 || strings.TrimSpace(partes[0]) == "" {
+			return nil, fmt.Errorf("umbral %q inválido, se espera moneda:salto:zscore", entrada)
+		}
+		salto, err := strconv.ParseFloat(partes[1], 64)
+		if err != nil || salto < 0 {
+			return nil, fmt.Errorf("salto máximo de %s inválido: %s", partes[0], partes[1])
+		}
+		zScore, err := strconv.ParseFloat(partes[2], 64)
+		if err != nil || zScore < 0 {
+			return nil, fmt.Errorf("z-score máximo de %s inválido: %s", partes[0], partes[2])
+		}
+		umbrales[strings.TrimSpace(partes[0])] = UmbralAnomalia{SaltoMaximo: salto, ZScoreMaximo: zScore}
+	}
+	return umbrales, nil
+}
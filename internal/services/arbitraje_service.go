@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"log"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
+	"time"
+)
+
+// VentanaHistorialArbitraje es el período que se usa para el historial cuando no se indica fecha de inicio.
+const VentanaHistorialArbitraje = 7 * 24 * time.Hour
+
+// Órdenes posibles del historial de arbitraje.
+const (
+	OrdenArbitrajeSpread = "spread"
+	OrdenArbitrajeFecha  = "fecha"
+)
+
+type ArbitrajeService struct {
+	exchanges  *ExchangeService
+	repoCripto repositories.CryptoRepository
+}
+
+func NewArbitrajeService(exchanges *ExchangeService, repoCripto repositories.CryptoRepository) *ArbitrajeService {
+	return &ArbitrajeService{exchanges: exchanges, repoCripto: repoCripto}
+}
+
+// VentanaArbitrajesActuales es la antigüedad máxima de la última consulta por exchange de una moneda para que
+// FindArbitrajesActuales la tenga en cuenta.
+const VentanaArbitrajesActuales = 24 * time.Hour
+
+// FindArbitrajesActuales calcula, con la última consulta por exchange guardada de cada moneda registrada, las
+// oportunidades con spread mayor a minSpread, ordenadas por spread porcentual. Solo lee la base: no consulta al
+// proveedor ni guarda cotizaciones. Con volumen mayor a cero solo usa las consultas hechas para ese volumen.
+func (s *ArbitrajeService) FindArbitrajesActuales(fiat string, minSpread, volumen float64) ([]criptomonedas.Arbitraje, error) {
+	if err := ValidarVolumen(volumen); err != nil {
+		return nil, err
+	}
+	monedas, err := s.repoCripto.FindAllMonedas()
+	if err != nil {
+		return nil, err
+	}
+
+	desde := time.Now().Add(-VentanaArbitrajesActuales)
+	var arbitrajes []criptomonedas.Arbitraje
+	for _, moneda := range monedas {
+		cotizaciones, err := s.exchanges.FindCotizacionesExchange(criptomonedas.CotizacionExchangeFilter{
+			Nombre:    moneda.Nombre,
+			Fiat:      &fiat,
+			StartDate: &desde,
+		})
+		if err != nil {
+			return nil, err
+		}
+		arbitrajes = agregarArbitraje(arbitrajes, moneda.Nombre, ultimaConsulta(cotizaciones, volumen), minSpread)
+	}
+
+	OrdenarArbitrajes(arbitrajes)
+	return arbitrajes, nil
+}
+
+// RefrescarArbitrajes consulta los exchanges para todas las monedas registradas con el volumen indicado, guarda
+// las cotizaciones obtenidas y devuelve las oportunidades con spread mayor a minSpread, ordenadas por spread
+// porcentual. Hace una consulta al proveedor por moneda.
+func (s *ArbitrajeService) RefrescarArbitrajes(ctx context.Context, fiat string, minSpread, volumen float64) ([]criptomonedas.Arbitraje, error) {
+	if err := ValidarVolumen(volumen); err != nil {
+		return nil, err
+	}
+	monedas, err := s.repoCripto.FindAllMonedas()
+	if err != nil {
+		return nil, err
+	}
+
+	var arbitrajes []criptomonedas.Arbitraje
+	for _, moneda := range monedas {
+		cotizaciones, err := s.exchanges.GuardarCotizacionesExchanges(ctx, moneda.Nombre, fiat, volumen)
+		if err != nil {
+			log.Println("Error al obtener cotizaciones por exchange para", moneda.Nombre, ":", err)
+			continue
+		}
+		arbitrajes = agregarArbitraje(arbitrajes, moneda.Nombre, cotizaciones, minSpread)
+	}
+
+	OrdenarArbitrajes(arbitrajes)
+	return arbitrajes, nil
+}
+
+// agregarArbitraje suma a arbitrajes el de la consulta si hay uno con spread mayor a minSpread.
+func agregarArbitraje(arbitrajes []criptomonedas.Arbitraje, moneda string, cotizaciones []criptomonedas.CotizacionExchange, minSpread float64) []criptomonedas.Arbitraje {
+	arbitraje, ok := DetectarArbitraje(cotizaciones)
+	if !ok || arbitraje.SpreadPorcentaje <= minSpread {
+		return arbitrajes
+	}
+	arbitraje.Moneda = moneda
+	return append(arbitrajes, arbitraje)
+}
+
+// ultimaConsulta devuelve las cotizaciones de la consulta más reciente, que comparten la fecha de registro. Con
+// volumen mayor a cero solo considera las consultas hechas para ese volumen.
+func ultimaConsulta(cotizaciones []criptomonedas.CotizacionExchange, volumen float64) []criptomonedas.CotizacionExchange {
+	var ultima []criptomonedas.CotizacionExchange
+	for _, cotizacion := range cotizaciones {
+		if volumen > 0 && cotizacion.Volumen != volumen {
+			continue
+		}
+		switch {
+		case len(ultima) == 0 || cotizacion.Fecha.After(ultima[0].Fecha):
+			ultima = []criptomonedas.CotizacionExchange{cotizacion}
+		case cotizacion.Fecha.Equal(ultima[0].Fecha):
+			ultima = append(ultima, cotizacion)
+		}
+	}
+	return ultima
+}
+
+// FindHistorialArbitraje reconstruye el arbitraje de cada consulta guardada para la moneda y fiat indicados.
+// Con OrdenArbitrajeFecha los devuelve del más nuevo al más viejo; con cualquier otro orden, por spread porcentual.
+func (s *ArbitrajeService) FindHistorialArbitraje(nombre, fiat, orden string, start, end *time.Time) ([]criptomonedas.Arbitraje, error) {
+	if start == nil {
+		desde := time.Now().Add(-VentanaHistorialArbitraje)
+		start = &desde
+	}
+	cotizaciones, err := s.exchanges.FindCotizacionesExchange(criptomonedas.CotizacionExchangeFilter{
+		Nombre:    nombre,
+		Fiat:      &fiat,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// las cotizaciones de una misma consulta comparten la fecha de registro
+	porFecha := make(map[time.Time][]criptomonedas.CotizacionExchange)
+	for _, cotizacion := range cotizaciones {
+		porFecha[cotizacion.Fecha] = append(porFecha[cotizacion.Fecha], cotizacion)
+	}
+
+	var arbitrajes []criptomonedas.Arbitraje
+	for _, grupo := range porFecha {
+		arbitraje, ok := DetectarArbitraje(grupo)
+		if !ok {
+			continue
+		}
+		arbitraje.Moneda = nombre
+		arbitrajes = append(arbitrajes, arbitraje)
+	}
+	sort.Slice(arbitrajes, func(i, j int) bool { return arbitrajes[i].Fecha.After(arbitrajes[j].Fecha) })
+	if orden != OrdenArbitrajeFecha {
+		OrdenarArbitrajes(arbitrajes)
+	}
+	return arbitrajes, nil
+}
+
+// DetectarArbitraje compara el menor totalAsk con el mayor totalBid de un conjunto de cotizaciones
+// tomadas en el mismo momento. Como los totales incluyen comisiones, el spread ya es neto.
+// Devuelve false si no hay al menos dos exchanges distintos con precios.
+func DetectarArbitraje(cotizaciones []criptomonedas.CotizacionExchange) (criptomonedas.Arbitraje, bool) {
+	var compra, venta *criptomonedas.CotizacionExchange
+	for i := range cotizaciones {
+		c := &cotizaciones[i]
+		if c.TotalAsk > 0 && (compra == nil || c.TotalAsk < compra.TotalAsk) {
+			compra = c
+		}
+		if c.TotalBid > 0 && (venta == nil || c.TotalBid > venta.TotalBid) {
+			venta = c
+		}
+	}
+	if compra == nil || venta == nil || compra.Exchange == venta.Exchange {
+		return criptomonedas.Arbitraje{}, false
+	}
+
+	spread := venta.TotalBid - compra.TotalAsk
+	return criptomonedas.Arbitraje{
+		CriptoMoneda_ID:  compra.CriptoMoneda_ID,
+		Fiat:             compra.Fiat,
+		ExchangeCompra:   compra.Exchange,
+		PrecioCompra:     compra.TotalAsk,
+		ExchangeVenta:    venta.Exchange,
+		PrecioVenta:      venta.TotalBid,
+		Spread:           spread,
+		SpreadPorcentaje: spread / compra.TotalAsk * 100,
+		Fecha:            compra.Fecha,
+	}, true
+}
+
+// OrdenarArbitrajes ordena de mayor a menor spread porcentual.
+func OrdenarArbitrajes(arbitrajes []criptomonedas.Arbitraje) {
+	sort.SliceStable(arbitrajes, func(i, j int) bool {
+		return arbitrajes[i].SpreadPorcentaje > arbitrajes[j].SpreadPorcentaje
+	})
+}
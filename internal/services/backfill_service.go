@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBackfillInvalido envuelve los errores de validación de un backfill.
+var ErrBackfillInvalido = errors.New("backfill inválido")
+
+// ErrBackfillOcupado indica que otro proceso tiene el lease del backfill y lo está corriendo.
+var ErrBackfillOcupado = errors.New("el backfill lo está corriendo otro proceso")
+
+// ConfiguracionBackfill define cada cuánto se buscan backfills pendientes y de a cuántas cotizaciones se piden.
+type ConfiguracionBackfill struct {
+	// Api es el proveedor que se usa cuando el backfill no indica uno.
+	Api string
+	// Revision es cada cuánto el trabajo en segundo plano busca backfills pendientes.
+	Revision time.Duration
+	// PuntosPorConsulta es la cantidad máxima de cotizaciones que se piden al proveedor en cada consulta.
+	// Después de cada consulta se guarda el avance, así que también es lo máximo que se repite al reanudar.
+	PuntosPorConsulta int
+	// Lease es cuánto dura el lease de un backfill sin renovarse. Se renueva antes de cada consulta, así que
+	// tiene que alcanzar para una consulta y su guardado.
+	Lease time.Duration
+}
+
+// ConfiguracionBackfillPorDefecto es la configuración que se usa para los valores que no se indican.
+var ConfiguracionBackfillPorDefecto = ConfiguracionBackfill{
+	Api:               "coinpaprika",
+	Revision:          30 * time.Second,
+	PuntosPorConsulta: 500,
+	Lease:             2 * time.Minute,
+}
+
+// BackfillService carga cotizaciones históricas de los proveedores que implementan HistoricalCotizador.
+// Cada backfill es un trabajo guardado en la base que avanza de a PuntosPorConsulta cotizaciones y guarda
+// su cursor después de cada tramo, así que si se corta sigue desde donde quedó. Volver a guardar un tramo ya
+// guardado no duplica cotizaciones, pero dos transacciones simultáneas sí podrían, así que con ConLease cada
+// backfill se corre con un lease propio en la base y un solo proceso a la vez lo carga.
+// Como TrabajoProgramado, corre en segundo plano los backfills pendientes de la base.
+type BackfillService struct {
+	repo         repositories.BackfillRepository
+	repoCripto   repositories.CryptoRepository
+	getHistorico func(name string) (cotizadores.HistoricalCotizador, error)
+	config       ConfiguracionBackfill
+	repoLease    repositories.LiderazgoRepository
+	instancia    string
+
+	aviso     chan struct{}
+	mu        sync.Mutex
+	cancelar  context.CancelFunc
+	terminado chan struct{}
+}
+
+func NewBackfillService(repo repositories.BackfillRepository, repoCripto repositories.CryptoRepository, getHistorico func(name string) (cotizadores.HistoricalCotizador, error), config ConfiguracionBackfill) *BackfillService {
+	if config.Api == "" {
+		config.Api = ConfiguracionBackfillPorDefecto.Api
+	}
+	if config.Revision <= 0 {
+		config.Revision = ConfiguracionBackfillPorDefecto.Revision
+	}
+	if config.PuntosPorConsulta <= 0 {
+		config.PuntosPorConsulta = ConfiguracionBackfillPorDefecto.PuntosPorConsulta
+	}
+	if config.Lease <= 0 {
+		config.Lease = ConfiguracionBackfillPorDefecto.Lease
+	}
+	return &BackfillService{
+		repo:         repo,
+		repoCripto:   repoCripto,
+		getHistorico: getHistorico,
+		config:       config,
+		aviso:        make(chan struct{}, 1),
+	}
+}
+
+// ConLease hace que cada backfill se corra con el lease "backfill-<id>" de la tabla de liderazgos, así el
+// trabajo en segundo plano del líder y el comando backfill no cargan el mismo backfill a la vez. Sin
+// instancia se usa el hostname y el pid.
+func (s *BackfillService) ConLease(repo repositories.LiderazgoRepository, instancia string) *BackfillService {
+	if instancia == "" {
+		instancia = instanciaPorDefecto()
+	}
+	s.repoLease = repo
+	s.instancia = instancia
+	return s
+}
+
+func (s *BackfillService) FindAllBackfills() ([]criptomonedas.Backfill, error) {
+	return s.repo.FindAllBackfills()
+}
+
+// FindBackfillByID devuelve nil si el backfill no existe.
+func (s *BackfillService) FindBackfillByID(id int) (*criptomonedas.Backfill, error) {
+	return s.repo.FindBackfillByID(id)
+}
+
+// CrearBackfill valida el pedido y lo deja pendiente. La moneda se indica por id o por nombre. Sin api se
+// usa la de la configuración, sin resolución la diaria, y un hasta en el futuro se recorta a ahora. Desde y
+// hasta se truncan al paso de la resolución. Si ya hay un backfill sin terminar de la misma moneda, api, fiat
+// y resolución cuyo rango cubre el pedido se devuelve ese en lugar de crear otro.
+func (s *BackfillService) CrearBackfill(backfill criptomonedas.Backfill) (criptomonedas.Backfill, error) {
+	backfill.Api = strings.TrimSpace(backfill.Api)
+	if backfill.Api == "" {
+		backfill.Api = s.config.Api
+	}
+	if _, err := s.getHistorico(backfill.Api); err != nil {
+		return backfill, fmt.Errorf("%w: %v", ErrBackfillInvalido, err)
+	}
+	if backfill.Resolucion == "" {
+		backfill.Resolucion = cotizadores.ResolucionDiaria
+	}
+	paso := cotizadores.PasoResolucion(backfill.Resolucion)
+	if paso == 0 {
+		return backfill, fmt.Errorf("%w: resolución %s no soportada, debe ser %s o %s", ErrBackfillInvalido, backfill.Resolucion, cotizadores.ResolucionDiaria, cotizadores.ResolucionHoraria)
+	}
+	backfill.Fiat = NormalizarFiat(backfill.Fiat)
+
+	moneda, err := s.buscarMoneda(backfill)
+	if err != nil {
+		return backfill, err
+	}
+	backfill.CriptoMoneda_ID = moneda.Id
+	backfill.Moneda = moneda.Nombre
+
+	ahora := time.Now()
+	if backfill.Desde.IsZero() || backfill.Hasta.IsZero() {
+		return backfill, fmt.Errorf("%w: hay que indicar desde y hasta", ErrBackfillInvalido)
+	}
+	if backfill.Hasta.After(ahora) {
+		backfill.Hasta = ahora
+	}
+	backfill.Desde = backfill.Desde.UTC().Truncate(paso)
+	// el paso en curso todavía no tiene cotización histórica, así que truncar hasta no pierde ninguna
+	backfill.Hasta = backfill.Hasta.UTC().Truncate(paso)
+	if backfill.Desde.After(backfill.Hasta) {
+		return backfill, fmt.Errorf("%w: desde debe ser anterior a hasta", ErrBackfillInvalido)
+	}
+
+	pendientes, err := s.repo.FindBackfillsPendientes()
+	if err != nil {
+		return backfill, err
+	}
+	for _, pendiente := range pendientes {
+		if pendiente.CriptoMoneda_ID == backfill.CriptoMoneda_ID && pendiente.Api == backfill.Api && pendiente.Fiat == backfill.Fiat &&
+			pendiente.Resolucion == backfill.Resolucion && !pendiente.Desde.After(backfill.Desde) && !pendiente.Hasta.Before(backfill.Hasta) {
+			return pendiente, nil
+		}
+	}
+
+	backfill.Id = 0
+	backfill.Cursor = backfill.Desde
+	backfill.Estado = criptomonedas.BackfillPendiente
+	backfill.Guardadas = 0
+	backfill.Omitidas = 0
+	backfill.Error = ""
+	backfill.Creado = ahora
+	backfill.Actualizado = ahora
+	if backfill.Id, err = s.repo.SaveBackfill(backfill); err != nil {
+		return backfill, err
+	}
+	s.avisar()
+	return backfill, nil
+}
+
+func (s *BackfillService) buscarMoneda(backfill criptomonedas.Backfill) (*criptomonedas.CriptoMoneda, error) {
+	var moneda *criptomonedas.CriptoMoneda
+	var err error
+	if backfill.CriptoMoneda_ID != 0 {
+		moneda, err = s.repoCripto.FindByMonedaID(backfill.CriptoMoneda_ID)
+	} else if backfill.Moneda != "" {
+		moneda, err = s.repoCripto.FindCryptoByName(backfill.Moneda)
+	} else {
+		return nil, fmt.Errorf("%w: hay que indicar la criptomoneda", ErrBackfillInvalido)
+	}
+	if err != nil || moneda == nil {
+		return nil, fmt.Errorf("%w: la criptomoneda no está registrada en la base de datos", ErrBackfillInvalido)
+	}
+	return moneda, nil
+}
+
+// Reanudar vuelve a dejar pendiente un backfill fallido, que sigue desde su cursor. Devuelve nil si no existe.
+func (s *BackfillService) Reanudar(id int) (*criptomonedas.Backfill, error) {
+	backfill, err := s.repo.FindBackfillByID(id)
+	if err != nil || backfill == nil {
+		return nil, err
+	}
+	if backfill.Estado == criptomonedas.BackfillCompletado {
+		return nil, fmt.Errorf("%w: el backfill %d ya está completado", ErrBackfillInvalido, id)
+	}
+	backfill.Estado = criptomonedas.BackfillPendiente
+	backfill.Error = ""
+	backfill.Actualizado = time.Now()
+	if err := s.repo.UpdateBackfill(*backfill); err != nil {
+		return nil, err
+	}
+	s.avisar()
+	return backfill, nil
+}
+
+// CorrerBackfill carga el backfill desde su cursor hasta el final, guardando el avance después de cada tramo.
+// Si el contexto se cancela el backfill queda pendiente para seguir después; si falla el proveedor o la
+// base queda fallido con el error.
+func (s *BackfillService) CorrerBackfill(ctx context.Context, id int) (*criptomonedas.Backfill, error) {
+	backfill, err := s.repo.FindBackfillByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if backfill == nil {
+		return nil, fmt.Errorf("el backfill %d no existe", id)
+	}
+	if backfill.Estado == criptomonedas.BackfillCompletado {
+		return backfill, nil
+	}
+	if err := s.tomarLease(ctx, backfill.Id); err != nil {
+		return backfill, err
+	}
+	defer s.liberarLease(backfill.Id)
+
+	historico, err := s.getHistorico(backfill.Api)
+	if err != nil {
+		return backfill, s.fallar(backfill, err)
+	}
+	moneda, err := s.repoCripto.FindByMonedaID(backfill.CriptoMoneda_ID)
+	if err != nil || moneda == nil {
+		return backfill, s.fallar(backfill, fmt.Errorf("la criptomoneda %d no está registrada en la base de datos", backfill.CriptoMoneda_ID))
+	}
+
+	backfill.Estado = criptomonedas.BackfillCorriendo
+	backfill.Error = ""
+	if err := s.actualizar(backfill); err != nil {
+		return backfill, err
+	}
+
+	paso := cotizadores.PasoResolucion(backfill.Resolucion)
+	ventana := paso * time.Duration(s.config.PuntosPorConsulta-1)
+	for !backfill.Cursor.After(backfill.Hasta) {
+		fin := backfill.Cursor.Add(ventana)
+		if fin.After(backfill.Hasta) {
+			fin = backfill.Hasta
+		}
+		// si no se pudo renovar el lease el backfill queda corriendo y lo retoma quien tenga el lease
+		if err := s.tomarLease(ctx, backfill.Id); err != nil {
+			return backfill, err
+		}
+
+		cotizaciones, err := historico.GetCotizacionesHistoricas(ctx, moneda.Nombre, moneda.Codigo, backfill.Fiat, backfill.Cursor, fin, backfill.Resolucion)
+		if err == nil {
+			for i := range cotizaciones {
+				cotizaciones[i].CriptoMoneda_ID = backfill.CriptoMoneda_ID
+				cotizaciones[i].Fiat = backfill.Fiat
+			}
+			var guardadas int
+			if guardadas, err = s.repoCripto.SaveCotizacionesHistoricas(cotizaciones); err == nil {
+				backfill.Guardadas += guardadas
+				backfill.Omitidas += len(cotizaciones) - guardadas
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// el apagado no es una falla, el backfill sigue desde el cursor en la próxima revisión
+				backfill.Estado = criptomonedas.BackfillPendiente
+				if errActualizar := s.actualizar(backfill); errActualizar != nil {
+					log.Println("Error al guardar el avance del backfill:", errActualizar)
+				}
+				return backfill, ctx.Err()
+			}
+			return backfill, s.fallar(backfill, err)
+		}
+
+		backfill.Cursor = fin.Add(paso)
+		if err := s.actualizar(backfill); err != nil {
+			return backfill, err
+		}
+	}
+
+	backfill.Estado = criptomonedas.BackfillCompletado
+	if err := s.actualizar(backfill); err != nil {
+		return backfill, err
+	}
+	log.Printf("Backfill %d de %s completado: %d cotizaciones nuevas, %d ya guardadas", backfill.Id, backfill.Moneda, backfill.Guardadas, backfill.Omitidas)
+	return backfill, nil
+}
+
+// tomarLease toma o renueva el lease del backfill. Devuelve ErrBackfillOcupado si lo tiene otro proceso.
+func (s *BackfillService) tomarLease(ctx context.Context, id int) error {
+	if s.repoLease == nil {
+		return nil
+	}
+	lease, err := s.repoLease.AdquirirLiderazgo(ctx, nombreLeaseBackfill(id), s.instancia, s.config.Lease)
+	if err != nil {
+		return fmt.Errorf("no se pudo tomar el lease del backfill %d: %w", id, err)
+	}
+	if lease.Instancia != s.instancia {
+		return fmt.Errorf("%w: el backfill %d lo tiene %s", ErrBackfillOcupado, id, lease.Instancia)
+	}
+	return nil
+}
+
+func (s *BackfillService) liberarLease(id int) {
+	if s.repoLease == nil {
+		return
+	}
+	if err := s.repoLease.LiberarLiderazgo(nombreLeaseBackfill(id), s.instancia); err != nil {
+		log.Println("Error al liberar el lease del backfill:", err)
+	}
+}
+
+func nombreLeaseBackfill(id int) string {
+	return fmt.Sprintf("backfill-%d", id)
+}
+
+func (s *BackfillService) actualizar(backfill *criptomonedas.Backfill) error {
+	backfill.Actualizado = time.Now()
+	return s.repo.UpdateBackfill(*backfill)
+}
+
+// fallar deja el backfill fallido con el error y devuelve el error.
+func (s *BackfillService) fallar(backfill *criptomonedas.Backfill, err error) error {
+	backfill.Estado = criptomonedas.BackfillFallido
+	backfill.Error = err.Error()
+	if len(backfill.Error) > 500 {
+		backfill.Error = backfill.Error[:500]
+	}
+	if errActualizar := s.actualizar(backfill); errActualizar != nil {
+		log.Println("Error al guardar el fallo del backfill:", errActualizar)
+	}
+	return err
+}
+
+// avisar despierta al trabajo en segundo plano para que no espere a la próxima revisión.
+func (s *BackfillService) avisar() {
+	select {
+	case s.aviso <- struct{}{}:
+	default:
+	}
+}
+
+// Iniciar corre en segundo plano, de a uno, los backfills pendientes y los que quedaron corriendo
+// cuando se detuvo la instancia anterior.
+func (s *BackfillService) Iniciar(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancelar != nil {
+		return fmt.Errorf("los backfills ya están iniciados")
+	}
+	ctx, cancelar := context.WithCancel(ctx)
+	s.cancelar = cancelar
+	s.terminado = make(chan struct{})
+
+	go func(terminado chan struct{}) {
+		defer close(terminado)
+		s.ciclo(ctx)
+	}(s.terminado)
+	return nil
+}
+
+// Detener corta el backfill en curso, que queda pendiente, y espera a que termine.
+func (s *BackfillService) Detener() {
+	s.mu.Lock()
+	cancelar, terminado := s.cancelar, s.terminado
+	s.cancelar = nil
+	s.mu.Unlock()
+	if cancelar == nil {
+		return
+	}
+	cancelar()
+	<-terminado
+}
+
+func (s *BackfillService) ciclo(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Revision)
+	defer ticker.Stop()
+	for {
+		s.correrPendientes(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.aviso:
+		}
+	}
+}
+
+func (s *BackfillService) correrPendientes(ctx context.Context) {
+	pendientes, err := s.repo.FindBackfillsPendientes()
+	if err != nil {
+		log.Println("Error al obtener los backfills pendientes:", err)
+		return
+	}
+	for _, pendiente := range pendientes {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.CorrerBackfill(ctx, pendiente.Id); err != nil && ctx.Err() == nil && !errors.Is(err, ErrBackfillOcupado) {
+			log.Printf("Error en el backfill %d de %s: %s", pendiente.Id, pendiente.Moneda, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"time"
+)
+
+// ErrFiltroHuecosInvalido envuelve los errores de validación de un pedido de huecos.
+var ErrFiltroHuecosInvalido = errors.New("filtro de huecos inválido")
+
+// RangoMaximoHuecos es el rango más largo que se revisa en una consulta de huecos.
+const RangoMaximoHuecos = 90 * 24 * time.Hour
+
+// maximoFaltantesCron corta la cuenta de ejecuciones faltantes de una expresión cron en huecos muy largos.
+const maximoFaltantesCron = 10000
+
+// Hueco es un intervalo en el que faltan cotizaciones según la cadencia esperada de la moneda.
+type Hueco struct {
+	// Desde es la primera ejecución que no dejó cotización.
+	Desde time.Time `json:"desde"`
+	// Hasta es la cotización siguiente al hueco, o el fin del rango revisado.
+	Hasta time.Time `json:"hasta"`
+	// Faltantes es cuántas ejecuciones no dejaron cotización.
+	Faltantes int `json:"faltantes"`
+	// BackfillId es el backfill encolado para rellenar el hueco, si se pidió.
+	BackfillId int `json:"backfill_id,omitempty"`
+}
+
+// HuecosMoneda son los huecos de la serie de cotizaciones de una moneda en un rango.
+type HuecosMoneda struct {
+	CriptoMoneda_ID int    `json:"cripto_id"`
+	Moneda          string `json:"moneda"`
+	Fiat            string `json:"fiat"`
+	// Cadencia es el intervalo del poller o la expresión cron de la política de la moneda.
+	Cadencia     string  `json:"cadencia"`
+	Cotizaciones int     `json:"cotizaciones"`
+	Faltantes    int     `json:"faltantes"`
+	Huecos       []Hueco `json:"huecos"`
+}
+
+// FiltroHuecos elige las monedas y el rango a revisar. Sin nombre se revisan todas las monedas, y sin
+// fechas las últimas 24 horas.
+type FiltroHuecos struct {
+	Nombre string
+	Desde  *time.Time
+	Hasta  *time.Time
+}
+
+// CalidadDatosService revisa que la serie de cotizaciones de cada moneda no tenga huecos respecto de la
+// cadencia con la que se refresca: su política de refresco si tiene una habilitada, o el intervalo global
+// del poller. Las monedas que el poller no refresca no tienen cadencia y no se revisan: las de política
+// deshabilitada, las que no sigue nadie con AlcanceSeguidas y todas si el poller está desactivado.
+type CalidadDatosService struct {
+	repo          repositories.CryptoRepository
+	repoPoliticas repositories.PoliticaRefrescoRepository
+	poller        ConfiguracionPoller
+	backfill      *BackfillService
+}
+
+func NewCalidadDatosService(repo repositories.CryptoRepository, repoPoliticas repositories.PoliticaRefrescoRepository, poller ConfiguracionPoller, backfill *BackfillService) *CalidadDatosService {
+	if poller.Intervalo <= 0 {
+		poller.Intervalo = ConfiguracionPollerPorDefecto.Intervalo
+	}
+	poller.Fiat = NormalizarFiat(poller.Fiat)
+	return &CalidadDatosService{repo: repo, repoPoliticas: repoPoliticas, poller: poller, backfill: backfill}
+}
+
+// cadencia es cada cuánto se espera una cotización de una moneda y en qué fiat.
+type cadencia struct {
+	descripcion string
+	fiat        string
+	// siguiente devuelve la próxima ejecución posterior a t.
+	siguiente func(t time.Time) time.Time
+	// paso es el tiempo fijo entre ejecuciones, en cero para las expresiones cron.
+	paso time.Duration
+}
+
+// BuscarHuecos devuelve, para cada moneda del filtro con cadencia, los huecos de su serie de cotizaciones.
+func (s *CalidadDatosService) BuscarHuecos(filtro FiltroHuecos) ([]HuecosMoneda, error) {
+	hasta := time.Now().UTC()
+	if filtro.Hasta != nil {
+		hasta = filtro.Hasta.UTC()
+	}
+	desde := hasta.Add(-24 * time.Hour)
+	if filtro.Desde != nil {
+		desde = filtro.Desde.UTC()
+	}
+	if !desde.Before(hasta) {
+		return nil, fmt.Errorf("%w: start_date debe ser anterior a end_date", ErrFiltroHuecosInvalido)
+	}
+	if hasta.Sub(desde) > RangoMaximoHuecos {
+		return nil, fmt.Errorf("%w: el rango no puede superar los %d días", ErrFiltroHuecosInvalido, int(RangoMaximoHuecos.Hours()/24))
+	}
+
+	var monedas []*criptomonedas.CriptoMoneda
+	if filtro.Nombre != "" {
+		moneda, err := s.repo.FindCryptoByName(filtro.Nombre)
+		if err != nil {
+			return nil, err
+		}
+		if moneda == nil {
+			return nil, fmt.Errorf("%w: la criptomoneda %s no está registrada en la base de datos", ErrFiltroHuecosInvalido, filtro.Nombre)
+		}
+		monedas = append(monedas, moneda)
+	} else {
+		var err error
+		if monedas, err = s.repo.FindAllMonedas(); err != nil {
+			return nil, err
+		}
+	}
+	if s.poller.Desactivado {
+		return []HuecosMoneda{}, nil
+	}
+	var seguidas map[int]bool
+	if s.poller.Alcance == AlcanceSeguidas {
+		monedasSeguidas, err := s.repo.FindMonedasSeguidas()
+		if err != nil {
+			return nil, err
+		}
+		seguidas = make(map[int]bool, len(monedasSeguidas))
+		for _, moneda := range monedasSeguidas {
+			seguidas[moneda.Id] = true
+		}
+	}
+
+	politicas, err := s.repoPoliticas.FindAllPoliticas()
+	if err != nil {
+		return nil, err
+	}
+	politicaDe := make(map[int]criptomonedas.PoliticaRefresco, len(politicas))
+	for _, politica := range politicas {
+		politicaDe[politica.CriptoMoneda_ID] = politica
+	}
+
+	reportes := make([]HuecosMoneda, 0, len(monedas))
+	for _, moneda := range monedas {
+		politica, tienePolitica := politicaDe[moneda.Id]
+		if (tienePolitica && !politica.Habilitada) || (seguidas != nil && !seguidas[moneda.Id]) {
+			continue
+		}
+		cadencia, err := s.cadenciaDe(politica, tienePolitica)
+		if err != nil {
+			return nil, fmt.Errorf("política de refresco de %s: %w", moneda.Nombre, err)
+		}
+
+		fechas, err := s.repo.FindFechasCotizaciones(moneda.Id, cadencia.fiat, desde, hasta)
+		if err != nil {
+			return nil, err
+		}
+		reporte := HuecosMoneda{
+			CriptoMoneda_ID: moneda.Id,
+			Moneda:          moneda.Nombre,
+			Fiat:            cadencia.fiat,
+			Cadencia:        cadencia.descripcion,
+			Cotizaciones:    len(fechas),
+			Huecos:          detectarHuecos(fechas, desde, hasta, cadencia),
+		}
+		for _, hueco := range reporte.Huecos {
+			reporte.Faltantes += hueco.Faltantes
+		}
+		reportes = append(reportes, reporte)
+	}
+	return reportes, nil
+}
+
+func (s *CalidadDatosService) cadenciaDe(politica criptomonedas.PoliticaRefresco, tienePolitica bool) (cadencia, error) {
+	if !tienePolitica {
+		intervalo := s.poller.Intervalo
+		return cadencia{
+			descripcion: intervalo.String(),
+			fiat:        s.poller.Fiat,
+			siguiente:   func(t time.Time) time.Time { return t.Add(intervalo) },
+			paso:        intervalo,
+		}, nil
+	}
+	cron, err := ParsearCron(politica.Cron)
+	if err != nil {
+		return cadencia{}, err
+	}
+	return cadencia{
+		descripcion: politica.Cron,
+		fiat:        NormalizarFiat(politica.Fiat),
+		// el poller evalúa las políticas en la zona local, así que acá también
+		siguiente: func(t time.Time) time.Time { return cron.Siguiente(t.Local()).UTC() },
+	}, nil
+}
+
+// detectarHuecos recorre las cotizaciones de a pares consecutivos, tomando el inicio del rango como si
+// hubiera una cotización. Después de una cotización se espera la siguiente en la próxima ejecución de la
+// cadencia, con una holgura de medio paso por el jitter y la demora del poller; si no llegó, desde esa
+// ejecución hasta la cotización que sí llegó hay un hueco.
+func detectarHuecos(fechas []time.Time, desde, hasta time.Time, cadencia cadencia) []Hueco {
+	huecos := []Hueco{}
+	anterior := desde
+	for i := 0; i <= len(fechas); i++ {
+		proxima := hasta
+		if i < len(fechas) {
+			proxima = fechas[i].UTC()
+		}
+
+		esperada := cadencia.siguiente(anterior)
+		if esperada.IsZero() {
+			// una expresión cron sin más ejecuciones no espera más cotizaciones
+			break
+		}
+		var holgura time.Duration
+		if despues := cadencia.siguiente(esperada); !despues.IsZero() {
+			holgura = despues.Sub(esperada) / 2
+		}
+		if proxima.After(esperada.Add(holgura)) {
+			huecos = append(huecos, Hueco{Desde: esperada, Hasta: proxima, Faltantes: contarFaltantes(esperada, proxima.Add(-holgura), cadencia)})
+		}
+		anterior = proxima
+	}
+	return huecos
+}
+
+// contarFaltantes cuenta las ejecuciones de la cadencia desde esperada hasta limite inclusive.
+func contarFaltantes(esperada, limite time.Time, cadencia cadencia) int {
+	if cadencia.paso > 0 {
+		return int(limite.Sub(esperada)/cadencia.paso) + 1
+	}
+	faltantes := 0
+	for t := esperada; !t.IsZero() && !t.After(limite) && faltantes < maximoFaltantesCron; t = cadencia.siguiente(t) {
+		faltantes++
+	}
+	return faltantes
+}
+
+// RellenarHuecos busca los huecos como BuscarHuecos y encola un backfill horario para cada hueco de al menos
+// una hora. Los huecos más cortos no se pueden rellenar con la historia de los proveedores, que es horaria.
+func (s *CalidadDatosService) RellenarHuecos(filtro FiltroHuecos) ([]HuecosMoneda, error) {
+	reportes, err := s.BuscarHuecos(filtro)
+	if err != nil {
+		return nil, err
+	}
+	paso := cotizadores.PasoResolucion(cotizadores.ResolucionHoraria)
+	for i := range reportes {
+		for j, hueco := range reportes[i].Huecos {
+			if hueco.Hasta.Sub(hueco.Desde) < paso {
+				continue
+			}
+			backfill, err := s.backfill.CrearBackfill(criptomonedas.Backfill{
+				CriptoMoneda_ID: reportes[i].CriptoMoneda_ID,
+				Fiat:            reportes[i].Fiat,
+				Resolucion:      cotizadores.ResolucionHoraria,
+				Desde:           hueco.Desde,
+				Hasta:           hueco.Hasta,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("no se pudo encolar el backfill de %s: %w", reportes[i].Moneda, err)
+			}
+			reportes[i].Huecos[j].BackfillId = backfill.Id
+		}
+	}
+	return reportes, nil
+}
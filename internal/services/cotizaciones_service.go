@@ -1,24 +1,40 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
 )
 
+// ErrVolumenInvalido indica que el volumen a cotizar no es un número positivo.
+var ErrVolumenInvalido = errors.New("volumen inválido")
+
 // Método para guardar una nueva criptomoneda
 func (s *CryptoService) SaveCotizacion(cripto criptomonedas.Cotizacion) error {
 	return s.repo.SaveCotizacion(cripto)
 }
 
+// SaveCotizacionProveedor guarda una cotización obtenida de un proveedor. Con filtro de anomalías, si la
+// cotización es sospechosa queda en cuarentena en lugar de guardarse y se devuelve un error que envuelve
+// ErrCotizacionEnCuarentena.
+func (s *CryptoService) SaveCotizacionProveedor(moneda string, cotizacion criptomonedas.Cotizacion) error {
+	if s.anomalias != nil {
+		if err := s.anomalias.Filtrar(moneda, cotizacion); err != nil {
+			return err
+		}
+	}
+	return s.repo.SaveCotizacion(cotizacion)
+}
+
 // Método para actualizar una criptomoneda por ID
 func (s *CryptoService) UpdateCotizacion(id int, cripto criptomonedas.Cotizacion) error {
 	return s.repo.UpdateCotizacion(id, cripto)
 }
 
-func (s *CryptoService) FindUltimaCotizacion(nombre string) (*criptomonedas.Cotizacion, error) {
-	return s.repo.FindUltimaCotizacion(nombre)
-}
-
 // Método para encontrar todas las cotizaciones
 func (s *CryptoService) FindAll() ([]*criptomonedas.Cotizacion, error) {
 	return s.repo.FindAllCotizaciones()
@@ -32,11 +48,16 @@ func (s *CryptoService) FindAllByFilterForUser(filter criptomonedas.CriptoMoneda
 	return s.repo.FindAllByFilterForUser(filter, userId)
 }
 
-// guardar cotizacion externa
-func (s *CryptoService) GuardarCotizacionExterna(nombreMoneda, api string) error {
-	cotizacion, err := s.GetCotizacion(api, nombreMoneda, "USD")
+// guardar cotizacion externa en la fiat pedida, USD si viene vacía, para el volumen pedido; en cero el
+// proveedor usa su volumen por defecto
+func (s *CryptoService) GuardarCotizacionExterna(ctx context.Context, nombreMoneda, api, fiat string, volumen float64) error {
+	fiat = NormalizarFiat(fiat)
+	if err := ValidarVolumen(volumen); err != nil {
+		return err
+	}
+	cotizacion, err := s.GetCotizacionVolumen(ctx, api, nombreMoneda, fiat, volumen)
 	if err != nil {
-		return fmt.Errorf("no se pudo guardar la cotizacion externa para moneda %s", nombreMoneda)
+		return fmt.Errorf("no se pudo guardar la cotizacion externa para moneda %s: %w", nombreMoneda, err)
 	}
 
 	cripto, err := s.repo.FindCryptoByName(nombreMoneda)
@@ -48,11 +69,17 @@ func (s *CryptoService) GuardarCotizacionExterna(nombreMoneda, api string) error
 	}
 
 	cotizacion.CriptoMoneda_ID = cripto.Id
-	s.repo.SaveCotizacion(cotizacion)
-	return nil
+	cotizacion.Fiat = fiat
+	return s.SaveCotizacionProveedor(cripto.Nombre, cotizacion)
 }
 
-func (s *CryptoService) GetCotizacion(api, moneda, fiat string) (criptomonedas.Cotizacion, error) {
+func (s *CryptoService) GetCotizacion(ctx context.Context, api, moneda, fiat string) (criptomonedas.Cotizacion, error) {
+	return s.GetCotizacionVolumen(ctx, api, moneda, fiat, 0)
+}
+
+// GetCotizacionVolumen cotiza la moneda para operar el volumen indicado. Los cotizadores con libro de órdenes
+// informan además el volumen usado y los precios efectivos con comisiones.
+func (s *CryptoService) GetCotizacionVolumen(ctx context.Context, api, moneda, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
 	cotizador, err := s.getCotizador(api)
 	if err != nil {
 		return criptomonedas.Cotizacion{}, fmt.Errorf("el Cotizador %s no es soportado", api)
@@ -64,5 +91,62 @@ func (s *CryptoService) GetCotizacion(api, moneda, fiat string) (criptomonedas.C
 	if monedaEnbase == nil {
 		return criptomonedas.Cotizacion{}, fmt.Errorf("la criptomoneda %s no está registrada en la base de datos", moneda)
 	}
-	return cotizador.GetCotizacionExterna(monedaEnbase.Nombre, monedaEnbase.Codigo, fiat)
+	cotizacion, err := cotizador.GetCotizacionExterna(ctx, monedaEnbase.Nombre, monedaEnbase.Codigo, fiat, volumen)
+	if err != nil {
+		return cotizacion, err
+	}
+	// los cotizadores informan su nombre; si alguno no lo hace queda registrado el que se pidió
+	if cotizacion.Fuente == "" {
+		cotizacion.Fuente, _, _ = strings.Cut(api, ":")
+	}
+	return cotizacion, nil
+}
+
+// GetCotizacionAgregada devuelve el precio agregado para el volumen pedido junto con el detalle de qué fuentes
+// se usaron y cuáles se descartaron.
+func (s *CryptoService) GetCotizacionAgregada(ctx context.Context, api, moneda, fiat string, volumen float64) (cotizadores.ReporteAgregado, error) {
+	if err := ValidarVolumen(volumen); err != nil {
+		return cotizadores.ReporteAgregado{}, err
+	}
+	cotizador, err := s.getCotizador(api)
+	if err != nil {
+		return cotizadores.ReporteAgregado{}, fmt.Errorf("el Cotizador %s no es soportado", api)
+	}
+	agregado, ok := cotizador.(cotizadores.CotizadorAgregado)
+	if !ok {
+		return cotizadores.ReporteAgregado{}, fmt.Errorf("el Cotizador %s no es un cotizador agregado", api)
+	}
+	monedaEnbase, err := s.repo.FindCryptoByName(moneda)
+	if err != nil || monedaEnbase == nil {
+		return cotizadores.ReporteAgregado{}, fmt.Errorf("la criptomoneda %s no está registrada en la base de datos", moneda)
+	}
+	return agregado.GetCotizacionAgregada(ctx, monedaEnbase.Nombre, monedaEnbase.Codigo, fiat, volumen)
+}
+
+// FindEstadoCircuitos devuelve el estado del circuit breaker de cada proveedor consultado.
+func (s *CryptoService) FindEstadoCircuitos() []cotizadores.EstadoCircuito {
+	return cotizadores.EstadosCircuitos()
+}
+
+// FindUsoProveedores devuelve los contadores de uso y el cupo restante de cada proveedor con límite.
+func (s *CryptoService) FindUsoProveedores() []cotizadores.UsoProveedor {
+	return cotizadores.UsoProveedores()
+}
+
+// NormalizarFiat pasa la fiat a mayúsculas y devuelve FiatPorDefecto si viene vacía.
+func NormalizarFiat(fiat string) string {
+	fiat = strings.ToUpper(strings.TrimSpace(fiat))
+	if fiat == "" {
+		return criptomonedas.FiatPorDefecto
+	}
+	return fiat
+}
+
+// ValidarVolumen devuelve un error que envuelve ErrVolumenInvalido si el volumen es negativo o no es un número.
+// Cero es válido y deja que el proveedor use su volumen por defecto.
+func ValidarVolumen(volumen float64) error {
+	if volumen < 0 || math.IsNaN(volumen) || math.IsInf(volumen, 0) {
+		return fmt.Errorf("%w: %v, debe ser un número positivo", ErrVolumenInvalido, volumen)
+	}
+	return nil
 }
@@ -2,12 +2,14 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"log"
 	cotizadores "primerProjecto/internal/adapters/cotizadores"
 	repositories "primerProjecto/internal/adapters/repositories"
 	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -16,8 +18,15 @@ import (
 type CryptoService struct {
 	repo         repositories.CryptoRepository
 	getCotizador func(name string) (cotizadores.Cotizador, error) // Función para obtener el cotizador
+	getBatch     func(name string) (cotizadores.BatchCotizador, error)
+	anomalias    *AnomaliasService
 	tasks        map[string]TaskStatusEntry
 	mu           sync.Mutex
+
+	repoPoliticas repositories.PoliticaRefrescoRepository
+	frescura      ConfiguracionFrescura
+	refrescos     map[string]*refrescoEnCurso
+	muRefrescos   sync.Mutex
 }
 
 type TaskStatus struct {
@@ -30,23 +39,40 @@ type TaskStatusEntry struct {
 	Status     TaskStatus
 }
 
+// OpcionesCSV filtran y agrupan las cotizaciones que se exportan.
+type OpcionesCSV struct {
+	// Fuente deja solo la última cotización de esa fuente. Vacía toma la última de cualquier fuente.
+	Fuente string
+	// AgruparPorFuente exporta la última cotización de cada fuente, con las filas agrupadas por fuente.
+	AgruparPorFuente bool
+}
+
 // NewCryptoService crea una nueva instancia del servicio de criptomonedas con un cotizador
 func NewCryptoService(repo repositories.CryptoRepository, getCotizador func(name string) (cotizadores.Cotizador, error)) *CryptoService {
 	return &CryptoService{
 		repo:         repo,
 		getCotizador: getCotizador,
 		tasks:        make(map[string]TaskStatusEntry),
+		frescura:     ConfiguracionFrescuraPorDefecto,
+		refrescos:    make(map[string]*refrescoEnCurso),
 	}
 }
 
+// ConFiltroAnomalias hace que las cotizaciones de los proveedores pasen por el filtro antes de guardarse.
+// Sin filtro se guardan todas.
+func (s *CryptoService) ConFiltroAnomalias(anomalias *AnomaliasService) *CryptoService {
+	s.anomalias = anomalias
+	return s
+}
+
 type CryptoServiceInterface interface {
-	GetCotizacion(api, moneda, fiat string) (criptomonedas.Cotizacion, error)
+	GetCotizacion(ctx context.Context, api, moneda, fiat string) (criptomonedas.Cotizacion, error)
 	FindMonedaByID(id int) (*criptomonedas.CriptoMoneda, error)
 	SaveMoneda(cripto criptomonedas.CriptoMoneda)
 	UpdateMoneda(id int, cripto criptomonedas.CriptoMoneda)
 	FindCriptoByNombre(nombre string) (*criptomonedas.CriptoMoneda, error)
-	SaveMonedaConCotizacion(nombre, api string) error
-	GenerateCSV() ([]byte, error)
+	SaveMonedaConCotizacion(ctx context.Context, nombre, codigo, api, fiat string, volumen float64) error
+	GenerateCSV(opciones OpcionesCSV) ([]byte, error)
 	GenerateCSVAsync(taskID string) chan TaskStatus
 	GetTaskStatus(taskID string) (TaskStatus, bool)
 }
@@ -70,8 +96,12 @@ func (s *CryptoService) FindCriptoByNombre(nombre string) (*criptomonedas.Cripto
 	return s.repo.FindCryptoByName(nombre)
 }
 
-// guardar moneda y buscar cotizacion en la api especificada
-func (s *CryptoService) SaveMonedaConCotizacion(nombre, api string) error {
+// guardar moneda y buscar cotizacion en la api especificada, en la fiat pedida o USD si viene vacía, para el
+// volumen pedido. Sin codigo solo la pueden cotizar los proveedores que buscan por nombre.
+func (s *CryptoService) SaveMonedaConCotizacion(ctx context.Context, nombre, codigo, api, fiat string, volumen float64) error {
+	if err := ValidarVolumen(volumen); err != nil {
+		return err
+	}
 
 	// Buscar la criptomoneda por nombre
 	cripto, err := s.repo.FindCryptoByName(nombre)
@@ -82,24 +112,31 @@ func (s *CryptoService) SaveMonedaConCotizacion(nombre, api string) error {
 	if cripto != nil {
 		return fmt.Errorf("la criptomoneda %s ya está registrada en la base de datos", nombre)
 	}
-	// Guardar la nueva criptomoneda
-	cripto = &criptomonedas.CriptoMoneda{Nombre: nombre}
-	if err := s.repo.SaveMoneda(*cripto); err != nil {
+	// Guardar la nueva criptomoneda y volver a leerla para tener el id que le asignó la base
+	if err := s.repo.SaveMoneda(criptomonedas.CriptoMoneda{Nombre: nombre, Codigo: codigo}); err != nil {
 		return fmt.Errorf("la criptomoneda %s no se pudo guardar", nombre)
 	}
+	cripto, err = s.repo.FindCryptoByName(nombre)
+	if err != nil {
+		return err
+	}
+	if cripto == nil {
+		return fmt.Errorf("la criptomoneda %s no se encontró después de guardarla", nombre)
+	}
 
 	// Obtener la cotización utilizando el handler apropiado
-	cotizacion, Error := s.GetCotizacion(api, nombre, "USD")
+	fiat = NormalizarFiat(fiat)
+	cotizacion, Error := s.GetCotizacionVolumen(ctx, api, nombre, fiat, volumen)
 	if Error != nil {
 
-		return fmt.Errorf("no se pudo guardar la cotizacion externa para moneda %s", nombre)
+		return fmt.Errorf("no se pudo guardar la cotizacion externa para moneda %s: %w", nombre, Error)
 	}
 	cotizacion.CriptoMoneda_ID = cripto.Id
-	s.repo.SaveCotizacion(cotizacion)
-	return nil
+	cotizacion.Fiat = fiat
+	return s.SaveCotizacionProveedor(nombre, cotizacion)
 }
 
-func (s *CryptoService) GenerateCSV() ([]byte, error) {
+func (s *CryptoService) GenerateCSV(opciones OpcionesCSV) ([]byte, error) {
 	monedas, err := s.repo.FindAllMonedas()
 	if err != nil {
 		return nil, err
@@ -110,7 +147,7 @@ func (s *CryptoService) GenerateCSV() ([]byte, error) {
 	writer := csv.NewWriter(&buffer)
 	defer writer.Flush()
 
-	headers := []string{"ID", "Nombre", "Codigo", "Cotización"}
+	headers := []string{"ID", "Nombre", "Codigo", "Cotización", "Fiat", "Fuente", "Exchange", "Fecha proveedor"}
 	if err := writer.Write(headers); err != nil {
 		log.Println("Error al escribir encabezados CSV:", err)
 		return nil, fmt.Errorf("error al escribir encabezados CSV: %w", err)
@@ -118,20 +155,36 @@ func (s *CryptoService) GenerateCSV() ([]byte, error) {
 
 	log.Println("Encabezados CSV escritos:", headers)
 
+	var records [][]string
 	for _, moneda := range monedas {
-		UltimaCotizacion, err := s.repo.FindUltimaCotizacion(moneda.Nombre)
+		cotizaciones, err := s.cotizacionesParaCSV(moneda.Nombre, opciones)
 		if err != nil {
 			log.Println("Error al obtener última cotización para", moneda.Nombre, ":", err)
-			UltimaCotizacion.Cotizacion = 0
+			cotizaciones = []criptomonedas.Cotizacion{{Fuente: opciones.Fuente}}
 		}
 
-		record := []string{
-			strconv.Itoa(moneda.Id),
-			moneda.Nombre,
-			moneda.Codigo,
-			strconv.FormatFloat(UltimaCotizacion.Cotizacion, 'f', 2, 64),
+		for _, UltimaCotizacion := range cotizaciones {
+			fechaProveedor := ""
+			if UltimaCotizacion.FechaProveedor != nil {
+				fechaProveedor = UltimaCotizacion.FechaProveedor.Format(time.RFC3339)
+			}
+			records = append(records, []string{
+				strconv.Itoa(moneda.Id),
+				moneda.Nombre,
+				moneda.Codigo,
+				strconv.FormatFloat(UltimaCotizacion.Cotizacion, 'f', 2, 64),
+				UltimaCotizacion.Fiat,
+				UltimaCotizacion.Fuente,
+				UltimaCotizacion.Exchange,
+				fechaProveedor,
+			})
 		}
+	}
+	if opciones.AgruparPorFuente {
+		sort.SliceStable(records, func(i, j int) bool { return records[i][5] < records[j][5] })
+	}
 
+	for _, record := range records {
 		if err := writer.Write(record); err != nil {
 			log.Println("Error al escribir datos CSV:", err)
 			return nil, fmt.Errorf("error al escribir datos CSV: %w", err)
@@ -151,7 +204,34 @@ func (s *CryptoService) GenerateCSV() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
-func (s *CryptoService) generateCSVAsync(taskID string) {
+// cotizacionesParaCSV devuelve las cotizaciones de la moneda que van al CSV según las opciones.
+// Una moneda sin cotizaciones sale igual, con la cotización en cero.
+func (s *CryptoService) cotizacionesParaCSV(nombre string, opciones OpcionesCSV) ([]criptomonedas.Cotizacion, error) {
+	if opciones.Fuente == "" && !opciones.AgruparPorFuente {
+		ultima, err := s.repo.FindUltimaCotizacion(nombre, "")
+		if err != nil || ultima == nil {
+			return []criptomonedas.Cotizacion{{}}, err
+		}
+		return []criptomonedas.Cotizacion{*ultima}, nil
+	}
+
+	ultimas, err := s.repo.FindUltimasCotizacionesPorFuente(nombre, "")
+	if err != nil {
+		return nil, err
+	}
+	var cotizaciones []criptomonedas.Cotizacion
+	for _, cotizacion := range ultimas {
+		if opciones.Fuente == "" || cotizacion.Fuente == opciones.Fuente {
+			cotizaciones = append(cotizaciones, cotizacion)
+		}
+	}
+	if len(cotizaciones) == 0 {
+		cotizaciones = append(cotizaciones, criptomonedas.Cotizacion{Fuente: opciones.Fuente})
+	}
+	return cotizaciones, nil
+}
+
+func (s *CryptoService) generateCSVAsync(taskID string, opciones OpcionesCSV) {
 	statusChan := make(chan TaskStatus, 1)
 	s.mu.Lock()
 	s.tasks[taskID] = TaskStatusEntry{StatusChan: statusChan}
@@ -160,7 +240,7 @@ func (s *CryptoService) generateCSVAsync(taskID string) {
 	defer close(statusChan)
 	status := TaskStatus{Status: "In Progress"}
 
-	csvData, err := s.GenerateCSV()
+	csvData, err := s.GenerateCSV(opciones)
 	if err != nil {
 		status.Status = "Failed"
 		status.Data = nil
@@ -217,8 +297,8 @@ func generateUniqueID() string {
 	return strconv.FormatInt(time.Now().UnixNano(), 10)
 }
 
-func (s *CryptoService) StartCSVTask() string {
+func (s *CryptoService) StartCSVTask(opciones OpcionesCSV) string {
 	taskID := generateUniqueID()
-	go s.generateCSVAsync(taskID)
+	go s.generateCSVAsync(taskID, opciones)
 	return taskID
 }
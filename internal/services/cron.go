@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExpresionCron es una expresión cron de cinco campos (minuto hora día mes día-de-semana) ya interpretada.
+// Cada campo acepta *, valores, rangos a-b, listas separadas por coma y pasos */n o a-b/n. El día de la
+// semana va de 0 a 7, donde 0 y 7 son domingo. Como en cron, si se restringen el día del mes y el de la
+// semana alcanza con que coincida uno de los dos.
+type ExpresionCron struct {
+	minutos, horas, dias, meses, diasSemana uint64
+	diaRestringido, semanaRestringida       bool
+}
+
+var macrosCron = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParsearCron interpreta una expresión de cinco campos o una de las macros @hourly, @daily, @weekly, @monthly y @yearly.
+func ParsearCron(expresion string) (ExpresionCron, error) {
+	expresion = strings.TrimSpace(expresion)
+	if macro, ok := macrosCron[strings.ToLower(expresion)]; ok {
+		expresion = macro
+	}
+	campos := strings.Fields(expresion)
+	if len(campos) != 5 {
+		return ExpresionCron{}, fmt.Errorf("la expresión cron %q debe tener 5 campos", expresion)
+	}
+
+	var cron ExpresionCron
+	var err error
+	if cron.minutos, err = parsearCampoCron(campos[0], 0, 59); err != nil {
+		return ExpresionCron{}, fmt.Errorf("minuto inválido en %q: %w", expresion, err)
+	}
+	if cron.horas, err = parsearCampoCron(campos[1], 0, 23); err != nil {
+		return ExpresionCron{}, fmt.Errorf("hora inválida en %q: %w", expresion, err)
+	}
+	if cron.dias, err = parsearCampoCron(campos[2], 1, 31); err != nil {
+		return ExpresionCron{}, fmt.Errorf("día inválido en %q: %w", expresion, err)
+	}
+	if cron.meses, err = parsearCampoCron(campos[3], 1, 12); err != nil {
+		return ExpresionCron{}, fmt.Errorf("mes inválido en %q: %w", expresion, err)
+	}
+	if cron.diasSemana, err = parsearCampoCron(campos[4], 0, 7); err != nil {
+		return ExpresionCron{}, fmt.Errorf("día de la semana inválido en %q: %w", expresion, err)
+	}
+	// el 7 es otra forma de escribir el domingo
+	if cron.diasSemana&(1<<7) != 0 {
+		cron.diasSemana |= 1
+	}
+	cron.diaRestringido = !strings.HasPrefix(campos[2], "*")
+	cron.semanaRestringida = !strings.HasPrefix(campos[4], "*")
+	return cron, nil
+}
+
+// parsearCampoCron devuelve los valores permitidos del campo como un conjunto de bits.
+func parsearCampoCron(campo string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, parte := range strings.Split(campo, ",") {
+		rango, pasoTexto, tienePaso := strings.Cut(parte, "/")
+		paso := 1
+		if tienePaso {
+			var err error
+			paso, err = strconv.Atoi(pasoTexto)
+			if err != nil || paso <= 0 {
+				return 0, fmt.Errorf("paso %q inválido", pasoTexto)
+			}
+		}
+
+		desde, hasta := min, max
+		if rango != "*" {
+			inicio, fin, esRango := strings.Cut(rango, "-")
+			var err error
+			if desde, err = strconv.Atoi(inicio); err != nil {
+				return 0, fmt.Errorf("valor %q inválido", inicio)
+			}
+			hasta = desde
+			if esRango {
+				if hasta, err = strconv.Atoi(fin); err != nil {
+					return 0, fmt.Errorf("valor %q inválido", fin)
+				}
+			} else if tienePaso {
+				// a/n significa desde a hasta el máximo cada n
+				hasta = max
+			}
+		}
+		if desde < min || hasta > max || desde > hasta {
+			return 0, fmt.Errorf("%q fuera del rango %d-%d", parte, min, max)
+		}
+		for valor := desde; valor <= hasta; valor += paso {
+			bits |= 1 << uint(valor)
+		}
+	}
+	return bits, nil
+}
+
+// Siguiente devuelve el primer minuto posterior a desde en el que corresponde ejecutar, en la zona de desde.
+// Si la expresión no coincide con ninguna fecha en los próximos cinco años (por ejemplo 30 de febrero)
+// devuelve el tiempo cero.
+func (c ExpresionCron) Siguiente(desde time.Time) time.Time {
+	t := desde.Truncate(time.Minute).Add(time.Minute)
+	limite := t.AddDate(5, 0, 0)
+	for t.Before(limite) {
+		switch {
+		case c.meses&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.coincideDia(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.horas&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minutos&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c ExpresionCron) coincideDia(t time.Time) bool {
+	dia := c.dias&(1<<uint(t.Day())) != 0
+	semana := c.diasSemana&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.diaRestringido && c.semanaRestringida:
+		return dia || semana
+	case c.diaRestringido:
+		return dia
+	case c.semanaRestringida:
+		return semana
+	}
+	return true
+}
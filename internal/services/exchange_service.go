@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
+	"time"
+)
+
+type ExchangeService struct {
+	repoExchange repositories.ExchangeRepository
+	repoCripto   repositories.CryptoRepository
+	fuente       cotizadores.ExchangesCotizador
+}
+
+// NewExchangeService crea el servicio de cotizaciones por exchange usando fuente para consultar al proveedor.
+func NewExchangeService(repoExchange repositories.ExchangeRepository, repoCripto repositories.CryptoRepository, fuente cotizadores.ExchangesCotizador) *ExchangeService {
+	return &ExchangeService{
+		repoExchange: repoExchange,
+		repoCripto:   repoCripto,
+		fuente:       fuente,
+	}
+}
+
+func (s *ExchangeService) FindAllExchanges() ([]criptomonedas.Exchange, error) {
+	return s.repoExchange.FindAllExchanges()
+}
+
+// GuardarCotizacionesExchanges consulta al proveedor y guarda una fila por cada exchange que informó precio.
+// Los precios con comisiones son los de operar el volumen, VolumenPorDefecto si viene en cero.
+func (s *ExchangeService) GuardarCotizacionesExchanges(ctx context.Context, nombreMoneda, fiat string, volumen float64) ([]criptomonedas.CotizacionExchange, error) {
+	if err := ValidarVolumen(volumen); err != nil {
+		return nil, err
+	}
+	if volumen == 0 {
+		volumen = cotizadores.VolumenPorDefecto
+	}
+	cripto, err := s.repoCripto.FindCryptoByName(nombreMoneda)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar la criptomoneda %s en la base de datos", nombreMoneda)
+	}
+	if cripto == nil {
+		return nil, fmt.Errorf("la criptomoneda %s no está registrada en la base de datos", nombreMoneda)
+	}
+
+	exchanges, err := s.fuente.GetExchanges(ctx, cripto.Codigo, fiat, volumen)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudieron obtener las cotizaciones por exchange para moneda %s: %w", nombreMoneda, err)
+	}
+
+	cotizaciones := CotizacionesDesdeExchanges(cripto.Id, fiat, volumen, exchanges, time.Now())
+	if len(cotizaciones) == 0 {
+		return nil, fmt.Errorf("ningún exchange informó cotización para moneda %s", nombreMoneda)
+	}
+	if err := s.repoExchange.SaveCotizacionesExchange(cotizaciones); err != nil {
+		return nil, fmt.Errorf("no se pudieron guardar las cotizaciones por exchange para moneda %s", nombreMoneda)
+	}
+	return cotizaciones, nil
+}
+
+func (s *ExchangeService) FindCotizacionesExchange(filter criptomonedas.CotizacionExchangeFilter) ([]criptomonedas.CotizacionExchange, error) {
+	return s.repoExchange.FindCotizacionesExchange(filter)
+}
+
+// CotizacionesDesdeExchanges arma las filas a guardar a partir de la respuesta del proveedor,
+// descartando los exchanges que no informaron ningún precio.
+func CotizacionesDesdeExchanges(criptoId int, fiat string, volumen float64, exchanges map[string]cotizadores.Exchange, fecha time.Time) []criptomonedas.CotizacionExchange {
+	var cotizaciones []criptomonedas.CotizacionExchange
+	for nombre, exchange := range exchanges {
+		if exchange.Ask <= 0 && exchange.Bid <= 0 && exchange.TotalAsk <= 0 && exchange.TotalBid <= 0 {
+			continue
+		}
+		cotizaciones = append(cotizaciones, criptomonedas.CotizacionExchange{
+			CriptoMoneda_ID: criptoId,
+			Exchange:        nombre,
+			Fiat:            fiat,
+			Ask:             exchange.Ask,
+			TotalAsk:        exchange.TotalAsk,
+			Bid:             exchange.Bid,
+			TotalBid:        exchange.TotalBid,
+			Volumen:         volumen,
+			FechaProveedor:  time.Unix(exchange.Time, 0).UTC(),
+			Fecha:           fecha,
+		})
+	}
+	// orden estable para que las inserciones y las respuestas sean reproducibles
+	sort.Slice(cotizaciones, func(i, j int) bool { return cotizaciones[i].Exchange < cotizaciones[j].Exchange })
+	return cotizaciones
+}
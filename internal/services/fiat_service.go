@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+)
+
+// FiatPuente es la moneda por la que se cruzan dos fiats que el proveedor no cotiza entre sí.
+const FiatPuente = "USD"
+
+type FiatService struct {
+	repoFiat   repositories.FiatRepository
+	repoCripto repositories.CryptoRepository
+	proveedor  cotizadores.CotizadorFiat
+}
+
+// NewFiatService crea el servicio de monedas fiat usando proveedor para los tipos de cambio.
+func NewFiatService(repoFiat repositories.FiatRepository, repoCripto repositories.CryptoRepository, proveedor cotizadores.CotizadorFiat) *FiatService {
+	return &FiatService{
+		repoFiat:   repoFiat,
+		repoCripto: repoCripto,
+		proveedor:  proveedor,
+	}
+}
+
+func (s *FiatService) FindAllFiats() ([]criptomonedas.MonedaFiat, error) {
+	return s.repoFiat.FindAllFiats()
+}
+
+// GetTasaFiat devuelve cuánto vale una unidad de base en destino. Si el proveedor no cotiza el par
+// y ninguna de las dos es FiatPuente, se cruza por FiatPuente con el mismo tipo de cambio.
+func (s *FiatService) GetTasaFiat(ctx context.Context, base, destino, tipo string) (cotizadores.TasaFiat, error) {
+	base, destino = strings.ToUpper(base), strings.ToUpper(destino)
+	for _, codigo := range []string{base, destino} {
+		fiat, err := s.repoFiat.FindFiatByCodigo(codigo)
+		if err != nil {
+			return cotizadores.TasaFiat{}, fmt.Errorf("error al buscar la moneda fiat %s en la base de datos", codigo)
+		}
+		if fiat == nil {
+			return cotizadores.TasaFiat{}, fmt.Errorf("la moneda fiat %s no está registrada en la base de datos", codigo)
+		}
+	}
+
+	tasa, err := s.proveedor.GetTasaFiat(ctx, base, destino, tipo)
+	if err == nil || base == FiatPuente || destino == FiatPuente {
+		return tasa, err
+	}
+
+	haciaPuente, err := s.proveedor.GetTasaFiat(ctx, base, FiatPuente, tipo)
+	if err != nil {
+		return cotizadores.TasaFiat{}, err
+	}
+	desdePuente, err := s.proveedor.GetTasaFiat(ctx, FiatPuente, destino, tipo)
+	if err != nil {
+		return cotizadores.TasaFiat{}, err
+	}
+	cruzada := cotizadores.TasaFiat{
+		Base:    base,
+		Destino: destino,
+		Tipo:    desdePuente.Tipo,
+		Tasa:    haciaPuente.Tasa * desdePuente.Tasa,
+		Fecha:   haciaPuente.Fecha,
+	}
+	// el tipo lo informa el tramo que lo distingue; el del BCE siempre es oficial
+	if cruzada.Tipo == cotizadores.TipoCambioOficial {
+		cruzada.Tipo = haciaPuente.Tipo
+	}
+	// la tasa cruzada es tan vieja como la más vieja de las dos
+	if desdePuente.Fecha.Before(cruzada.Fecha) {
+		cruzada.Fecha = desdePuente.Fecha
+	}
+	return cruzada, nil
+}
+
+// ConvertirCotizacion expresa una cotización guardada en la fiat destino. Si ya está en esa fiat se
+// devuelve igual y sin tipo de cambio.
+func (s *FiatService) ConvertirCotizacion(ctx context.Context, cotizacion criptomonedas.Cotizacion, destino, tipo string) (criptomonedas.CotizacionConvertida, error) {
+	origen := cotizacion.Fiat
+	if origen == "" {
+		origen = criptomonedas.FiatPorDefecto
+	}
+	destino = strings.ToUpper(destino)
+	convertida := criptomonedas.CotizacionConvertida{
+		Id:                 cotizacion.Id,
+		CriptoMoneda_ID:    cotizacion.CriptoMoneda_ID,
+		Cotizacion:         cotizacion.Cotizacion,
+		Fiat:               origen,
+		CotizacionOriginal: cotizacion.Cotizacion,
+		FiatOriginal:       origen,
+		Tasa:               1,
+		Fecha:              cotizacion.Fecha,
+	}
+	if destino == "" || destino == origen {
+		return convertida, nil
+	}
+
+	tasa, err := s.GetTasaFiat(ctx, origen, destino, tipo)
+	if err != nil {
+		return criptomonedas.CotizacionConvertida{}, err
+	}
+	convertida.Cotizacion = cotizacion.Cotizacion * tasa.Tasa
+	convertida.Fiat = destino
+	convertida.TipoCambio = tasa.Tipo
+	convertida.Tasa = tasa.Tasa
+	convertida.FechaTasa = tasa.Fecha
+	return convertida, nil
+}
+
+// FindUltimaCotizacionConvertida toma la última cotización guardada de la moneda, en cualquier fiat, y la convierte.
+func (s *FiatService) FindUltimaCotizacionConvertida(ctx context.Context, nombre, destino, tipo string) (criptomonedas.CotizacionConvertida, error) {
+	cotizacion, err := s.repoCripto.FindUltimaCotizacion(nombre, "")
+	if err != nil || cotizacion == nil {
+		return criptomonedas.CotizacionConvertida{}, fmt.Errorf("no hay cotizaciones guardadas para la criptomoneda %s", nombre)
+	}
+	return s.ConvertirCotizacion(ctx, *cotizacion, destino, tipo)
+}
+
+// ConvertirCotizacionByID convierte una cotización guardada buscándola por su id.
+func (s *FiatService) ConvertirCotizacionByID(ctx context.Context, id int, destino, tipo string) (criptomonedas.CotizacionConvertida, error) {
+	cotizacion, err := s.repoCripto.FindByCotizacionID(id)
+	if err != nil || cotizacion == nil {
+		return criptomonedas.CotizacionConvertida{}, fmt.Errorf("no se encontró la cotización %d", id)
+	}
+	return s.ConvertirCotizacion(ctx, *cotizacion, destino, tipo)
+}
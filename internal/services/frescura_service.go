@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+	"time"
+)
+
+// ConfiguracionFrescura define cuándo la última cotización de una moneda está vencida y cómo se refresca.
+type ConfiguracionFrescura struct {
+	// Maxima es la antigüedad máxima de la última cotización de las monedas cuya política no indica otra.
+	Maxima time.Duration
+	// Api es el cotizador con el que se refrescan las monedas sin política.
+	Api string
+	// Timeout es el tiempo máximo que se espera al proveedor en un refresco.
+	Timeout time.Duration
+}
+
+// ConfiguracionFrescuraPorDefecto es la configuración que se usa para los valores que no se indican.
+var ConfiguracionFrescuraPorDefecto = ConfiguracionFrescura{
+	Maxima:  15 * time.Minute,
+	Api:     "fallback",
+	Timeout: 30 * time.Second,
+}
+
+// refrescoEnCurso es un pedido al proveedor que comparten las solicitudes de la misma moneda y fiat.
+type refrescoEnCurso struct {
+	listo      chan struct{}
+	cotizacion criptomonedas.Cotizacion
+	err        error
+}
+
+// ConFrescura hace que la frescura máxima y el cotizador de refresco de cada moneda salgan de su política de
+// refresco, y de config para las monedas sin política o sin frescura.
+func (s *CryptoService) ConFrescura(repoPoliticas repositories.PoliticaRefrescoRepository, config ConfiguracionFrescura) *CryptoService {
+	if config.Maxima <= 0 {
+		config.Maxima = ConfiguracionFrescuraPorDefecto.Maxima
+	}
+	if config.Api == "" {
+		config.Api = ConfiguracionFrescuraPorDefecto.Api
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = ConfiguracionFrescuraPorDefecto.Timeout
+	}
+	s.repoPoliticas = repoPoliticas
+	s.frescura = config
+	return s
+}
+
+// FindUltimaCotizacion devuelve la última cotización de la moneda en la fiat pedida, o en cualquier fiat si viene
+// vacía, con su antigüedad y si está vencida. Con refrescar, una cotización vencida se pide al proveedor en el
+// momento para el volumen indicado; si el refresco falla se devuelve la guardada con el motivo. Devuelve nil si la
+// moneda no existe o no tiene cotizaciones y no se pudo refrescar.
+func (s *CryptoService) FindUltimaCotizacion(ctx context.Context, nombre, fiat string, refrescar bool, volumen float64) (*criptomonedas.CotizacionVigente, error) {
+	if err := ValidarVolumen(volumen); err != nil {
+		return nil, err
+	}
+	fiat = strings.ToUpper(strings.TrimSpace(fiat))
+	moneda, err := s.repo.FindCryptoByName(nombre)
+	if err != nil || moneda == nil {
+		return nil, err
+	}
+
+	maxima, api, fiatPolitica := s.frescura.Maxima, s.frescura.Api, ""
+	if s.repoPoliticas != nil {
+		politica, err := s.repoPoliticas.FindPoliticaByMonedaID(moneda.Id)
+		if err != nil {
+			return nil, err
+		}
+		if politica != nil {
+			api, fiatPolitica = politica.Api, politica.Fiat
+			if frescura, err := time.ParseDuration(politica.Frescura); err == nil && frescura > 0 {
+				maxima = frescura
+			}
+		}
+	}
+
+	ultima, err := s.repo.FindUltimaCotizacion(moneda.Nombre, fiat)
+	if err != nil {
+		return nil, err
+	}
+	vigente := cotizacionVigente(ultima, maxima)
+	if !refrescar || !vigente.Vencida {
+		if ultima == nil {
+			return nil, nil
+		}
+		return &vigente, nil
+	}
+
+	// sin fiat pedida se refresca la fiat de la última cotización, o la de la política
+	fiatRefresco := fiat
+	if fiatRefresco == "" && ultima != nil {
+		fiatRefresco = ultima.Fiat
+	}
+	if fiatRefresco == "" {
+		fiatRefresco = fiatPolitica
+	}
+	refrescada, err := s.refrescarCompartido(ctx, moneda, api, NormalizarFiat(fiatRefresco), volumen)
+	if err != nil {
+		if ultima == nil {
+			return nil, fmt.Errorf("la criptomoneda %s no tiene cotizaciones y no se pudo refrescar: %w", moneda.Nombre, err)
+		}
+		vigente.ErrorRefresco = err.Error()
+		return &vigente, nil
+	}
+	vigente = cotizacionVigente(&refrescada, maxima)
+	vigente.Refrescada = true
+	return &vigente, nil
+}
+
+// cotizacionVigente calcula la antigüedad de la cotización desde la fecha del proveedor, o la de guardado si no
+// la informó. Sin cotización está vencida.
+func cotizacionVigente(cotizacion *criptomonedas.Cotizacion, maxima time.Duration) criptomonedas.CotizacionVigente {
+	vigente := criptomonedas.CotizacionVigente{FrescuraMaxima: int64(maxima.Seconds()), Vencida: true}
+	if cotizacion == nil {
+		return vigente
+	}
+	vigente.Cotizacion = *cotizacion
+	fecha := cotizacion.Fecha
+	if cotizacion.FechaProveedor != nil {
+		fecha = *cotizacion.FechaProveedor
+	}
+	antiguedad := time.Since(fecha)
+	if antiguedad < 0 {
+		antiguedad = 0
+	}
+	vigente.Antiguedad = int64(antiguedad.Seconds())
+	vigente.Vencida = antiguedad > maxima
+	return vigente
+}
+
+// refrescarCompartido pide la cotización al proveedor y la guarda. Si ya hay un refresco de la misma moneda, fiat
+// y volumen en curso, espera ese en lugar de hacer otro pedido. El refresco no se corta si se cancela la solicitud
+// que lo empezó, porque puede haber otras esperándolo.
+func (s *CryptoService) refrescarCompartido(ctx context.Context, moneda *criptomonedas.CriptoMoneda, api, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	clave := fmt.Sprintf("%d:%s:%v", moneda.Id, fiat, volumen)
+	s.muRefrescos.Lock()
+	if enCurso, existe := s.refrescos[clave]; existe {
+		s.muRefrescos.Unlock()
+		select {
+		case <-enCurso.listo:
+			return enCurso.cotizacion, enCurso.err
+		case <-ctx.Done():
+			return criptomonedas.Cotizacion{}, ctx.Err()
+		}
+	}
+	enCurso := &refrescoEnCurso{listo: make(chan struct{})}
+	s.refrescos[clave] = enCurso
+	s.muRefrescos.Unlock()
+
+	refresco, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.frescura.Timeout)
+	defer cancel()
+	enCurso.cotizacion, enCurso.err = s.refrescar(refresco, moneda, api, fiat, volumen)
+
+	s.muRefrescos.Lock()
+	delete(s.refrescos, clave)
+	s.muRefrescos.Unlock()
+	close(enCurso.listo)
+	return enCurso.cotizacion, enCurso.err
+}
+
+func (s *CryptoService) refrescar(ctx context.Context, moneda *criptomonedas.CriptoMoneda, api, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+	cotizacion, err := s.GetCotizacionVolumen(ctx, api, moneda.Nombre, fiat, volumen)
+	if err != nil {
+		return cotizacion, err
+	}
+	cotizacion.CriptoMoneda_ID = moneda.Id
+	cotizacion.Fiat = fiat
+	if err := s.SaveCotizacionProveedor(moneda.Nombre, cotizacion); err != nil {
+		return cotizacion, err
+	}
+	return cotizacion, nil
+}
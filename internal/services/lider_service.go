@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	repositories "primerProjecto/internal/adapters/repositories"
+	"sync"
+	"time"
+)
+
+// TrabajoProgramado es un trabajo en segundo plano que solo debe correr en una instancia a la vez,
+// como PollerService.
+type TrabajoProgramado interface {
+	Iniciar(ctx context.Context) error
+	Detener()
+}
+
+// ConfiguracionLiderazgo define el lease que se disputan las instancias y cada cuánto se renueva.
+type ConfiguracionLiderazgo struct {
+	// Nombre identifica el lease. Las instancias con el mismo nombre eligen un único líder.
+	Nombre string
+	// Instancia identifica a esta instancia. Sin valor se usa el hostname y el pid.
+	Instancia string
+	// Duracion es cuánto dura el lease sin renovarse. Si el líder muere, otra instancia toma el
+	// liderazgo a lo sumo Duracion más Renovacion después.
+	Duracion time.Duration
+	// Renovacion es cada cuánto el líder renueva el lease y las demás instancias intentan tomarlo.
+	// Tiene que ser bastante menor que Duracion.
+	Renovacion time.Duration
+}
+
+// ConfiguracionLiderazgoPorDefecto es la configuración que se usa para los valores que no se indican.
+var ConfiguracionLiderazgoPorDefecto = ConfiguracionLiderazgo{
+	Nombre:     "trabajos-programados",
+	Duracion:   30 * time.Second,
+	Renovacion: 10 * time.Second,
+}
+
+// EstadoLiderazgo indica si esta instancia es líder y quién tiene el lease.
+type EstadoLiderazgo struct {
+	Nombre     string     `json:"nombre"`
+	Instancia  string     `json:"instancia"`
+	EsLider    bool       `json:"es_lider"`
+	LiderDesde *time.Time `json:"lider_desde,omitempty"`
+	// Lider es la instancia que tenía el lease en el último intento, vacío si nadie lo tiene.
+	Lider       string     `json:"lider,omitempty"`
+	Vence       *time.Time `json:"vence,omitempty"`
+	UltimoError string     `json:"ultimo_error,omitempty"`
+}
+
+// LiderService elige, mediante un lease en la base de datos, una única instancia que corre los trabajos
+// programados. La instancia que tiene el lease lo renueva cada Renovacion; si no puede renovarlo detiene los
+// trabajos enseguida. Cada renovación tiene un plazo menor que Duracion menos Renovacion, y si la base no
+// responde ni siquiera para cortar la consulta, los trabajos se detienen igual cuando pasa Duracion desde la
+// última renovación confirmada, que es cuando el lease puede pasar a otra instancia.
+type LiderService struct {
+	repo     repositories.LiderazgoRepository
+	config   ConfiguracionLiderazgo
+	trabajos []TrabajoProgramado
+
+	mu          sync.Mutex
+	esLider     bool
+	liderDesde  *time.Time
+	lider       string
+	vence       *time.Time
+	ultimoError string
+	// renovado es cuándo empezó la última renovación confirmada. El lease vence a lo sumo Duracion después.
+	renovado    time.Time
+	vigilancia  *time.Timer
+	ctxTrabajos context.Context
+	cancelar    context.CancelFunc
+	terminado   chan struct{}
+}
+
+func NewLiderService(repo repositories.LiderazgoRepository, config ConfiguracionLiderazgo, trabajos ...TrabajoProgramado) *LiderService {
+	if config.Nombre == "" {
+		config.Nombre = ConfiguracionLiderazgoPorDefecto.Nombre
+	}
+	if config.Instancia == "" {
+		config.Instancia = instanciaPorDefecto()
+	}
+	if config.Duracion <= 0 {
+		config.Duracion = ConfiguracionLiderazgoPorDefecto.Duracion
+	}
+	if config.Renovacion <= 0 {
+		config.Renovacion = ConfiguracionLiderazgoPorDefecto.Renovacion
+	}
+	return &LiderService{repo: repo, config: config, trabajos: trabajos}
+}
+
+func instanciaPorDefecto() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "instancia"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Iniciar empieza a disputar el liderazgo en segundo plano. El primer intento es inmediato.
+func (s *LiderService) Iniciar(ctx context.Context) error {
+	if s.config.Renovacion >= s.config.Duracion {
+		return fmt.Errorf("la renovación del liderazgo (%s) debe ser menor que la duración del lease (%s)", s.config.Renovacion, s.config.Duracion)
+	}
+
+	s.mu.Lock()
+	if s.cancelar != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("la elección de líder ya está iniciada")
+	}
+	ctx, cancelar := context.WithCancel(ctx)
+	s.cancelar = cancelar
+	s.ctxTrabajos = ctx
+	s.terminado = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.terminado)
+		s.ciclo(ctx)
+	}()
+	return nil
+}
+
+// Detener deja de disputar el liderazgo, detiene los trabajos y, si era líder, libera el lease para que
+// otra instancia lo tome sin esperar a que venza.
+func (s *LiderService) Detener() {
+	s.mu.Lock()
+	cancelar, terminado := s.cancelar, s.terminado
+	s.mu.Unlock()
+	if cancelar == nil {
+		return
+	}
+	cancelar()
+	<-terminado
+
+	s.mu.Lock()
+	s.cancelar = nil
+	s.mu.Unlock()
+}
+
+func (s *LiderService) ciclo(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Renovacion)
+	defer ticker.Stop()
+	for {
+		s.Intentar()
+
+		select {
+		case <-ctx.Done():
+			s.detenerVigilancia()
+			if s.EsLider() {
+				s.renunciar()
+				if err := s.repo.LiberarLiderazgo(s.config.Nombre, s.config.Instancia); err != nil {
+					log.Println("Error al liberar el liderazgo:", err)
+				}
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Intentar toma o renueva el lease una vez. Si la instancia pasa a ser líder inicia los trabajos, y si
+// deja de serlo, o no se pudo confirmar que lo sigue siendo, los detiene.
+func (s *LiderService) Intentar() {
+	inicio := time.Now()
+	ctx, cancelar := context.WithTimeout(context.Background(), s.plazoRenovacion())
+	defer cancelar()
+	liderazgo, err := s.repo.AdquirirLiderazgo(ctx, s.config.Nombre, s.config.Instancia, s.config.Duracion)
+	esLider := err == nil && liderazgo.Instancia == s.config.Instancia
+
+	s.mu.Lock()
+	if esLider {
+		s.renovado = inicio
+	}
+	if err != nil {
+		s.ultimoError = err.Error()
+	} else {
+		s.ultimoError = ""
+		s.lider = liderazgo.Instancia
+		s.vence = nil
+		if !liderazgo.Vence.IsZero() {
+			vence := liderazgo.Vence
+			s.vence = &vence
+		}
+	}
+	eraLider := s.esLider
+	s.mu.Unlock()
+
+	if esLider {
+		s.vigilar(inicio)
+	}
+	switch {
+	case esLider && !eraLider:
+		s.asumir()
+	case !esLider && eraLider:
+		if err != nil {
+			log.Printf("No se pudo renovar el liderazgo de %s: %s", s.config.Nombre, err)
+		}
+		s.renunciar()
+	}
+}
+
+// plazoRenovacion es cuánto puede tardar una renovación. Es la mitad del margen entre la renovación y el
+// vencimiento, así una renovación cortada por el plazo todavía deja detener los trabajos a tiempo.
+func (s *LiderService) plazoRenovacion() time.Duration {
+	plazo := (s.config.Duracion - s.config.Renovacion) / 2
+	if plazo <= 0 {
+		plazo = s.config.Duracion / 2
+	}
+	return plazo
+}
+
+// vigilar programa la detención de los trabajos para cuando venza el lease renovado en inicio. Cada renovación
+// confirmada la vuelve a programar, así solo se dispara si las renovaciones dejan de volver, aunque la consulta
+// siga colgada.
+func (s *LiderService) vigilar(inicio time.Time) {
+	espera := s.config.Duracion - time.Since(inicio)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vigilancia != nil {
+		s.vigilancia.Stop()
+	}
+	s.vigilancia = time.AfterFunc(espera, s.vencer)
+}
+
+func (s *LiderService) detenerVigilancia() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vigilancia != nil {
+		s.vigilancia.Stop()
+		s.vigilancia = nil
+	}
+}
+
+// vencer detiene los trabajos si pasó Duracion desde la última renovación confirmada.
+func (s *LiderService) vencer() {
+	s.mu.Lock()
+	vencido := s.esLider && time.Since(s.renovado) >= s.config.Duracion
+	s.mu.Unlock()
+	if vencido {
+		log.Printf("El lease de %s venció sin poder renovarse", s.config.Nombre)
+		s.renunciar()
+	}
+}
+
+func (s *LiderService) asumir() {
+	ahora := time.Now()
+	s.mu.Lock()
+	s.esLider = true
+	s.liderDesde = &ahora
+	ctx := s.ctxTrabajos
+	s.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	log.Printf("La instancia %s es líder de %s", s.config.Instancia, s.config.Nombre)
+	for _, trabajo := range s.trabajos {
+		if err := trabajo.Iniciar(ctx); err != nil {
+			log.Println("Error al iniciar un trabajo programado:", err)
+		}
+	}
+}
+
+// renunciar detiene los trabajos. Puede llamarse a la vez desde Intentar y desde la vigilancia del lease; solo
+// la primera los detiene.
+func (s *LiderService) renunciar() {
+	s.mu.Lock()
+	if !s.esLider {
+		s.mu.Unlock()
+		return
+	}
+	s.esLider = false
+	s.liderDesde = nil
+	s.mu.Unlock()
+
+	log.Printf("La instancia %s deja de ser líder de %s", s.config.Instancia, s.config.Nombre)
+	for _, trabajo := range s.trabajos {
+		trabajo.Detener()
+	}
+}
+
+// EsLider indica si esta instancia está corriendo los trabajos programados.
+func (s *LiderService) EsLider() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.esLider
+}
+
+// Estado devuelve si esta instancia es líder y quién tenía el lease en el último intento.
+func (s *LiderService) Estado() EstadoLiderazgo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return EstadoLiderazgo{
+		Nombre:      s.config.Nombre,
+		Instancia:   s.config.Instancia,
+		EsLider:     s.esLider,
+		LiderDesde:  s.liderDesde,
+		Lider:       s.lider,
+		Vence:       s.vence,
+		UltimoError: s.ultimoError,
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"strings"
+	"time"
+)
+
+// ErrPoliticaInvalida envuelve los errores de validación de una política de refresco.
+var ErrPoliticaInvalida = errors.New("política de refresco inválida")
+
+type PoliticaRefrescoService struct {
+	repo         repositories.PoliticaRefrescoRepository
+	repoCripto   repositories.CryptoRepository
+	getCotizador func(name string) (cotizadores.Cotizador, error)
+}
+
+func NewPoliticaRefrescoService(repo repositories.PoliticaRefrescoRepository, repoCripto repositories.CryptoRepository, getCotizador func(name string) (cotizadores.Cotizador, error)) *PoliticaRefrescoService {
+	return &PoliticaRefrescoService{repo: repo, repoCripto: repoCripto, getCotizador: getCotizador}
+}
+
+func (s *PoliticaRefrescoService) FindAllPoliticas() ([]criptomonedas.PoliticaRefresco, error) {
+	return s.repo.FindAllPoliticas()
+}
+
+// FindPoliticaByMonedaID devuelve nil si la moneda no tiene política.
+func (s *PoliticaRefrescoService) FindPoliticaByMonedaID(criptoId int) (*criptomonedas.PoliticaRefresco, error) {
+	return s.repo.FindPoliticaByMonedaID(criptoId)
+}
+
+// SavePolitica valida la política y la guarda, reemplazando la que tuviera la moneda. Sin api se usa el
+// cotizador fallback, sin fiat FiatPorDefecto y sin frescura la frescura global.
+func (s *PoliticaRefrescoService) SavePolitica(politica criptomonedas.PoliticaRefresco) (criptomonedas.PoliticaRefresco, error) {
+	if _, err := ParsearCron(politica.Cron); err != nil {
+		return politica, fmt.Errorf("%w: %v", ErrPoliticaInvalida, err)
+	}
+	politica.Api = strings.TrimSpace(politica.Api)
+	if politica.Api == "" {
+		politica.Api = ConfiguracionPollerPorDefecto.Api
+	}
+	if _, err := s.getCotizador(politica.Api); err != nil {
+		return politica, fmt.Errorf("%w: %v", ErrPoliticaInvalida, err)
+	}
+	politica.Fiat = NormalizarFiat(politica.Fiat)
+	politica.Frescura = strings.TrimSpace(politica.Frescura)
+	if politica.Frescura != "" {
+		if frescura, err := time.ParseDuration(politica.Frescura); err != nil || frescura <= 0 {
+			return politica, fmt.Errorf("%w: frescura %q inválida, se espera una duración como 30m", ErrPoliticaInvalida, politica.Frescura)
+		}
+	}
+
+	moneda, err := s.repoCripto.FindByMonedaID(politica.CriptoMoneda_ID)
+	if err != nil || moneda == nil {
+		return politica, fmt.Errorf("%w: la criptomoneda %d no está registrada en la base de datos", ErrPoliticaInvalida, politica.CriptoMoneda_ID)
+	}
+	politica.Moneda = moneda.Nombre
+	politica.Actualizada = time.Now()
+
+	if err := s.repo.SavePolitica(politica); err != nil {
+		return politica, err
+	}
+	return politica, nil
+}
+
+// DeletePolitica borra la política de la moneda y la devuelve, o devuelve nil si no tenía.
+// Sin política la moneda vuelve a refrescarse con el intervalo global.
+func (s *PoliticaRefrescoService) DeletePolitica(criptoId int) (*criptomonedas.PoliticaRefresco, error) {
+	politica, err := s.repo.FindPoliticaByMonedaID(criptoId)
+	if err != nil || politica == nil {
+		return nil, err
+	}
+	if err := s.repo.DeletePolitica(criptoId); err != nil {
+		return nil, err
+	}
+	return politica, nil
+}
@@ -0,0 +1,521 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	repositories "primerProjecto/internal/adapters/repositories"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Monedas que refresca el poller en cada corrida.
+const (
+	// AlcanceTodas refresca todas las monedas registradas.
+	AlcanceTodas = "todas"
+	// AlcanceSeguidas refresca solo las monedas que sigue algún usuario.
+	AlcanceSeguidas = "seguidas"
+)
+
+// ConfiguracionPoller define cada cuánto y cómo se refrescan las cotizaciones en segundo plano.
+type ConfiguracionPoller struct {
+	// Intervalo es el tiempo entre el inicio de una corrida y la siguiente.
+	Intervalo time.Duration
+	// Alcance es AlcanceTodas o AlcanceSeguidas. Vale también para las monedas con política de refresco.
+	Alcance string
+	// Api es el cotizador que se consulta, con las mismas opciones que acepta GetCotizador.
+	Api string
+	// Fiat es la moneda en la que se guardan las cotizaciones.
+	Fiat string
+	// Trabajadores es la cantidad máxima de monedas que se cotizan a la vez.
+	Trabajadores int
+	// Jitter es el retraso aleatorio máximo antes de cotizar cada moneda, para no pegarle al proveedor en ráfaga.
+	Jitter time.Duration
+	// TimeoutMoneda es el tiempo máximo para cotizar y guardar una moneda.
+	TimeoutMoneda time.Duration
+	// ResolucionPoliticas es cada cuánto se revisa qué políticas de refresco tienen que correr.
+	ResolucionPoliticas time.Duration
+	// Desactivado indica que el poller no corre, ni por intervalo ni por políticas, y no se espera que
+	// refresque ninguna moneda.
+	Desactivado bool
+}
+
+// ConfiguracionPollerPorDefecto es la configuración que se usa para los valores que no se indican.
+var ConfiguracionPollerPorDefecto = ConfiguracionPoller{
+	Intervalo:           5 * time.Minute,
+	Alcance:             AlcanceTodas,
+	Api:                 "fallback",
+	Fiat:                criptomonedas.FiatPorDefecto,
+	Trabajadores:        4,
+	Jitter:              5 * time.Second,
+	TimeoutMoneda:       30 * time.Second,
+	ResolucionPoliticas: time.Minute,
+}
+
+// EstadoMonedaPoller es el resultado de las corridas del poller para una moneda.
+type EstadoMonedaPoller struct {
+	Moneda             string     `json:"moneda"`
+	Api                string     `json:"api"`
+	Fiat               string     `json:"fiat"`
+	Seguidores         int        `json:"seguidores"`
+	UltimoExito        *time.Time `json:"ultimo_exito,omitempty"`
+	UltimoIntento      time.Time  `json:"ultimo_intento"`
+	UltimoError        string     `json:"ultimo_error,omitempty"`
+	FallasConsecutivas int        `json:"fallas_consecutivas"`
+	// UltimaPostergacion es la última vez que no se refrescó por falta de cupo diario del proveedor.
+	UltimaPostergacion *time.Time `json:"ultima_postergacion,omitempty"`
+}
+
+// EstadoPoller resume la configuración del poller, sus corridas y el estado de cada moneda.
+type EstadoPoller struct {
+	Activo         bool                 `json:"activo"`
+	Intervalo      string               `json:"intervalo"`
+	Alcance        string               `json:"alcance"`
+	Api            string               `json:"api"`
+	Fiat           string               `json:"fiat"`
+	Corridas       int64                `json:"corridas"`
+	UltimaCorrida  *time.Time           `json:"ultima_corrida,omitempty"`
+	ProximaCorrida *time.Time           `json:"proxima_corrida,omitempty"`
+	Monedas        []EstadoMonedaPoller `json:"monedas"`
+}
+
+// PollerService refresca periódicamente las cotizaciones de las monedas registradas o seguidas. Las monedas
+// con política de refresco siguen su propia expresión cron, cotizador y fiat en lugar del intervalo global.
+// Cuando a un proveedor no le alcanza el cupo diario se refrescan primero las monedas con más seguidores.
+type PollerService struct {
+	cripto        *CryptoService
+	repo          repositories.CryptoRepository
+	repoUsuario   repositories.UsuarioRepository
+	repoPoliticas repositories.PoliticaRefrescoRepository
+	config        ConfiguracionPoller
+
+	mu             sync.Mutex
+	estados        map[string]*EstadoMonedaPoller
+	corridas       int64
+	ultimaCorrida  *time.Time
+	proximaCorrida *time.Time
+	cancelar       context.CancelFunc
+	terminado      chan struct{}
+}
+
+func NewPollerService(cripto *CryptoService, repo repositories.CryptoRepository, repoUsuario repositories.UsuarioRepository, repoPoliticas repositories.PoliticaRefrescoRepository, config ConfiguracionPoller) *PollerService {
+	if config.Intervalo <= 0 {
+		config.Intervalo = ConfiguracionPollerPorDefecto.Intervalo
+	}
+	if config.Alcance == "" {
+		config.Alcance = ConfiguracionPollerPorDefecto.Alcance
+	}
+	if config.Api == "" {
+		config.Api = ConfiguracionPollerPorDefecto.Api
+	}
+	if config.Trabajadores <= 0 {
+		config.Trabajadores = ConfiguracionPollerPorDefecto.Trabajadores
+	}
+	if config.Jitter < 0 {
+		config.Jitter = 0
+	}
+	if config.TimeoutMoneda <= 0 {
+		config.TimeoutMoneda = ConfiguracionPollerPorDefecto.TimeoutMoneda
+	}
+	if config.ResolucionPoliticas <= 0 {
+		config.ResolucionPoliticas = ConfiguracionPollerPorDefecto.ResolucionPoliticas
+	}
+	config.Fiat = NormalizarFiat(config.Fiat)
+	return &PollerService{
+		cripto:        cripto,
+		repo:          repo,
+		repoUsuario:   repoUsuario,
+		repoPoliticas: repoPoliticas,
+		config:        config,
+		estados:       make(map[string]*EstadoMonedaPoller),
+	}
+}
+
+// Iniciar arranca el poller en segundo plano. La primera corrida es inmediata y las siguientes
+// cada Intervalo; si una corrida se demora más que el intervalo, la siguiente empieza al terminar.
+func (s *PollerService) Iniciar(ctx context.Context) error {
+	if err := s.Validar(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.cancelar != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("el poller ya está iniciado")
+	}
+	ctx, cancelar := context.WithCancel(ctx)
+	s.cancelar = cancelar
+	s.terminado = make(chan struct{})
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.ciclo(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		s.cicloPoliticas(ctx)
+	}()
+	go func() {
+		wg.Wait()
+		close(s.terminado)
+	}()
+	return nil
+}
+
+// Validar revisa la configuración sin iniciar el poller, para fallar al arrancar aunque la instancia no sea líder.
+func (s *PollerService) Validar() error {
+	if s.config.Alcance != AlcanceTodas && s.config.Alcance != AlcanceSeguidas {
+		return fmt.Errorf("alcance %s no soportado, debe ser %s o %s", s.config.Alcance, AlcanceTodas, AlcanceSeguidas)
+	}
+	return nil
+}
+
+// Detener cancela la corrida en curso y espera a que terminen los trabajadores.
+func (s *PollerService) Detener() {
+	s.mu.Lock()
+	cancelar, terminado := s.cancelar, s.terminado
+	s.mu.Unlock()
+	if cancelar == nil {
+		return
+	}
+	cancelar()
+	<-terminado
+
+	s.mu.Lock()
+	s.cancelar = nil
+	s.proximaCorrida = nil
+	s.mu.Unlock()
+}
+
+func (s *PollerService) ciclo(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Intervalo)
+	defer ticker.Stop()
+	for {
+		s.Correr(ctx)
+
+		proxima := time.Now().Add(s.config.Intervalo)
+		s.mu.Lock()
+		s.proximaCorrida = &proxima
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cicloPoliticas revisa cada ResolucionPoliticas qué políticas tenían una ejecución desde la revisión anterior.
+func (s *PollerService) cicloPoliticas(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ResolucionPoliticas)
+	defer ticker.Stop()
+	ultimaRevision := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ahora := <-ticker.C:
+			s.CorrerPoliticas(ctx, ultimaRevision, ahora)
+			ultimaRevision = ahora
+		}
+	}
+}
+
+// tareaRefresco es una moneda a refrescar con el cotizador y la fiat que le corresponden.
+type tareaRefresco struct {
+	moneda     *criptomonedas.CriptoMoneda
+	api        string
+	fiat       string
+	seguidores int
+}
+
+// Correr refresca una vez todas las monedas del alcance configurado que no tienen política de refresco,
+// con un pool de Trabajadores. Los errores de cada moneda quedan en su estado y no cortan la corrida.
+func (s *PollerService) Correr(ctx context.Context) {
+	monedas, err := s.monedas()
+	if err != nil {
+		log.Println("Error al obtener las monedas a refrescar:", err)
+		return
+	}
+	politicas, err := s.repoPoliticas.FindAllPoliticas()
+	if err != nil {
+		log.Println("Error al obtener las políticas de refresco:", err)
+		return
+	}
+	conPolitica := make(map[int]bool, len(politicas))
+	for _, politica := range politicas {
+		conPolitica[politica.CriptoMoneda_ID] = true
+	}
+
+	var tareas []tareaRefresco
+	for _, moneda := range monedas {
+		if !conPolitica[moneda.Id] {
+			tareas = append(tareas, tareaRefresco{moneda: moneda, api: s.config.Api, fiat: s.config.Fiat})
+		}
+	}
+	s.ejecutar(ctx, s.priorizar(tareas))
+	if ctx.Err() != nil {
+		// una corrida cortada por el apagado no cuenta como última corrida
+		return
+	}
+
+	ahora := time.Now()
+	s.mu.Lock()
+	s.corridas++
+	s.ultimaCorrida = &ahora
+	s.mu.Unlock()
+}
+
+// CorrerPoliticas refresca las monedas con política habilitada cuya expresión cron tuvo una ejecución
+// después de desde y hasta hasta inclusive. Con AlcanceSeguidas se saltean las que no sigue nadie.
+func (s *PollerService) CorrerPoliticas(ctx context.Context, desde, hasta time.Time) {
+	politicas, err := s.repoPoliticas.FindAllPoliticas()
+	if err != nil {
+		log.Println("Error al obtener las políticas de refresco:", err)
+		return
+	}
+	var seguidas map[int]bool
+	if s.config.Alcance == AlcanceSeguidas {
+		monedas, err := s.repo.FindMonedasSeguidas()
+		if err != nil {
+			log.Println("Error al obtener las monedas a refrescar:", err)
+			return
+		}
+		seguidas = make(map[int]bool, len(monedas))
+		for _, moneda := range monedas {
+			seguidas[moneda.Id] = true
+		}
+	}
+
+	var tareas []tareaRefresco
+	for _, politica := range politicas {
+		if !politica.Habilitada || (seguidas != nil && !seguidas[politica.CriptoMoneda_ID]) {
+			continue
+		}
+		cron, err := ParsearCron(politica.Cron)
+		if err != nil {
+			log.Printf("Política de refresco de %s inválida: %s", politica.Moneda, err)
+			continue
+		}
+		if siguiente := cron.Siguiente(desde); siguiente.IsZero() || siguiente.After(hasta) {
+			continue
+		}
+		tareas = append(tareas, tareaRefresco{
+			moneda: &criptomonedas.CriptoMoneda{Id: politica.CriptoMoneda_ID, Nombre: politica.Moneda},
+			api:    politica.Api,
+			fiat:   NormalizarFiat(politica.Fiat),
+		})
+	}
+	s.ejecutar(ctx, s.priorizar(tareas))
+}
+
+// priorizar ordena las tareas de más a menos seguidores y, para cada proveedor con presupuesto diario,
+// posterga las que exceden el cupo que le queda hoy.
+func (s *PollerService) priorizar(tareas []tareaRefresco) []tareaRefresco {
+	for i := range tareas {
+		usuarios, err := s.repoUsuario.FindUsuariosByMonedaID(tareas[i].moneda.Id)
+		if err != nil {
+			log.Println("Error al contar los seguidores de", tareas[i].moneda.Nombre, ":", err)
+			continue
+		}
+		tareas[i].seguidores = len(usuarios)
+	}
+	sort.SliceStable(tareas, func(i, j int) bool {
+		if tareas[i].seguidores != tareas[j].seguidores {
+			return tareas[i].seguidores > tareas[j].seguidores
+		}
+		return tareas[i].moneda.Nombre < tareas[j].moneda.Nombre
+	})
+
+	cupos := make(map[string]int)
+	priorizadas := make([]tareaRefresco, 0, len(tareas))
+	for _, tarea := range tareas {
+		if !consumirCupo(cupos, tarea.api) {
+			s.postergar(tarea)
+			continue
+		}
+		priorizadas = append(priorizadas, tarea)
+	}
+	return priorizadas
+}
+
+// consumirCupo descuenta de cupos el pedido que hará una tarea con la api indicada y devuelve false si no
+// queda cupo. Un fallback usa el primero de sus proveedores que tenga cupo; un agregado consulta todas sus
+// fuentes, así que necesita cupo en cada una. cupos guarda lo que le queda hoy a cada proveedor, con -1 para
+// los que no tienen tope.
+func consumirCupo(cupos map[string]int, api string) bool {
+	cupoDe := func(proveedor string) int {
+		cupo, conCupo := cupos[proveedor]
+		if !conCupo {
+			var limitado bool
+			if cupo, limitado = cotizadores.CupoDiarioRestante(proveedor); !limitado {
+				cupo = -1
+			}
+			cupos[proveedor] = cupo
+		}
+		return cupo
+	}
+	descontar := func(proveedor string) {
+		if cupos[proveedor] > 0 {
+			cupos[proveedor]--
+		}
+	}
+
+	proveedores, alcanzaUno := cotizadores.ProveedoresConsultados(api)
+	if alcanzaUno {
+		for _, proveedor := range proveedores {
+			if cupoDe(proveedor) != 0 {
+				descontar(proveedor)
+				return true
+			}
+		}
+		return false
+	}
+	for _, proveedor := range proveedores {
+		if cupoDe(proveedor) == 0 {
+			return false
+		}
+	}
+	for _, proveedor := range proveedores {
+		descontar(proveedor)
+	}
+	return true
+}
+
+// ejecutar reparte las tareas entre Trabajadores y espera a que terminen o a que se cancele el contexto.
+func (s *PollerService) ejecutar(ctx context.Context, tareas []tareaRefresco) {
+	trabajos := make(chan tareaRefresco)
+	var wg sync.WaitGroup
+	for i := 0; i < s.config.Trabajadores; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tarea := range trabajos {
+				s.refrescar(ctx, tarea)
+			}
+		}()
+	}
+
+encolar:
+	for _, tarea := range tareas {
+		select {
+		case trabajos <- tarea:
+		case <-ctx.Done():
+			break encolar
+		}
+	}
+	close(trabajos)
+	wg.Wait()
+}
+
+func (s *PollerService) monedas() ([]*criptomonedas.CriptoMoneda, error) {
+	if s.config.Alcance == AlcanceSeguidas {
+		return s.repo.FindMonedasSeguidas()
+	}
+	return s.repo.FindAllMonedas()
+}
+
+// refrescar cotiza y guarda una moneda, después de esperar un retraso aleatorio de hasta Jitter.
+func (s *PollerService) refrescar(ctx context.Context, tarea tareaRefresco) {
+	if s.config.Jitter > 0 {
+		espera := time.NewTimer(time.Duration(rand.Int64N(int64(s.config.Jitter))))
+		select {
+		case <-ctx.Done():
+			espera.Stop()
+			return
+		case <-espera.C:
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.TimeoutMoneda)
+	defer cancel()
+
+	err := s.guardar(ctx, tarea)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		// el apagado no cuenta como falla de la moneda, solo el timeout
+		return
+	}
+	s.registrar(tarea, err)
+}
+
+func (s *PollerService) guardar(ctx context.Context, tarea tareaRefresco) error {
+	cotizacion, err := s.cripto.GetCotizacion(ctx, tarea.api, tarea.moneda.Nombre, tarea.fiat)
+	if err != nil {
+		return err
+	}
+	cotizacion.CriptoMoneda_ID = tarea.moneda.Id
+	cotizacion.Fiat = tarea.fiat
+	return s.cripto.SaveCotizacionProveedor(tarea.moneda.Nombre, cotizacion)
+}
+
+// estadoDe devuelve el estado de la moneda de la tarea, creándolo si hace falta. Se llama con mu tomado.
+func (s *PollerService) estadoDe(tarea tareaRefresco) *EstadoMonedaPoller {
+	estado, existe := s.estados[tarea.moneda.Nombre]
+	if !existe {
+		estado = &EstadoMonedaPoller{Moneda: tarea.moneda.Nombre}
+		s.estados[tarea.moneda.Nombre] = estado
+	}
+	estado.Api = tarea.api
+	estado.Fiat = tarea.fiat
+	estado.Seguidores = tarea.seguidores
+	return estado
+}
+
+func (s *PollerService) registrar(tarea tareaRefresco, err error) {
+	ahora := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	estado := s.estadoDe(tarea)
+	estado.UltimoIntento = ahora
+	if err != nil {
+		estado.UltimoError = err.Error()
+		estado.FallasConsecutivas++
+		log.Printf("Error al refrescar la cotización de %s: %s", tarea.moneda.Nombre, err)
+		return
+	}
+	estado.UltimoExito = &ahora
+	estado.UltimoError = ""
+	estado.FallasConsecutivas = 0
+}
+
+// postergar registra que la moneda no se refrescó por falta de cupo. No cuenta como falla.
+func (s *PollerService) postergar(tarea tareaRefresco) {
+	ahora := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.estadoDe(tarea).UltimaPostergacion = &ahora
+	log.Printf("Refresco de %s postergado: %s no tiene cupo diario", tarea.moneda.Nombre, tarea.api)
+}
+
+// Estado devuelve la configuración, las corridas y el estado de cada moneda ordenado por nombre.
+func (s *PollerService) Estado() EstadoPoller {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	estado := EstadoPoller{
+		Activo:         s.cancelar != nil,
+		Intervalo:      s.config.Intervalo.String(),
+		Alcance:        s.config.Alcance,
+		Api:            s.config.Api,
+		Fiat:           s.config.Fiat,
+		Corridas:       s.corridas,
+		UltimaCorrida:  s.ultimaCorrida,
+		ProximaCorrida: s.proximaCorrida,
+		Monedas:        make([]EstadoMonedaPoller, 0, len(s.estados)),
+	}
+	for _, moneda := range s.estados {
+		estado.Monedas = append(estado.Monedas, *moneda)
+	}
+	sort.Slice(estado.Monedas, func(i, j int) bool { return estado.Monedas[i].Moneda < estado.Monedas[j].Moneda })
+	return estado
+}
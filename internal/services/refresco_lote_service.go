@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	cotizadores "primerProjecto/internal/adapters/cotizadores"
+	criptomonedas "primerProjecto/internal/entities/criptomonedas"
+	"slices"
+	"strings"
+)
+
+// ErrRefrescoInvalido envuelve los errores de validación de un pedido de refresco de varias monedas.
+var ErrRefrescoInvalido = errors.New("pedido de refresco inválido")
+
+// ApiRefrescoPorDefecto es el cotizador que se usa para refrescar varias monedas cuando no se indica uno.
+const ApiRefrescoPorDefecto = "coinpaprika"
+
+// PedidoRefresco indica qué monedas refrescar, con qué cotizador y en qué fiat.
+type PedidoRefresco struct {
+	// Monedas son los nombres de las monedas. Vacío refresca todas las registradas.
+	Monedas []string `json:"monedas"`
+	// Api es el cotizador. Si cotiza varias monedas en una consulta se hace una sola.
+	Api string `json:"api"`
+	// Fiat es la moneda en la que se guardan las cotizaciones, USD si viene vacía.
+	Fiat string `json:"fiat"`
+}
+
+// ResultadoRefresco es cómo terminó el refresco de una moneda.
+type ResultadoRefresco struct {
+	Moneda     string                    `json:"moneda"`
+	Cotizacion *criptomonedas.Cotizacion `json:"cotizacion,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+}
+
+// ReporteRefresco resume el refresco de varias monedas.
+type ReporteRefresco struct {
+	Api  string `json:"api"`
+	Fiat string `json:"fiat"`
+	// Lote indica que todas las monedas se cotizaron en una sola consulta al proveedor.
+	Lote       bool                `json:"lote"`
+	Guardadas  int                 `json:"guardadas"`
+	Fallidas   int                 `json:"fallidas"`
+	Resultados []ResultadoRefresco `json:"resultados"`
+}
+
+// ConCotizadorBatch hace que RefrescarCotizaciones use una sola consulta con los cotizadores que lo permiten.
+func (s *CryptoService) ConCotizadorBatch(getBatch func(name string) (cotizadores.BatchCotizador, error)) *CryptoService {
+	s.getBatch = getBatch
+	return s
+}
+
+// RefrescarCotizaciones cotiza varias monedas y guarda las cotizaciones obtenidas en una transacción. Si el
+// cotizador implementa BatchCotizador se hace una sola consulta; si no, una por moneda. El reporte indica
+// qué pasó con cada moneda; una moneda desconocida, sin cotización o en cuarentena no impide guardar las demás.
+func (s *CryptoService) RefrescarCotizaciones(ctx context.Context, pedido PedidoRefresco) (ReporteRefresco, error) {
+	reporte := ReporteRefresco{Api: strings.TrimSpace(pedido.Api), Fiat: NormalizarFiat(pedido.Fiat)}
+	if reporte.Api == "" {
+		reporte.Api = ApiRefrescoPorDefecto
+	}
+	cotizador, err := s.getCotizador(reporte.Api)
+	if err != nil {
+		return reporte, fmt.Errorf("%w: %v", ErrRefrescoInvalido, err)
+	}
+
+	monedas, orden, resultados, err := s.monedasARefrescar(pedido.Monedas)
+	if err != nil {
+		return reporte, err
+	}
+
+	cotizaciones := make(map[string]criptomonedas.Cotizacion, len(monedas))
+	errores := make(map[string]error)
+	if batch, lote := s.cotizadorBatch(reporte.Api); lote && len(monedas) > 0 {
+		reporte.Lote = true
+		obtenidas, err := batch.GetCotizacionesExternas(ctx, monedas, reporte.Fiat)
+		for _, moneda := range monedas {
+			if err != nil {
+				errores[moneda.Nombre] = err
+			} else if cotizacion, ok := obtenidas[moneda.Nombre]; ok {
+				cotizaciones[moneda.Nombre] = cotizacion
+			} else {
+				errores[moneda.Nombre] = fmt.Errorf("%s no informó la cotización de %s en %s", reporte.Api, moneda.Nombre, reporte.Fiat)
+			}
+		}
+	} else {
+		for _, moneda := range monedas {
+			cotizacion, err := cotizador.GetCotizacionExterna(ctx, moneda.Nombre, moneda.Codigo, reporte.Fiat, 0)
+			if err != nil {
+				errores[moneda.Nombre] = err
+				continue
+			}
+			cotizaciones[moneda.Nombre] = cotizacion
+		}
+	}
+
+	var guardar []criptomonedas.Cotizacion
+	for _, moneda := range monedas {
+		cotizacion, ok := cotizaciones[moneda.Nombre]
+		if ok {
+			cotizacion.CriptoMoneda_ID = moneda.Id
+			cotizacion.Fiat = reporte.Fiat
+			if cotizacion.Fuente == "" {
+				cotizacion.Fuente, _, _ = strings.Cut(reporte.Api, ":")
+			}
+			if s.anomalias != nil {
+				if err := s.anomalias.Filtrar(moneda.Nombre, cotizacion); err != nil {
+					errores[moneda.Nombre] = err
+					ok = false
+				}
+			}
+		}
+		resultado := ResultadoRefresco{Moneda: moneda.Nombre}
+		if ok {
+			guardar = append(guardar, cotizacion)
+			resultado.Cotizacion = &cotizacion
+		} else {
+			resultado.Error = errores[moneda.Nombre].Error()
+		}
+		resultados[moneda.Nombre] = resultado
+	}
+
+	if len(guardar) > 0 {
+		if err := s.repo.SaveCotizaciones(guardar); err != nil {
+			return reporte, fmt.Errorf("no se pudieron guardar las cotizaciones: %w", err)
+		}
+	}
+
+	for _, nombre := range orden {
+		resultado := resultados[nombre]
+		if resultado.Error == "" {
+			reporte.Guardadas++
+		} else {
+			reporte.Fallidas++
+		}
+		reporte.Resultados = append(reporte.Resultados, resultado)
+	}
+	return reporte, nil
+}
+
+// monedasARefrescar busca las monedas pedidas, o todas si no se pidió ninguna, sin repetir. Devuelve también
+// los nombres de los resultados en el orden pedido; las monedas que no están registradas ya vuelven con su
+// resultado fallido.
+func (s *CryptoService) monedasARefrescar(nombres []string) ([]criptomonedas.CriptoMoneda, []string, map[string]ResultadoRefresco, error) {
+	resultados := make(map[string]ResultadoRefresco)
+	var monedas []criptomonedas.CriptoMoneda
+	var orden []string
+	if len(nombres) == 0 {
+		todas, err := s.repo.FindAllMonedas()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, moneda := range todas {
+			monedas = append(monedas, *moneda)
+			orden = append(orden, moneda.Nombre)
+		}
+		return monedas, orden, resultados, nil
+	}
+
+	for _, nombre := range nombres {
+		nombre = strings.TrimSpace(nombre)
+		if nombre == "" {
+			continue
+		}
+		moneda, err := s.repo.FindCryptoByName(nombre)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if moneda == nil {
+			moneda = &criptomonedas.CriptoMoneda{Nombre: nombre}
+			resultados[nombre] = ResultadoRefresco{Moneda: nombre, Error: fmt.Sprintf("la criptomoneda %s no está registrada en la base de datos", nombre)}
+		} else if _, repetida := resultados[moneda.Nombre]; !repetida {
+			resultados[moneda.Nombre] = ResultadoRefresco{Moneda: moneda.Nombre}
+			monedas = append(monedas, *moneda)
+		}
+		if !slices.Contains(orden, moneda.Nombre) {
+			orden = append(orden, moneda.Nombre)
+		}
+	}
+	if len(orden) == 0 {
+		return nil, nil, nil, fmt.Errorf("%w: no se indicó ninguna moneda", ErrRefrescoInvalido)
+	}
+	return monedas, orden, resultados, nil
+}
+
+func (s *CryptoService) cotizadorBatch(api string) (cotizadores.BatchCotizador, bool) {
+	if s.getBatch == nil {
+		return nil, false
+	}
+	batch, err := s.getBatch(api)
+	return batch, err == nil
+}
@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// historiaAnomalias son las últimas cotizaciones de Bitcoin, de la más nueva a la más vieja, alrededor de 65000.
+func historiaAnomalias() []criptomonedas.Cotizacion {
+	precios := []float64{65100, 64900, 65050, 64950, 65000, 65200, 64800, 65100, 64900, 65000}
+	cotizaciones := make([]criptomonedas.Cotizacion, len(precios))
+	for i, precio := range precios {
+		cotizaciones[i] = criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: precio, Fiat: "USD", Fuente: "coinpaprika"}
+	}
+	return cotizaciones
+}
+
+func TestAnomalias_Evaluar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCuarentena := mockRepo.NewMockCuarentenaRepository(ctrl)
+	repoCripto.EXPECT().FindCotizacionesRecientes(1, "USD", gomock.Any()).Return(historiaAnomalias(), nil).Times(3)
+	repoCripto.EXPECT().FindCotizacionesRecientes(2, "USD", gomock.Any()).Return(nil, nil)
+	// las dos sospechosas buscan si las pendientes confirman el movimiento
+	repoCuarentena.EXPECT().FindAllCuarentena(criptomonedas.CuarentenaPendiente).Return(nil, nil).Times(2)
+	service := services.NewAnomaliasService(repoCuarentena, repoCripto, services.ConfiguracionAnomaliasPorDefecto)
+	cotizacion := func(criptoId int, precio float64) criptomonedas.Cotizacion {
+		return criptomonedas.Cotizacion{CriptoMoneda_ID: criptoId, Cotizacion: precio, Fiat: "USD", Fuente: "coinpaprika"}
+	}
+
+	// un movimiento normal se acepta
+	cuarentena, err := service.Evaluar("Bitcoin", cotizacion(1, 65300))
+	assert.Nil(t, err)
+	assert.Nil(t, cuarentena)
+
+	// un precio con un cero de más salta más del 20%
+	cuarentena, err = service.Evaluar("Bitcoin", cotizacion(1, 651000))
+	assert.Nil(t, err)
+	if assert.NotNil(t, cuarentena) {
+		assert.Equal(t, criptomonedas.CuarentenaPendiente, cuarentena.Estado)
+		assert.Equal(t, 65100.0, cuarentena.Referencia)
+		assert.InDelta(t, 900, cuarentena.Salto, 0.01)
+		assert.Contains(t, cuarentena.Motivo, "salto")
+	}
+
+	// un 5% no supera el salto pero sí el z-score de una serie tan quieta
+	cuarentena, err = service.Evaluar("Bitcoin", cotizacion(1, 68250))
+	assert.Nil(t, err)
+	if assert.NotNil(t, cuarentena) {
+		assert.Greater(t, cuarentena.ZScore, 8.0)
+		assert.NotContains(t, cuarentena.Motivo, "salto")
+	}
+
+	// sin historia no hay con qué comparar
+	cuarentena, err = service.Evaluar("Ethereum", cotizacion(2, 1))
+	assert.Nil(t, err)
+	assert.Nil(t, cuarentena)
+}
+
+func TestAnomalias_UmbralPorMoneda(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	config := services.ConfiguracionAnomaliasPorDefecto
+	config.PorMoneda = map[string]services.UmbralAnomalia{"bitcoin": {SaltoMaximo: 50}}
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCuarentena := mockRepo.NewMockCuarentenaRepository(ctrl)
+	repoCripto.EXPECT().FindCotizacionesRecientes(1, "USD", gomock.Any()).Return(historiaAnomalias(), nil).Times(2)
+	repoCuarentena.EXPECT().FindAllCuarentena(criptomonedas.CuarentenaPendiente).Return(nil, nil)
+	service := services.NewAnomaliasService(repoCuarentena, repoCripto, config)
+
+	// para Bitcoin solo cuenta un salto de más del 50%, sin z-score
+	cuarentena, err := service.Evaluar("Bitcoin", criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: 80000, Fiat: "USD"})
+	assert.Nil(t, err)
+	assert.Nil(t, cuarentena)
+	cuarentena, err = service.Evaluar("Bitcoin", criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: 100000, Fiat: "USD"})
+	assert.Nil(t, err)
+	assert.NotNil(t, cuarentena)
+}
+
+func TestAnomalias_MovimientoSostenidoSeAcepta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCuarentena := mockRepo.NewMockCuarentenaRepository(ctrl)
+	repoCripto.EXPECT().FindCotizacionesRecientes(1, "USD", gomock.Any()).Return(historiaAnomalias(), nil).AnyTimes()
+	pendiente := func(criptoId int, precio float64) criptomonedas.CotizacionCuarentena {
+		return criptomonedas.CotizacionCuarentena{CriptoMoneda_ID: criptoId, Cotizacion: precio, Fiat: "USD", Estado: criptomonedas.CuarentenaPendiente}
+	}
+	// Bitcoin subió a 90000 y las últimas cotizaciones quedaron en cuarentena contra la referencia de 65100
+	repoCuarentena.EXPECT().FindAllCuarentena(criptomonedas.CuarentenaPendiente).Return([]criptomonedas.CotizacionCuarentena{
+		pendiente(1, 90200), pendiente(2, 3000), pendiente(1, 89800), pendiente(1, 90000),
+	}, nil).Times(3)
+	service := services.NewAnomaliasService(repoCuarentena, repoCripto, services.ConfiguracionAnomaliasPorDefecto)
+	cotizacion := func(precio float64) criptomonedas.Cotizacion {
+		return criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: precio, Fiat: "USD", Fuente: "coinpaprika"}
+	}
+
+	// tres pendientes a menos del 2% confirman el movimiento
+	cuarentena, err := service.Evaluar("Bitcoin", cotizacion(90500))
+	assert.Nil(t, err)
+	assert.Nil(t, cuarentena)
+
+	// un precio que no coincide con las pendientes sigue yendo a cuarentena
+	cuarentena, err = service.Evaluar("Bitcoin", cotizacion(130000))
+	assert.Nil(t, err)
+	assert.NotNil(t, cuarentena)
+
+	// con más confirmaciones que pendientes no alcanza
+	config := services.ConfiguracionAnomaliasPorDefecto
+	config.Confirmaciones = 4
+	cuarentena, err = services.NewAnomaliasService(repoCuarentena, repoCripto, config).Evaluar("Bitcoin", cotizacion(90500))
+	assert.Nil(t, err)
+	assert.NotNil(t, cuarentena)
+}
+
+func TestAnomalias_GuardarCotizacionExternaEnCuarentena(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCuarentena := mockRepo.NewMockCuarentenaRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, nil).AnyTimes()
+	repoCripto.EXPECT().FindCotizacionesRecientes(1, "USD", 30).Return(historiaAnomalias(), nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 651000}, nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 65100}, nil)
+	repoCuarentena.EXPECT().SaveCuarentena(gomock.Any()).DoAndReturn(func(cuarentena criptomonedas.CotizacionCuarentena) (int, error) {
+		assert.Equal(t, 651000.0, cuarentena.Cotizacion)
+		assert.Equal(t, "coinpaprika", cuarentena.Fuente)
+		return 4, nil
+	})
+	repoCuarentena.EXPECT().FindAllCuarentena(criptomonedas.CuarentenaPendiente).Return(nil, nil)
+	// solo la cotización normal llega a cotizaciones
+	repoCripto.EXPECT().SaveCotizacion(criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: 65100, Fiat: "USD", Fuente: "coinpaprika"}).Return(nil)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	anomalias := services.NewAnomaliasService(repoCuarentena, repoCripto, services.ConfiguracionAnomaliasPorDefecto)
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConFiltroAnomalias(anomalias)
+
+	err := cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "coinpaprika", "", 0)
+	assert.ErrorIs(t, err, services.ErrCotizacionEnCuarentena)
+	assert.Contains(t, err.Error(), "cotización 4 de Bitcoin")
+
+	err = cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "coinpaprika", "", 0)
+	assert.Nil(t, err)
+}
+
+func TestSaveMonedaConCotizacion_FiltraConElIdGuardado(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCuarentena := mockRepo.NewMockCuarentenaRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	solana := &criptomonedas.CriptoMoneda{Id: 7, Nombre: "Solana", Codigo: "SOL"}
+	repoCripto.EXPECT().FindCryptoByName("Solana").Return(nil, nil)
+	repoCripto.EXPECT().SaveMoneda(criptomonedas.CriptoMoneda{Nombre: "Solana", Codigo: "SOL"}).Return(nil)
+	// se relee para tener el id que asignó la base, y otra vez al cotizar
+	repoCripto.EXPECT().FindCryptoByName("Solana").Return(solana, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Solana", "SOL", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 180, Fuente: "binance"}, nil)
+	// el filtro mira la historia de la moneda guardada y no la de la moneda 0
+	repoCripto.EXPECT().FindCotizacionesRecientes(7, "USD", gomock.Any()).Return(nil, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).DoAndReturn(func(cotizacion criptomonedas.Cotizacion) error {
+		assert.Equal(t, 7, cotizacion.CriptoMoneda_ID)
+		return nil
+	})
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	anomalias := services.NewAnomaliasService(repoCuarentena, repoCripto, services.ConfiguracionAnomaliasPorDefecto)
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConFiltroAnomalias(anomalias)
+
+	assert.Nil(t, cs.SaveMonedaConCotizacion(context.Background(), "Solana", "SOL", "binance", "", 0))
+}
+
+func TestAnomalias_Resolver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCuarentena := mockRepo.NewMockCuarentenaRepository(ctrl)
+	service := services.NewAnomaliasService(repoCuarentena, mockRepo.NewMockCryptoRepository(ctrl), services.ConfiguracionAnomalias{})
+	revisada := time.Now()
+	repoCuarentena.EXPECT().ResolverCuarentena(1, criptomonedas.CuarentenaAprobada, gomock.Any()).Return(true, nil)
+	repoCuarentena.EXPECT().FindCuarentenaByID(1).Return(&criptomonedas.CotizacionCuarentena{Id: 1, Estado: criptomonedas.CuarentenaAprobada, Revisada: &revisada}, nil).Times(2)
+	repoCuarentena.EXPECT().ResolverCuarentena(1, criptomonedas.CuarentenaRechazada, gomock.Any()).Return(false, nil)
+	repoCuarentena.EXPECT().ResolverCuarentena(9, criptomonedas.CuarentenaRechazada, gomock.Any()).Return(false, nil)
+	repoCuarentena.EXPECT().FindCuarentenaByID(9).Return(nil, nil)
+
+	aprobada, err := service.Aprobar(1)
+	assert.Nil(t, err)
+	assert.Equal(t, criptomonedas.CuarentenaAprobada, aprobada.Estado)
+
+	// una cotización ya revisada no se puede volver a revisar
+	_, err = service.Rechazar(1)
+	assert.ErrorIs(t, err, services.ErrCuarentenaResuelta)
+
+	inexistente, err := service.Rechazar(9)
+	assert.Nil(t, err)
+	assert.Nil(t, inexistente)
+}
+
+func TestParsearUmbralesMonedas(t *testing.T) {
+	umbrales, err := services.ParsearUmbralesMonedas("Tether:1:0, Bitcoin:10:6")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]services.UmbralAnomalia{
+		"Tether":  {SaltoMaximo: 1},
+		"Bitcoin": {SaltoMaximo: 10, ZScoreMaximo: 6},
+	}, umbrales)
+
+	for _, invalido := range []string{"Bitcoin:10", ":1:1", "Bitcoin:x:1", "Bitcoin:1:-2"} {
+		_, err := services.ParsearUmbralesMonedas(invalido)
+		assert.NotNil(t, err, invalido)
+	}
+}
@@ -0,0 +1,291 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCoinPaprikaCotizador_Historico(t *testing.T) {
+	var consulta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/coins":
+			fmt.Fprint(w, `[{"id":"btc-bitcoin","name":"Bitcoin","symbol":"BTC","rank":1,"is_active":true}]`)
+		case "/v1/tickers/btc-bitcoin/historical":
+			consulta = r.URL.RawQuery
+			http.ServeFile(w, r, filepath.Join("testdata", "coinpaprika_historical.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+
+	desde := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hasta := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cotizaciones, err := cotizador.GetCotizacionesHistoricas(context.Background(), "Bitcoin", "BTC", "USD", desde, hasta, cotizadores.ResolucionDiaria)
+	assert.Nil(t, err)
+	assert.Equal(t, "start=2024-01-01T00:00:00Z&end=2024-01-02T00:00:00Z&interval=1d&quote=usd&limit=5000", consulta)
+	// el punto del 3 de enero queda fuera del rango pedido
+	if assert.Len(t, cotizaciones, 2) {
+		assert.Equal(t, 42280.23, cotizaciones[0].Cotizacion)
+		assert.Equal(t, desde, cotizaciones[0].Fecha)
+		assert.Equal(t, "coinpaprika", cotizaciones[0].Fuente)
+		assert.Equal(t, &desde, cotizaciones[0].FechaProveedor)
+		assert.Equal(t, hasta, cotizaciones[1].Fecha)
+	}
+
+	_, err = cotizador.GetCotizacionesHistoricas(context.Background(), "Bitcoin", "BTC", "USD", desde, hasta, "semanal")
+	assert.EqualError(t, err, "resolución semanal no soportada por coinpaprika")
+}
+
+func TestCoinGeckoCotizador_Historico(t *testing.T) {
+	server := servidorFixture(t, map[string]string{
+		"/api/v3/coins/bitcoin/market_chart/range?vs_currency=usd&from=1704067200&to=1704240000": "coingecko_market_chart_range.json",
+	}, http.StatusOK)
+	defer server.Close()
+	cotizador := cotizadores.NewCoinGeckoCotizador(server.Client(), server.URL, time.Second)
+
+	desde := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hasta := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	cotizaciones, err := cotizador.GetCotizacionesHistoricas(context.Background(), "Bitcoin", "BTC", "USD", desde, hasta, cotizadores.ResolucionDiaria)
+	assert.Nil(t, err)
+	// CoinGecko responde puntos horarios para rangos cortos y se conserva el primero de cada día
+	if assert.Len(t, cotizaciones, 3) {
+		assert.Equal(t, 42280.23, cotizaciones[0].Cotizacion)
+		assert.Equal(t, 44179.92, cotizaciones[1].Cotizacion)
+		assert.Equal(t, hasta, cotizaciones[2].Fecha)
+		assert.Equal(t, "coingecko", cotizaciones[2].Fuente)
+	}
+
+	horarias, err := cotizador.GetCotizacionesHistoricas(context.Background(), "Bitcoin", "BTC", "USD", desde, hasta, cotizadores.ResolucionHoraria)
+	assert.Nil(t, err)
+	assert.Len(t, horarias, 5)
+}
+
+func TestGetCotizadorHistorico(t *testing.T) {
+	_, err := cotizadores.GetCotizadorHistorico("coinpaprika")
+	assert.Nil(t, err)
+	_, err = cotizadores.GetCotizadorHistorico("criptoya")
+	assert.EqualError(t, err, "el cotizador criptoya no informa cotizaciones históricas")
+	_, err = cotizadores.GetCotizadorHistorico("inexistente")
+	assert.EqualError(t, err, "cotizador inexistente no soportado")
+}
+
+// repoBackfillEnMemoria hace que el mock del repositorio de backfills guarde los trabajos en un mapa. El mapa
+// se comparte con el test, que lo lee solo cuando no hay un backfill corriendo en segundo plano.
+func repoBackfillEnMemoria(ctrl *gomock.Controller) (*mockRepo.MockBackfillRepository, map[int]*criptomonedas.Backfill) {
+	repo := mockRepo.NewMockBackfillRepository(ctrl)
+	backfills := map[int]*criptomonedas.Backfill{}
+	var mu sync.Mutex
+	repo.EXPECT().SaveBackfill(gomock.Any()).DoAndReturn(func(backfill criptomonedas.Backfill) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		backfill.Id = len(backfills) + 1
+		backfills[backfill.Id] = &backfill
+		return backfill.Id, nil
+	}).AnyTimes()
+	repo.EXPECT().UpdateBackfill(gomock.Any()).DoAndReturn(func(backfill criptomonedas.Backfill) error {
+		mu.Lock()
+		defer mu.Unlock()
+		backfills[backfill.Id] = &backfill
+		return nil
+	}).AnyTimes()
+	repo.EXPECT().FindBackfillByID(gomock.Any()).DoAndReturn(func(id int) (*criptomonedas.Backfill, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		backfill, ok := backfills[id]
+		if !ok {
+			return nil, nil
+		}
+		copia := *backfill
+		return &copia, nil
+	}).AnyTimes()
+	repo.EXPECT().FindBackfillsPendientes().DoAndReturn(func() ([]criptomonedas.Backfill, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var pendientes []criptomonedas.Backfill
+		for id := 1; id <= len(backfills); id++ {
+			if estado := backfills[id].Estado; estado == criptomonedas.BackfillPendiente || estado == criptomonedas.BackfillCorriendo {
+				pendientes = append(pendientes, *backfills[id])
+			}
+		}
+		return pendientes, nil
+	}).AnyTimes()
+	return repo, backfills
+}
+
+func TestBackfillService_CrearBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, backfills := repoBackfillEnMemoria(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, nil).AnyTimes()
+	repoCripto.EXPECT().FindCryptoByName("Inexistente").Return(nil, nil)
+	service := services.NewBackfillService(repo, repoCripto, cotizadores.GetCotizadorHistorico, services.ConfiguracionBackfill{})
+
+	desde := time.Date(2024, 1, 1, 15, 30, 0, 0, time.UTC)
+	hasta := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	backfill, err := service.CrearBackfill(criptomonedas.Backfill{Moneda: "Bitcoin", Desde: desde, Hasta: hasta})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, backfill.Id)
+	assert.Equal(t, 1, backfill.CriptoMoneda_ID)
+	assert.Equal(t, "coinpaprika", backfill.Api)
+	assert.Equal(t, "USD", backfill.Fiat)
+	assert.Equal(t, cotizadores.ResolucionDiaria, backfill.Resolucion)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), backfill.Desde)
+	assert.Equal(t, backfill.Desde, backfill.Cursor)
+	assert.Equal(t, criptomonedas.BackfillPendiente, backfill.Estado)
+
+	// pedir lo mismo otra vez devuelve el backfill que todavía no terminó
+	repetido, err := service.CrearBackfill(criptomonedas.Backfill{Moneda: "Bitcoin", Desde: desde, Hasta: hasta})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, repetido.Id)
+	assert.Len(t, backfills, 1)
+
+	// tampoco un pedido dentro del rango pendiente, aunque hasta no coincida hasta que se trunca al día
+	contenido, err := service.CrearBackfill(criptomonedas.Backfill{Moneda: "Bitcoin", Desde: desde.Add(48 * time.Hour), Hasta: hasta.Add(5 * time.Hour)})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, contenido.Id)
+	assert.Len(t, backfills, 1)
+
+	invalidos := []criptomonedas.Backfill{
+		{Moneda: "Bitcoin", Desde: desde, Hasta: hasta, Api: "criptoya"},
+		{Moneda: "Bitcoin", Desde: desde, Hasta: hasta, Resolucion: "semanal"},
+		{Moneda: "Bitcoin", Desde: hasta, Hasta: desde},
+		{Moneda: "Bitcoin", Hasta: hasta},
+		{Moneda: "Inexistente", Desde: desde, Hasta: hasta},
+		{Desde: desde, Hasta: hasta},
+	}
+	for _, invalido := range invalidos {
+		_, err := service.CrearBackfill(invalido)
+		assert.ErrorIs(t, err, services.ErrBackfillInvalido)
+	}
+}
+
+func TestBackfillService_CorrerYReanudar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, backfills := repoBackfillEnMemoria(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	bitcoin := &criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}
+	repoCripto.EXPECT().FindByMonedaID(1).Return(bitcoin, nil).AnyTimes()
+	historico := mockCotizador.NewMockHistoricalCotizador(ctrl)
+	getHistorico := func(name string) (cotizadores.HistoricalCotizador, error) {
+		return historico, nil
+	}
+	service := services.NewBackfillService(repo, repoCripto, getHistorico, services.ConfiguracionBackfill{PuntosPorConsulta: 3})
+
+	dia := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	diarias := func(desde, hasta time.Time) []criptomonedas.Cotizacion {
+		var cotizaciones []criptomonedas.Cotizacion
+		for fecha := desde; !fecha.After(hasta); fecha = fecha.AddDate(0, 0, 1) {
+			cotizaciones = append(cotizaciones, criptomonedas.Cotizacion{Cotizacion: 100, Fecha: fecha, Fuente: "coinpaprika"})
+		}
+		return cotizaciones
+	}
+	backfill, err := service.CrearBackfill(criptomonedas.Backfill{CriptoMoneda_ID: 1, Desde: dia(1), Hasta: dia(5), Fiat: "ars"})
+	assert.Nil(t, err)
+
+	// el primer tramo se guarda y el segundo falla: queda fallido con el cursor después del primer tramo
+	historico.EXPECT().GetCotizacionesHistoricas(gomock.Any(), "Bitcoin", "BTC", "ARS", dia(1), dia(3), cotizadores.ResolucionDiaria).Return(diarias(dia(1), dia(3)), nil)
+	historico.EXPECT().GetCotizacionesHistoricas(gomock.Any(), "Bitcoin", "BTC", "ARS", dia(4), dia(5), cotizadores.ResolucionDiaria).Return(nil, errors.New("error en la solicitud de cotizaciones históricas: 502 Bad Gateway"))
+	repoCripto.EXPECT().SaveCotizacionesHistoricas(gomock.Any()).DoAndReturn(func(cotizaciones []criptomonedas.Cotizacion) (int, error) {
+		assert.Len(t, cotizaciones, 3)
+		assert.Equal(t, 1, cotizaciones[0].CriptoMoneda_ID)
+		assert.Equal(t, "ARS", cotizaciones[0].Fiat)
+		return 3, nil
+	})
+	_, err = service.CorrerBackfill(context.Background(), backfill.Id)
+	assert.NotNil(t, err)
+	assert.Equal(t, criptomonedas.BackfillFallido, backfills[1].Estado)
+	assert.Equal(t, dia(4), backfills[1].Cursor)
+	assert.Equal(t, 3, backfills[1].Guardadas)
+	assert.Contains(t, backfills[1].Error, "502 Bad Gateway")
+
+	// al reanudar sigue desde el cursor; una cotización ya estaba guardada de antes
+	_, err = service.Reanudar(backfill.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, criptomonedas.BackfillPendiente, backfills[1].Estado)
+	historico.EXPECT().GetCotizacionesHistoricas(gomock.Any(), "Bitcoin", "BTC", "ARS", dia(4), dia(5), cotizadores.ResolucionDiaria).Return(diarias(dia(4), dia(5)), nil)
+	repoCripto.EXPECT().SaveCotizacionesHistoricas(gomock.Any()).Return(1, nil)
+	terminado, err := service.CorrerBackfill(context.Background(), backfill.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, criptomonedas.BackfillCompletado, terminado.Estado)
+	assert.Equal(t, dia(6), terminado.Cursor)
+	assert.Equal(t, 4, terminado.Guardadas)
+	assert.Equal(t, 1, terminado.Omitidas)
+	assert.Empty(t, terminado.Error)
+
+	// un backfill completado no se vuelve a correr ni se puede reanudar
+	_, err = service.CorrerBackfill(context.Background(), backfill.Id)
+	assert.Nil(t, err)
+	_, err = service.Reanudar(backfill.Id)
+	assert.ErrorIs(t, err, services.ErrBackfillInvalido)
+}
+
+func TestBackfillService_CorrePendientesEnSegundoPlano(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, _ := repoBackfillEnMemoria(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCripto.EXPECT().FindByMonedaID(1).Return(&criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, nil).AnyTimes()
+	historico := mockCotizador.NewMockHistoricalCotizador(ctrl)
+	historico.EXPECT().GetCotizacionesHistoricas(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	getHistorico := func(name string) (cotizadores.HistoricalCotizador, error) {
+		return historico, nil
+	}
+	repoCripto.EXPECT().SaveCotizacionesHistoricas(gomock.Any()).Return(0, nil)
+	service := services.NewBackfillService(repo, repoCripto, getHistorico, services.ConfiguracionBackfill{Revision: time.Hour})
+
+	assert.Nil(t, service.Iniciar(context.Background()))
+	defer service.Detener()
+	backfill, err := service.CrearBackfill(criptomonedas.Backfill{CriptoMoneda_ID: 1, Desde: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Hasta: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		estado, _ := service.FindBackfillByID(backfill.Id)
+		return estado.Estado == criptomonedas.BackfillCompletado
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBackfillService_ConLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo, backfills := repoBackfillEnMemoria(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCripto.EXPECT().FindByMonedaID(1).Return(&criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, nil).AnyTimes()
+	repoLease := mockRepo.NewMockLiderazgoRepository(ctrl)
+	historico := mockCotizador.NewMockHistoricalCotizador(ctrl)
+	getHistorico := func(name string) (cotizadores.HistoricalCotizador, error) {
+		return historico, nil
+	}
+	service := services.NewBackfillService(repo, repoCripto, getHistorico, services.ConfiguracionBackfill{Lease: time.Minute}).ConLease(repoLease, "a")
+	backfill, err := service.CrearBackfill(criptomonedas.Backfill{CriptoMoneda_ID: 1, Desde: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Hasta: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)})
+	assert.Nil(t, err)
+
+	// mientras otro proceso tiene el lease no se consulta al proveedor ni cambia el estado
+	repoLease.EXPECT().AdquirirLiderazgo(gomock.Any(), "backfill-1", "a", time.Minute).Return(criptomonedas.Liderazgo{Nombre: "backfill-1", Instancia: "b"}, nil)
+	_, err = service.CorrerBackfill(context.Background(), backfill.Id)
+	assert.ErrorIs(t, err, services.ErrBackfillOcupado)
+	assert.Equal(t, criptomonedas.BackfillPendiente, backfills[1].Estado)
+
+	// con el lease se renueva antes de cada tramo y se libera al terminar
+	repoLease.EXPECT().AdquirirLiderazgo(gomock.Any(), "backfill-1", "a", time.Minute).Return(criptomonedas.Liderazgo{Nombre: "backfill-1", Instancia: "a"}, nil).Times(2)
+	historico.EXPECT().GetCotizacionesHistoricas(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	repoCripto.EXPECT().SaveCotizacionesHistoricas(gomock.Any()).Return(0, nil)
+	repoLease.EXPECT().LiberarLiderazgo("backfill-1", "a").Return(nil)
+	terminado, err := service.CorrerBackfill(context.Background(), backfill.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, criptomonedas.BackfillCompletado, terminado.Estado)
+}
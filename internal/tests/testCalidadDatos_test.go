@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// monedasCalidad son las monedas registradas en las pruebas de huecos.
+var monedasCalidad = []*criptomonedas.CriptoMoneda{
+	{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"},
+	{Id: 2, Nombre: "Ethereum", Codigo: "ETH"},
+	{Id: 3, Nombre: "Monedita", Codigo: "MNT"},
+}
+
+func hora(h, m, s int) time.Time { return time.Date(2024, 7, 29, h, m, s, 0, time.UTC) }
+
+// fechasBitcoin son cada 5 minutos con un hueco entre 10:14 y 10:39.
+var fechasBitcoin = []time.Time{hora(10, 4, 0), hora(10, 9, 0), hora(10, 14, 0), hora(10, 39, 0), hora(10, 44, 0), hora(10, 49, 0), hora(10, 54, 0), hora(10, 59, 0)}
+
+// fechasEthereum siguen una política horaria sin las ejecuciones de las 3 y las 4.
+var fechasEthereum = []time.Time{hora(1, 0, 20), hora(2, 0, 15), hora(5, 0, 10)}
+
+func TestCalidadDatos_HuecosSegunIntervaloDelPoller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	desde := time.Date(2024, 7, 29, 10, 0, 0, 0, time.UTC)
+	hasta := time.Date(2024, 7, 29, 11, 0, 0, 0, time.UTC)
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasCalidad, nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(1, "USD", desde, hasta).Return(fechasBitcoin, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(2, "USD", desde, hasta).Return(nil, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(3, "USD", desde, hasta).Return(nil, nil)
+	service := services.NewCalidadDatosService(repoCripto, repoPoliticas, services.ConfiguracionPoller{Intervalo: 5 * time.Minute}, nil)
+
+	reportes, err := service.BuscarHuecos(services.FiltroHuecos{Desde: &desde, Hasta: &hasta})
+	assert.Nil(t, err)
+	if !assert.Len(t, reportes, 3) {
+		return
+	}
+	bitcoin := reportes[0]
+	assert.Equal(t, "5m0s", bitcoin.Cadencia)
+	assert.Equal(t, "USD", bitcoin.Fiat)
+	assert.Equal(t, 8, bitcoin.Cotizaciones)
+	assert.Equal(t, []services.Hueco{{
+		Desde:     time.Date(2024, 7, 29, 10, 19, 0, 0, time.UTC),
+		Hasta:     time.Date(2024, 7, 29, 10, 39, 0, 0, time.UTC),
+		Faltantes: 4,
+	}}, bitcoin.Huecos)
+
+	// sin ninguna cotización todo el rango es un hueco
+	monedita := reportes[2]
+	assert.Equal(t, 0, monedita.Cotizaciones)
+	assert.Equal(t, 11, monedita.Faltantes)
+	assert.Equal(t, []services.Hueco{{Desde: desde.Add(5 * time.Minute), Hasta: hasta, Faltantes: 11}}, monedita.Huecos)
+}
+
+func TestCalidadDatos_HuecosSegunPolitica(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	desde := time.Date(2024, 7, 29, 0, 30, 0, 0, time.UTC)
+	hasta := time.Date(2024, 7, 29, 6, 30, 0, 0, time.UTC)
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasCalidad, nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return([]criptomonedas.PoliticaRefresco{
+		{CriptoMoneda_ID: 2, Cron: "0 * * * *", Fiat: "ars", Habilitada: true},
+		{CriptoMoneda_ID: 3, Cron: "* * * * *", Habilitada: false},
+	}, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(1, "USD", desde, hasta).Return(nil, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(2, "ARS", desde, hasta).Return(fechasEthereum, nil)
+	service := services.NewCalidadDatosService(repoCripto, repoPoliticas, services.ConfiguracionPoller{Intervalo: 5 * time.Minute}, nil)
+
+	reportes, err := service.BuscarHuecos(services.FiltroHuecos{Desde: &desde, Hasta: &hasta})
+	assert.Nil(t, err)
+	// Monedita tiene la política deshabilitada y no se revisa
+	if !assert.Len(t, reportes, 2) {
+		return
+	}
+	ethereum := reportes[1]
+	assert.Equal(t, "0 * * * *", ethereum.Cadencia)
+	assert.Equal(t, "ARS", ethereum.Fiat)
+	assert.Equal(t, []services.Hueco{{
+		Desde:     time.Date(2024, 7, 29, 3, 0, 0, 0, time.UTC),
+		Hasta:     time.Date(2024, 7, 29, 5, 0, 10, 0, time.UTC),
+		Faltantes: 2,
+	}}, ethereum.Huecos)
+}
+
+func TestCalidadDatos_SoloMonedasQueRefrescaElPoller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	desde := time.Date(2024, 7, 29, 10, 0, 0, 0, time.UTC)
+	hasta := time.Date(2024, 7, 29, 11, 0, 0, 0, time.UTC)
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasCalidad, nil).Times(2)
+	// con AlcanceSeguidas no se revisan Bitcoin ni Monedita, que no sigue nadie
+	repoCripto.EXPECT().FindMonedasSeguidas().Return(monedasCalidad[1:2], nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(2, "USD", desde, hasta).Return(nil, nil)
+	seguidas := services.NewCalidadDatosService(repoCripto, repoPoliticas, services.ConfiguracionPoller{Alcance: services.AlcanceSeguidas}, nil)
+	desactivado := services.NewCalidadDatosService(repoCripto, repoPoliticas, services.ConfiguracionPoller{Desactivado: true}, nil)
+
+	reportes, err := seguidas.BuscarHuecos(services.FiltroHuecos{Desde: &desde, Hasta: &hasta})
+	assert.Nil(t, err)
+	if assert.Len(t, reportes, 1) {
+		assert.Equal(t, "Ethereum", reportes[0].Moneda)
+	}
+
+	// con el poller desactivado no se espera ninguna cotización
+	reportes, err = desactivado.BuscarHuecos(services.FiltroHuecos{Desde: &desde, Hasta: &hasta})
+	assert.Nil(t, err)
+	assert.Empty(t, reportes)
+}
+
+func TestCalidadDatos_RellenarHuecos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	repoBackfill := mockRepo.NewMockBackfillRepository(ctrl)
+	desde := time.Date(2024, 7, 29, 0, 30, 0, 0, time.UTC)
+	hasta := time.Date(2024, 7, 29, 11, 0, 0, 0, time.UTC)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasCalidad[0], nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return([]criptomonedas.PoliticaRefresco{{CriptoMoneda_ID: 2, Cron: "0 * * * *", Habilitada: true}}, nil)
+	repoCripto.EXPECT().FindFechasCotizaciones(1, "USD", desde, hasta).Return(fechasBitcoin, nil)
+	// solo el hueco inicial dura una hora o más y lleva backfill
+	repoCripto.EXPECT().FindByMonedaID(1).Return(monedasCalidad[0], nil)
+	repoBackfill.EXPECT().FindBackfillsPendientes().Return(nil, nil)
+	repoBackfill.EXPECT().SaveBackfill(gomock.Any()).DoAndReturn(func(backfill criptomonedas.Backfill) (int, error) {
+		assert.Equal(t, cotizadores.ResolucionHoraria, backfill.Resolucion)
+		assert.Equal(t, time.Date(2024, 7, 29, 0, 0, 0, 0, time.UTC), backfill.Desde)
+		assert.Equal(t, time.Date(2024, 7, 29, 10, 0, 0, 0, time.UTC), backfill.Hasta)
+		return 1, nil
+	})
+	getHistorico := func(name string) (cotizadores.HistoricalCotizador, error) {
+		return mockCotizador.NewMockHistoricalCotizador(ctrl), nil
+	}
+	serviceBackfill := services.NewBackfillService(repoBackfill, repoCripto, getHistorico, services.ConfiguracionBackfill{})
+	service := services.NewCalidadDatosService(repoCripto, repoPoliticas, services.ConfiguracionPoller{Intervalo: 5 * time.Minute}, serviceBackfill)
+
+	reportes, err := service.RellenarHuecos(services.FiltroHuecos{Nombre: "Bitcoin", Desde: &desde, Hasta: &hasta})
+	assert.Nil(t, err)
+	assert.Len(t, reportes, 1)
+	// Bitcoin no tiene cotizaciones antes de las 10:04 y después tiene un hueco de 20 minutos
+	if assert.Len(t, reportes[0].Huecos, 2) {
+		assert.Equal(t, 1, reportes[0].Huecos[0].BackfillId)
+		assert.Zero(t, reportes[0].Huecos[1].BackfillId)
+	}
+}
+
+func TestCalidadDatos_FiltroInvalido(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoCripto.EXPECT().FindCryptoByName("Inexistente").Return(nil, nil)
+	service := services.NewCalidadDatosService(repoCripto, mockRepo.NewMockPoliticaRefrescoRepository(ctrl), services.ConfiguracionPoller{}, nil)
+	desde := time.Date(2024, 7, 29, 10, 0, 0, 0, time.UTC)
+	hasta := desde.Add(-time.Hour)
+	lejos := desde.Add(-100 * 24 * time.Hour)
+
+	_, err := service.BuscarHuecos(services.FiltroHuecos{Desde: &desde, Hasta: &hasta})
+	assert.ErrorIs(t, err, services.ErrFiltroHuecosInvalido)
+	_, err = service.BuscarHuecos(services.FiltroHuecos{Desde: &lejos, Hasta: &desde})
+	assert.ErrorIs(t, err, services.ErrFiltroHuecosInvalido)
+	_, err = service.BuscarHuecos(services.FiltroHuecos{Nombre: "Inexistente"})
+	assert.ErrorIs(t, err, services.ErrFiltroHuecosInvalido)
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"primerProjecto/internal/adapters/controllers"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSaveCotizacionExterna_ExchangeSoloConCriptoYa(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	var pedidos []string
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		pedidos = append(pedidos, name)
+		return cotizador, nil
+	}
+	router := gin.New()
+	router.POST("/cotization/externa", controllers.NewCryptoController(services.NewCryptoService(repoCripto, getCotizador)).SaveCotizacionExterna)
+	pedir := func(query string) *httptest.ResponseRecorder {
+		respuesta := httptest.NewRecorder()
+		router.ServeHTTP(respuesta, httptest.NewRequest(http.MethodPost, "/cotization/externa?"+query, nil))
+		return respuesta
+	}
+
+	// los demás proveedores no cotizan por exchange y no se consulta ninguno
+	for _, query := range []string{"nombre=Bitcoin&api=coinpaprika&exchange=letsbit", "nombre=Bitcoin&api=fallback&lado=bid"} {
+		respuesta := pedir(query)
+		assert.Equal(t, http.StatusBadRequest, respuesta.Code, query)
+		assert.Contains(t, respuesta.Body.String(), "api=criptoya", query)
+	}
+	assert.Empty(t, pedidos)
+
+	// con exchange y sin api se usa CriptoYa
+	bitcoin := &criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(bitcoin, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 61000, Fuente: "criptoya"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil)
+
+	respuesta := pedir("nombre=Bitcoin&exchange=letsbit&lado=bid")
+	assert.Equal(t, http.StatusOK, respuesta.Code)
+	assert.Equal(t, []string{"criptoya:letsbit:bid"}, pedidos)
+}
@@ -1,8 +1,10 @@
 package tests
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"primerProjecto/internal/adapters/cotizadores"
 	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
 	mockRepo "primerProjecto/internal/adapters/repositories/mock"
@@ -60,7 +62,7 @@ func TestGuardarCotizacionExterna_Succes(t *testing.T) {
 	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
 	cotizador := mockCotizador.NewMockCotizador(ctrl)
 	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Nombre: "A", Codigo: "B"}, nil).Times(1)
-	cotizador.EXPECT().GetCotizacionExterna("A", "B", "USD").Return(criptomonedas.Cotizacion{}, nil).Times(1)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "A", "B", "USD", 0.0).Return(criptomonedas.Cotizacion{}, nil).Times(1)
 	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Nombre: "A", Codigo: "B"}, nil).Times(1)
 	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil)
 	getCotizador := func(name string) (cotizadores.Cotizador, error) {
@@ -71,7 +73,7 @@ func TestGuardarCotizacionExterna_Succes(t *testing.T) {
 	}
 	cs := services.NewCryptoService(repoCripto, getCotizador)
 
-	err := cs.GuardarCotizacionExterna("Bitcoin", "criptoya")
+	err := cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "criptoya", "", 0)
 	assert.Nil(t, err)
 }
 
@@ -86,7 +88,7 @@ func TestGuardarCotizacionExterna_Fail(t *testing.T) {
 		api           string
 	}{
 		{
-			expectedError: fmt.Errorf("no se pudo guardar la cotizacion externa para moneda Bitcoin"),
+			expectedError: fmt.Errorf("no se pudo guardar la cotizacion externa para moneda Bitcoin: el Cotizador criptoLLa no es soportado"),
 			service: func() *services.CryptoService {
 				ctrl := gomock.NewController(t)
 				repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
@@ -105,7 +107,7 @@ func TestGuardarCotizacionExterna_Fail(t *testing.T) {
 				repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
 				cotizador := mockCotizador.NewMockCotizador(ctrl)
 				repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Nombre: "A", Codigo: "B"}, nil).Times(1)
-				cotizador.EXPECT().GetCotizacionExterna("A", "B", "USD").Return(criptomonedas.Cotizacion{}, nil).Times(1)
+				cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "A", "B", "USD", 0.0).Return(criptomonedas.Cotizacion{}, nil).Times(1)
 				repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(nil, errors.New("error al buscar la criptomoneda")).Times(1)
 				getCotizador := func(name string) (cotizadores.Cotizador, error) {
 					if name == "criptoya" {
@@ -128,7 +130,7 @@ func TestGuardarCotizacionExterna_Fail(t *testing.T) {
 				repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
 				cotizador := mockCotizador.NewMockCotizador(ctrl)
 				repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Nombre: "A", Codigo: "B"}, nil).Times(1)
-				cotizador.EXPECT().GetCotizacionExterna("A", "B", "USD").Return(criptomonedas.Cotizacion{}, nil).Times(1)
+				cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "A", "B", "USD", 0.0).Return(criptomonedas.Cotizacion{}, nil).Times(1)
 				repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(nil, nil).Times(1)
 				getCotizador := func(name string) (cotizadores.Cotizador, error) {
 					if name == "criptoya" {
@@ -147,7 +149,7 @@ func TestGuardarCotizacionExterna_Fail(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.service.GuardarCotizacionExterna("Bitcoin", tc.api)
+			err := tc.service.GuardarCotizacionExterna(context.Background(), "Bitcoin", tc.api, "", 0)
 			assertions := assert.New(t)
 			assertions.True(err.Error() == tc.expectedError.Error())
 		})
@@ -159,7 +161,7 @@ func TestGetCotizacion_Succes(t *testing.T) {
 	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
 	cotizador := mockCotizador.NewMockCotizador(ctrl)
 	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Nombre: "A", Codigo: "B"}, nil).Times(1)
-	cotizador.EXPECT().GetCotizacionExterna("A", "B", "USD").Return(criptomonedas.Cotizacion{}, nil).Times(1)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "A", "B", "USD", 0.0).Return(criptomonedas.Cotizacion{}, nil).Times(1)
 	getCotizador := func(name string) (cotizadores.Cotizador, error) {
 		if name == "criptoya" {
 			return cotizador, nil
@@ -167,7 +169,7 @@ func TestGetCotizacion_Succes(t *testing.T) {
 		return nil, fmt.Errorf("cotizador %s no soportado", name)
 	}
 	cs := services.NewCryptoService(repoCripto, getCotizador)
-	cripto, err := cs.GetCotizacion("criptoya", "Bitcoin", "USD")
+	cripto, err := cs.GetCotizacion(context.Background(), "criptoya", "Bitcoin", "USD")
 	assert.Nil(t, err)
 	assert.NotNil(t, cripto)
 }
@@ -242,7 +244,7 @@ func TestGetCotizacion_Fail(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			cotizacion,err := tc.service.GetCotizacion(tc.api,"Bitcoin","USD")
+			cotizacion,err := tc.service.GetCotizacion(context.Background(), tc.api,"Bitcoin","USD")
 			assertions := assert.New(t)
 			assertions.True(err.Error() == tc.expectedError.Error())
 			assertions.True(cotizacion == tc.cotizacion)
@@ -269,3 +271,120 @@ testCases := []struct {
 		Cotizacion:      100,
 		Fecha:           time.Now(),
 	}*/
+
+func TestGuardarCotizacionExterna_Fiat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Bitcoin", Codigo: "BTC"}, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "ARS", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 65000000}, nil).Times(1)
+	repoCripto.EXPECT().SaveCotizacion(criptomonedas.Cotizacion{CriptoMoneda_ID: 7, Cotizacion: 65000000, Fiat: "ARS", Fuente: "criptoya"}).Return(nil)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+
+	err := cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "criptoya", "ars", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "USD", services.NormalizarFiat(" "))
+}
+
+func TestGuardarCotizacionExterna_Volumen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Bitcoin", Codigo: "BTC"}, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "ARS", 2.0).Return(criptomonedas.Cotizacion{
+		Cotizacion: 65000000, Fuente: "criptoya", Exchange: "letsbit", Volumen: 2, TotalAsk: 65500000, TotalBid: 64400000,
+	}, nil)
+	// el volumen y los precios con comisiones se guardan con la cotización
+	repoCripto.EXPECT().SaveCotizacion(criptomonedas.Cotizacion{
+		CriptoMoneda_ID: 7, Cotizacion: 65000000, Fiat: "ARS", Fuente: "criptoya", Exchange: "letsbit", Volumen: 2, TotalAsk: 65500000, TotalBid: 64400000,
+	}).Return(nil)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+
+	err := cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "criptoya:letsbit", "ars", 2)
+	assert.Nil(t, err)
+
+	err = cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "criptoya:letsbit", "ars", -1)
+	assert.ErrorIs(t, err, services.ErrVolumenInvalido)
+	assert.ErrorIs(t, services.ValidarVolumen(math.NaN()), services.ErrVolumenInvalido)
+}
+
+func TestGuardarCotizacionExterna_LimiteExcedido(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	reintento := time.Now().Add(time.Minute)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Bitcoin", Codigo: "BTC"}, nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{},
+		&cotizadores.LimiteExcedidoError{Proveedor: "coinpaprika", Motivo: cotizadores.MotivoPresupuestoDiario, ReintentoEn: reintento})
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+
+	err := cs.GuardarCotizacionExterna(context.Background(), "Bitcoin", "coinpaprika", "", 0)
+
+	// el controlador necesita el error del proveedor para responder 429 con Retry-After
+	var limite *cotizadores.LimiteExcedidoError
+	if assert.ErrorAs(t, err, &limite) {
+		assert.Equal(t, reintento, limite.ReintentoEn)
+	}
+}
+
+func TestSaveMonedaConCotizacion_LimiteExcedido(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	reintento := time.Now().Add(time.Minute)
+	repoCripto.EXPECT().FindCryptoByName("Solana").Return(nil, nil)
+	repoCripto.EXPECT().SaveMoneda(criptomonedas.CriptoMoneda{Nombre: "Solana", Codigo: "SOL"}).Return(nil)
+	repoCripto.EXPECT().FindCryptoByName("Solana").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Solana", Codigo: "SOL"}, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Solana", "SOL", "USD", 0.0).Return(criptomonedas.Cotizacion{},
+		&cotizadores.LimiteExcedidoError{Proveedor: "binance", Motivo: cotizadores.MotivoPresupuestoDiario, ReintentoEn: reintento})
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+
+	err := cs.SaveMonedaConCotizacion(context.Background(), "Solana", "SOL", "binance", "", 0)
+
+	// como en GuardarCotizacionExterna, el controlador necesita el error del proveedor para responder 429
+	var limite *cotizadores.LimiteExcedidoError
+	if assert.ErrorAs(t, err, &limite) {
+		assert.Equal(t, reintento, limite.ReintentoEn)
+	}
+}
+
+func TestGenerateCSV_PorFuente(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	fecha := time.Date(2024, 7, 29, 12, 0, 0, 0, time.UTC)
+	repoCripto.EXPECT().FindAllMonedas().Return([]*criptomonedas.CriptoMoneda{{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, {Id: 2, Nombre: "Ethereum", Codigo: "ETH"}}, nil).Times(2)
+	repoCripto.EXPECT().FindUltimasCotizacionesPorFuente("Bitcoin", "").Return([]criptomonedas.Cotizacion{
+		{Cotizacion: 50000, Fiat: "USD", Fuente: "coinpaprika", FechaProveedor: &fecha},
+		{Cotizacion: 61000000, Fiat: "ARS", Fuente: "criptoya", Exchange: "letsbit"},
+	}, nil).Times(2)
+	repoCripto.EXPECT().FindUltimasCotizacionesPorFuente("Ethereum", "").Return([]criptomonedas.Cotizacion{
+		{Cotizacion: 3000, Fiat: "USD", Fuente: "coinpaprika"},
+	}, nil).Times(2)
+	cs := services.NewCryptoService(repoCripto, nil)
+
+	agrupado, err := cs.GenerateCSV(services.OpcionesCSV{AgruparPorFuente: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "ID,Nombre,Codigo,Cotización,Fiat,Fuente,Exchange,Fecha proveedor\n"+
+		"1,Bitcoin,BTC,50000.00,USD,coinpaprika,,2024-07-29T12:00:00Z\n"+
+		"2,Ethereum,ETH,3000.00,USD,coinpaprika,,\n"+
+		"1,Bitcoin,BTC,61000000.00,ARS,criptoya,letsbit,\n", string(agrupado))
+
+	// una moneda sin cotizaciones de la fuente sale con la cotización en cero
+	filtrado, err := cs.GenerateCSV(services.OpcionesCSV{Fuente: "criptoya"})
+	assert.Nil(t, err)
+	assert.Equal(t, "ID,Nombre,Codigo,Cotización,Fiat,Fuente,Exchange,Fecha proveedor\n"+
+		"1,Bitcoin,BTC,61000000.00,ARS,criptoya,letsbit,\n"+
+		"2,Ethereum,ETH,0.00,,criptoya,,\n", string(filtrado))
+}
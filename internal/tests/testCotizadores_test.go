@@ -0,0 +1,644 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCoinPaprikaCotizador_Succes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/coins":
+			fmt.Fprint(w, `[{"id":"btc-bitcoin","name":"Bitcoin"},{"id":"eth-ethereum","name":"Ethereum"}]`)
+		case "/v1/tickers/btc-bitcoin":
+			fmt.Fprint(w, `{"name":"Bitcoin","quotes":{"USD":{"price":50000.5}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 50000.5, cotizacion.Cotizacion)
+}
+
+func TestCoinPaprikaCotizador_FiatNoUSD(t *testing.T) {
+	server := servidorFixture(t, map[string]string{
+		"/v1/coins":                          "coinpaprika_coins.json",
+		"/v1/tickers/btc-bitcoin?quotes=ARS": "coinpaprika_ticker_ars.json",
+	}, http.StatusOK)
+	defer server.Close()
+
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "ars", 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 62483226.5, cotizacion.Cotizacion)
+	assert.Equal(t, time.Date(2024, 7, 29, 12, 0, 0, 0, time.UTC), *cotizacion.FechaProveedor)
+}
+
+func TestCryptoYaCotizador_Succes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/BTC/USD/0.1" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"satoshitango":{"ask":61000,"totalAsk":61100,"bid":60000,"totalBid":59900,"time":1722254400}}`)
+	}))
+	defer server.Close()
+
+	cotizador := cotizadores.NewCryptoYaCotizador(server.Client(), server.URL, time.Second)
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 61000.0, cotizacion.Cotizacion)
+	assert.Equal(t, cotizadores.VolumenPorDefecto, cotizacion.Volumen)
+}
+
+func TestCryptoYaCotizador_Volumen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// CriptoYa calcula los precios con comisiones para el volumen de la URL
+		switch r.URL.Path {
+		case "/api/BTC/ARS/2":
+			fmt.Fprint(w, `{"letsbit":{"ask":100,"totalAsk":103.5,"bid":90,"totalBid":86.5,"time":1722254400},
+				"fiwind":{"ask":102,"totalAsk":105,"bid":95,"totalBid":92,"time":1722254400},
+				"binancep2p":{"ask":104,"totalAsk":104,"bid":96,"totalBid":96,"time":1722254400}}`)
+		case "/api/BTC/ARS/0.005":
+			fmt.Fprint(w, `{"letsbit":{"ask":100,"totalAsk":101,"bid":90,"totalBid":89,"time":1722254400}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	base := cotizadores.NewCryptoYaCotizador(server.Client(), server.URL, time.Second)
+	letsbit, err := base.ConOpciones("letsbit")
+	assert.Nil(t, err)
+	cotizacion, err := letsbit.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "ARS", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, cotizacion.Cotizacion)
+	assert.Equal(t, 2.0, cotizacion.Volumen)
+	assert.Equal(t, 103.5, cotizacion.TotalAsk)
+	assert.Equal(t, 86.5, cotizacion.TotalBid)
+
+	// los volúmenes chicos no se redondean
+	cotizacion, err = letsbit.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "ARS", 0.005)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.005, cotizacion.Volumen)
+	assert.Equal(t, 101.0, cotizacion.TotalAsk)
+
+	// con la mediana los precios con comisiones también son medianas
+	mediana, err := base.ConOpciones("mediana")
+	assert.Nil(t, err)
+	cotizacion, err = mediana.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "ARS", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 102.0, cotizacion.Cotizacion)
+	assert.Equal(t, 104.0, cotizacion.TotalAsk)
+	assert.Equal(t, 92.0, cotizacion.TotalBid)
+
+	exchanges, err := base.GetExchanges(context.Background(), "BTC", "ARS", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 105.0, exchanges["fiwind"].TotalAsk)
+}
+
+func TestCotizador_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	cotizador := cotizadores.NewCryptoYaCotizador(server.Client(), server.URL, 50*time.Millisecond)
+	inicio := time.Now()
+	_, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(inicio), time.Second)
+}
+
+func TestCotizador_ContextoCancelado(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+	_, err := cotizador.GetCotizacionExterna(ctx, "Bitcoin", "BTC", "USD", 0)
+
+	assert.NotNil(t, err)
+}
+
+const respuestaCriptoYa = `{
+	"satoshitango":{"ask":0,"totalAsk":0,"bid":0,"totalBid":0,"time":1722254400},
+	"letsbit":{"ask":100,"totalAsk":101,"bid":90,"totalBid":89,"time":1722254400},
+	"binancep2p":{"ask":104,"totalAsk":104,"bid":96,"totalBid":96,"time":1722254400},
+	"fiwind":{"ask":102,"totalAsk":103,"bid":95,"totalBid":94,"time":1722254400}
+}`
+
+func TestCryptoYaCotizador_Seleccion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, respuestaCriptoYa)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name     string
+		opciones string
+		esperado float64
+		exchange string
+	}{
+		{name: "exchange con lado por defecto", opciones: "letsbit", esperado: 100, exchange: "letsbit"},
+		{name: "exchange y bid", opciones: "fiwind:bid", esperado: 95, exchange: "fiwind"},
+		{name: "exchange y totalAsk", opciones: "binancep2p:totalAsk", esperado: 104, exchange: "binancep2p"},
+		{name: "mejor ask", opciones: "mejor:ask", esperado: 100, exchange: "letsbit"},
+		{name: "mejor bid", opciones: "mejor:bid", esperado: 96, exchange: "binancep2p"},
+		{name: "mediana totalAsk", opciones: "mediana:totalAsk", esperado: 103},
+		{name: "mediana bid", opciones: "mediana:bid", esperado: 95},
+	}
+
+	base := cotizadores.NewCryptoYaCotizador(server.Client(), server.URL, time.Second)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cotizador, err := base.ConOpciones(tc.opciones)
+			assert.Nil(t, err)
+			cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "ARS", 0)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.esperado, cotizacion.Cotizacion)
+			assert.Equal(t, "criptoya", cotizacion.Fuente)
+			assert.Equal(t, tc.exchange, cotizacion.Exchange)
+			assert.Equal(t, time.Unix(1722254400, 0), *cotizacion.FechaProveedor)
+		})
+	}
+}
+
+func TestCryptoYaCotizador_ExchangeSinPrecio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, respuestaCriptoYa)
+	}))
+	defer server.Close()
+
+	// satoshitango no informa precio y no debe reemplazarse por el de otro exchange
+	cotizador := cotizadores.NewCryptoYaCotizador(server.Client(), server.URL, time.Second)
+	_, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "ARS", 0)
+
+	assert.NotNil(t, err)
+}
+
+func TestGetCotizador_Opciones(t *testing.T) {
+	_, err := cotizadores.GetCotizador("criptoya:letsbit:bid")
+	assert.Nil(t, err)
+
+	_, err = cotizadores.GetCotizador("criptoya:noexiste")
+	assert.NotNil(t, err)
+
+	_, err = cotizadores.GetCotizador("criptoya:letsbit:precio")
+	assert.NotNil(t, err)
+
+	_, err = cotizadores.GetCotizador("coinpaprika:letsbit")
+	assert.NotNil(t, err)
+}
+
+func TestAgregadoCotizador(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	precios := map[string]float64{"fuenteA": 100, "fuenteB": 102, "fuenteC": 150}
+	for nombre, precio := range precios {
+		cotizador := mockCotizador.NewMockCotizador(ctrl)
+		cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: precio}, nil).AnyTimes()
+		cotizadores.CotizadoresMap[nombre] = cotizador
+	}
+	fallida := mockCotizador.NewMockCotizador(ctrl)
+	fallida.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{}, errors.New("error en la solicitud: 500 Internal Server Error")).AnyTimes()
+	cotizadores.CotizadoresMap["fuenteD"] = fallida
+	defer func() {
+		for _, nombre := range []string{"fuenteA", "fuenteB", "fuenteC", "fuenteD"} {
+			delete(cotizadores.CotizadoresMap, nombre)
+		}
+	}()
+
+	agregado := cotizadores.NewAgregadoCotizador([]cotizadores.FuenteAgregada{
+		{Nombre: "fuenteA", Peso: 1},
+		{Nombre: "fuenteB", Peso: 3},
+		{Nombre: "fuenteC", Peso: 1},
+		{Nombre: "fuenteD", Peso: 1},
+	}, cotizadores.MetodoMediana, 0.05)
+
+	reporte, err := agregado.GetCotizacionAgregada(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 101.0, reporte.Precio)
+	assert.True(t, reporte.Fuentes[0].Usada)
+	assert.True(t, reporte.Fuentes[1].Usada)
+	assert.True(t, reporte.Fuentes[2].Descartada)
+	assert.NotEmpty(t, reporte.Fuentes[3].Error)
+
+	ponderado, err := agregado.ConOpciones("ponderado")
+	assert.Nil(t, err)
+	cotizacion, err := ponderado.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 101.5, cotizacion.Cotizacion)
+
+	// sin umbral no se descarta ninguna fuente
+	sinUmbral, err := agregado.ConOpciones("mediana:0")
+	assert.Nil(t, err)
+	cotizacion, err = sinUmbral.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 102.0, cotizacion.Cotizacion)
+}
+
+func TestAgregadoCotizador_DosFuentesNoAlcanzanParaDescartar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	for nombre, precio := range map[string]float64{"fuenteA": 100, "fuenteC": 150} {
+		cotizador := mockCotizador.NewMockCotizador(ctrl)
+		cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 2.0).Return(criptomonedas.Cotizacion{Cotizacion: precio}, nil)
+		cotizadores.CotizadoresMap[nombre] = cotizador
+		defer delete(cotizadores.CotizadoresMap, nombre)
+	}
+	agregado := cotizadores.NewAgregadoCotizador([]cotizadores.FuenteAgregada{{Nombre: "fuenteA"}, {Nombre: "fuenteC"}}, cotizadores.MetodoMediana, 0.05)
+
+	_, err := agregado.GetCotizacionAgregada(context.Background(), "Bitcoin", "BTC", "USD", 2)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "al menos 3")
+	}
+}
+
+func TestFallbackCotizador_CircuitBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	caido := mockCotizador.NewMockCotizador(ctrl)
+	respaldo := mockCotizador.NewMockCotizador(ctrl)
+	cotizadores.CotizadoresMap["caido"] = caido
+	cotizadores.CotizadoresMap["respaldo"] = respaldo
+	defer delete(cotizadores.CotizadoresMap, "caido")
+	defer delete(cotizadores.CotizadoresMap, "respaldo")
+	cotizadores.ConfigurarBreaker("caido", cotizadores.ConfiguracionBreaker{UmbralFallas: 2, Espera: 50 * time.Millisecond})
+
+	// las dos primeras consultas fallan en el proveedor caído y abren el circuito
+	caido.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{}, errors.New("error en la solicitud: 503")).Times(2)
+	respaldo.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100}, nil).Times(3)
+
+	fallback, err := cotizadores.GetCotizador("fallback:caido,respaldo")
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		cotizacion, err := fallback.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 100.0, cotizacion.Cotizacion)
+	}
+
+	estado := estadoCircuito(t, "caido")
+	assert.Equal(t, cotizadores.CircuitoAbierto, estado.Estado)
+	assert.Equal(t, 2, estado.FallasConsecutivas)
+
+	// pasada la espera se deja pasar una prueba y, si responde, el circuito se cierra
+	time.Sleep(60 * time.Millisecond)
+	caido.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 101}, nil).Times(1)
+	cotizacion, err := fallback.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 101.0, cotizacion.Cotizacion)
+	assert.Equal(t, cotizadores.CircuitoCerrado, estadoCircuito(t, "caido").Estado)
+}
+
+func TestCircuitBreaker_PruebaFallida(t *testing.T) {
+	breaker := cotizadores.NewCircuitBreaker("test", cotizadores.ConfiguracionBreaker{UmbralFallas: 1, Espera: 20 * time.Millisecond})
+
+	assert.Nil(t, breaker.Permitir())
+	breaker.RegistrarFalla(errors.New("timeout"))
+	var abierto *cotizadores.CircuitoAbiertoError
+	assert.ErrorAs(t, breaker.Permitir(), &abierto)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Nil(t, breaker.Permitir())
+	assert.Equal(t, cotizadores.CircuitoSemiAbierto, breaker.Estado().Estado)
+	// mientras la prueba está en curso no pasan otras consultas
+	assert.NotNil(t, breaker.Permitir())
+
+	breaker.RegistrarFalla(errors.New("timeout"))
+	assert.Equal(t, cotizadores.CircuitoAbierto, breaker.Estado().Estado)
+}
+
+func TestEstadosCircuitos_IncluyeProveedoresSinConsultar(t *testing.T) {
+	for _, proveedor := range []string{"coinpaprika", "criptoya", "coingecko", "binance", "kraken"} {
+		assert.Equal(t, proveedor, estadoCircuito(t, proveedor).Proveedor)
+	}
+	// los compuestos no tienen circuito propio
+	for _, estado := range cotizadores.EstadosCircuitos() {
+		assert.NotContains(t, []string{"fallback", "agregado"}, estado.Proveedor)
+	}
+}
+
+func estadoCircuito(t *testing.T, proveedor string) cotizadores.EstadoCircuito {
+	for _, estado := range cotizadores.EstadosCircuitos() {
+		if estado.Proveedor == proveedor {
+			return estado
+		}
+	}
+	t.Fatalf("no hay circuito para %s", proveedor)
+	return cotizadores.EstadoCircuito{}
+}
+
+func servidorCoinPaprika(t *testing.T, descargas *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/coins":
+			atomic.AddInt32(descargas, 1)
+			time.Sleep(20 * time.Millisecond)
+			fmt.Fprint(w, `[
+				{"id":"btc-bitcoin","name":"Bitcoin","symbol":"BTC","rank":1,"is_active":true},
+				{"id":"btc-fake","name":"Fake Bitcoin","symbol":"BTC","rank":900,"is_active":true},
+				{"id":"eth-ethereum","name":"Ethereum","symbol":"ETH","rank":2,"is_active":true}
+			]`)
+		case "/v1/tickers/btc-bitcoin":
+			fmt.Fprint(w, `{"name":"Bitcoin","quotes":{"USD":{"price":50000}}}`)
+		case "/v1/tickers/eth-ethereum":
+			fmt.Fprint(w, `{"name":"Ethereum","quotes":{"USD":{"price":3000}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCoinPaprikaCotizador_IndiceEnMemoria(t *testing.T) {
+	var descargas int32
+	server := servidorCoinPaprika(t, &descargas)
+	defer server.Close()
+
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+
+	// varias consultas en paralelo comparten una sola descarga de la lista
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// búsqueda por símbolo cuando el nombre registrado no coincide con el de CoinPaprika
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Ether", "ETH", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3000.0, cotizacion.Cotizacion)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&descargas))
+}
+
+func TestCoinPaprikaCotizador_Snapshot(t *testing.T) {
+	var descargas int32
+	server := servidorCoinPaprika(t, &descargas)
+	defer server.Close()
+	ruta := filepath.Join(t.TempDir(), "coinpaprika.json")
+
+	primero := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+	primero.ConfigurarIndice(time.Hour, ruta)
+	_, err := primero.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+
+	// un cotizador nuevo arranca desde el snapshot sin volver a descargar la lista
+	segundo := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+	segundo.ConfigurarIndice(time.Hour, ruta)
+	cotizacion, err := segundo.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 50000.0, cotizacion.Cotizacion)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&descargas))
+}
+
+func TestLimitador_RafagaYEspera(t *testing.T) {
+	limitador := cotizadores.NewLimitador("test", cotizadores.ConfiguracionLimite{TasaPorSegundo: 20, Rafaga: 2, Esperar: true})
+
+	// la ráfaga sale sin esperar y la siguiente queda encolada hasta reponer un token
+	assert.Nil(t, limitador.Esperar(context.Background()))
+	assert.Nil(t, limitador.Esperar(context.Background()))
+	inicio := time.Now()
+	assert.Nil(t, limitador.Esperar(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(inicio), 40*time.Millisecond)
+
+	// si el contexto vence antes del próximo token se falla enseguida
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var limite *cotizadores.LimiteExcedidoError
+	assert.ErrorAs(t, limitador.Esperar(ctx), &limite)
+	assert.Equal(t, cotizadores.MotivoTasa, limite.Motivo)
+
+	uso := limitador.Uso()
+	assert.Equal(t, int64(3), uso.Permitidas)
+	assert.Equal(t, int64(1), uso.Encoladas)
+	assert.Equal(t, int64(1), uso.Rechazadas)
+	assert.Equal(t, 3, uso.UsadasHoy)
+}
+
+func TestCoinPaprikaCotizador_PresupuestoDiario(t *testing.T) {
+	var descargas int32
+	server := servidorCoinPaprika(t, &descargas)
+	defer server.Close()
+
+	limitador := cotizadores.NewLimitador("coinpaprika-test", cotizadores.ConfiguracionLimite{TasaPorSegundo: 100, Rafaga: 10, PresupuestoDiario: 2})
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second).ConLimitador(limitador)
+
+	// la lista de monedas y el ticker consumen el presupuesto del día
+	_, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	var limite *cotizadores.LimiteExcedidoError
+	assert.ErrorAs(t, err, &limite)
+	assert.Equal(t, cotizadores.MotivoPresupuestoDiario, limite.Motivo)
+	assert.True(t, limite.ReintentoEn.After(time.Now()))
+	assert.Equal(t, 2, limitador.Uso().UsadasHoy)
+}
+
+func TestLimitador_NoAbreCircuito(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	limitado := mockCotizador.NewMockCotizador(ctrl)
+	cotizadores.CotizadoresMap["limitado"] = limitado
+	defer delete(cotizadores.CotizadoresMap, "limitado")
+	cotizadores.ConfigurarBreaker("limitado", cotizadores.ConfiguracionBreaker{UmbralFallas: 1, Espera: time.Minute})
+
+	limitado.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{}, fmt.Errorf("error al obtener la cotización: %w", &cotizadores.LimiteExcedidoError{Proveedor: "limitado", Motivo: cotizadores.MotivoTasa})).Times(1)
+
+	cotizador, err := cotizadores.GetCotizador("limitado")
+	assert.Nil(t, err)
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, cotizadores.CircuitoCerrado, estadoCircuito(t, "limitado").Estado)
+}
+
+// servidorFixture responde con el archivo de testdata que corresponde a la URL pedida (path y query).
+func servidorFixture(t *testing.T, fixtures map[string]string, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archivo, ok := fixtures[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", archivo))
+		if err != nil {
+			t.Errorf("no se pudo leer el fixture %s: %v", archivo, err)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write(data)
+	}))
+}
+
+func TestCoinGeckoCotizador(t *testing.T) {
+	server := servidorFixture(t, map[string]string{
+		"/api/v3/simple/price?ids=bitcoin&vs_currencies=usd&include_last_updated_at=true":   "coingecko_simple_price.json",
+		"/api/v3/simple/price?ids=bitcoin&vs_currencies=eur&include_last_updated_at=true":   "coingecko_simple_price.json",
+		"/api/v3/simple/price?ids=shiba-inu&vs_currencies=usd&include_last_updated_at=true": "coingecko_simple_price.json",
+	}, http.StatusOK)
+	defer server.Close()
+	cotizador := cotizadores.NewCoinGeckoCotizador(server.Client(), server.URL, time.Second)
+
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 67187.34, cotizacion.Cotizacion)
+	assert.Equal(t, "coingecko", cotizacion.Fuente)
+	assert.Equal(t, time.Unix(1722254400, 0), *cotizacion.FechaProveedor)
+
+	// el fixture no trae EUR
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "EUR", 0)
+	assert.EqualError(t, err, "no se encontró la cotización para la moneda fiat EUR")
+
+	// sin mapeo se usa el nombre en minúsculas, que el servidor no conoce
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Monedita", "MNT", "USD", 0)
+	assert.NotNil(t, err)
+
+	// los nombres de varias palabras se separan con guiones; el fixture responde sin esa moneda
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Shiba Inu", "SHIB", "USD", 0)
+	assert.EqualError(t, err, "no se encontró la criptomoneda Shiba Inu en CoinGecko")
+}
+
+func TestBinanceCotizador(t *testing.T) {
+	assert.Equal(t, "BTCUSDT", cotizadores.BinanceSimbolo("btc", "usd"))
+	assert.Equal(t, "ETHARS", cotizadores.BinanceSimbolo("ETH", "ARS"))
+
+	server := servidorFixture(t, map[string]string{
+		"/api/v3/ticker/price?symbol=BTCUSDT": "binance_ticker_price.json",
+	}, http.StatusOK)
+	defer server.Close()
+	cotizador := cotizadores.NewBinanceCotizador(server.Client(), server.URL, time.Second)
+
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 67190.01, cotizacion.Cotizacion)
+
+	invalido := servidorFixture(t, map[string]string{
+		"/api/v3/ticker/price?symbol=XYZUSDT": "binance_invalid_symbol.json",
+	}, http.StatusBadRequest)
+	defer invalido.Close()
+	cotizador = cotizadores.NewBinanceCotizador(invalido.Client(), invalido.URL, time.Second)
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Xyz", "XYZ", "USD", 0)
+	assert.EqualError(t, err, "error en la solicitud de cotización de XYZUSDT: Invalid symbol.")
+}
+
+func TestKrakenCotizador(t *testing.T) {
+	assert.Equal(t, "XBTUSD", cotizadores.KrakenPar("BTC", "USD"))
+	assert.Equal(t, "ETHEUR", cotizadores.KrakenPar("eth", "eur"))
+
+	server := servidorFixture(t, map[string]string{
+		"/0/public/Ticker?pair=XBTUSD": "kraken_ticker.json",
+		"/0/public/Ticker?pair=XYZUSD": "kraken_unknown_pair.json",
+	}, http.StatusOK)
+	defer server.Close()
+	cotizador := cotizadores.NewKrakenCotizador(server.Client(), server.URL, time.Second)
+
+	// Kraken devuelve el par con su nombre interno XXBTZUSD
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 67195.05, cotizacion.Cotizacion)
+
+	// los errores llegan con status 200 dentro del cuerpo
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Xyz", "XYZ", "USD", 0)
+	assert.EqualError(t, err, "error en la solicitud de cotización de XYZUSD: EQuery:Unknown asset pair")
+}
+
+func TestGenericoCotizador(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secreta" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/ticker/xbt-usdt":
+			fmt.Fprint(w, `{"data":{"tickers":[{"last":"65000.5"},{"last":"1"}]}}`)
+		case "/ticker/eth-usdt":
+			fmt.Fprint(w, `{"data":{"tickers":[]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cotizador, err := cotizadores.NewGenericoCotizador(server.Client(), cotizadores.ConfiguracionGenerico{
+		Nombre:     "regional",
+		URL:        server.URL + "/ticker/{codigo}-{fiat}",
+		Precio:     "data.tickers[0].last",
+		Headers:    map[string]string{"X-Api-Key": "secreta"},
+		Simbolos:   map[string]string{"BTC": "XBT"},
+		Fiats:      map[string]string{"USD": "USDT"},
+		Minusculas: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/ticker/xbt-usdt", cotizador.URLPara("BTC", "USD"))
+
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 65000.5, cotizacion.Cotizacion)
+
+	_, err = cotizador.GetCotizacionExterna(context.Background(), "Ethereum", "ETH", "USD", 0)
+	assert.EqualError(t, err, "no se encontró el precio de ETH/USD en regional: índice 0 fuera de rango")
+
+	_, err = cotizadores.NewGenericoCotizador(nil, cotizadores.ConfiguracionGenerico{Nombre: "sin-codigo", URL: "http://x/{fiat}", Precio: "p"})
+	assert.NotNil(t, err)
+}
+
+func TestCargarCotizadoresGenericos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"price":123.4}`)
+	}))
+	defer server.Close()
+
+	ruta := filepath.Join(t.TempDir(), "cotizadores.json")
+	contenido := fmt.Sprintf(`{"cotizadores":[{"nombre":"archivo","url":"%s/{codigo}/{fiat}","precio":"price","limite":{"tasa_por_segundo":5,"rafaga":1}}]}`, server.URL)
+	assert.Nil(t, os.WriteFile(ruta, []byte(contenido), 0o644))
+
+	nombres, err := cotizadores.CargarCotizadoresGenericos(ruta)
+	assert.Nil(t, err)
+	defer delete(cotizadores.CotizadoresMap, "archivo")
+	assert.Equal(t, []string{"archivo"}, nombres)
+
+	cotizador, err := cotizadores.GetCotizador("archivo")
+	assert.Nil(t, err)
+	cotizacion, err := cotizador.GetCotizacionExterna(context.Background(), "Bitcoin", "BTC", "USD", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 123.4, cotizacion.Cotizacion)
+	assert.Equal(t, 1, cotizadores.LimitadorPara("archivo").Uso().Rafaga)
+
+	// un nombre ya registrado no se pisa
+	_, err = cotizadores.CargarCotizadoresGenericos(ruta)
+	assert.EqualError(t, err, "el cotizador archivo ya está registrado")
+}
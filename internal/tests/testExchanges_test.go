@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGuardarCotizacionesExchanges_Succes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoExchange := mockRepo.NewMockExchangeRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	fuente := mockCotizador.NewMockExchangesCotizador(ctrl)
+
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Bitcoin", Codigo: "BTC"}, nil)
+	fuente.EXPECT().GetExchanges(gomock.Any(), "BTC", "ARS", cotizadores.VolumenPorDefecto).Return(map[string]cotizadores.Exchange{
+		"letsbit":      {Ask: 100, TotalAsk: 101, Bid: 90, TotalBid: 89, Time: 1722254400},
+		"fiwind":       {Ask: 102, TotalAsk: 103, Bid: 95, TotalBid: 94, Time: 1722254401},
+		"satoshitango": {},
+	}, nil)
+	repoExchange.EXPECT().SaveCotizacionesExchange(gomock.Any()).DoAndReturn(func(cotizaciones []criptomonedas.CotizacionExchange) error {
+		assert.Len(t, cotizaciones, 2)
+		return nil
+	})
+
+	cs := services.NewExchangeService(repoExchange, repoCripto, fuente)
+	cotizaciones, err := cs.GuardarCotizacionesExchanges(context.Background(), "Bitcoin", "ARS", 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fiwind", cotizaciones[0].Exchange)
+	assert.Equal(t, "letsbit", cotizaciones[1].Exchange)
+	assert.Equal(t, 7, cotizaciones[1].CriptoMoneda_ID)
+	assert.Equal(t, int64(1722254400), cotizaciones[1].FechaProveedor.Unix())
+	assert.Equal(t, cotizadores.VolumenPorDefecto, cotizaciones[1].Volumen)
+}
+
+func TestGuardarCotizacionesExchanges_Volumen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoExchange := mockRepo.NewMockExchangeRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	fuente := mockCotizador.NewMockExchangesCotizador(ctrl)
+
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Bitcoin", Codigo: "BTC"}, nil)
+	fuente.EXPECT().GetExchanges(gomock.Any(), "BTC", "ARS", 2.0).Return(map[string]cotizadores.Exchange{
+		"letsbit": {Ask: 100, TotalAsk: 103.5, Bid: 90, TotalBid: 86.5, Time: 1722254400},
+	}, nil)
+	repoExchange.EXPECT().SaveCotizacionesExchange(gomock.Any()).Return(nil)
+
+	cs := services.NewExchangeService(repoExchange, repoCripto, fuente)
+	cotizaciones, err := cs.GuardarCotizacionesExchanges(context.Background(), "Bitcoin", "ARS", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, cotizaciones[0].Volumen)
+	assert.Equal(t, 103.5, cotizaciones[0].TotalAsk)
+
+	_, err = cs.GuardarCotizacionesExchanges(context.Background(), "Bitcoin", "ARS", -0.5)
+	assert.ErrorIs(t, err, services.ErrVolumenInvalido)
+}
+
+func TestGuardarCotizacionesExchanges_Fail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoExchange := mockRepo.NewMockExchangeRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	fuente := mockCotizador.NewMockExchangesCotizador(ctrl)
+
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 7, Nombre: "Bitcoin", Codigo: "BTC"}, nil)
+	fuente.EXPECT().GetExchanges(gomock.Any(), "BTC", "ARS", cotizadores.VolumenPorDefecto).Return(nil, errors.New("error en la solicitud: 503"))
+
+	cs := services.NewExchangeService(repoExchange, repoCripto, fuente)
+	_, err := cs.GuardarCotizacionesExchanges(context.Background(), "Bitcoin", "ARS", 0)
+
+	assert.NotNil(t, err)
+}
+
+func TestDetectarArbitraje(t *testing.T) {
+	fecha := time.Now()
+	cotizaciones := []criptomonedas.CotizacionExchange{
+		{Exchange: "letsbit", TotalAsk: 101, TotalBid: 89, Fecha: fecha},
+		{Exchange: "fiwind", TotalAsk: 103, TotalBid: 104, Fecha: fecha},
+		{Exchange: "binancep2p", TotalAsk: 104, TotalBid: 96, Fecha: fecha},
+	}
+
+	arbitraje, ok := services.DetectarArbitraje(cotizaciones)
+
+	assert.True(t, ok)
+	assert.Equal(t, "letsbit", arbitraje.ExchangeCompra)
+	assert.Equal(t, "fiwind", arbitraje.ExchangeVenta)
+	assert.Equal(t, 3.0, arbitraje.Spread)
+	assert.InDelta(t, 2.97, arbitraje.SpreadPorcentaje, 0.01)
+}
+
+func TestDetectarArbitraje_MismoExchange(t *testing.T) {
+	cotizaciones := []criptomonedas.CotizacionExchange{
+		{Exchange: "letsbit", TotalAsk: 100, TotalBid: 110},
+		{Exchange: "fiwind", TotalAsk: 105, TotalBid: 90},
+	}
+
+	_, ok := services.DetectarArbitraje(cotizaciones)
+
+	assert.False(t, ok)
+}
+
+func TestOrdenarArbitrajes(t *testing.T) {
+	arbitrajes := []criptomonedas.Arbitraje{
+		{Moneda: "Ethereum", SpreadPorcentaje: 0.5},
+		{Moneda: "Bitcoin", SpreadPorcentaje: 1.2},
+		{Moneda: "Litecoin", SpreadPorcentaje: -0.3},
+	}
+
+	services.OrdenarArbitrajes(arbitrajes)
+
+	assert.Equal(t, "Bitcoin", arbitrajes[0].Moneda)
+	assert.Equal(t, "Litecoin", arbitrajes[2].Moneda)
+}
+
+func TestFindHistorialArbitraje_Orden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoExchange := mockRepo.NewMockExchangeRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	fuente := mockCotizador.NewMockExchangesCotizador(ctrl)
+	vieja := time.Date(2024, 7, 29, 12, 0, 0, 0, time.UTC)
+	nueva := vieja.Add(time.Hour)
+
+	repoExchange.EXPECT().FindCotizacionesExchange(gomock.Any()).Return([]criptomonedas.CotizacionExchange{
+		{Exchange: "letsbit", TotalAsk: 100, TotalBid: 99, Fecha: vieja},
+		{Exchange: "fiwind", TotalAsk: 103, TotalBid: 105, Fecha: vieja},
+		{Exchange: "letsbit", TotalAsk: 100, TotalBid: 99, Fecha: nueva},
+		{Exchange: "fiwind", TotalAsk: 103, TotalBid: 101, Fecha: nueva},
+	}, nil).Times(2)
+
+	as := services.NewArbitrajeService(services.NewExchangeService(repoExchange, repoCripto, fuente), repoCripto)
+	porSpread, err := as.FindHistorialArbitraje("Bitcoin", "ARS", services.OrdenArbitrajeSpread, nil, nil)
+	assert.Nil(t, err)
+	porFecha, err := as.FindHistorialArbitraje("Bitcoin", "ARS", services.OrdenArbitrajeFecha, nil, nil)
+	assert.Nil(t, err)
+
+	if assert.Len(t, porSpread, 2) && assert.Len(t, porFecha, 2) {
+		assert.Equal(t, vieja, porSpread[0].Fecha)
+		assert.Equal(t, 5.0, porSpread[0].SpreadPorcentaje)
+		assert.Equal(t, nueva, porFecha[0].Fecha)
+	}
+}
+
+func TestFindArbitrajesActuales_SoloLeeLaUltimaConsulta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoExchange := mockRepo.NewMockExchangeRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	fuente := mockCotizador.NewMockExchangesCotizador(ctrl)
+	vieja := time.Now().Add(-2 * time.Hour)
+	nueva := vieja.Add(time.Hour)
+
+	repoCripto.EXPECT().FindAllMonedas().Return([]*criptomonedas.CriptoMoneda{{Id: 1, Nombre: "Bitcoin"}, {Id: 2, Nombre: "Ethereum"}}, nil)
+	repoExchange.EXPECT().FindCotizacionesExchange(gomock.Any()).DoAndReturn(func(filter criptomonedas.CotizacionExchangeFilter) ([]criptomonedas.CotizacionExchange, error) {
+		assert.Equal(t, "ARS", *filter.Fiat)
+		assert.NotNil(t, filter.StartDate)
+		if filter.Nombre == "Ethereum" {
+			return nil, nil
+		}
+		return []criptomonedas.CotizacionExchange{
+			{Exchange: "letsbit", TotalAsk: 100, TotalBid: 99, Fecha: vieja},
+			{Exchange: "fiwind", TotalAsk: 103, TotalBid: 110, Fecha: vieja},
+			{Exchange: "letsbit", TotalAsk: 100, TotalBid: 99, Fecha: nueva},
+			{Exchange: "fiwind", TotalAsk: 103, TotalBid: 102, Fecha: nueva},
+		}, nil
+	}).Times(2)
+
+	as := services.NewArbitrajeService(services.NewExchangeService(repoExchange, repoCripto, fuente), repoCripto)
+	arbitrajes, err := as.FindArbitrajesActuales("ARS", 0, 0)
+
+	assert.Nil(t, err)
+	if assert.Len(t, arbitrajes, 1) {
+		assert.Equal(t, "Bitcoin", arbitrajes[0].Moneda)
+		assert.Equal(t, nueva, arbitrajes[0].Fecha)
+		assert.Equal(t, 2.0, arbitrajes[0].SpreadPorcentaje)
+	}
+}
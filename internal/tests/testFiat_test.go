@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDolarCotizadorFiat(t *testing.T) {
+	server := servidorFixture(t, map[string]string{"/api/dolar": "criptoya_dolar.json"}, http.StatusOK)
+	defer server.Close()
+	dolar := cotizadores.NewDolarCotizadorFiat(server.Client(), server.URL, time.Second)
+
+	testCases := []struct {
+		tipo string
+		tasa float64
+	}{
+		{tipo: "", tasa: 970.5},
+		{tipo: cotizadores.TipoCambioMEP, tasa: 1280.4},
+		{tipo: cotizadores.TipoCambioBlue, tasa: 1320},
+	}
+	for _, tc := range testCases {
+		tasa, err := dolar.GetTasaFiat(context.Background(), "USD", "ARS", tc.tipo)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.tasa, tasa.Tasa)
+	}
+
+	invertida, err := dolar.GetTasaFiat(context.Background(), "ars", "usd", cotizadores.TipoCambioBlue)
+	assert.Nil(t, err)
+	assert.Equal(t, "ARS", invertida.Base)
+	assert.InDelta(t, 1/1320.0, invertida.Tasa, 1e-12)
+	assert.Equal(t, time.Unix(1722254460, 0), invertida.Fecha)
+
+	_, err = dolar.GetTasaFiat(context.Background(), "USD", "EUR", "")
+	assert.NotNil(t, err)
+	_, err = dolar.GetTasaFiat(context.Background(), "USD", "ARS", "tarjeta")
+	assert.EqualError(t, err, "tipo de cambio tarjeta no soportado")
+}
+
+func TestFiatService_ConvertirCotizacion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoFiat := mockRepo.NewMockFiatRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	proveedor := mockCotizador.NewMockCotizadorFiat(ctrl)
+	fecha := time.Date(2024, 7, 29, 12, 0, 0, 0, time.UTC)
+
+	repoFiat.EXPECT().FindFiatByCodigo(gomock.Any()).DoAndReturn(func(codigo string) (*criptomonedas.MonedaFiat, error) {
+		return &criptomonedas.MonedaFiat{Codigo: codigo}, nil
+	}).AnyTimes()
+	repoCripto.EXPECT().FindUltimaCotizacion("Bitcoin", "").Return(&criptomonedas.Cotizacion{Id: 3, CriptoMoneda_ID: 1, Cotizacion: 50000, Fiat: "USD", Fecha: fecha}, nil)
+	proveedor.EXPECT().GetTasaFiat(gomock.Any(), "USD", "ARS", "blue").Return(cotizadores.TasaFiat{Base: "USD", Destino: "ARS", Tipo: "blue", Tasa: 1300, Fecha: fecha}, nil)
+
+	fs := services.NewFiatService(repoFiat, repoCripto, proveedor)
+	convertida, err := fs.FindUltimaCotizacionConvertida(context.Background(), "Bitcoin", "ars", "blue")
+	assert.Nil(t, err)
+	assert.Equal(t, 65000000.0, convertida.Cotizacion)
+	assert.Equal(t, "ARS", convertida.Fiat)
+	assert.Equal(t, 50000.0, convertida.CotizacionOriginal)
+	assert.Equal(t, "USD", convertida.FiatOriginal)
+	assert.Equal(t, "blue", convertida.TipoCambio)
+
+	// en la misma fiat no se consulta al proveedor
+	igual, err := fs.ConvertirCotizacion(context.Background(), criptomonedas.Cotizacion{Cotizacion: 10, Fiat: "USD"}, "USD", "blue")
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, igual.Cotizacion)
+	assert.Equal(t, "", igual.TipoCambio)
+}
+
+func TestFiatService_TasaCruzada(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoFiat := mockRepo.NewMockFiatRepository(ctrl)
+	proveedor := mockCotizador.NewMockCotizadorFiat(ctrl)
+	vieja := time.Date(2024, 7, 29, 11, 0, 0, 0, time.UTC)
+	nueva := vieja.Add(time.Hour)
+
+	repoFiat.EXPECT().FindFiatByCodigo(gomock.Any()).DoAndReturn(func(codigo string) (*criptomonedas.MonedaFiat, error) {
+		return &criptomonedas.MonedaFiat{Codigo: codigo}, nil
+	}).AnyTimes()
+	proveedor.EXPECT().GetTasaFiat(gomock.Any(), "EUR", "ARS", "mep").Return(cotizadores.TasaFiat{}, errors.New("par no soportado"))
+	proveedor.EXPECT().GetTasaFiat(gomock.Any(), "EUR", "USD", "mep").Return(cotizadores.TasaFiat{Tipo: "mep", Tasa: 1.1, Fecha: nueva}, nil)
+	proveedor.EXPECT().GetTasaFiat(gomock.Any(), "USD", "ARS", "mep").Return(cotizadores.TasaFiat{Tipo: "mep", Tasa: 1200, Fecha: vieja}, nil)
+
+	fs := services.NewFiatService(repoFiat, mockRepo.NewMockCryptoRepository(ctrl), proveedor)
+	tasa, err := fs.GetTasaFiat(context.Background(), "EUR", "ARS", "mep")
+	assert.Nil(t, err)
+	assert.InDelta(t, 1320.0, tasa.Tasa, 1e-9)
+	assert.Equal(t, "EUR", tasa.Base)
+	assert.Equal(t, vieja, tasa.Fecha)
+}
+
+func TestFiatService_FiatNoRegistrada(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoFiat := mockRepo.NewMockFiatRepository(ctrl)
+	repoFiat.EXPECT().FindFiatByCodigo("USD").Return(&criptomonedas.MonedaFiat{Codigo: "USD"}, nil)
+	repoFiat.EXPECT().FindFiatByCodigo("XYZ").Return(nil, nil)
+
+	fs := services.NewFiatService(repoFiat, mockRepo.NewMockCryptoRepository(ctrl), mockCotizador.NewMockCotizadorFiat(ctrl))
+	_, err := fs.GetTasaFiat(context.Background(), "usd", "xyz", "")
+	assert.EqualError(t, err, "la moneda fiat XYZ no está registrada en la base de datos")
+}
+
+func TestFiatService_ConvertirDesdeEuro(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoFiat := mockRepo.NewMockFiatRepository(ctrl)
+	server := servidorFixture(t, map[string]string{
+		"/api/dolar":              "criptoya_dolar.json",
+		"/latest?from=EUR&to=USD": "frankfurter_eur_usd.json",
+	}, http.StatusOK)
+	defer server.Close()
+	proveedor := cotizadores.NewCadenaCotizadorFiat(
+		cotizadores.NewDolarCotizadorFiat(server.Client(), server.URL, time.Second),
+		cotizadores.NewFrankfurterCotizadorFiat(server.Client(), server.URL, time.Second),
+	)
+
+	repoFiat.EXPECT().FindFiatByCodigo("EUR").Return(&criptomonedas.MonedaFiat{Codigo: "EUR"}, nil)
+	repoFiat.EXPECT().FindFiatByCodigo("ARS").Return(&criptomonedas.MonedaFiat{Codigo: "ARS"}, nil)
+
+	fs := services.NewFiatService(repoFiat, mockRepo.NewMockCryptoRepository(ctrl), proveedor)
+	convertida, err := fs.ConvertirCotizacion(context.Background(), criptomonedas.Cotizacion{Cotizacion: 50000, Fiat: "EUR"}, "ARS", cotizadores.TipoCambioBlue)
+	assert.Nil(t, err)
+	assert.InDelta(t, 50000*1.0823*1320, convertida.Cotizacion, 1e-6)
+	assert.Equal(t, cotizadores.TipoCambioBlue, convertida.TipoCambio)
+
+	// el BCE no publica el peso argentino: EUR/ARS se cruza por el dólar
+	_, err = proveedor.GetTasaFiat(context.Background(), "EUR", "ARS", "")
+	assert.NotNil(t, err)
+}
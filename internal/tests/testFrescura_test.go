@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// cotizacionGuardada es la última cotización guardada de una moneda, con la antigüedad indicada.
+func cotizacionGuardada(antiguedad time.Duration) *criptomonedas.Cotizacion {
+	return &criptomonedas.Cotizacion{Id: 1, Cotizacion: 100, Fiat: "ARS", Fecha: time.Now().Add(-antiguedad), Fuente: "coinpaprika"}
+}
+
+func TestFrescura_Vencida(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(&criptomonedas.CriptoMoneda{Id: 2, Nombre: "Ethereum", Codigo: "ETH"}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Monedita").Return(&criptomonedas.CriptoMoneda{Id: 3, Nombre: "Monedita", Codigo: "MNT"}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Inexistente").Return(nil, nil)
+	repoPoliticas.EXPECT().FindPoliticaByMonedaID(1).Return(nil, nil)
+	repoPoliticas.EXPECT().FindPoliticaByMonedaID(2).Return(&criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 2, Api: "coinpaprika", Fiat: "USD", Frescura: "2h"}, nil)
+	repoPoliticas.EXPECT().FindPoliticaByMonedaID(3).Return(nil, nil)
+	repoCripto.EXPECT().FindUltimaCotizacion("Bitcoin", "").Return(cotizacionGuardada(time.Hour), nil)
+	repoCripto.EXPECT().FindUltimaCotizacion("Ethereum", "").Return(cotizacionGuardada(time.Hour), nil)
+	repoCripto.EXPECT().FindUltimaCotizacion("Monedita", "").Return(cotizacionGuardada(time.Minute), nil)
+	// ninguna se refresca: el cotizador no espera llamadas
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConFrescura(repoPoliticas, services.ConfiguracionFrescura{Maxima: 15 * time.Minute})
+
+	// sin refresh no se consulta al proveedor aunque esté vencida
+	bitcoin, err := cs.FindUltimaCotizacion(context.Background(), "Bitcoin", "", false, 0)
+	assert.Nil(t, err)
+	assert.True(t, bitcoin.Vencida)
+	assert.False(t, bitcoin.Refrescada)
+	assert.InDelta(t, 3600, bitcoin.Antiguedad, 5)
+	assert.Equal(t, int64(900), bitcoin.FrescuraMaxima)
+
+	// la política de Ethereum acepta dos horas
+	ethereum, err := cs.FindUltimaCotizacion(context.Background(), "Ethereum", "", true, 0)
+	assert.Nil(t, err)
+	assert.False(t, ethereum.Vencida)
+	assert.Equal(t, int64(7200), ethereum.FrescuraMaxima)
+
+	monedita, err := cs.FindUltimaCotizacion(context.Background(), "Monedita", "", true, 0)
+	assert.Nil(t, err)
+	assert.False(t, monedita.Vencida)
+	assert.Equal(t, 100.0, monedita.Cotizacion.Cotizacion)
+
+	inexistente, err := cs.FindUltimaCotizacion(context.Background(), "Inexistente", "", true, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, inexistente)
+}
+
+func TestFrescura_Refrescar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	bitcoin := &criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}
+	// cada refresco vuelve a buscar la moneda para cotizarla
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(bitcoin, nil).Times(4)
+	repoPoliticas.EXPECT().FindPoliticaByMonedaID(1).Return(nil, nil).Times(2)
+	repoCripto.EXPECT().FindUltimaCotizacion("Bitcoin", "").Return(cotizacionGuardada(time.Hour), nil)
+	repoCripto.EXPECT().FindUltimaCotizacion("Bitcoin", "USD").Return(cotizacionGuardada(time.Hour), nil)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConFrescura(repoPoliticas, services.ConfiguracionFrescura{Maxima: 15 * time.Minute})
+	// se refresca en la fiat de la última cotización guardada
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "ARS", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 120, Fecha: time.Now(), Fuente: "coinpaprika"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).DoAndReturn(func(cotizacion criptomonedas.Cotizacion) error {
+		assert.Equal(t, 1, cotizacion.CriptoMoneda_ID)
+		assert.Equal(t, "ARS", cotizacion.Fiat)
+		return nil
+	})
+
+	vigente, err := cs.FindUltimaCotizacion(context.Background(), "Bitcoin", "", true, 0)
+	assert.Nil(t, err)
+	assert.True(t, vigente.Refrescada)
+	assert.False(t, vigente.Vencida)
+	assert.Equal(t, 120.0, vigente.Cotizacion.Cotizacion)
+
+	// si el proveedor falla se devuelve la guardada con el motivo; el volumen pedido llega al proveedor
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.5).Return(criptomonedas.Cotizacion{}, errors.New("proveedor caído"))
+	vigente, err = cs.FindUltimaCotizacion(context.Background(), "Bitcoin", "usd", true, 0.5)
+	assert.Nil(t, err)
+	assert.True(t, vigente.Vencida)
+	assert.False(t, vigente.Refrescada)
+	assert.Equal(t, "proveedor caído", vigente.ErrorRefresco)
+	assert.Equal(t, 100.0, vigente.Cotizacion.Cotizacion)
+}
+
+func TestFrescura_RefrescoCompartido(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	// cinco solicitudes y un solo refresco, que vuelve a buscar la moneda para cotizarla
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(&criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}, nil).Times(6)
+	repoPoliticas.EXPECT().FindPoliticaByMonedaID(1).Return(nil, nil).Times(5)
+	repoCripto.EXPECT().FindUltimaCotizacion("Bitcoin", "").Return(cotizacionGuardada(time.Hour), nil).Times(5)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConFrescura(repoPoliticas, services.ConfiguracionFrescura{Maxima: 15 * time.Minute})
+	liberar := make(chan struct{})
+	// las solicitudes concurrentes de la misma moneda hacen un solo pedido al proveedor
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "ARS", 0.0).DoAndReturn(
+		func(ctx context.Context, moneda, codigo, fiat string, volumen float64) (criptomonedas.Cotizacion, error) {
+			<-liberar
+			return criptomonedas.Cotizacion{Cotizacion: 120, Fecha: time.Now(), Fuente: "coinpaprika"}, nil
+		}).Times(1)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil).Times(1)
+
+	var wg sync.WaitGroup
+	resultados := make([]*criptomonedas.CotizacionVigente, 5)
+	for i := range resultados {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resultados[i], _ = cs.FindUltimaCotizacion(context.Background(), "Bitcoin", "", true, 0)
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(liberar)
+	wg.Wait()
+
+	for _, resultado := range resultados {
+		if assert.NotNil(t, resultado) {
+			assert.True(t, resultado.Refrescada)
+			assert.Equal(t, 120.0, resultado.Cotizacion.Cotizacion)
+		}
+	}
+}
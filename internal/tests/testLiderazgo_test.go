@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// trabajoDePrueba cuenta las veces que se inicia y se detiene.
+type trabajoDePrueba struct {
+	mu        sync.Mutex
+	iniciado  bool
+	inicios   int
+	detencion int
+}
+
+func (t *trabajoDePrueba) Iniciar(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.iniciado = true
+	t.inicios++
+	return nil
+}
+
+func (t *trabajoDePrueba) Detener() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.iniciado = false
+	t.detencion++
+}
+
+func (t *trabajoDePrueba) corriendo() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.iniciado
+}
+
+func TestLiderService_Intentar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockRepo.NewMockLiderazgoRepository(ctrl)
+	trabajo := &trabajoDePrueba{}
+	config := services.ConfiguracionLiderazgo{Nombre: "trabajos", Instancia: "a", Duracion: 30 * time.Second, Renovacion: 10 * time.Second}
+	lider := services.NewLiderService(repo, config, trabajo)
+	vence := time.Date(2024, 7, 29, 12, 0, 30, 0, time.UTC)
+
+	// otra instancia tiene el lease
+	repo.EXPECT().AdquirirLiderazgo(gomock.Any(), "trabajos", "a", 30*time.Second).Return(criptomonedas.Liderazgo{Nombre: "trabajos", Instancia: "b", Vence: vence}, nil)
+	lider.Intentar()
+	assert.False(t, lider.EsLider())
+	assert.False(t, trabajo.corriendo())
+	assert.Equal(t, "b", lider.Estado().Lider)
+
+	// el lease de b venció y lo toma a, renovarlo no reinicia los trabajos
+	repo.EXPECT().AdquirirLiderazgo(gomock.Any(), "trabajos", "a", 30*time.Second).Return(criptomonedas.Liderazgo{Nombre: "trabajos", Instancia: "a", Vence: vence}, nil).Times(2)
+	lider.Intentar()
+	lider.Intentar()
+	assert.True(t, lider.EsLider())
+	assert.True(t, trabajo.corriendo())
+	assert.Equal(t, 1, trabajo.inicios)
+	assert.NotNil(t, lider.Estado().LiderDesde)
+
+	// sin poder confirmar el lease se detienen los trabajos antes de que venza
+	repo.EXPECT().AdquirirLiderazgo(gomock.Any(), "trabajos", "a", 30*time.Second).Return(criptomonedas.Liderazgo{}, errors.New("sin conexión"))
+	lider.Intentar()
+	estado := lider.Estado()
+	assert.False(t, estado.EsLider)
+	assert.Nil(t, estado.LiderDesde)
+	assert.Equal(t, "sin conexión", estado.UltimoError)
+	assert.False(t, trabajo.corriendo())
+	assert.Equal(t, 1, trabajo.detencion)
+}
+
+func TestLiderService_RenovacionColgadaDetieneLosTrabajos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockRepo.NewMockLiderazgoRepository(ctrl)
+	trabajo := &trabajoDePrueba{}
+	config := services.ConfiguracionLiderazgo{Nombre: "trabajos", Instancia: "a", Duracion: 100 * time.Millisecond, Renovacion: 40 * time.Millisecond}
+	lider := services.NewLiderService(repo, config, trabajo)
+
+	repo.EXPECT().AdquirirLiderazgo(gomock.Any(), "trabajos", "a", 100*time.Millisecond).Return(criptomonedas.Liderazgo{Instancia: "a"}, nil)
+	lider.Intentar()
+	assert.True(t, trabajo.corriendo())
+
+	// la base no responde ni respeta el plazo de la consulta
+	liberar := make(chan struct{})
+	defer close(liberar)
+	repo.EXPECT().AdquirirLiderazgo(gomock.Any(), "trabajos", "a", 100*time.Millisecond).DoAndReturn(
+		func(ctx context.Context, nombre, instancia string, duracion time.Duration) (criptomonedas.Liderazgo, error) {
+			limite, tiene := ctx.Deadline()
+			assert.True(t, tiene)
+			assert.WithinDuration(t, time.Now().Add(30*time.Millisecond), limite, 10*time.Millisecond)
+			<-liberar
+			return criptomonedas.Liderazgo{}, ctx.Err()
+		})
+	go lider.Intentar()
+
+	// los trabajos se detienen cuando vence el lease aunque la renovación siga colgada
+	assert.Eventually(t, func() bool { return !trabajo.corriendo() }, time.Second, 5*time.Millisecond)
+	assert.False(t, lider.EsLider())
+	assert.Equal(t, 1, trabajo.detencion)
+}
+
+func TestLiderService_DetenerLiberaElLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockRepo.NewMockLiderazgoRepository(ctrl)
+	trabajo := &trabajoDePrueba{}
+	config := services.ConfiguracionLiderazgo{Instancia: "a", Duracion: time.Hour, Renovacion: time.Minute}
+	lider := services.NewLiderService(repo, config, trabajo)
+	repo.EXPECT().AdquirirLiderazgo(gomock.Any(), services.ConfiguracionLiderazgoPorDefecto.Nombre, "a", time.Hour).Return(criptomonedas.Liderazgo{Instancia: "a"}, nil)
+	repo.EXPECT().LiberarLiderazgo(services.ConfiguracionLiderazgoPorDefecto.Nombre, "a").Return(nil)
+
+	assert.Nil(t, lider.Iniciar(context.Background()))
+	assert.NotNil(t, lider.Iniciar(context.Background()))
+	assert.Eventually(t, trabajo.corriendo, time.Second, 5*time.Millisecond)
+
+	lider.Detener()
+	assert.False(t, lider.EsLider())
+	assert.False(t, trabajo.corriendo())
+}
+
+func TestLiderService_RenovacionMayorQueDuracion(t *testing.T) {
+	lider := services.NewLiderService(nil, services.ConfiguracionLiderazgo{Duracion: time.Second, Renovacion: time.Minute})
+	assert.NotNil(t, lider.Iniciar(context.Background()))
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"primerProjecto/internal/migrations"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentencias(t *testing.T) {
+	script := `-- comentario de cabecera
+CREATE TABLE a (
+	id INT PRIMARY KEY
+);
+
+-- la siguiente va en una sola línea
+INSERT INTO a (id) VALUES (1);
+UPDATE a SET id = 2 WHERE id = 1;
+-- comentario final sin sentencia
+`
+	sentencias := migrations.Sentencias(script)
+
+	require.Len(t, sentencias, 3)
+	assert.Equal(t, "-- comentario de cabecera\nCREATE TABLE a (\n\tid INT PRIMARY KEY\n)", sentencias[0])
+	assert.Equal(t, "-- la siguiente va en una sola línea\nINSERT INTO a (id) VALUES (1)", sentencias[1])
+	assert.Equal(t, "UPDATE a SET id = 2 WHERE id = 1", sentencias[2])
+}
+
+func TestCargarMigraciones(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mysql/0002_segunda.up.sql":   {Data: []byte("ALTER TABLE a ADD COLUMN b INT;\n")},
+		"mysql/0002_segunda.down.sql": {Data: []byte("ALTER TABLE a DROP COLUMN b;\n")},
+		"mysql/0001_primera.up.sql":   {Data: []byte("CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);\n")},
+		"mysql/0001_primera.down.sql": {Data: []byte("DROP TABLE a;\n")},
+		"mysql/LEEME.md":              {Data: []byte("no es una migración")},
+	}
+
+	migraciones, err := migrations.Cargar(fsys, "mysql")
+
+	require.NoError(t, err)
+	require.Len(t, migraciones, 2)
+	assert.Equal(t, 1, migraciones[0].Version)
+	assert.Equal(t, "primera", migraciones[0].Nombre)
+	assert.Equal(t, []string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"}, migraciones[0].Up)
+	assert.Equal(t, []string{"DROP TABLE a"}, migraciones[0].Down)
+	assert.Equal(t, 2, migraciones[1].Version)
+	assert.Equal(t, "segunda", migraciones[1].Nombre)
+}
+
+func TestCargarMigraciones_Errores(t *testing.T) {
+	casos := map[string]fstest.MapFS{
+		"falta una versión": {
+			"mysql/0001_primera.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+			"mysql/0001_primera.down.sql": {Data: []byte("DROP TABLE a;")},
+			"mysql/0003_tercera.up.sql":   {Data: []byte("CREATE TABLE c (id INT);")},
+			"mysql/0003_tercera.down.sql": {Data: []byte("DROP TABLE c;")},
+		},
+		"falta el down": {
+			"mysql/0001_primera.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		},
+		"down vacío": {
+			"mysql/0001_primera.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+			"mysql/0001_primera.down.sql": {Data: []byte("-- nada que revertir\n")},
+		},
+		"dos nombres para una versión": {
+			"mysql/0001_primera.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+			"mysql/0001_otra.down.sql":  {Data: []byte("DROP TABLE a;")},
+		},
+	}
+	for nombre, fsys := range casos {
+		t.Run(nombre, func(t *testing.T) {
+			_, err := migrations.Cargar(fsys, "mysql")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewMigrador_MigracionesEmbebidas(t *testing.T) {
+	migrador, err := migrations.NewMigrador(nil, migrations.DialectoMySQL)
+
+	require.NoError(t, err)
+	migraciones := migrador.Migraciones()
+	require.NotEmpty(t, migraciones)
+	assert.Equal(t, "esquema_inicial", migraciones[0].Nombre)
+	// usuarios tiene que existir antes que cotizaciones, que la referencia
+	var usuarios, cotizaciones int
+	for i, sentencia := range migraciones[0].Up {
+		switch {
+		case creaTabla(sentencia, "usuarios"):
+			usuarios = i
+		case creaTabla(sentencia, "cotizaciones"):
+			cotizaciones = i
+		}
+	}
+	assert.Less(t, usuarios, cotizaciones)
+}
+
+func TestNewMigrador_MismasVersionesEnTodosLosMotores(t *testing.T) {
+	mysql, err := migrations.NewMigrador(nil, migrations.DialectoMySQL)
+	require.NoError(t, err)
+
+	for _, dialecto := range []string{migrations.DialectoSQLite, migrations.DialectoPostgres} {
+		migrador, err := migrations.NewMigrador(nil, dialecto)
+		require.NoError(t, err, dialecto)
+		require.Len(t, migrador.Migraciones(), len(mysql.Migraciones()), dialecto)
+		for i, migracion := range migrador.Migraciones() {
+			assert.Equal(t, mysql.Migraciones()[i].Nombre, migracion.Nombre, dialecto)
+		}
+	}
+}
+
+func TestNewMigrador_DialectoDesconocido(t *testing.T) {
+	_, err := migrations.NewMigrador(nil, "oracle")
+
+	assert.Error(t, err)
+}
+
+func creaTabla(sentencia, tabla string) bool {
+	return strings.Contains(sentencia, "CREATE TABLE IF NOT EXISTS "+tabla+" (")
+}
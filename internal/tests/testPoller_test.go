@@ -0,0 +1,364 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var monedasPoller = []*criptomonedas.CriptoMoneda{
+	{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"},
+	{Id: 2, Nombre: "Ethereum", Codigo: "ETH"},
+	{Id: 3, Nombre: "Monedita", Codigo: "MNT"},
+}
+
+func TestPoller_Correr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{Api: "coinpaprika", Trabajadores: 2})
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasPoller, nil).Times(2)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil).Times(2)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(1).Return([]int{10}, nil).Times(2)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(2).Return([]int{10, 11, 12}, nil).Times(2)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(3).Return(nil, nil).Times(2)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasPoller[0], nil).Times(2)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasPoller[1], nil).Times(2)
+	repoCripto.EXPECT().FindCryptoByName("Monedita").Return(monedasPoller[2], nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil).Times(2)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Monedita", "MNT", "USD", 0.0).Return(criptomonedas.Cotizacion{}, errors.New("moneda desconocida")).Times(2)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).DoAndReturn(func(cotizacion criptomonedas.Cotizacion) error {
+		assert.Equal(t, "USD", cotizacion.Fiat)
+		assert.Contains(t, []int{1, 2}, cotizacion.CriptoMoneda_ID)
+		return nil
+	}).Times(4)
+
+	poller.Correr(context.Background())
+	poller.Correr(context.Background())
+
+	estado := poller.Estado()
+	assert.False(t, estado.Activo)
+	assert.Equal(t, int64(2), estado.Corridas)
+	assert.Len(t, estado.Monedas, 3)
+	assert.Equal(t, "Bitcoin", estado.Monedas[0].Moneda)
+	assert.NotNil(t, estado.Monedas[0].UltimoExito)
+	assert.Equal(t, 0, estado.Monedas[0].FallasConsecutivas)
+	// una moneda que falla no corta la corrida de las demás
+	assert.Equal(t, "Monedita", estado.Monedas[2].Moneda)
+	assert.Nil(t, estado.Monedas[2].UltimoExito)
+	assert.Equal(t, 2, estado.Monedas[2].FallasConsecutivas)
+	assert.Contains(t, estado.Monedas[2].UltimoError, "moneda desconocida")
+}
+
+func TestPoller_SoloMonedasSeguidas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{Alcance: services.AlcanceSeguidas})
+	repoCripto.EXPECT().FindMonedasSeguidas().Return(monedasPoller[1:2], nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(2).Return([]int{10, 11, 12}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasPoller[1], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil)
+
+	poller.Correr(context.Background())
+
+	estado := poller.Estado()
+	assert.Len(t, estado.Monedas, 1)
+	assert.Equal(t, "Ethereum", estado.Monedas[0].Moneda)
+}
+
+func TestPoller_IniciarYDetener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{Intervalo: time.Hour, Jitter: time.Millisecond})
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(1).Return([]int{10}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasPoller[0], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil)
+	corrio := make(chan struct{})
+	repoCripto.EXPECT().FindAllMonedas().DoAndReturn(func() ([]*criptomonedas.CriptoMoneda, error) {
+		close(corrio)
+		return monedasPoller[:1], nil
+	})
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil)
+
+	assert.Nil(t, poller.Iniciar(context.Background()))
+	assert.NotNil(t, poller.Iniciar(context.Background()))
+	<-corrio
+	assert.Eventually(t, func() bool { return poller.Estado().ProximaCorrida != nil }, time.Second, 5*time.Millisecond)
+	assert.True(t, poller.Estado().Activo)
+
+	poller.Detener()
+	estado := poller.Estado()
+	assert.False(t, estado.Activo)
+	assert.Equal(t, int64(1), estado.Corridas)
+	assert.Nil(t, estado.ProximaCorrida)
+}
+
+func TestPoller_AlcanceInvalido(t *testing.T) {
+	poller := services.NewPollerService(nil, nil, nil, nil, services.ConfiguracionPoller{Alcance: "algunas"})
+	assert.EqualError(t, poller.Iniciar(context.Background()), "alcance algunas no soportado, debe ser todas o seguidas")
+}
+
+func TestPoller_MonedasConPoliticaNoUsanElIntervaloGlobal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	politica := criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 1, Moneda: "Bitcoin", Cron: "* * * * *", Habilitada: false}
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{})
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasPoller[:2], nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return([]criptomonedas.PoliticaRefresco{politica}, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(2).Return([]int{10, 11, 12}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasPoller[1], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).DoAndReturn(func(cotizacion criptomonedas.Cotizacion) error {
+		assert.Equal(t, 2, cotizacion.CriptoMoneda_ID)
+		return nil
+	})
+
+	poller.Correr(context.Background())
+
+	estado := poller.Estado()
+	assert.Len(t, estado.Monedas, 1)
+	assert.Equal(t, "Ethereum", estado.Monedas[0].Moneda)
+	assert.Equal(t, 3, estado.Monedas[0].Seguidores)
+}
+
+func TestPoller_CorrerPoliticas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	politicas := []criptomonedas.PoliticaRefresco{
+		{CriptoMoneda_ID: 1, Moneda: "Bitcoin", Cron: "*/15 * * * *", Api: "coingecko", Fiat: "ars", Habilitada: true},
+		{CriptoMoneda_ID: 2, Moneda: "Ethereum", Cron: "0 * * * *", Api: "coinpaprika", Habilitada: true},
+		{CriptoMoneda_ID: 3, Moneda: "Monedita", Cron: "*/15 * * * *", Habilitada: false},
+	}
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{})
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(politicas, nil)
+	// solo la de Bitcoin tuvo una ejecución en el minuto
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(1).Return([]int{10}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasPoller[0], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "ARS", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coingecko"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).DoAndReturn(func(cotizacion criptomonedas.Cotizacion) error {
+		assert.Equal(t, 1, cotizacion.CriptoMoneda_ID)
+		assert.Equal(t, "ARS", cotizacion.Fiat)
+		return nil
+	})
+
+	desde := time.Date(2024, 7, 29, 10, 14, 30, 0, time.UTC)
+	poller.CorrerPoliticas(context.Background(), desde, desde.Add(time.Minute))
+
+	estado := poller.Estado()
+	assert.Len(t, estado.Monedas, 1)
+	assert.Equal(t, "coingecko", estado.Monedas[0].Api)
+	assert.Equal(t, "ARS", estado.Monedas[0].Fiat)
+	assert.NotNil(t, estado.Monedas[0].UltimoExito)
+}
+
+func TestPoller_PoliticasSoloDeMonedasSeguidas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{Alcance: services.AlcanceSeguidas})
+	repoPoliticas.EXPECT().FindAllPoliticas().Return([]criptomonedas.PoliticaRefresco{
+		{CriptoMoneda_ID: 1, Moneda: "Bitcoin", Cron: "* * * * *", Habilitada: true},
+		{CriptoMoneda_ID: 2, Moneda: "Ethereum", Cron: "* * * * *", Habilitada: true},
+	}, nil)
+	// nadie sigue Bitcoin y su política no corre
+	repoCripto.EXPECT().FindMonedasSeguidas().Return(monedasPoller[1:2], nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(2).Return([]int{10, 11, 12}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasPoller[1], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil)
+
+	desde := time.Date(2024, 7, 29, 10, 14, 30, 0, time.UTC)
+	poller.CorrerPoliticas(context.Background(), desde, desde.Add(time.Minute))
+
+	estado := poller.Estado()
+	assert.Len(t, estado.Monedas, 1)
+	assert.Equal(t, "Ethereum", estado.Monedas[0].Moneda)
+}
+
+func TestPoller_PostergaLasMenosSeguidasSinCupo(t *testing.T) {
+	cotizadores.ConfigurarLimite("proveedor-de-prueba", cotizadores.ConfiguracionLimite{TasaPorSegundo: 1, Rafaga: 1, PresupuestoDiario: 1})
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{Api: "proveedor-de-prueba", Trabajadores: 1})
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasPoller[:2], nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(1).Return([]int{10}, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(2).Return([]int{10, 11, 12}, nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasPoller[1], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "proveedor-de-prueba"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).DoAndReturn(func(cotizacion criptomonedas.Cotizacion) error {
+		// Ethereum tiene más seguidores y se lleva el único pedido que queda
+		assert.Equal(t, 2, cotizacion.CriptoMoneda_ID)
+		return nil
+	})
+
+	poller.Correr(context.Background())
+
+	estado := poller.Estado()
+	assert.Equal(t, "Bitcoin", estado.Monedas[0].Moneda)
+	assert.NotNil(t, estado.Monedas[0].UltimaPostergacion)
+	assert.Equal(t, 0, estado.Monedas[0].FallasConsecutivas)
+	assert.Nil(t, estado.Monedas[0].UltimoExito)
+	assert.NotNil(t, estado.Monedas[1].UltimoExito)
+}
+
+func TestPoller_FallbackUsaElCupoDeSusProveedores(t *testing.T) {
+	for _, proveedor := range []string{"coinpaprika", "criptoya"} {
+		cotizadores.ConfigurarLimite(proveedor, cotizadores.ConfiguracionLimite{TasaPorSegundo: 1, Rafaga: 1, PresupuestoDiario: 1})
+		t.Cleanup(func() { cotizadores.ConfigurarLimite(proveedor, cotizadores.LimitesPorDefecto[proveedor]) })
+	}
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	repoUsuario := mockRepo.NewMockUsuarioRepository(ctrl)
+	repoPoliticas := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador)
+	poller := services.NewPollerService(cs, repoCripto, repoUsuario, repoPoliticas, services.ConfiguracionPoller{Api: "fallback", Trabajadores: 1})
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasPoller, nil)
+	repoPoliticas.EXPECT().FindAllPoliticas().Return(nil, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(1).Return([]int{10}, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(2).Return([]int{10, 11, 12}, nil)
+	repoUsuario.EXPECT().FindUsuariosByMonedaID(3).Return(nil, nil)
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasPoller[0], nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasPoller[1], nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "criptoya"}, nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 100, Fuente: "coinpaprika"}, nil)
+	repoCripto.EXPECT().SaveCotizacion(gomock.Any()).Return(nil).Times(2)
+
+	poller.Correr(context.Background())
+
+	// Ethereum usa el cupo de coinpaprika, Bitcoin el de criptoya y a Monedita no le queda ninguno
+	estado := poller.Estado()
+	assert.Equal(t, "Monedita", estado.Monedas[2].Moneda)
+	assert.NotNil(t, estado.Monedas[2].UltimaPostergacion)
+	assert.Nil(t, estado.Monedas[0].UltimaPostergacion)
+	assert.Nil(t, estado.Monedas[1].UltimaPostergacion)
+}
+
+func TestParsearCron(t *testing.T) {
+	base := time.Date(2024, 7, 29, 10, 14, 30, 0, time.UTC) // lunes
+	casos := []struct {
+		expresion string
+		siguiente time.Time
+	}{
+		{"* * * * *", time.Date(2024, 7, 29, 10, 15, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2024, 7, 29, 10, 20, 0, 0, time.UTC)},
+		{"5,45 9-11 * * *", time.Date(2024, 7, 29, 10, 45, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 7, 30, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 7, 29, 11, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, 8, 4, 12, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 3", time.Date(2024, 7, 31, 0, 0, 0, 0, time.UTC)},
+		{"30 6 1 1 *", time.Date(2025, 1, 1, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, caso := range casos {
+		cron, err := services.ParsearCron(caso.expresion)
+		if assert.Nil(t, err, caso.expresion) {
+			assert.Equal(t, caso.siguiente, cron.Siguiente(base), caso.expresion)
+		}
+	}
+
+	cron, err := services.ParsearCron("0 0 30 2 *")
+	assert.Nil(t, err)
+	assert.True(t, cron.Siguiente(base).IsZero())
+
+	for _, invalida := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := services.ParsearCron(invalida)
+		assert.NotNil(t, err, invalida)
+	}
+}
+
+func TestPoliticaRefrescoService_SavePolitica(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockRepo.NewMockPoliticaRefrescoRepository(ctrl)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		if name != "fallback" {
+			return nil, errors.New("api no soportada")
+		}
+		return mockCotizador.NewMockCotizador(ctrl), nil
+	}
+	service := services.NewPoliticaRefrescoService(repo, repoCripto, getCotizador)
+
+	_, err := service.SavePolitica(criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 1, Cron: "cada minuto"})
+	assert.ErrorIs(t, err, services.ErrPoliticaInvalida)
+	_, err = service.SavePolitica(criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 1, Cron: "@hourly", Api: "desconocida"})
+	assert.ErrorIs(t, err, services.ErrPoliticaInvalida)
+	_, err = service.SavePolitica(criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 1, Cron: "@hourly", Frescura: "media hora"})
+	assert.ErrorIs(t, err, services.ErrPoliticaInvalida)
+
+	repoCripto.EXPECT().FindByMonedaID(9).Return(nil, nil)
+	_, err = service.SavePolitica(criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 9, Cron: "@hourly"})
+	assert.ErrorIs(t, err, services.ErrPoliticaInvalida)
+
+	repoCripto.EXPECT().FindByMonedaID(1).Return(monedasPoller[0], nil)
+	repo.EXPECT().SavePolitica(gomock.Any()).Return(nil)
+	politica, err := service.SavePolitica(criptomonedas.PoliticaRefresco{CriptoMoneda_ID: 1, Cron: "*/5 * * * *", Habilitada: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "Bitcoin", politica.Moneda)
+	assert.Equal(t, "fallback", politica.Api)
+	assert.Equal(t, "USD", politica.Fiat)
+	assert.False(t, politica.Actualizada.IsZero())
+}
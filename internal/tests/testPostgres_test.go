@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"database/sql"
+	"primerProjecto/internal/adapters/repositories"
+	"primerProjecto/internal/entities/criptomonedas"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Sin un servidor PostgreSQL, estas pruebas verifican con sqlmock las consultas que arman los repositorios:
+// marcadores $n numerados en orden, RETURNING para los ids y json_agg en el resumen por usuario.
+
+func nuevoMockPostgres(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	return db, mock
+}
+
+var columnasCotizacionMock = []string{"id", "cotizacion", "fiat", "fecha", "cripto_id", "fuente", "exchange", "fecha_proveedor", "volumen", "total_ask", "total_bid"}
+
+func TestPostgresUsuarioRepository_SaveUsuario_DevuelveIdConReturning(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresUsuarioRepository(db)
+	usuario := criptomonedas.Usuario{Nombre: "Ana", Apellidos: "Gomez", CodigoUsuario: "A1", Email: "ana@example.com", TipoDocumento: "DNI"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)\n\t\tRETURNING id")).
+		WithArgs("Ana", "Gomez", sqlmock.AnyArg(), "A1", "ana@example.com", "DNI", sqlmock.AnyArg(), false, criptomonedas.FiatPorDefecto).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+
+	id, err := repo.SaveUsuario(usuario)
+
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+}
+
+func TestPostgresUsuarioRepository_PatchUsuarioByID(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresUsuarioRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE usuarios SET nombre = $1 WHERE id = $2")).
+		WithArgs("Ana María", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.PatchUsuarioByID(7, map[string]interface{}{"nombre": "Ana María"}))
+}
+
+func TestPostgresCryptoRepository_GuardarCotizacionManual_DevuelveIdConReturning(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresCryptoRepository(db)
+	fecha := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("VALUES ($1, $2, $3, $4, TRUE, $5, $6) RETURNING id")).
+		WithArgs(1, 60000.0, "USD", fecha, 3, criptomonedas.FuenteManual).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(15))
+
+	cotizacion, err := repo.GuardarCotizacionManual(3, criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: 60000, Fecha: fecha})
+
+	require.NoError(t, err)
+	assert.Equal(t, 15, cotizacion.Id)
+	assert.True(t, cotizacion.Manual)
+	assert.Equal(t, "USD", cotizacion.Fiat)
+}
+
+func TestPostgresCryptoRepository_SaveMoneda_ConIdAdelantaLaSecuencia(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresCryptoRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO monedas (id, nombre, codigo) VALUES ($1, $2, $3)")).
+		WithArgs(7, "Ethereum", "ETH").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("setval(pg_get_serial_sequence('monedas', 'id')")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.SaveMoneda(criptomonedas.CriptoMoneda{Id: 7, Nombre: "Ethereum", Codigo: "ETH"}))
+}
+
+func TestPostgresCryptoRepository_FindAllByFilter_NumeraLosMarcadores(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresCryptoRepository(db)
+	nombre, fuente, fiat := "bit", "criptoya", "USD"
+	fecha := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE 1=1 AND cm.nombre ILIKE $1 AND c.fuente = $2 AND c.fiat = $3 LIMIT $4 OFFSET $5")).
+		WithArgs("%bit%", "criptoya", "USD", 10, 10).
+		WillReturnRows(sqlmock.NewRows(columnasCotizacionMock).
+			AddRow(1, 60000.0, "USD", fecha, 1, "criptoya", "binance", nil, 0.5, 60200.0, 60000.0))
+	// el resumen repite las condiciones sin la paginación
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE 1=1 AND cm.nombre ILIKE $1 AND c.fuente = $2 AND c.fiat = $3\n\t\tGROUP BY c.fuente")).
+		WithArgs("%bit%", "criptoya", "USD").
+		WillReturnRows(sqlmock.NewRows([]string{"fuente", "count", "min", "max", "avg", "max"}).
+			AddRow("criptoya", 1, 60000.0, 60000.0, 60000.0, fecha))
+
+	cotizaciones, summary, err := repo.FindAllByFilter(criptomonedas.CriptoMonedaFilter{
+		Nombre: &nombre, Fuente: &fuente, Fiat: &fiat, PageNumber: 2, PageSize: 10, AgruparPorFuente: true,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, cotizaciones, 1)
+	assert.Equal(t, "binance", cotizaciones[0].Exchange)
+	assert.Nil(t, cotizaciones[0].FechaProveedor)
+	require.Len(t, summary.PorFuente, 1)
+	assert.Equal(t, fecha, summary.PorFuente[0].Ultima)
+}
+
+func TestPostgresCryptoRepository_FindAllByFilterForUser_UsaJsonAgg(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresCryptoRepository(db)
+	fiat := "ARS"
+	minima := 1000.0
+	fecha := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`json_agg\(c\.cotizacion\), json_agg\(c\.fecha\), json_agg\(cm\.nombre\)(.|\n)*`+
+		regexp.QuoteMeta("WHERE um.usuario_id = $1 AND c.fiat = $2 AND c.cotizacion >= $3 GROUP BY c.id LIMIT $4 OFFSET $5")).
+		WithArgs(5, "ARS", 1000.0, 20, 0).
+		WillReturnRows(sqlmock.NewRows(append(columnasCotizacionMock, "valores", "fechas", "nombres")).
+			AddRow(1, 55000000.0, "ARS", fecha, 1, "criptoya", "", nil, 0.0, 0.0, 0.0,
+				`[55000000.00]`, `["2024-05-01T12:00:00+00:00"]`, `["Bitcoin"]`))
+
+	cotizaciones, summary, err := repo.FindAllByFilterForUser(criptomonedas.CriptoMonedaFilter{
+		Fiat: &fiat, MinCotizacion: &minima, PageNumber: 1, PageSize: 20,
+	}, 5)
+
+	require.NoError(t, err)
+	require.Len(t, cotizaciones, 1)
+	assert.Equal(t, []float64{55000000}, summary.CotizacionesValores)
+	assert.Equal(t, []string{"2024-05-01T12:00:00+00:00"}, summary.CotizacionesFechas)
+	assert.Equal(t, []string{"Bitcoin"}, summary.CriptoNombres)
+	assert.Equal(t, 1, summary.TotalResults)
+}
+
+func TestPostgresCryptoRepository_FindUltimaCotizacion_SinCotizaciones(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresCryptoRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE cm.nombre = $1 AND ($2::VARCHAR = '' OR c.fiat = $2)")).
+		WithArgs("Bitcoin", "").
+		WillReturnRows(sqlmock.NewRows(columnasCotizacionMock))
+
+	cotizacion, err := repo.FindUltimaCotizacion("Bitcoin", "")
+
+	assert.NoError(t, err)
+	assert.Nil(t, cotizacion)
+}
+
+func TestPostgresExchangeRepository_SaveCotizacionesExchange_UpsertConReturning(t *testing.T) {
+	db, mock := nuevoMockPostgres(t)
+	repo := repositories.NewPostgresExchangeRepository(db)
+	fecha := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (nombre) DO UPDATE SET nombre = EXCLUDED.nombre RETURNING id")).
+		WithArgs("binance").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta("VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)")).
+		WithArgs(1, 4, "ARS", 100.0, 101.0, 99.0, 98.0, 0.1, fecha, fecha).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.SaveCotizacionesExchange([]criptomonedas.CotizacionExchange{{
+		CriptoMoneda_ID: 1, Exchange: "binance", Fiat: "ARS", Ask: 100, TotalAsk: 101, Bid: 99, TotalBid: 98, Volumen: 0.1,
+		FechaProveedor: fecha, Fecha: fecha,
+	}})
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"primerProjecto/internal/adapters/cotizadores"
+	mockCotizador "primerProjecto/internal/adapters/cotizadores/mock"
+	mockRepo "primerProjecto/internal/adapters/repositories/mock"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/services"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// monedasRefresco son las monedas registradas en las pruebas de refresco. CoinPaprika no conoce Monedita.
+var monedasRefresco = []*criptomonedas.CriptoMoneda{
+	{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"},
+	{Id: 2, Nombre: "Ethereum", Codigo: "ETH"},
+	{Id: 3, Nombre: "Monedita", Codigo: "MNT"},
+}
+
+func TestCoinPaprikaCotizador_Batch(t *testing.T) {
+	var consultas int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/v1/coins":
+			fmt.Fprint(w, `[
+				{"id":"btc-bitcoin","name":"Bitcoin","symbol":"BTC","rank":1,"is_active":true},
+				{"id":"eth-ethereum","name":"Ethereum","symbol":"ETH","rank":2,"is_active":true}
+			]`)
+		case "/v1/tickers?quotes=USD":
+			atomic.AddInt32(&consultas, 1)
+			http.ServeFile(w, r, filepath.Join("testdata", "coinpaprika_tickers.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	cotizador := cotizadores.NewCoinPaprikaCotizador(server.Client(), server.URL, time.Second)
+
+	cotizaciones, err := cotizador.GetCotizacionesExternas(context.Background(), monedasSinPuntero(monedasRefresco), "usd")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), consultas)
+	// Monedita no está en CoinPaprika y queda fuera del resultado
+	assert.Len(t, cotizaciones, 2)
+	assert.Equal(t, 67187.34, cotizaciones["Bitcoin"].Cotizacion)
+	assert.Equal(t, 3321.05, cotizaciones["Ethereum"].Cotizacion)
+	assert.Equal(t, "coinpaprika", cotizaciones["Ethereum"].Fuente)
+	assert.Equal(t, time.Date(2024, 7, 29, 12, 0, 0, 0, time.UTC), *cotizaciones["Bitcoin"].FechaProveedor)
+
+	_, err = cotizadores.GetCotizadorBatch("criptoya")
+	assert.NotNil(t, err)
+}
+
+func monedasSinPuntero(monedas []*criptomonedas.CriptoMoneda) []criptomonedas.CriptoMoneda {
+	valores := make([]criptomonedas.CriptoMoneda, len(monedas))
+	for i, moneda := range monedas {
+		valores[i] = *moneda
+	}
+	return valores
+}
+
+func TestRefrescarCotizaciones_Lote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	batch := mockCotizador.NewMockBatchCotizador(ctrl)
+	getBatch := func(name string) (cotizadores.BatchCotizador, error) {
+		return batch, nil
+	}
+	cs := services.NewCryptoService(repoCripto, cotizadores.GetCotizador).ConCotizadorBatch(getBatch)
+	// Bitcoin se pidió dos veces: se busca dos veces y se cotiza una
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasRefresco[0], nil).Times(2)
+	repoCripto.EXPECT().FindCryptoByName("Inexistente").Return(nil, nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasRefresco[1], nil)
+	batch.EXPECT().GetCotizacionesExternas(gomock.Any(), monedasSinPuntero(monedasRefresco[:2]), "ARS").Return(map[string]criptomonedas.Cotizacion{
+		"Bitcoin": {Cotizacion: 65000000, Fuente: "coinpaprika"},
+	}, nil)
+	repoCripto.EXPECT().SaveCotizaciones([]criptomonedas.Cotizacion{
+		{CriptoMoneda_ID: 1, Cotizacion: 65000000, Fiat: "ARS", Fuente: "coinpaprika"},
+	}).Return(nil)
+
+	reporte, err := cs.RefrescarCotizaciones(context.Background(), services.PedidoRefresco{
+		Monedas: []string{"Bitcoin", "Inexistente", "Ethereum", "Bitcoin"},
+		Fiat:    "ars",
+	})
+	assert.Nil(t, err)
+	assert.True(t, reporte.Lote)
+	assert.Equal(t, "coinpaprika", reporte.Api)
+	assert.Equal(t, 1, reporte.Guardadas)
+	assert.Equal(t, 2, reporte.Fallidas)
+	if assert.Len(t, reporte.Resultados, 3) {
+		assert.Equal(t, "Bitcoin", reporte.Resultados[0].Moneda)
+		assert.Equal(t, 65000000.0, reporte.Resultados[0].Cotizacion.Cotizacion)
+		assert.Contains(t, reporte.Resultados[1].Error, "no está registrada")
+		assert.Equal(t, "Ethereum", reporte.Resultados[2].Moneda)
+		assert.Contains(t, reporte.Resultados[2].Error, "no informó")
+	}
+}
+
+func TestRefrescarCotizaciones_DeAUna(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	cotizador := mockCotizador.NewMockCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		return cotizador, nil
+	}
+	getBatch := func(name string) (cotizadores.BatchCotizador, error) {
+		return nil, errors.New("no cotiza varias monedas")
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConCotizadorBatch(getBatch)
+	repoCripto.EXPECT().FindAllMonedas().Return(monedasRefresco, nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Bitcoin", "BTC", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 50000}, nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Ethereum", "ETH", "USD", 0.0).Return(criptomonedas.Cotizacion{Cotizacion: 3000}, nil)
+	cotizador.EXPECT().GetCotizacionExterna(gomock.Any(), "Monedita", "MNT", "USD", 0.0).Return(criptomonedas.Cotizacion{}, errors.New("moneda desconocida"))
+	repoCripto.EXPECT().SaveCotizaciones(gomock.Len(2)).Return(nil)
+
+	// criptoya no cotiza en lote, se consulta una moneda por vez
+	reporte, err := cs.RefrescarCotizaciones(context.Background(), services.PedidoRefresco{Api: "criptoya"})
+	assert.Nil(t, err)
+	assert.False(t, reporte.Lote)
+	assert.Equal(t, 2, reporte.Guardadas)
+	assert.Equal(t, 1, reporte.Fallidas)
+	assert.Equal(t, "criptoya", reporte.Resultados[0].Cotizacion.Fuente)
+	assert.Equal(t, "moneda desconocida", reporte.Resultados[2].Error)
+}
+
+func TestRefrescarCotizaciones_Errores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repoCripto := mockRepo.NewMockCryptoRepository(ctrl)
+	batch := mockCotizador.NewMockBatchCotizador(ctrl)
+	getCotizador := func(name string) (cotizadores.Cotizador, error) {
+		if name == "desconocida" {
+			return nil, errors.New("cotizador desconocida no soportado")
+		}
+		return mockCotizador.NewMockCotizador(ctrl), nil
+	}
+	getBatch := func(name string) (cotizadores.BatchCotizador, error) {
+		return batch, nil
+	}
+	cs := services.NewCryptoService(repoCripto, getCotizador).ConCotizadorBatch(getBatch)
+
+	_, err := cs.RefrescarCotizaciones(context.Background(), services.PedidoRefresco{Api: "desconocida"})
+	assert.ErrorIs(t, err, services.ErrRefrescoInvalido)
+	_, err = cs.RefrescarCotizaciones(context.Background(), services.PedidoRefresco{Monedas: []string{" "}})
+	assert.ErrorIs(t, err, services.ErrRefrescoInvalido)
+
+	// si la transacción falla no se guarda ninguna
+	repoCripto.EXPECT().FindCryptoByName("Bitcoin").Return(monedasRefresco[0], nil)
+	repoCripto.EXPECT().FindCryptoByName("Ethereum").Return(monedasRefresco[1], nil)
+	batch.EXPECT().GetCotizacionesExternas(gomock.Any(), gomock.Any(), "USD").Return(map[string]criptomonedas.Cotizacion{
+		"Bitcoin":  {Cotizacion: 50000},
+		"Ethereum": {Cotizacion: 3000},
+	}, nil)
+	repoCripto.EXPECT().SaveCotizaciones(gomock.Len(2)).Return(errors.New("deadlock"))
+	_, err = cs.RefrescarCotizaciones(context.Background(), services.PedidoRefresco{Monedas: []string{"Bitcoin", "Ethereum"}})
+	assert.ErrorContains(t, err, "deadlock")
+}
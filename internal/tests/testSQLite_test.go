@@ -0,0 +1,317 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"primerProjecto/internal/adapters/repositories"
+	"primerProjecto/internal/entities/criptomonedas"
+	"primerProjecto/internal/migrations"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nuevaBaseSQLite abre una base SQLite en memoria con todas las migraciones aplicadas.
+func nuevaBaseSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := repositories.AbrirSQLite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrador, err := migrations.NewMigrador(db, migrations.DialectoSQLite)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+func TestMigrador_SQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := repositories.AbrirSQLite(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	migrador, err := migrations.NewMigrador(db, migrations.DialectoSQLite)
+	require.NoError(t, err)
+	total := len(migrador.Migraciones())
+
+	assert.ErrorIs(t, migrador.Verificar(ctx), migrations.ErrEsquemaDesactualizado)
+
+	subidas, err := migrador.Subir(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subidas, total)
+	assert.NoError(t, migrador.Verificar(ctx))
+	subidas, err = migrador.Subir(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, subidas)
+
+	bajadas, err := migrador.Bajar(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, bajadas, 2)
+	assert.Equal(t, total, bajadas[0].Version)
+	assert.Equal(t, total-1, bajadas[1].Version)
+	assert.ErrorIs(t, migrador.Verificar(ctx), migrations.ErrEsquemaDesactualizado)
+
+	estados, err := migrador.Estado(ctx)
+	require.NoError(t, err)
+	require.Len(t, estados, total)
+	assert.NotNil(t, estados[0].Aplicada)
+	assert.Nil(t, estados[total-1].Aplicada)
+
+	// las migraciones se pueden revertir todas y volver a aplicar
+	_, err = migrador.Bajar(ctx, total)
+	require.NoError(t, err)
+	subidas, err = migrador.Subir(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subidas, total)
+	assert.NoError(t, migrador.Verificar(ctx))
+}
+
+func TestMigrador_SQLite_Sucia(t *testing.T) {
+	ctx := context.Background()
+	db := nuevaBaseSQLite(t)
+	migrador, err := migrations.NewMigrador(db, migrations.DialectoSQLite)
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE schema_migrations SET sucia = TRUE WHERE version = 2")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, migrador.Verificar(ctx), migrations.ErrMigracionSucia)
+	_, err = migrador.Subir(ctx)
+	assert.ErrorIs(t, err, migrations.ErrMigracionSucia)
+	_, err = migrador.Bajar(ctx, 1)
+	assert.ErrorIs(t, err, migrations.ErrMigracionSucia)
+}
+
+// esquemaBase es el esquema que creaba la aplicación antes de las migraciones, sin las columnas que se
+// agregaron después.
+const esquemaBase = `
+CREATE TABLE monedas (id INTEGER PRIMARY KEY AUTOINCREMENT, nombre VARCHAR(100) NOT NULL);
+CREATE TABLE usuarios (
+	id INTEGER PRIMARY KEY AUTOINCREMENT, nombre VARCHAR(100) NOT NULL, apellidos VARCHAR(100) NOT NULL,
+	fecha_nacimiento DATE NOT NULL, codigo_usuario VARCHAR(100) NOT NULL UNIQUE, email VARCHAR(255) NOT NULL UNIQUE,
+	tipo_documento VARCHAR(20) NOT NULL, fecha_registro DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	esta_activo BOOLEAN NOT NULL DEFAULT TRUE
+);
+CREATE TABLE cotizaciones (
+	id INTEGER PRIMARY KEY AUTOINCREMENT, cripto_id INTEGER NOT NULL REFERENCES monedas(id), cotizacion REAL NOT NULL,
+	fecha DATETIME NOT NULL, manual BOOLEAN NOT NULL DEFAULT FALSE, usuario_id INTEGER DEFAULT NULL REFERENCES usuarios(id)
+);
+CREATE TABLE usuario_moneda (
+	usuario_id INTEGER NOT NULL REFERENCES usuarios(id), moneda_id INTEGER NOT NULL REFERENCES monedas(id),
+	PRIMARY KEY (usuario_id, moneda_id)
+);
+CREATE TABLE auditoria_cotizacion (
+	id INTEGER PRIMARY KEY AUTOINCREMENT, usuario_id INTEGER, cotizacion_id INTEGER, log TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO monedas (id, nombre) VALUES (1, 'Bitcoin');
+INSERT INTO usuarios (id, nombre, apellidos, fecha_nacimiento, codigo_usuario, email, tipo_documento)
+	VALUES (1, 'Ana', 'Gomez', '1990-05-15', 'A1', 'ana@example.com', 'DNI');
+INSERT INTO cotizaciones (id, cripto_id, cotizacion, fecha, manual, usuario_id) VALUES (1, 1, 60000, '2024-05-01 12:00:00', TRUE, 1);
+`
+
+func TestMigrador_SQLite_AdoptaBaseSinMigraciones(t *testing.T) {
+	ctx := context.Background()
+	db, err := repositories.AbrirSQLite(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(esquemaBase)
+	require.NoError(t, err)
+	migrador, err := migrations.NewMigrador(db, migrations.DialectoSQLite)
+	require.NoError(t, err)
+
+	_, err = migrador.Subir(ctx)
+
+	require.NoError(t, err)
+	assert.NoError(t, migrador.Verificar(ctx))
+	// las filas que ya existían toman los valores por defecto de las columnas nuevas
+	cotizacion, err := repositories.NewSQLiteCryptoRepository(db).FindByCotizacionID(1)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", cotizacion.Fiat)
+	assert.Equal(t, criptomonedas.FuenteManual, cotizacion.Fuente)
+	usuario, err := repositories.NewSQLiteUsuarioRepository(db).FindUsuarioById(1)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", usuario.FiatPreferida)
+	moneda, err := repositories.NewSQLiteCryptoRepository(db).FindByMonedaID(1)
+	require.NoError(t, err)
+	assert.Equal(t, "", moneda.Codigo)
+	var indices int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name IN ('idx_cotizaciones_fiat', 'idx_cotizaciones_fuente')").Scan(&indices))
+	assert.Equal(t, 2, indices)
+}
+
+func TestSQLiteCryptoRepository_Cotizaciones(t *testing.T) {
+	repo := repositories.NewSQLiteCryptoRepository(nuevaBaseSQLite(t))
+	require.NoError(t, repo.SaveMoneda(criptomonedas.CriptoMoneda{Nombre: "Bitcoin", Codigo: "BTC"}))
+	moneda, err := repo.FindCryptoByName("Bitcoin")
+	require.NoError(t, err)
+	require.NotNil(t, moneda)
+	assert.Equal(t, "BTC", moneda.Codigo)
+	porCodigo, err := repo.FindCryptoByCode("BTC")
+	require.NoError(t, err)
+	assert.Equal(t, moneda, porCodigo)
+	inexistente, err := repo.FindCryptoByName("Dogecoin")
+	assert.NoError(t, err)
+	assert.Nil(t, inexistente)
+
+	// las fechas en otra zona se guardan en UTC, así el orden no depende de la zona con la que llegaron
+	buenosAires := time.FixedZone("ART", -3*60*60)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	proveedor := base.Add(-time.Minute)
+	require.NoError(t, repo.SaveCotizaciones([]criptomonedas.Cotizacion{
+		{CriptoMoneda_ID: moneda.Id, Cotizacion: 60000, Fiat: "USD", Fecha: base, Fuente: "coinpaprika"},
+		{CriptoMoneda_ID: moneda.Id, Cotizacion: 60100, Fiat: "USD", Fecha: base.Add(time.Hour).In(buenosAires), Fuente: "criptoya",
+			Exchange: "binance", FechaProveedor: &proveedor, Volumen: 0.5, TotalAsk: 60200, TotalBid: 60000},
+		{CriptoMoneda_ID: moneda.Id, Cotizacion: 55000000, Fiat: "ARS", Fecha: base.Add(2 * time.Hour), Fuente: "criptoya"},
+	}))
+
+	ultima, err := repo.FindUltimaCotizacion("Bitcoin", "USD")
+	require.NoError(t, err)
+	require.NotNil(t, ultima)
+	assert.Equal(t, 60100.0, ultima.Cotizacion)
+	assert.True(t, base.Add(time.Hour).Equal(ultima.Fecha))
+	assert.Equal(t, "binance", ultima.Exchange)
+	require.NotNil(t, ultima.FechaProveedor)
+	assert.True(t, proveedor.Equal(*ultima.FechaProveedor))
+	assert.Equal(t, 0.5, ultima.Volumen)
+	assert.Equal(t, 60200.0, ultima.TotalAsk)
+
+	cualquiera, err := repo.FindUltimaCotizacion("Bitcoin", "")
+	require.NoError(t, err)
+	assert.Equal(t, "ARS", cualquiera.Fiat)
+
+	porFuente, err := repo.FindUltimasCotizacionesPorFuente("Bitcoin", "USD")
+	require.NoError(t, err)
+	require.Len(t, porFuente, 2)
+	assert.Equal(t, "coinpaprika", porFuente[0].Fuente)
+	assert.Equal(t, "criptoya", porFuente[1].Fuente)
+
+	recientes, err := repo.FindCotizacionesRecientes(moneda.Id, "USD", 10)
+	require.NoError(t, err)
+	require.Len(t, recientes, 2)
+	assert.Equal(t, 60100.0, recientes[0].Cotizacion)
+
+	fechas, err := repo.FindFechasCotizaciones(moneda.Id, "USD", base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, fechas, 2)
+
+	nombre, fiat := "Bit", "USD"
+	cotizaciones, summary, err := repo.FindAllByFilter(criptomonedas.CriptoMonedaFilter{
+		Nombre: &nombre, Fiat: &fiat, PageNumber: 1, PageSize: 10, AgruparPorFuente: true,
+	})
+	require.NoError(t, err)
+	assert.Len(t, cotizaciones, 2)
+	require.Len(t, summary.PorFuente, 2)
+	assert.Equal(t, "criptoya", summary.PorFuente[1].Fuente)
+	assert.Equal(t, 1, summary.PorFuente[1].Cantidad)
+	assert.True(t, base.Add(time.Hour).Equal(summary.PorFuente[1].Ultima))
+}
+
+func TestSQLiteCryptoRepository_Historicas(t *testing.T) {
+	repo := repositories.NewSQLiteCryptoRepository(nuevaBaseSQLite(t))
+	require.NoError(t, repo.SaveMoneda(criptomonedas.CriptoMoneda{Id: 7, Nombre: "Ethereum", Codigo: "ETH"}))
+	dia := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	historicas := []criptomonedas.Cotizacion{
+		{CriptoMoneda_ID: 7, Cotizacion: 2300, Fecha: dia, Fuente: "coingecko"},
+		{CriptoMoneda_ID: 7, Cotizacion: 2350, Fecha: dia.AddDate(0, 0, 1), Fuente: "coingecko"},
+	}
+
+	guardadas, err := repo.SaveCotizacionesHistoricas(historicas)
+	require.NoError(t, err)
+	assert.Equal(t, 2, guardadas)
+	// volver a cargar el mismo rango no duplica
+	guardadas, err = repo.SaveCotizacionesHistoricas(append(historicas, criptomonedas.Cotizacion{
+		CriptoMoneda_ID: 7, Cotizacion: 2400, Fecha: dia.AddDate(0, 0, 2), Fuente: "coingecko",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, guardadas)
+
+	todas, err := repo.FindAllCotizaciones()
+	require.NoError(t, err)
+	assert.Len(t, todas, 3)
+	assert.Equal(t, criptomonedas.FiatPorDefecto, todas[0].Fiat)
+}
+
+func TestSQLiteUsuarioRepository(t *testing.T) {
+	db := nuevaBaseSQLite(t)
+	repoUsuario := repositories.NewSQLiteUsuarioRepository(db)
+	repoCripto := repositories.NewSQLiteCryptoRepository(db)
+	require.NoError(t, repoCripto.SaveMoneda(criptomonedas.CriptoMoneda{Id: 1, Nombre: "Bitcoin", Codigo: "BTC"}))
+	require.NoError(t, repoCripto.SaveMoneda(criptomonedas.CriptoMoneda{Id: 2, Nombre: "Ethereum", Codigo: "ETH"}))
+
+	id, err := repoUsuario.SaveUsuario(criptomonedas.Usuario{
+		Nombre: "Ana", Apellidos: "Gomez", Fecha_Nacimiento: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC),
+		CodigoUsuario: "A1", Email: "ana@example.com", TipoDocumento: "DNI", Fecha_registro: time.Now(), Esta_activo: true, FiatPreferida: "ARS",
+	})
+	require.NoError(t, err)
+	usuario, err := repoUsuario.FindUsuarioById(id)
+	require.NoError(t, err)
+	require.NotNil(t, usuario)
+	assert.Equal(t, "Ana", usuario.Nombre)
+	assert.Equal(t, "ARS", usuario.FiatPreferida)
+	assert.True(t, usuario.Esta_activo)
+	assert.Equal(t, 1990, usuario.Fecha_Nacimiento.Year())
+
+	require.NoError(t, repoUsuario.PatchUsuarioByID(id, map[string]interface{}{"nombre": "Ana María"}))
+	usuario, err = repoUsuario.FindUsuarioById(id)
+	require.NoError(t, err)
+	assert.Equal(t, "Ana María", usuario.Nombre)
+
+	require.NoError(t, repoUsuario.UpdateMonedasDeInteres(id, []int{2, 1}))
+	monedas, err := repoUsuario.FindMonedasByUsuarioID(id)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, monedas)
+	usuarios, err := repoUsuario.FindUsuariosByMonedaID(2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{id}, usuarios)
+
+	// sin fiat en el filtro se ven las cotizaciones en la fiat preferida del usuario
+	fecha := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repoCripto.SaveCotizacion(criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: 60000, Fiat: "USD", Fecha: fecha}))
+	manual, err := repoCripto.GuardarCotizacionManual(id, criptomonedas.Cotizacion{CriptoMoneda_ID: 1, Cotizacion: 55000000, Fiat: "ARS", Fecha: fecha})
+	require.NoError(t, err)
+	require.NoError(t, repoUsuario.RegistrarAuditoria(id, manual.Id, "cotizacion manual"))
+
+	cotizaciones, summary, err := repoCripto.FindAllByFilterForUser(criptomonedas.CriptoMonedaFilter{PageNumber: 1, PageSize: 10}, id)
+	require.NoError(t, err)
+	require.Len(t, cotizaciones, 1)
+	assert.Equal(t, manual.Id, cotizaciones[0].Id)
+	assert.Equal(t, []float64{55000000}, summary.CotizacionesValores)
+	assert.Equal(t, []string{"Bitcoin"}, summary.CriptoNombres)
+
+	guardada, err := repoCripto.FindByCotizacionID(manual.Id)
+	require.NoError(t, err)
+	assert.True(t, guardada.Manual)
+	require.NotNil(t, guardada.UsuarioId)
+	assert.Equal(t, id, *guardada.UsuarioId)
+	assert.Equal(t, criptomonedas.FuenteManual, guardada.Fuente)
+
+	_, err = repoCripto.FindByCotizacionID(999)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSQLiteLiderazgoRepository(t *testing.T) {
+	repo := repositories.NewSQLiteLiderazgoRepository(nuevaBaseSQLite(t))
+
+	lider, err := repo.AdquirirLiderazgo(context.Background(), "trabajos", "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "a", lider.Instancia)
+
+	// mientras el lease de a no vence, b no lo toma
+	lider, err = repo.AdquirirLiderazgo(context.Background(), "trabajos", "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "a", lider.Instancia)
+
+	// un lease vencido lo toma otra instancia
+	lider, err = repo.AdquirirLiderazgo(context.Background(), "trabajos", "a", -time.Second)
+	require.NoError(t, err)
+	lider, err = repo.AdquirirLiderazgo(context.Background(), "trabajos", "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", lider.Instancia)
+
+	require.NoError(t, repo.LiberarLiderazgo("trabajos", "b"))
+	liberado, err := repo.FindLiderazgo("trabajos")
+	require.NoError(t, err)
+	assert.Nil(t, liberado)
+}